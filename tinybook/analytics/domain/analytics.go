@@ -0,0 +1,80 @@
+package domain
+
+import "time"
+
+// DayLayout 按天统计时的日期格式 使用服务器本地时区
+const DayLayout = "2006-01-02"
+
+// Ranges 看板支持的时间范围 单位为天
+var Ranges = []int{7, 30, 90}
+
+// Day t 所在的日期
+func Day(t time.Time) string {
+	return t.Format(DayLayout)
+}
+
+// Stats 一段时间内的数据 Readers 为独立读者数，只统计登录用户
+// 点赞和收藏是净变化 当天取消以前的点赞/收藏时可能为负数
+type Stats struct {
+	Reads    int64 `json:"reads"`
+	Readers  int64 `json:"readers"`
+	Likes    int64 `json:"likes"`
+	Collects int64 `json:"collects"`
+}
+
+// Sub 逐项相减
+func (s Stats) Sub(o Stats) Stats {
+	return Stats{
+		Reads:    s.Reads - o.Reads,
+		Readers:  s.Readers - o.Readers,
+		Likes:    s.Likes - o.Likes,
+		Collects: s.Collects - o.Collects,
+	}
+}
+
+// DailyStats 某一天的数据 Readers 为当天的独立读者数
+type DailyStats struct {
+	Day string `json:"day"`
+	Stats
+}
+
+// Dashboard 从 Start 到 End (含) 最近 Days 天的每日数据，没有数据的日子补 0
+// Total 为这段时间的合计，Previous 为之前相同天数的合计，Delta = Total - Previous
+// 合计中的 Readers 是整段时间内的独立读者数，不是每日读者数之和
+type Dashboard struct {
+	AuthorId  int64        `json:"authorId"`
+	ArticleId int64        `json:"articleId,omitempty"`
+	Days      int          `json:"days"`
+	Start     string       `json:"start"`
+	End       string       `json:"end"`
+	Series    []DailyStats `json:"series"`
+	Total     Stats        `json:"total"`
+	Previous  Stats        `json:"previous"`
+	Delta     Stats        `json:"delta"`
+}
+
+// Position 事件在 kafka 中的位置 重复投递的事件按位置去重，Topic 为空时不去重
+type Position struct {
+	Topic     string
+	Partition int
+	Offset    int64
+}
+
+// Read 一次阅读 Uid 为 0 表示未登录用户
+type Read struct {
+	ArticleId int64
+	AuthorId  int64
+	Uid       int64
+	Day       string
+	Position  Position
+}
+
+// Interaction 一次点赞/收藏的变化 取消时为 -1
+type Interaction struct {
+	ArticleId int64
+	AuthorId  int64
+	Day       string
+	Likes     int64
+	Collects  int64
+	Position  Position
+}
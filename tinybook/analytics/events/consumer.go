@@ -0,0 +1,149 @@
+package events
+
+import (
+	"context"
+	"github.com/bytedance/sonic"
+	"github.com/segmentio/kafka-go"
+	"go.uber.org/zap"
+	"time"
+	"tinybook/tinybook/analytics/domain"
+	"tinybook/tinybook/analytics/service"
+	"tinybook/tinybook/article/events/readcount"
+	"tinybook/tinybook/interactive/events/collect"
+	"tinybook/tinybook/interactive/events/rank"
+	"tinybook/tinybook/pkg/kafkax"
+)
+
+const (
+	GroupAnalyticsRead    = "group-analytics-read"
+	GroupAnalyticsLike    = "group-analytics-like"
+	GroupAnalyticsCollect = "group-analytics-collect"
+
+	// batchSize 一次汇总的最大事件数 batchWait 攒一批事件的最长等待时间
+	batchSize = 100
+	batchWait = 500 * time.Millisecond
+)
+
+// AnalyticsConsumer 消费阅读、点赞、收藏事件 按天汇总到作者的数据看板
+// 三个 topic 各用一个独立的消费者组，与互动服务的消费互不影响
+// 事件按批写入，位移异步提交，重复投递的事件由 dao 按分区位移去重
+type AnalyticsConsumer struct {
+	readReader    *kafka.Reader
+	likeReader    *kafka.Reader
+	collectReader *kafka.Reader
+	svc           service.AnalyticsService
+	log           *zap.Logger
+}
+
+func NewAnalyticsConsumer(svc service.AnalyticsService, brokers kafkax.Brokers, log *zap.Logger) *AnalyticsConsumer {
+	// 新的消费者组从头消费 把 kafka 中还保留的事件也汇总进来
+	return &AnalyticsConsumer{
+		readReader:    kafkax.NewGroupReader(brokers, GroupAnalyticsRead, readcount.TopicArticleRead, kafka.FirstOffset),
+		likeReader:    kafkax.NewGroupReader(brokers, GroupAnalyticsLike, rank.TopicInteractiveLikeRank, kafka.FirstOffset),
+		collectReader: kafkax.NewGroupReader(brokers, GroupAnalyticsCollect, collect.TopicArticleCollect, kafka.FirstOffset),
+		svc:           svc,
+		log:           log,
+	}
+}
+
+func (c *AnalyticsConsumer) Start() {
+	go func() {
+		c.Consume(context.Background(), c.readReader, c.handleReads)
+	}()
+	go func() {
+		c.Consume(context.Background(), c.likeReader, c.handleLikes)
+	}()
+	go func() {
+		c.Consume(context.Background(), c.collectReader, c.handleCollects)
+	}()
+}
+
+func (c *AnalyticsConsumer) Consume(ctx context.Context, reader *kafka.Reader,
+	handle func(ctx context.Context, msgs []kafka.Message) error) {
+	defer func(reader *kafka.Reader) {
+		err := reader.Close()
+		if err != nil {
+			c.log.Error("close kafka consumer failed", zap.Error(err))
+		}
+	}(reader)
+	topic := reader.Config().Topic
+	err := kafkax.BatchConsume(ctx, reader, batchSize, batchWait, handle, func(err error) {
+		c.log.Error("record analytics failed", zap.String("topic", topic), zap.Error(err))
+	})
+	c.log.Error("analytics consumer stopped", zap.String("topic", topic), zap.Error(err))
+}
+
+func (c *AnalyticsConsumer) handleReads(ctx context.Context, msgs []kafka.Message) error {
+	reads := make([]domain.Read, 0, len(msgs))
+	for _, message := range msgs {
+		var evt readcount.ReadEvent
+		if err := sonic.Unmarshal(message.Value, &evt); err != nil {
+			c.log.Error("consumer unmarshal message failed", zap.Error(err))
+			continue
+		}
+		reads = append(reads, domain.Read{
+			ArticleId: evt.ArticleID,
+			Uid:       evt.UserID,
+			Day:       c.day(message),
+			Position:  c.position(message),
+		})
+	}
+	return c.svc.RecordReads(ctx, reads)
+}
+
+func (c *AnalyticsConsumer) handleLikes(ctx context.Context, msgs []kafka.Message) error {
+	inters := make([]domain.Interaction, 0, len(msgs))
+	for _, message := range msgs {
+		var evt rank.LikeRankEvent
+		if err := sonic.Unmarshal(message.Value, &evt); err != nil {
+			c.log.Error("consumer unmarshal message failed", zap.Error(err))
+			continue
+		}
+		// 只刷新点赞榜的事件没有变化量
+		if evt.Delta == 0 {
+			continue
+		}
+		inters = append(inters, domain.Interaction{
+			ArticleId: evt.ArticleID,
+			Day:       c.day(message),
+			Likes:     evt.Delta,
+			Position:  c.position(message),
+		})
+	}
+	return c.svc.RecordInteractions(ctx, inters)
+}
+
+func (c *AnalyticsConsumer) handleCollects(ctx context.Context, msgs []kafka.Message) error {
+	inters := make([]domain.Interaction, 0, len(msgs))
+	for _, message := range msgs {
+		var evt collect.CollectEvent
+		if err := sonic.Unmarshal(message.Value, &evt); err != nil {
+			c.log.Error("consumer unmarshal message failed", zap.Error(err))
+			continue
+		}
+		inters = append(inters, domain.Interaction{
+			ArticleId: evt.ArticleID,
+			Day:       c.day(message),
+			Collects:  evt.Delta,
+			Position:  c.position(message),
+		})
+	}
+	return c.svc.RecordInteractions(ctx, inters)
+}
+
+// day 以消息写入kafka的时间所在的日期为准 延迟消费的事件也计入发生的那一天
+func (c *AnalyticsConsumer) day(message kafka.Message) string {
+	t := message.Time
+	if t.IsZero() {
+		t = time.Now()
+	}
+	return domain.Day(t)
+}
+
+func (c *AnalyticsConsumer) position(message kafka.Message) domain.Position {
+	return domain.Position{
+		Topic:     message.Topic,
+		Partition: message.Partition,
+		Offset:    message.Offset,
+	}
+}
@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"context"
+	"github.com/samber/lo"
+	"tinybook/tinybook/analytics/domain"
+	"tinybook/tinybook/analytics/repository/dao"
+)
+
+type AnalyticsRepository interface {
+	// AddReads 一批阅读按天汇总写入 已经汇总过的事件按位置跳过
+	AddReads(ctx context.Context, reads []domain.Read) error
+	AddInteractions(ctx context.Context, inters []domain.Interaction) error
+	// ArticleDaily start 到 end (含) 之间有数据的日子 按日期排列
+	ArticleDaily(ctx context.Context, artId int64, start string, end string) ([]domain.DailyStats, error)
+	AuthorDaily(ctx context.Context, authorId int64, start string, end string) ([]domain.DailyStats, error)
+	// ArticleReaders start 到 end (含) 之间的独立读者数
+	ArticleReaders(ctx context.Context, artId int64, start string, end string) (int64, error)
+	AuthorReaders(ctx context.Context, authorId int64, start string, end string) (int64, error)
+}
+
+type analyticsRepository struct {
+	dao dao.AnalyticsDAO
+}
+
+func NewAnalyticsRepository(dao dao.AnalyticsDAO) AnalyticsRepository {
+	return &analyticsRepository{dao: dao}
+}
+
+func (a *analyticsRepository) AddReads(ctx context.Context, reads []domain.Read) error {
+	return a.dao.AddReads(ctx, lo.Map(reads, func(read domain.Read, index int) dao.DailyRead {
+		return dao.DailyRead{
+			ArticleId: read.ArticleId,
+			AuthorId:  read.AuthorId,
+			Uid:       read.Uid,
+			Day:       read.Day,
+			Position:  a.toPosition(read.Position),
+		}
+	}))
+}
+
+func (a *analyticsRepository) AddInteractions(ctx context.Context, inters []domain.Interaction) error {
+	return a.dao.AddInteractions(ctx, lo.Map(inters, func(inter domain.Interaction, index int) dao.DailyInteraction {
+		return dao.DailyInteraction{
+			ArticleId:    inter.ArticleId,
+			AuthorId:     inter.AuthorId,
+			Day:          inter.Day,
+			LikeCount:    inter.Likes,
+			CollectCount: inter.Collects,
+			Position:     a.toPosition(inter.Position),
+		}
+	}))
+}
+
+func (a *analyticsRepository) ArticleDaily(ctx context.Context, artId int64, start string, end string) ([]domain.DailyStats, error) {
+	list, err := a.dao.GetByArticle(ctx, artId, start, end)
+	if err != nil {
+		return nil, err
+	}
+	return lo.Map(list, func(s dao.ArticleDailyStats, index int) domain.DailyStats {
+		return a.toDomain(s.Day, s.Counts)
+	}), nil
+}
+
+func (a *analyticsRepository) AuthorDaily(ctx context.Context, authorId int64, start string, end string) ([]domain.DailyStats, error) {
+	list, err := a.dao.GetByAuthor(ctx, authorId, start, end)
+	if err != nil {
+		return nil, err
+	}
+	return lo.Map(list, func(s dao.AuthorDailyStats, index int) domain.DailyStats {
+		return a.toDomain(s.Day, s.Counts)
+	}), nil
+}
+
+func (a *analyticsRepository) ArticleReaders(ctx context.Context, artId int64, start string, end string) (int64, error) {
+	return a.dao.CountArticleReaders(ctx, artId, start, end)
+}
+
+func (a *analyticsRepository) AuthorReaders(ctx context.Context, authorId int64, start string, end string) (int64, error) {
+	return a.dao.CountAuthorReaders(ctx, authorId, start, end)
+}
+
+func (a *analyticsRepository) toPosition(pos domain.Position) dao.Position {
+	return dao.Position{
+		Topic:     pos.Topic,
+		Partition: pos.Partition,
+		Offset:    pos.Offset,
+	}
+}
+
+func (a *analyticsRepository) toDomain(day string, counts dao.Counts) domain.DailyStats {
+	return domain.DailyStats{
+		Day: day,
+		Stats: domain.Stats{
+			Reads:    counts.ReadCount,
+			Readers:  counts.ReaderCount,
+			Likes:    counts.LikeCount,
+			Collects: counts.CollectCount,
+		},
+	}
+}
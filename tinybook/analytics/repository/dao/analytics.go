@@ -0,0 +1,383 @@
+package dao
+
+import (
+	"context"
+	"github.com/samber/lo"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+// Counts 每日的计数 嵌入到文章和作者的日统计表中
+type Counts struct {
+	ReadCount    int64 `gorm:"column:read_count;not null;default:0"`
+	ReaderCount  int64 `gorm:"column:reader_count;not null;default:0"`
+	LikeCount    int64 `gorm:"column:like_count;not null;default:0"`
+	CollectCount int64 `gorm:"column:collect_count;not null;default:0"`
+}
+
+// ArticleDailyStats 文章每天的数据 由阅读、点赞、收藏事件累加
+type ArticleDailyStats struct {
+	ID        int64  `gorm:"column:id;primaryKey;autoIncrement;not null"`
+	ArticleId int64  `gorm:"column:article_id;not null;uniqueIndex:uk_article_day,priority:1"`
+	AuthorId  int64  `gorm:"column:author_id;not null"`
+	Day       string `gorm:"column:day;type:char(10);not null;uniqueIndex:uk_article_day,priority:2"`
+	Counts    `gorm:"embedded"`
+	Ctime     int64 `gorm:"column:ctime;not null"`
+	Utime     int64 `gorm:"column:utime;not null"`
+}
+
+// AuthorDailyStats 作者全部文章每天的数据
+type AuthorDailyStats struct {
+	ID       int64  `gorm:"column:id;primaryKey;autoIncrement;not null"`
+	AuthorId int64  `gorm:"column:author_id;not null;uniqueIndex:uk_author_day,priority:1"`
+	Day      string `gorm:"column:day;type:char(10);not null;uniqueIndex:uk_author_day,priority:2"`
+	Counts   `gorm:"embedded"`
+	Ctime    int64 `gorm:"column:ctime;not null"`
+	Utime    int64 `gorm:"column:utime;not null"`
+}
+
+// ArticleDailyReader 文章每天的读者 用于按天去重和统计一段时间内的独立读者
+type ArticleDailyReader struct {
+	ID        int64  `gorm:"column:id;primaryKey;autoIncrement;not null"`
+	ArticleId int64  `gorm:"column:article_id;not null;uniqueIndex:uk_article_day_uid,priority:1"`
+	Day       string `gorm:"column:day;type:char(10);not null;uniqueIndex:uk_article_day_uid,priority:2"`
+	Uid       int64  `gorm:"column:uid;not null;uniqueIndex:uk_article_day_uid,priority:3"`
+}
+
+// AuthorDailyReader 作者每天的读者 读了作者任意一篇文章都算
+type AuthorDailyReader struct {
+	ID       int64  `gorm:"column:id;primaryKey;autoIncrement;not null"`
+	AuthorId int64  `gorm:"column:author_id;not null;uniqueIndex:uk_author_day_uid,priority:1"`
+	Day      string `gorm:"column:day;type:char(10);not null;uniqueIndex:uk_author_day_uid,priority:2"`
+	Uid      int64  `gorm:"column:uid;not null;uniqueIndex:uk_author_day_uid,priority:3"`
+}
+
+// AnalyticsConsumedOffset 每个分区已经汇总过的最大位移 重复投递的事件按位移跳过
+type AnalyticsConsumedOffset struct {
+	ID        int64  `gorm:"column:id;primaryKey;autoIncrement;not null"`
+	Topic     string `gorm:"column:topic;type:varchar(255);not null;uniqueIndex:uk_topic_partition,priority:1"`
+	Partition int    `gorm:"column:partition;not null;uniqueIndex:uk_topic_partition,priority:2"`
+	Offset    int64  `gorm:"column:offset;not null"`
+	Utime     int64  `gorm:"column:utime;not null"`
+}
+
+// Position 事件在 kafka 中的位置 Topic 为空时不去重
+type Position struct {
+	Topic     string
+	Partition int
+	Offset    int64
+}
+
+// DailyRead 一次阅读 Uid 为 0 表示未登录用户
+type DailyRead struct {
+	ArticleId int64
+	AuthorId  int64
+	Uid       int64
+	Day       string
+	Position
+}
+
+// DailyInteraction 一次点赞/收藏的变化
+type DailyInteraction struct {
+	ArticleId    int64
+	AuthorId     int64
+	Day          string
+	LikeCount    int64
+	CollectCount int64
+	Position
+}
+
+type AnalyticsDAO interface {
+	// AddReads 累加阅读数 登录用户当天第一次阅读时累加读者数
+	// 一批阅读在一个事务中按天汇总写入，位移不大于已汇总位移的阅读会被跳过
+	AddReads(ctx context.Context, reads []DailyRead) error
+	// AddInteractions 累加点赞数和收藏数 去重方式与 AddReads 相同
+	AddInteractions(ctx context.Context, inters []DailyInteraction) error
+	// GetByArticle start 到 end (含) 之间有数据的日子 按日期排列
+	GetByArticle(ctx context.Context, artId int64, start string, end string) ([]ArticleDailyStats, error)
+	GetByAuthor(ctx context.Context, authorId int64, start string, end string) ([]AuthorDailyStats, error)
+	// CountArticleReaders start 到 end (含) 之间的独立读者数
+	CountArticleReaders(ctx context.Context, artId int64, start string, end string) (int64, error)
+	CountAuthorReaders(ctx context.Context, authorId int64, start string, end string) (int64, error)
+}
+
+type GormAnalyticsDAO struct {
+	db *gorm.DB
+}
+
+func NewGormAnalyticsDAO(db *gorm.DB) AnalyticsDAO {
+	return &GormAnalyticsDAO{db: db}
+}
+
+func (g *GormAnalyticsDAO) AddReads(ctx context.Context, reads []DailyRead) error {
+	if len(reads) == 0 {
+		return nil
+	}
+	now := time.Now().Unix()
+	return g.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		consumed, err := g.lockOffsets(tx, lo.Map(reads, func(read DailyRead, index int) Position {
+			return read.Position
+		}))
+		if err != nil {
+			return err
+		}
+		stats := newDailyCounts()
+		// 同一批里重复的读者只需要写一次
+		readers := make(map[DailyRead]struct{}, len(reads))
+		for _, read := range reads {
+			if !consumed.fresh(read.Position) {
+				continue
+			}
+			counts := Counts{ReadCount: 1}
+			authorCounts := Counts{ReadCount: 1}
+			key := DailyRead{ArticleId: read.ArticleId, AuthorId: read.AuthorId, Uid: read.Uid, Day: read.Day}
+			if _, ok := readers[key]; read.Uid > 0 && !ok {
+				readers[key] = struct{}{}
+				first, err := g.firstRead(tx, &ArticleDailyReader{ArticleId: read.ArticleId, Day: read.Day, Uid: read.Uid})
+				if err != nil {
+					return err
+				}
+				if first {
+					counts.ReaderCount = 1
+				}
+				first, err = g.firstRead(tx, &AuthorDailyReader{AuthorId: read.AuthorId, Day: read.Day, Uid: read.Uid})
+				if err != nil {
+					return err
+				}
+				if first {
+					authorCounts.ReaderCount = 1
+				}
+			}
+			stats.add(read.ArticleId, read.AuthorId, read.Day, counts, authorCounts)
+		}
+		err = g.upsert(tx, stats, now)
+		if err != nil {
+			return err
+		}
+		return g.saveOffsets(tx, consumed, now)
+	})
+}
+
+func (g *GormAnalyticsDAO) AddInteractions(ctx context.Context, inters []DailyInteraction) error {
+	if len(inters) == 0 {
+		return nil
+	}
+	now := time.Now().Unix()
+	return g.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		consumed, err := g.lockOffsets(tx, lo.Map(inters, func(inter DailyInteraction, index int) Position {
+			return inter.Position
+		}))
+		if err != nil {
+			return err
+		}
+		stats := newDailyCounts()
+		for _, inter := range inters {
+			if !consumed.fresh(inter.Position) {
+				continue
+			}
+			counts := Counts{LikeCount: inter.LikeCount, CollectCount: inter.CollectCount}
+			stats.add(inter.ArticleId, inter.AuthorId, inter.Day, counts, counts)
+		}
+		err = g.upsert(tx, stats, now)
+		if err != nil {
+			return err
+		}
+		return g.saveOffsets(tx, consumed, now)
+	})
+}
+
+func (g *GormAnalyticsDAO) GetByArticle(ctx context.Context, artId int64, start string, end string) ([]ArticleDailyStats, error) {
+	var list []ArticleDailyStats
+	err := g.db.WithContext(ctx).
+		Where("article_id = ? AND day BETWEEN ? AND ?", artId, start, end).
+		Order("day").
+		Find(&list).Error
+	return list, err
+}
+
+func (g *GormAnalyticsDAO) GetByAuthor(ctx context.Context, authorId int64, start string, end string) ([]AuthorDailyStats, error) {
+	var list []AuthorDailyStats
+	err := g.db.WithContext(ctx).
+		Where("author_id = ? AND day BETWEEN ? AND ?", authorId, start, end).
+		Order("day").
+		Find(&list).Error
+	return list, err
+}
+
+func (g *GormAnalyticsDAO) CountArticleReaders(ctx context.Context, artId int64, start string, end string) (int64, error) {
+	var count int64
+	err := g.db.WithContext(ctx).Model(&ArticleDailyReader{}).
+		Where("article_id = ? AND day BETWEEN ? AND ?", artId, start, end).
+		Distinct("uid").
+		Count(&count).Error
+	return count, err
+}
+
+func (g *GormAnalyticsDAO) CountAuthorReaders(ctx context.Context, authorId int64, start string, end string) (int64, error) {
+	var count int64
+	err := g.db.WithContext(ctx).Model(&AuthorDailyReader{}).
+		Where("author_id = ? AND day BETWEEN ? AND ?", authorId, start, end).
+		Distinct("uid").
+		Count(&count).Error
+	return count, err
+}
+
+// firstRead 记录当天的读者 返回是否是当天第一次阅读
+// 已经存在时 ON DUPLICATE KEY UPDATE id = id 不会修改记录，影响的行数为0
+func (g *GormAnalyticsDAO) firstRead(tx *gorm.DB, reader any) (bool, error) {
+	res := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(reader)
+	return res.RowsAffected == 1, res.Error
+}
+
+// partitionKey 位移按 topic 和分区记录
+type partitionKey struct {
+	topic     string
+	partition int
+}
+
+// consumedOffsets 事务开始时每个分区已经汇总过的位移，以及这一批处理后的最大位移
+type consumedOffsets struct {
+	committed map[partitionKey]int64
+	latest    map[partitionKey]int64
+}
+
+// fresh 位移大于已汇总的位移时返回 true，并记录这一批的最大位移
+func (c consumedOffsets) fresh(pos Position) bool {
+	if pos.Topic == "" {
+		return true
+	}
+	key := partitionKey{topic: pos.Topic, partition: pos.Partition}
+	if pos.Offset <= c.committed[key] {
+		return false
+	}
+	c.latest[key] = max(c.latest[key], pos.Offset)
+	return true
+}
+
+// lockOffsets 锁住这一批涉及的分区的位移记录 同一个分区的批次只能串行汇总
+// 消费者组重新分配分区后，新的消费者会等待旧的事务结束再判断是否重复
+func (g *GormAnalyticsDAO) lockOffsets(tx *gorm.DB, positions []Position) (consumedOffsets, error) {
+	res := consumedOffsets{
+		committed: make(map[partitionKey]int64),
+		latest:    make(map[partitionKey]int64),
+	}
+	for _, pos := range positions {
+		key := partitionKey{topic: pos.Topic, partition: pos.Partition}
+		if _, ok := res.committed[key]; ok || pos.Topic == "" {
+			continue
+		}
+		err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&AnalyticsConsumedOffset{
+			Topic:     pos.Topic,
+			Partition: pos.Partition,
+			Offset:    -1,
+		}).Error
+		if err != nil {
+			return consumedOffsets{}, err
+		}
+		var offset AnalyticsConsumedOffset
+		err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("topic = ? AND `partition` = ?", pos.Topic, pos.Partition).
+			First(&offset).Error
+		if err != nil {
+			return consumedOffsets{}, err
+		}
+		res.committed[key] = offset.Offset
+	}
+	return res, nil
+}
+
+func (g *GormAnalyticsDAO) saveOffsets(tx *gorm.DB, consumed consumedOffsets, now int64) error {
+	for key, offset := range consumed.latest {
+		err := tx.Model(&AnalyticsConsumedOffset{}).
+			Where("topic = ? AND `partition` = ?", key.topic, key.partition).
+			Updates(map[string]any{"offset": offset, "utime": now}).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type articleDay struct {
+	articleId int64
+	day       string
+}
+
+type authorDay struct {
+	authorId int64
+	day      string
+}
+
+// dailyCounts 一批事件按文章和作者每天汇总后的计数
+type dailyCounts struct {
+	articles map[articleDay]*ArticleDailyStats
+	authors  map[authorDay]*AuthorDailyStats
+}
+
+func newDailyCounts() dailyCounts {
+	return dailyCounts{
+		articles: make(map[articleDay]*ArticleDailyStats),
+		authors:  make(map[authorDay]*AuthorDailyStats),
+	}
+}
+
+func (d dailyCounts) add(artId int64, authorId int64, day string, counts Counts, authorCounts Counts) {
+	art, ok := d.articles[articleDay{articleId: artId, day: day}]
+	if !ok {
+		art = &ArticleDailyStats{ArticleId: artId, AuthorId: authorId, Day: day}
+		d.articles[articleDay{articleId: artId, day: day}] = art
+	}
+	art.Counts = art.Counts.plus(counts)
+	author, ok := d.authors[authorDay{authorId: authorId, day: day}]
+	if !ok {
+		author = &AuthorDailyStats{AuthorId: authorId, Day: day}
+		d.authors[authorDay{authorId: authorId, day: day}] = author
+	}
+	author.Counts = author.Counts.plus(authorCounts)
+}
+
+func (c Counts) plus(o Counts) Counts {
+	return Counts{
+		ReadCount:    c.ReadCount + o.ReadCount,
+		ReaderCount:  c.ReaderCount + o.ReaderCount,
+		LikeCount:    c.LikeCount + o.LikeCount,
+		CollectCount: c.CollectCount + o.CollectCount,
+	}
+}
+
+// upsert 把汇总后的计数累加到文章和作者的日统计中 各用一条多行的 INSERT ... ON DUPLICATE KEY UPDATE
+func (g *GormAnalyticsDAO) upsert(tx *gorm.DB, stats dailyCounts, now int64) error {
+	if len(stats.articles) == 0 {
+		return nil
+	}
+	articles := make([]ArticleDailyStats, 0, len(stats.articles))
+	for _, art := range stats.articles {
+		art.Ctime, art.Utime = now, now
+		articles = append(articles, *art)
+	}
+	err := tx.Clauses(clause.OnConflict{
+		DoUpdates: g.increments(now),
+	}).Create(&articles).Error
+	if err != nil {
+		return err
+	}
+	authors := make([]AuthorDailyStats, 0, len(stats.authors))
+	for _, author := range stats.authors {
+		author.Ctime, author.Utime = now, now
+		authors = append(authors, *author)
+	}
+	return tx.Clauses(clause.OnConflict{
+		DoUpdates: g.increments(now),
+	}).Create(&authors).Error
+}
+
+func (g *GormAnalyticsDAO) increments(now int64) clause.Set {
+	return clause.Assignments(map[string]any{
+		"read_count":    gorm.Expr("read_count + VALUES(read_count)"),
+		"reader_count":  gorm.Expr("reader_count + VALUES(reader_count)"),
+		"like_count":    gorm.Expr("like_count + VALUES(like_count)"),
+		"collect_count": gorm.Expr("collect_count + VALUES(collect_count)"),
+		"utime":         now,
+	})
+}
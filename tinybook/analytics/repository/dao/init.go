@@ -0,0 +1,11 @@
+package dao
+
+import "gorm.io/gorm"
+
+func CreateTableForAnalytics(db *gorm.DB) {
+	err := db.AutoMigrate(&ArticleDailyStats{}, &AuthorDailyStats{}, &ArticleDailyReader{}, &AuthorDailyReader{},
+		&AnalyticsConsumedOffset{})
+	if err != nil {
+		panic(err)
+	}
+}
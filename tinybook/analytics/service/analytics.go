@@ -0,0 +1,188 @@
+package service
+
+import (
+	"context"
+	"github.com/cockroachdb/errors"
+	"github.com/samber/lo"
+	"time"
+	"tinybook/tinybook/analytics/domain"
+	"tinybook/tinybook/analytics/repository"
+)
+
+var (
+	ErrInvalidRange     = errors.New("不支持的时间范围")
+	ErrArticleNotFound  = errors.New("文章不存在")
+	ErrNotArticleAuthor = errors.New("不是文章的作者")
+)
+
+// AuthorResolver 查询文章的作者 文章不存在时返回 ErrArticleNotFound
+type AuthorResolver interface {
+	Resolve(ctx context.Context, artId int64) (int64, error)
+}
+
+// AuthorResolverFunc 函数形式的 AuthorResolver
+type AuthorResolverFunc func(ctx context.Context, artId int64) (int64, error)
+
+func (f AuthorResolverFunc) Resolve(ctx context.Context, artId int64) (int64, error) {
+	return f(ctx, artId)
+}
+
+// AnalyticsService 作者的数据看板 由阅读、点赞、收藏事件按天汇总
+type AnalyticsService interface {
+	// RecordReads 记录一批阅读 查不到作者的文章会被忽略
+	RecordReads(ctx context.Context, reads []domain.Read) error
+	// RecordInteractions 记录一批点赞/收藏的变化 查不到作者的文章会被忽略
+	RecordInteractions(ctx context.Context, inters []domain.Interaction) error
+	// AuthorDashboard 作者全部文章最近 days 天的数据 days 只能是 domain.Ranges 中的值
+	AuthorDashboard(ctx context.Context, uid int64, days int) (domain.Dashboard, error)
+	// ArticleDashboard 文章最近 days 天的数据 只有作者本人可以查看
+	ArticleDashboard(ctx context.Context, uid int64, artId int64, days int) (domain.Dashboard, error)
+}
+
+type analyticsService struct {
+	repo     repository.AnalyticsRepository
+	resolver AuthorResolver
+}
+
+func NewAnalyticsService(repo repository.AnalyticsRepository, resolver AuthorResolver) AnalyticsService {
+	return &analyticsService{repo: repo, resolver: resolver}
+}
+
+func (s *analyticsService) RecordReads(ctx context.Context, reads []domain.Read) error {
+	authors := make(map[int64]int64, len(reads))
+	res := make([]domain.Read, 0, len(reads))
+	for _, read := range reads {
+		authorId, err := s.author(ctx, authors, read.ArticleId)
+		if err != nil {
+			return err
+		}
+		if authorId == 0 {
+			continue
+		}
+		read.AuthorId = authorId
+		res = append(res, read)
+	}
+	return s.repo.AddReads(ctx, res)
+}
+
+func (s *analyticsService) RecordInteractions(ctx context.Context, inters []domain.Interaction) error {
+	authors := make(map[int64]int64, len(inters))
+	res := make([]domain.Interaction, 0, len(inters))
+	for _, inter := range inters {
+		authorId, err := s.author(ctx, authors, inter.ArticleId)
+		if err != nil {
+			return err
+		}
+		if authorId == 0 {
+			continue
+		}
+		inter.AuthorId = authorId
+		res = append(res, inter)
+	}
+	return s.repo.AddInteractions(ctx, res)
+}
+
+// author 查询文章的作者 同一批事件中的文章只查一次，查不到的文章返回 0
+func (s *analyticsService) author(ctx context.Context, authors map[int64]int64, artId int64) (int64, error) {
+	if authorId, ok := authors[artId]; ok {
+		return authorId, nil
+	}
+	authorId, err := s.resolver.Resolve(ctx, artId)
+	if errors.Is(err, ErrArticleNotFound) {
+		authors[artId] = 0
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	authors[artId] = authorId
+	return authorId, nil
+}
+
+func (s *analyticsService) AuthorDashboard(ctx context.Context, uid int64, days int) (domain.Dashboard, error) {
+	dashboard, err := s.dashboard(days, func(start string, end string) ([]domain.DailyStats, error) {
+		return s.repo.AuthorDaily(ctx, uid, start, end)
+	}, func(start string, end string) (int64, error) {
+		return s.repo.AuthorReaders(ctx, uid, start, end)
+	})
+	if err != nil {
+		return domain.Dashboard{}, err
+	}
+	dashboard.AuthorId = uid
+	return dashboard, nil
+}
+
+func (s *analyticsService) ArticleDashboard(ctx context.Context, uid int64, artId int64, days int) (domain.Dashboard, error) {
+	if !lo.Contains(domain.Ranges, days) {
+		return domain.Dashboard{}, ErrInvalidRange
+	}
+	authorId, err := s.resolver.Resolve(ctx, artId)
+	if err != nil {
+		return domain.Dashboard{}, err
+	}
+	if authorId != uid {
+		return domain.Dashboard{}, ErrNotArticleAuthor
+	}
+	dashboard, err := s.dashboard(days, func(start string, end string) ([]domain.DailyStats, error) {
+		return s.repo.ArticleDaily(ctx, artId, start, end)
+	}, func(start string, end string) (int64, error) {
+		return s.repo.ArticleReaders(ctx, artId, start, end)
+	})
+	if err != nil {
+		return domain.Dashboard{}, err
+	}
+	dashboard.AuthorId, dashboard.ArticleId = uid, artId
+	return dashboard, nil
+}
+
+// dashboard 最近 days 天(含今天)的每日数据，以及与之前相同天数的对比
+// 一次查出两段时间的每日数据，独立读者数需要分别去重
+func (s *analyticsService) dashboard(days int,
+	daily func(start string, end string) ([]domain.DailyStats, error),
+	readers func(start string, end string) (int64, error)) (domain.Dashboard, error) {
+	if !lo.Contains(domain.Ranges, days) {
+		return domain.Dashboard{}, ErrInvalidRange
+	}
+	today := time.Now()
+	start := today.AddDate(0, 0, -(days - 1))
+	prevStart, prevEnd := today.AddDate(0, 0, -(2*days-1)), today.AddDate(0, 0, -days)
+	list, err := daily(domain.Day(prevStart), domain.Day(today))
+	if err != nil {
+		return domain.Dashboard{}, err
+	}
+	byDay := lo.SliceToMap(list, func(item domain.DailyStats) (string, domain.Stats) {
+		return item.Day, item.Stats
+	})
+	dashboard := domain.Dashboard{
+		Days:   days,
+		Start:  domain.Day(start),
+		End:    domain.Day(today),
+		Series: make([]domain.DailyStats, 0, days),
+	}
+	for i := 0; i < days; i++ {
+		day := domain.Day(start.AddDate(0, 0, i))
+		stats := byDay[day]
+		dashboard.Series = append(dashboard.Series, domain.DailyStats{Day: day, Stats: stats})
+		dashboard.Total = s.add(dashboard.Total, stats)
+		prev := byDay[domain.Day(prevStart.AddDate(0, 0, i))]
+		dashboard.Previous = s.add(dashboard.Previous, prev)
+	}
+	dashboard.Total.Readers, err = readers(dashboard.Start, dashboard.End)
+	if err != nil {
+		return domain.Dashboard{}, err
+	}
+	dashboard.Previous.Readers, err = readers(domain.Day(prevStart), domain.Day(prevEnd))
+	if err != nil {
+		return domain.Dashboard{}, err
+	}
+	dashboard.Delta = dashboard.Total.Sub(dashboard.Previous)
+	return dashboard, nil
+}
+
+// add 累加阅读、点赞、收藏数 独立读者数不能按天累加
+func (s *analyticsService) add(total domain.Stats, stats domain.Stats) domain.Stats {
+	total.Reads += stats.Reads
+	total.Likes += stats.Likes
+	total.Collects += stats.Collects
+	return total
+}
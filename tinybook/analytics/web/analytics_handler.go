@@ -0,0 +1,109 @@
+package web
+
+import (
+	"github.com/cockroachdb/errors"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"net/http"
+	"strconv"
+	"tinybook/tinybook/analytics/service"
+	"tinybook/tinybook/internal/web/jwt"
+)
+
+// AnalyticsHandler 作者的数据看板 只能查看自己的数据
+type AnalyticsHandler struct {
+	analyticsService service.AnalyticsService
+	l                *zap.Logger
+}
+
+func NewAnalyticsHandler(analyticsService service.AnalyticsService, l *zap.Logger) *AnalyticsHandler {
+	return &AnalyticsHandler{analyticsService: analyticsService, l: l}
+}
+
+// Author 当前作者全部文章的数据 days 为 7、30 或 90，默认 7
+func (h *AnalyticsHandler) Author(ctx *gin.Context) {
+	days, err := strconv.Atoi(ctx.DefaultQuery("days", "7"))
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 400,
+			Msg:  "参数错误",
+		})
+		return
+	}
+	claims := (ctx.MustGet("userClaims")).(jwt.UserClaims)
+	dashboard, err := h.analyticsService.AuthorDashboard(ctx, claims.Uid, days)
+	if err != nil {
+		h.handleServiceErr(ctx, err, "获取作者数据失败, 作者ID: "+strconv.FormatInt(claims.Uid, 10))
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Code: 200,
+		Msg:  "获取成功",
+		Data: dashboard,
+	})
+}
+
+// Article 单篇文章的数据 只有作者本人可以查看
+func (h *AnalyticsHandler) Article(ctx *gin.Context) {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 400,
+			Msg:  "参数错误",
+		})
+		return
+	}
+	days, err := strconv.Atoi(ctx.DefaultQuery("days", "7"))
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 400,
+			Msg:  "参数错误",
+		})
+		return
+	}
+	claims := (ctx.MustGet("userClaims")).(jwt.UserClaims)
+	dashboard, err := h.analyticsService.ArticleDashboard(ctx, claims.Uid, id, days)
+	if err != nil {
+		h.handleServiceErr(ctx, err, "获取文章数据失败, 文章ID: "+strconv.FormatInt(id, 10))
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Code: 200,
+		Msg:  "获取成功",
+		Data: dashboard,
+	})
+}
+
+func (h *AnalyticsHandler) handleServiceErr(ctx *gin.Context, err error, msg string) {
+	switch {
+	case errors.Is(err, service.ErrInvalidRange):
+		ctx.JSON(http.StatusOK, Result{
+			Code: 400,
+			Msg:  err.Error(),
+		})
+		return
+	case errors.Is(err, service.ErrNotArticleAuthor):
+		ctx.JSON(http.StatusOK, Result{
+			Code: 401,
+			Msg:  "无权限",
+		})
+		return
+	case errors.Is(err, service.ErrArticleNotFound):
+		ctx.JSON(http.StatusOK, Result{
+			Code: 404,
+			Msg:  err.Error(),
+		})
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Code: 500,
+		Msg:  "服务器错误",
+	})
+	h.l.Error(msg, zap.Error(err))
+}
+
+func (h *AnalyticsHandler) RegisterRoutes(engine *gin.Engine) {
+	group := engine.Group("/analytics")
+	group.GET("/author", h.Author)       // 作者全部文章的每日数据
+	group.GET("/article/:id", h.Article) // 单篇文章的每日数据
+}
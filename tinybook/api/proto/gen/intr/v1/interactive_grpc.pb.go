@@ -26,6 +26,16 @@ const (
 	InteractiveService_GetInteractive_FullMethodName    = "/intr.v1.InteractiveService/GetInteractive"
 	InteractiveService_GetLikeRanks_FullMethodName      = "/intr.v1.InteractiveService/GetLikeRanks"
 	InteractiveService_GetByIds_FullMethodName          = "/intr.v1.InteractiveService/GetByIds"
+	InteractiveService_Uncollect_FullMethodName         = "/intr.v1.InteractiveService/Uncollect"
+	InteractiveService_CreateFolder_FullMethodName      = "/intr.v1.InteractiveService/CreateFolder"
+	InteractiveService_RenameFolder_FullMethodName      = "/intr.v1.InteractiveService/RenameFolder"
+	InteractiveService_DeleteFolder_FullMethodName      = "/intr.v1.InteractiveService/DeleteFolder"
+	InteractiveService_ListFolders_FullMethodName       = "/intr.v1.InteractiveService/ListFolders"
+	InteractiveService_MoveItem_FullMethodName          = "/intr.v1.InteractiveService/MoveItem"
+	InteractiveService_ListFolderItems_FullMethodName   = "/intr.v1.InteractiveService/ListFolderItems"
+	InteractiveService_ListReadHistory_FullMethodName   = "/intr.v1.InteractiveService/ListReadHistory"
+	InteractiveService_DeleteReadHistory_FullMethodName = "/intr.v1.InteractiveService/DeleteReadHistory"
+	InteractiveService_ClearReadHistory_FullMethodName  = "/intr.v1.InteractiveService/ClearReadHistory"
 )
 
 // InteractiveServiceClient is the client API for InteractiveService service.
@@ -39,6 +49,18 @@ type InteractiveServiceClient interface {
 	GetInteractive(ctx context.Context, in *GetInteractiveRequest, opts ...grpc.CallOption) (*GetInteractiveResponse, error)
 	GetLikeRanks(ctx context.Context, in *GetLikeRanksRequest, opts ...grpc.CallOption) (*GetLikeRanksResponse, error)
 	GetByIds(ctx context.Context, in *GetByIdsRequest, opts ...grpc.CallOption) (*GetByIdsResponse, error)
+	Uncollect(ctx context.Context, in *UncollectRequest, opts ...grpc.CallOption) (*UncollectResponse, error)
+	// 收藏夹管理
+	CreateFolder(ctx context.Context, in *CreateFolderRequest, opts ...grpc.CallOption) (*CreateFolderResponse, error)
+	RenameFolder(ctx context.Context, in *RenameFolderRequest, opts ...grpc.CallOption) (*RenameFolderResponse, error)
+	DeleteFolder(ctx context.Context, in *DeleteFolderRequest, opts ...grpc.CallOption) (*DeleteFolderResponse, error)
+	ListFolders(ctx context.Context, in *ListFoldersRequest, opts ...grpc.CallOption) (*ListFoldersResponse, error)
+	MoveItem(ctx context.Context, in *MoveItemRequest, opts ...grpc.CallOption) (*MoveItemResponse, error)
+	ListFolderItems(ctx context.Context, in *ListFolderItemsRequest, opts ...grpc.CallOption) (*ListFolderItemsResponse, error)
+	// 阅读记录
+	ListReadHistory(ctx context.Context, in *ListReadHistoryRequest, opts ...grpc.CallOption) (*ListReadHistoryResponse, error)
+	DeleteReadHistory(ctx context.Context, in *DeleteReadHistoryRequest, opts ...grpc.CallOption) (*DeleteReadHistoryResponse, error)
+	ClearReadHistory(ctx context.Context, in *ClearReadHistoryRequest, opts ...grpc.CallOption) (*ClearReadHistoryResponse, error)
 }
 
 type interactiveServiceClient struct {
@@ -112,6 +134,96 @@ func (c *interactiveServiceClient) GetByIds(ctx context.Context, in *GetByIdsReq
 	return out, nil
 }
 
+func (c *interactiveServiceClient) Uncollect(ctx context.Context, in *UncollectRequest, opts ...grpc.CallOption) (*UncollectResponse, error) {
+	out := new(UncollectResponse)
+	err := c.cc.Invoke(ctx, InteractiveService_Uncollect_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *interactiveServiceClient) CreateFolder(ctx context.Context, in *CreateFolderRequest, opts ...grpc.CallOption) (*CreateFolderResponse, error) {
+	out := new(CreateFolderResponse)
+	err := c.cc.Invoke(ctx, InteractiveService_CreateFolder_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *interactiveServiceClient) RenameFolder(ctx context.Context, in *RenameFolderRequest, opts ...grpc.CallOption) (*RenameFolderResponse, error) {
+	out := new(RenameFolderResponse)
+	err := c.cc.Invoke(ctx, InteractiveService_RenameFolder_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *interactiveServiceClient) DeleteFolder(ctx context.Context, in *DeleteFolderRequest, opts ...grpc.CallOption) (*DeleteFolderResponse, error) {
+	out := new(DeleteFolderResponse)
+	err := c.cc.Invoke(ctx, InteractiveService_DeleteFolder_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *interactiveServiceClient) ListFolders(ctx context.Context, in *ListFoldersRequest, opts ...grpc.CallOption) (*ListFoldersResponse, error) {
+	out := new(ListFoldersResponse)
+	err := c.cc.Invoke(ctx, InteractiveService_ListFolders_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *interactiveServiceClient) MoveItem(ctx context.Context, in *MoveItemRequest, opts ...grpc.CallOption) (*MoveItemResponse, error) {
+	out := new(MoveItemResponse)
+	err := c.cc.Invoke(ctx, InteractiveService_MoveItem_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *interactiveServiceClient) ListFolderItems(ctx context.Context, in *ListFolderItemsRequest, opts ...grpc.CallOption) (*ListFolderItemsResponse, error) {
+	out := new(ListFolderItemsResponse)
+	err := c.cc.Invoke(ctx, InteractiveService_ListFolderItems_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *interactiveServiceClient) ListReadHistory(ctx context.Context, in *ListReadHistoryRequest, opts ...grpc.CallOption) (*ListReadHistoryResponse, error) {
+	out := new(ListReadHistoryResponse)
+	err := c.cc.Invoke(ctx, InteractiveService_ListReadHistory_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *interactiveServiceClient) DeleteReadHistory(ctx context.Context, in *DeleteReadHistoryRequest, opts ...grpc.CallOption) (*DeleteReadHistoryResponse, error) {
+	out := new(DeleteReadHistoryResponse)
+	err := c.cc.Invoke(ctx, InteractiveService_DeleteReadHistory_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *interactiveServiceClient) ClearReadHistory(ctx context.Context, in *ClearReadHistoryRequest, opts ...grpc.CallOption) (*ClearReadHistoryResponse, error) {
+	out := new(ClearReadHistoryResponse)
+	err := c.cc.Invoke(ctx, InteractiveService_ClearReadHistory_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // InteractiveServiceServer is the server API for InteractiveService service.
 // All implementations must embed UnimplementedInteractiveServiceServer
 // for forward compatibility
@@ -123,6 +235,18 @@ type InteractiveServiceServer interface {
 	GetInteractive(context.Context, *GetInteractiveRequest) (*GetInteractiveResponse, error)
 	GetLikeRanks(context.Context, *GetLikeRanksRequest) (*GetLikeRanksResponse, error)
 	GetByIds(context.Context, *GetByIdsRequest) (*GetByIdsResponse, error)
+	Uncollect(context.Context, *UncollectRequest) (*UncollectResponse, error)
+	// 收藏夹管理
+	CreateFolder(context.Context, *CreateFolderRequest) (*CreateFolderResponse, error)
+	RenameFolder(context.Context, *RenameFolderRequest) (*RenameFolderResponse, error)
+	DeleteFolder(context.Context, *DeleteFolderRequest) (*DeleteFolderResponse, error)
+	ListFolders(context.Context, *ListFoldersRequest) (*ListFoldersResponse, error)
+	MoveItem(context.Context, *MoveItemRequest) (*MoveItemResponse, error)
+	ListFolderItems(context.Context, *ListFolderItemsRequest) (*ListFolderItemsResponse, error)
+	// 阅读记录
+	ListReadHistory(context.Context, *ListReadHistoryRequest) (*ListReadHistoryResponse, error)
+	DeleteReadHistory(context.Context, *DeleteReadHistoryRequest) (*DeleteReadHistoryResponse, error)
+	ClearReadHistory(context.Context, *ClearReadHistoryRequest) (*ClearReadHistoryResponse, error)
 	mustEmbedUnimplementedInteractiveServiceServer()
 }
 
@@ -151,6 +275,36 @@ func (UnimplementedInteractiveServiceServer) GetLikeRanks(context.Context, *GetL
 func (UnimplementedInteractiveServiceServer) GetByIds(context.Context, *GetByIdsRequest) (*GetByIdsResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetByIds not implemented")
 }
+func (UnimplementedInteractiveServiceServer) Uncollect(context.Context, *UncollectRequest) (*UncollectResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Uncollect not implemented")
+}
+func (UnimplementedInteractiveServiceServer) CreateFolder(context.Context, *CreateFolderRequest) (*CreateFolderResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateFolder not implemented")
+}
+func (UnimplementedInteractiveServiceServer) RenameFolder(context.Context, *RenameFolderRequest) (*RenameFolderResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RenameFolder not implemented")
+}
+func (UnimplementedInteractiveServiceServer) DeleteFolder(context.Context, *DeleteFolderRequest) (*DeleteFolderResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteFolder not implemented")
+}
+func (UnimplementedInteractiveServiceServer) ListFolders(context.Context, *ListFoldersRequest) (*ListFoldersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListFolders not implemented")
+}
+func (UnimplementedInteractiveServiceServer) MoveItem(context.Context, *MoveItemRequest) (*MoveItemResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method MoveItem not implemented")
+}
+func (UnimplementedInteractiveServiceServer) ListFolderItems(context.Context, *ListFolderItemsRequest) (*ListFolderItemsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListFolderItems not implemented")
+}
+func (UnimplementedInteractiveServiceServer) ListReadHistory(context.Context, *ListReadHistoryRequest) (*ListReadHistoryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListReadHistory not implemented")
+}
+func (UnimplementedInteractiveServiceServer) DeleteReadHistory(context.Context, *DeleteReadHistoryRequest) (*DeleteReadHistoryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteReadHistory not implemented")
+}
+func (UnimplementedInteractiveServiceServer) ClearReadHistory(context.Context, *ClearReadHistoryRequest) (*ClearReadHistoryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ClearReadHistory not implemented")
+}
 func (UnimplementedInteractiveServiceServer) mustEmbedUnimplementedInteractiveServiceServer() {}
 
 // UnsafeInteractiveServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -290,6 +444,186 @@ func _InteractiveService_GetByIds_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _InteractiveService_Uncollect_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UncollectRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InteractiveServiceServer).Uncollect(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InteractiveService_Uncollect_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InteractiveServiceServer).Uncollect(ctx, req.(*UncollectRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InteractiveService_CreateFolder_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateFolderRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InteractiveServiceServer).CreateFolder(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InteractiveService_CreateFolder_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InteractiveServiceServer).CreateFolder(ctx, req.(*CreateFolderRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InteractiveService_RenameFolder_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RenameFolderRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InteractiveServiceServer).RenameFolder(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InteractiveService_RenameFolder_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InteractiveServiceServer).RenameFolder(ctx, req.(*RenameFolderRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InteractiveService_DeleteFolder_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteFolderRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InteractiveServiceServer).DeleteFolder(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InteractiveService_DeleteFolder_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InteractiveServiceServer).DeleteFolder(ctx, req.(*DeleteFolderRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InteractiveService_ListFolders_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListFoldersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InteractiveServiceServer).ListFolders(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InteractiveService_ListFolders_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InteractiveServiceServer).ListFolders(ctx, req.(*ListFoldersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InteractiveService_MoveItem_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MoveItemRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InteractiveServiceServer).MoveItem(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InteractiveService_MoveItem_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InteractiveServiceServer).MoveItem(ctx, req.(*MoveItemRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InteractiveService_ListFolderItems_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListFolderItemsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InteractiveServiceServer).ListFolderItems(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InteractiveService_ListFolderItems_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InteractiveServiceServer).ListFolderItems(ctx, req.(*ListFolderItemsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InteractiveService_ListReadHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListReadHistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InteractiveServiceServer).ListReadHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InteractiveService_ListReadHistory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InteractiveServiceServer).ListReadHistory(ctx, req.(*ListReadHistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InteractiveService_DeleteReadHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteReadHistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InteractiveServiceServer).DeleteReadHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InteractiveService_DeleteReadHistory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InteractiveServiceServer).DeleteReadHistory(ctx, req.(*DeleteReadHistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _InteractiveService_ClearReadHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ClearReadHistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InteractiveServiceServer).ClearReadHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: InteractiveService_ClearReadHistory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InteractiveServiceServer).ClearReadHistory(ctx, req.(*ClearReadHistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // InteractiveService_ServiceDesc is the grpc.ServiceDesc for InteractiveService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -325,6 +659,46 @@ var InteractiveService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetByIds",
 			Handler:    _InteractiveService_GetByIds_Handler,
 		},
+		{
+			MethodName: "Uncollect",
+			Handler:    _InteractiveService_Uncollect_Handler,
+		},
+		{
+			MethodName: "CreateFolder",
+			Handler:    _InteractiveService_CreateFolder_Handler,
+		},
+		{
+			MethodName: "RenameFolder",
+			Handler:    _InteractiveService_RenameFolder_Handler,
+		},
+		{
+			MethodName: "DeleteFolder",
+			Handler:    _InteractiveService_DeleteFolder_Handler,
+		},
+		{
+			MethodName: "ListFolders",
+			Handler:    _InteractiveService_ListFolders_Handler,
+		},
+		{
+			MethodName: "MoveItem",
+			Handler:    _InteractiveService_MoveItem_Handler,
+		},
+		{
+			MethodName: "ListFolderItems",
+			Handler:    _InteractiveService_ListFolderItems_Handler,
+		},
+		{
+			MethodName: "ListReadHistory",
+			Handler:    _InteractiveService_ListReadHistory_Handler,
+		},
+		{
+			MethodName: "DeleteReadHistory",
+			Handler:    _InteractiveService_DeleteReadHistory_Handler,
+		},
+		{
+			MethodName: "ClearReadHistory",
+			Handler:    _InteractiveService_ClearReadHistory_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "intr/v1/interactive.proto",
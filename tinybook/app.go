@@ -12,4 +12,5 @@ type App struct {
 	consumers []events.Consumer
 	cron      *cron.Cron
 	scheduler *job.Scheduler
+	searchJob *job.SearchIndexJob
 }
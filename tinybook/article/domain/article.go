@@ -1,26 +1,84 @@
 package domain
 
+import (
+	"encoding/base64"
+	"github.com/cockroachdb/errors"
+	"strconv"
+	"strings"
+	"time"
+	"tinybook/tinybook/pkg/mdx"
+)
+
+var ErrInvalidCursor = errors.New("分页游标不合法")
+
 type Article struct {
-	ID       int64         `json:"id"`
-	Title    string        `json:"title"`
-	Content  string        `json:"content"`
-	Abstract string        `json:"abstract"`
-	Author   Author        `json:"author"`
-	Status   ArticleStatus `json:"status"`
-	Ctime    int64         `json:"ctime"`
-	Utime    int64         `json:"utime"`
+	ID         int64         `json:"id"`
+	Title      string        `json:"title"`
+	Content    string        `json:"content"`
+	ContentKey string        `json:"contentKey,omitempty"` // 正文在对象存储中的 key 缓存中只保存 key
+	Abstract   string        `json:"abstract"`
+	Author     Author        `json:"author"`
+	Status     ArticleStatus `json:"status"`
+	RevisionId int64         `json:"revisionId"`
+	Version    int64         `json:"version"` // 乐观锁版本号
+	Category   string        `json:"category"`
+	Tags       []string      `json:"tags"`
+	// 以下字段由 Content(Markdown) 渲染得到
+	Html        string    `json:"html"`
+	WordCount   int       `json:"wordCount"`
+	ReadingTime int       `json:"readingTime"` // 预计阅读分钟数
+	Toc         []TocItem `json:"toc"`
+	Dtime       int64     `json:"dtime"` // 移入回收站的时间
+	Ctime       int64     `json:"ctime"`
+	Utime       int64     `json:"utime"`
+}
+
+// Render 由 Content 渲染出过滤过 XSS 的 Html，以及纯文本摘要、字数、阅读时间和目录
+func (a *Article) Render() error {
+	res, err := mdx.Render(a.Content)
+	if err != nil {
+		return err
+	}
+	a.Html = res.HTML
+	a.Abstract = res.Abstract
+	a.WordCount = res.WordCount
+	a.ReadingTime = res.ReadingTime
+	a.Toc = make([]TocItem, 0, len(res.TOC))
+	for _, h := range res.TOC {
+		a.Toc = append(a.Toc, TocItem{Level: h.Level, Title: h.Title, Anchor: h.Anchor})
+	}
+	return nil
+}
+
+// TocItem 文章目录中的一项 Anchor 为渲染后标题的 id
+type TocItem struct {
+	Level  int    `json:"level"`
+	Title  string `json:"title"`
+	Anchor string `json:"anchor"`
 }
 
 type ArticleVo struct {
-	ID         int64  `json:"id,omitempty"`
-	Title      string `json:"title,omitempty"`
-	Content    string `json:"content,omitempty"`
-	Abstract   string `json:"abstract,omitempty"`
-	Author     string `json:"author,omitempty"`
-	AuthorName string `json:"authorName,omitempty"`
-	Status     string `json:"status,omitempty"`
-	Ctime      string `json:"ctime,omitempty"`
-	Utime      string `json:"utime,omitempty"`
+	ID         int64    `json:"id,omitempty"`
+	Title      string   `json:"title,omitempty"`
+	Content    string   `json:"content,omitempty"`
+	Abstract   string   `json:"abstract,omitempty"`
+	Author     string   `json:"author,omitempty"`
+	AuthorName string   `json:"authorName,omitempty"`
+	Status     string   `json:"status,omitempty"`
+	RevisionId int64    `json:"revisionId,omitempty"`
+	Version    int64    `json:"version,omitempty"`
+	Category   string   `json:"category,omitempty"`
+	Tags       []string `json:"tags,omitempty"`
+	// Html 为渲染后的正文，Content 仍为 Markdown 原文
+	Html        string    `json:"html,omitempty"`
+	WordCount   int       `json:"wordCount,omitempty"`
+	ReadingTime int       `json:"readingTime,omitempty"`
+	Toc         []TocItem `json:"toc,omitempty"`
+	// 回收站中的文章才有，PurgeTime 之后会被彻底删除
+	Dtime     string `json:"dtime,omitempty"`
+	PurgeTime string `json:"purgeTime,omitempty"`
+	Ctime     string `json:"ctime,omitempty"`
+	Utime     string `json:"utime,omitempty"`
 
 	// 以下字段为interactive服务字段，用于前端展示
 	BizId        int64  `json:"bizId,omitempty"`
@@ -28,8 +86,67 @@ type ArticleVo struct {
 	ReadCount    int64  `json:"readCount,omitempty"`
 	LikeCount    int64  `json:"likeCount,omitempty"`
 	CollectCount int64  `json:"collectCount,omitempty"`
+	CommentCount int64  `json:"commentCount,omitempty"`
 	Liked        bool   `json:"liked,omitempty"`
 	Collected    bool   `json:"collected,omitempty"`
+
+	// 读者查看已发表的文章时，文章所在的系列以及前后相邻的文章
+	Series *SeriesNavVo `json:"series,omitempty"`
+}
+
+// Cursor 列表按 (utime, id) 倒序翻页的游标，零值表示第一页
+type Cursor struct {
+	Utime int64
+	ID    int64
+}
+
+// CursorOf 以文章作为下一页的起点
+func CursorOf(art Article) Cursor {
+	return Cursor{Utime: art.Utime, ID: art.ID}
+}
+
+func (c Cursor) IsZero() bool {
+	return c.Utime == 0 && c.ID == 0
+}
+
+// Encode 编码为对前端不透明的字符串，零值编码为空字符串
+func (c Cursor) Encode() string {
+	if c.IsZero() {
+		return ""
+	}
+	raw := strconv.FormatInt(c.Utime, 10) + "," + strconv.FormatInt(c.ID, 10)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// ParseCursor 解析 Encode 生成的游标，空字符串表示第一页
+func ParseCursor(s string) (Cursor, error) {
+	if s == "" {
+		return Cursor{}, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+	utime, id, ok := strings.Cut(string(raw), ",")
+	if !ok {
+		return Cursor{}, ErrInvalidCursor
+	}
+	var c Cursor
+	c.Utime, err = strconv.ParseInt(utime, 10, 64)
+	if err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+	c.ID, err = strconv.ParseInt(id, 10, 64)
+	if err != nil || c.Utime < 0 || c.ID < 0 {
+		return Cursor{}, ErrInvalidCursor
+	}
+	return c, nil
+}
+
+// ArticleListVo 游标分页的结果 NextCursor 为空表示没有更多数据
+type ArticleListVo struct {
+	Articles   []ArticleVo `json:"articles"`
+	NextCursor string      `json:"nextCursor,omitempty"`
 }
 
 type Author struct {
@@ -44,4 +161,79 @@ const (
 	ArticleStatusUnpublished
 	ArticleStatusPublished
 	ArticleStatusPrivate
+	ArticleStatusScheduled     // 定时发表中
+	ArticleStatusDeleted       // 在回收站中
+	ArticleStatusPendingReview // 命中敏感词 等待人工审核
+)
+
+// TrashRetention 文章在回收站中保留的时间，超过后不能恢复并会被定时任务彻底删除
+const TrashRetention = 30 * 24 * time.Hour
+
+const (
+	MaxArticleTags    = 5  // 每篇文章最多的标签数
+	MaxTagLength      = 32 // 标签最大长度(字符数)
+	MaxCategoryLength = 64
 )
+
+// MaxDiffLines 对比版本时每个版本正文的最大行数 对比的耗时与行数和差异的乘积成正比
+const MaxDiffLines = 5000
+
+// TagCount 标签云中的一项
+type TagCount struct {
+	Tag   string `json:"tag"`
+	Count int64  `json:"count"`
+}
+
+// ArticlePublishExecutor 定时发表任务的执行器名称
+const ArticlePublishExecutor = "article_publish"
+
+// ScheduledPublish 定时发表任务的参数
+type ScheduledPublish struct {
+	ArticleId int64 `json:"article_id"`
+	AuthorId  int64 `json:"author_id"`
+	PublishAt int64 `json:"publish_at"`
+}
+
+// JobName 每篇文章只有一个定时发表任务，用文章ID作为任务名
+func (s ScheduledPublish) JobName() string {
+	return ArticlePublishExecutor + ":" + strconv.FormatInt(s.ArticleId, 10)
+}
+
+// ArticleSearchVo 搜索结果 文章的 Title、Abstract、AuthorName 为高亮后的 HTML 片段
+type ArticleSearchVo struct {
+	Total    int         `json:"total"`
+	Articles []ArticleVo `json:"articles"`
+}
+
+// ArticleRevision 文章的历史版本
+type ArticleRevision struct {
+	ID        int64  `json:"id"`
+	ArticleID int64  `json:"articleId"`
+	Title     string `json:"title"`
+	Content   string `json:"content"`
+	Ctime     int64  `json:"ctime"`
+}
+
+type ArticleRevisionVo struct {
+	ID        int64  `json:"id,omitempty"`
+	ArticleID int64  `json:"articleId,omitempty"`
+	Title     string `json:"title,omitempty"`
+	Content   string `json:"content,omitempty"`
+	Current   bool   `json:"current,omitempty"`   // 是否为当前草稿版本
+	Published bool   `json:"published,omitempty"` // 是否为线上版本
+	Ctime     string `json:"ctime,omitempty"`
+}
+
+// DiffLine 版本对比中的一行 Op 为 equal/insert/delete
+type DiffLine struct {
+	Op   string `json:"op"`
+	Text string `json:"text"`
+}
+
+type ArticleRevisionDiffVo struct {
+	ArticleID int64      `json:"articleId"`
+	From      int64      `json:"from"`
+	To        int64      `json:"to"`
+	Title     []DiffLine `json:"title"`
+	Content   []DiffLine `json:"content"`
+}
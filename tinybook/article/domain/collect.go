@@ -0,0 +1,25 @@
+package domain
+
+// CollectFolderVo 收藏夹 id为0的是默认收藏夹
+type CollectFolderVo struct {
+	Id          int64  `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	ItemCount   int64  `json:"itemCount"`
+	Ctime       string `json:"ctime,omitempty"`
+	Utime       string `json:"utime,omitempty"`
+}
+
+// CollectItemVo 收藏夹中的一篇文章 文章已撤回或删除时 Article 为空
+type CollectItemVo struct {
+	Cid     int64      `json:"cid"`
+	BizId   int64      `json:"bizId"`
+	Ctime   string     `json:"ctime"`
+	Article *ArticleVo `json:"article,omitempty"`
+}
+
+type CollectItemListVo struct {
+	Items []CollectItemVo `json:"items"`
+	// 为 0 表示没有更多了
+	NextCursor int64 `json:"nextCursor,omitempty"`
+}
@@ -0,0 +1,14 @@
+package domain
+
+// ReadHistoryVo 最近阅读的一篇文章 文章已撤回或删除时 Article 为空
+type ReadHistoryVo struct {
+	BizId   int64      `json:"bizId"`
+	Rtime   string     `json:"rtime"`
+	Article *ArticleVo `json:"article,omitempty"`
+}
+
+type ReadHistoryListVo struct {
+	Items []ReadHistoryVo `json:"items"`
+	// 为 0 表示没有更多了
+	NextCursor int64 `json:"nextCursor,omitempty"`
+}
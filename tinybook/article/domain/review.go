@@ -0,0 +1,54 @@
+package domain
+
+// MaxRejectReasonLength 驳回理由的最大字符数
+const MaxRejectReasonLength = 200
+
+type ReviewStatus uint8
+
+const (
+	ReviewStatusUnknown  ReviewStatus = iota
+	ReviewStatusPending               // 等待审核
+	ReviewStatusApproved              // 审核通过 文章已发表
+	ReviewStatusRejected              // 审核驳回 文章退回草稿
+	ReviewStatusCanceled              // 作者修改了文章或重新提交，审核失效
+)
+
+// ArticleReview 文章发表前的人工审核 Words 为命中的需要审核的敏感词
+type ArticleReview struct {
+	ID        int64
+	ArticleId int64
+	AuthorId  int64
+	Title     string
+	Words     []string
+	// 定时发表的时间 审核通过时还没到就继续定时发表，为 0 表示立即发表
+	PublishAt int64
+	Status    ReviewStatus
+	Reason    string // 驳回理由
+	Reviewer  int64
+	Ctime     int64
+	Utime     int64
+}
+
+type ArticleReviewVo struct {
+	Id        int64    `json:"id"`
+	ArticleId int64    `json:"articleId"`
+	AuthorId  int64    `json:"authorId"`
+	Title     string   `json:"title"`
+	Words     []string `json:"words"`
+	PublishAt string   `json:"publishAt,omitempty"`
+	Status    uint8    `json:"status"`
+	Reason    string   `json:"reason,omitempty"`
+	Reviewer  int64    `json:"reviewer,omitempty"`
+	Ctime     string   `json:"ctime"`
+	Utime     string   `json:"utime"`
+	// 以下字段只有审核详情才有
+	Content  string   `json:"content,omitempty"`
+	Category string   `json:"category,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+}
+
+// ArticleReviewListVo 审核队列 按提交时间先后排列
+type ArticleReviewListVo struct {
+	Reviews    []ArticleReviewVo `json:"reviews"`
+	NextCursor int64             `json:"nextCursor"` // 为 0 表示没有下一页
+}
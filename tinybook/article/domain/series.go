@@ -0,0 +1,48 @@
+package domain
+
+const (
+	// MaxSeriesNameLength 系列名称最大长度(字符数)
+	MaxSeriesNameLength = 64
+	// MaxSeriesDescriptionLength 系列简介最大长度(字符数)
+	MaxSeriesDescriptionLength = 500
+	// MaxSeriesPerAuthor 每个作者最多创建的系列数量
+	MaxSeriesPerAuthor = 50
+	// MaxArticlesPerSeries 每个系列最多包含的文章数量
+	MaxArticlesPerSeries = 200
+)
+
+// Series 作者的系列(专栏) 一篇文章只能属于一个系列，ArticleIds 为系列中文章的顺序
+// ArticleIds 包含草稿等未发表的文章，展示给读者前需要过滤
+type Series struct {
+	ID          int64
+	AuthorId    int64
+	Name        string
+	Description string
+	ArticleIds  []int64
+	Ctime       int64
+	Utime       int64
+}
+
+// SeriesVo ArticleCount 为当前用户可见的文章数，读者只能看到已发表的文章
+type SeriesVo struct {
+	Id           int64       `json:"id"`
+	AuthorId     int64       `json:"authorId"`
+	Name         string      `json:"name"`
+	Description  string      `json:"description,omitempty"`
+	ArticleCount int         `json:"articleCount"`
+	Articles     []ArticleVo `json:"articles,omitempty"`
+	// 只有作者本人才返回，包含未发表的文章，用于调整顺序
+	ArticleIds []int64 `json:"articleIds,omitempty"`
+	Ctime      string  `json:"ctime,omitempty"`
+	Utime      string  `json:"utime,omitempty"`
+}
+
+// SeriesNavVo 读者查看文章时所在的系列，以及系列中前后相邻的已发表文章
+type SeriesNavVo struct {
+	Id    int64      `json:"id"`
+	Name  string     `json:"name"`
+	Index int        `json:"index"` // 在系列已发表文章中的序号 从 1 开始
+	Total int        `json:"total"`
+	Prev  *ArticleVo `json:"prev,omitempty"`
+	Next  *ArticleVo `json:"next,omitempty"`
+}
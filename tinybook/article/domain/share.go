@@ -0,0 +1,32 @@
+package domain
+
+import "time"
+
+const (
+	// DefaultShareTTL 不指定有效期时分享链接的有效期
+	DefaultShareTTL = 7 * 24 * time.Hour
+	// MaxShareTTL 分享链接最长的有效期
+	MaxShareTTL = 30 * 24 * time.Hour
+	// MaxActiveShareLinks 一篇文章同时有效的分享链接数
+	MaxActiveShareLinks = 20
+)
+
+// ShareLink 文章的分享链接 RevisionId 为 0 表示分享文章的最新内容
+type ShareLink struct {
+	ID         int64
+	ArticleId  int64
+	AuthorId   int64
+	RevisionId int64
+	ExpireAt   int64
+	Revoked    bool
+	Ctime      int64
+}
+
+type ShareLinkVo struct {
+	Id         int64  `json:"id"`
+	ArticleId  int64  `json:"articleId"`
+	RevisionId int64  `json:"revisionId,omitempty"`
+	Token      string `json:"token"`
+	ExpireAt   string `json:"expireAt"`
+	Ctime      string `json:"ctime"`
+}
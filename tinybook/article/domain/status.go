@@ -0,0 +1,99 @@
+package domain
+
+// articleTransitions 文章状态机 key 为当前状态，value 为允许流转到的状态
+// ArticleStatusUnknown 表示还没有保存过的新文章
+var articleTransitions = map[ArticleStatus][]ArticleStatus{
+	ArticleStatusUnknown: {
+		ArticleStatusUnpublished, ArticleStatusPublished, ArticleStatusScheduled, ArticleStatusPendingReview,
+	},
+	ArticleStatusUnpublished: {
+		ArticleStatusUnpublished, ArticleStatusPublished, ArticleStatusScheduled, ArticleStatusPendingReview,
+		ArticleStatusDeleted,
+	},
+	// 已发表和仅自己可见的文章修改草稿时保持原状态，线上版本不受影响
+	ArticleStatusPublished: {
+		ArticleStatusPublished, ArticleStatusPrivate, ArticleStatusScheduled, ArticleStatusPendingReview,
+		ArticleStatusDeleted,
+	},
+	ArticleStatusPrivate: {
+		ArticleStatusPrivate, ArticleStatusPublished, ArticleStatusScheduled, ArticleStatusPendingReview,
+		ArticleStatusDeleted,
+	},
+	ArticleStatusScheduled: {
+		ArticleStatusUnpublished, ArticleStatusPublished, ArticleStatusScheduled, ArticleStatusPendingReview,
+		ArticleStatusDeleted,
+	},
+	ArticleStatusPendingReview: {
+		ArticleStatusUnpublished, ArticleStatusPublished, ArticleStatusScheduled, ArticleStatusPendingReview,
+		ArticleStatusDeleted,
+	},
+	// 回收站中的文章只能恢复为草稿
+	ArticleStatusDeleted: {ArticleStatusUnpublished},
+}
+
+// CanTransitTo 是否允许从当前状态流转到 to
+func (s ArticleStatus) CanTransitTo(to ArticleStatus) bool {
+	for _, status := range articleTransitions[s] {
+		if status == to {
+			return true
+		}
+	}
+	return false
+}
+
+func (s ArticleStatus) String() string {
+	switch s {
+	case ArticleStatusUnpublished:
+		return "draft"
+	case ArticleStatusPublished:
+		return "published"
+	case ArticleStatusPrivate:
+		return "private"
+	case ArticleStatusScheduled:
+		return "scheduled"
+	case ArticleStatusDeleted:
+		return "deleted"
+	case ArticleStatusPendingReview:
+		return "pending_review"
+	default:
+		return "unknown"
+	}
+}
+
+// 引起状态变化的操作 记录在审计日志中
+const (
+	StatusActionSave             = "save"
+	StatusActionPublish          = "publish"
+	StatusActionSchedule         = "schedule"
+	StatusActionCancelSchedule   = "cancel_schedule"
+	StatusActionScheduledPublish = "scheduled_publish" // 定时任务到期发表
+	StatusActionWithdraw         = "withdraw"
+	StatusActionSubmitReview     = "submit_review"
+	StatusActionApprove          = "approve"
+	StatusActionReject           = "reject"
+	StatusActionDelete           = "delete"
+	StatusActionRestore          = "restore"
+)
+
+// SystemOperator 定时任务等系统操作的操作人
+const SystemOperator int64 = 0
+
+// ArticleStatusLog 文章状态变化的审计日志 只追加不修改
+type ArticleStatusLog struct {
+	ID        int64
+	ArticleId int64
+	Operator  int64
+	From      ArticleStatus
+	To        ArticleStatus
+	Action    string
+	Ctime     int64
+}
+
+type ArticleStatusLogVo struct {
+	Id       int64  `json:"id"`
+	Operator int64  `json:"operator"`
+	From     string `json:"from"`
+	To       string `json:"to"`
+	Action   string `json:"action"`
+	Ctime    string `json:"ctime"`
+}
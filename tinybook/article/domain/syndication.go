@@ -0,0 +1,11 @@
+package domain
+
+// MaxSyndicationItems 订阅源中最多包含的文章数
+const MaxSyndicationItems = 20
+
+// SyndicationDocument 渲染好的 RSS/Atom 文档 Modified 为其中文章最近的更新时间(秒)
+type SyndicationDocument struct {
+	Data     []byte `json:"data"`
+	ETag     string `json:"etag"`
+	Modified int64  `json:"modified"`
+}
@@ -0,0 +1,38 @@
+package domain
+
+import "time"
+
+const (
+	// MaxImportFiles 一次最多导入的文章数
+	MaxImportFiles = 200
+	// MaxImportFileSize 导入时单篇 Markdown 的最大字节数
+	MaxImportFileSize = 1 << 20
+)
+
+// ArticleFrontMatter 导出的 Markdown 开头的元信息 导入时只使用标题、分类和标签
+type ArticleFrontMatter struct {
+	Title    string    `yaml:"title"`
+	Status   string    `yaml:"status,omitempty"`
+	Category string    `yaml:"category,omitempty"`
+	Tags     []string  `yaml:"tags,omitempty"`
+	Ctime    time.Time `yaml:"ctime,omitempty"`
+	Utime    time.Time `yaml:"utime,omitempty"`
+}
+
+// ImportItem 导入报告中的一篇文章 Error 不为空表示这篇没有(或不会)被创建
+type ImportItem struct {
+	File  string `json:"file"`
+	Title string `json:"title,omitempty"`
+	// 创建的草稿ID 预演时为 0
+	Id    int64  `json:"id,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// ImportReport 导入结果 预演(DryRun)时只校验 不会创建草稿
+type ImportReport struct {
+	DryRun  bool         `json:"dryRun"`
+	Total   int          `json:"total"`
+	Created int          `json:"created"`
+	Failed  int          `json:"failed"`
+	Items   []ImportItem `json:"items"`
+}
@@ -0,0 +1,91 @@
+package invalidation
+
+import (
+	"context"
+	"github.com/bytedance/sonic"
+	"github.com/segmentio/kafka-go"
+	"go.uber.org/zap"
+	"time"
+	"tinybook/tinybook/pkg/kafkax"
+)
+
+const (
+	// maxEvictAttempts 删除缓存的最大尝试次数 第 n 次失败后等待 evictBackoff * 2^(n-1) 再重试
+	maxEvictAttempts = 5
+	evictBackoff     = 200 * time.Millisecond
+)
+
+// Evictor 删除文章相关的全部缓存 包括当前实例的本地缓存
+type Evictor interface {
+	EvictCaches(ctx context.Context, articleId int64, authorId int64) error
+}
+
+// CacheInvalidationConsumer 每个实例都要消费全部的失效事件 才能删除各自的本地缓存，
+// 因此不使用消费者组，每个分区一个 reader，只从启动时最新的位置开始消费，也不需要提交位移
+// 分区只在启动时读取一次：topic 扩容分区后，新分区上的事件要等实例重启才会被消费，
+// 在那之前本地缓存只能等过期，所以扩容分区后需要滚动重启所有实例
+type CacheInvalidationConsumer struct {
+	readers []*kafka.Reader
+	evictor Evictor
+	log     *zap.Logger
+}
+
+func NewCacheInvalidationConsumer(evictor Evictor, brokers kafkax.Brokers, log *zap.Logger) *CacheInvalidationConsumer {
+	readers, err := kafkax.NewPartitionReaders(brokers, TopicArticleCacheInvalidation)
+	if err != nil {
+		panic(err)
+	}
+	return &CacheInvalidationConsumer{
+		readers: readers,
+		evictor: evictor,
+		log:     log,
+	}
+}
+
+func (c *CacheInvalidationConsumer) Start() {
+	for _, reader := range c.readers {
+		go func(reader *kafka.Reader) {
+			c.Consume(context.Background(), reader)
+		}(reader)
+	}
+}
+
+func (c *CacheInvalidationConsumer) Consume(ctx context.Context, reader *kafka.Reader) {
+	defer func(reader *kafka.Reader) {
+		err := reader.Close()
+		if err != nil {
+			c.log.Error("close kafka consumer failed", zap.Error(err))
+		}
+	}(reader)
+	for {
+		// 没有消费者组 ReadMessage 不会提交位移
+		message, err := reader.ReadMessage(ctx)
+		if err != nil {
+			c.log.Error("read message failed", zap.Error(err))
+			if !kafkax.ShouldRetryFetch(ctx, err) {
+				return
+			}
+			continue
+		}
+		// 重试都失败后继续消费 缓存最终会过期
+		c.handle(ctx, message)
+	}
+}
+
+func (c *CacheInvalidationConsumer) handle(ctx context.Context, message kafka.Message) {
+	var evt CacheInvalidationEvent
+	err := sonic.Unmarshal(message.Value, &evt)
+	if err != nil {
+		c.log.Error("consumer unmarshal message failed", zap.Error(err))
+		return
+	}
+	err = kafkax.Retry(ctx, maxEvictAttempts, evictBackoff, func() error {
+		return c.evictor.EvictCaches(ctx, evt.ArticleId, evt.AuthorId)
+	}, func(attempt int, err error) {
+		c.log.Warn("evict article caches failed", zap.Int64("article_id", evt.ArticleId),
+			zap.Int("attempt", attempt), zap.Error(err))
+	})
+	if err != nil {
+		c.log.Error("evict article caches failed after retries", zap.Int64("article_id", evt.ArticleId), zap.Error(err))
+	}
+}
@@ -0,0 +1,41 @@
+package invalidation
+
+import (
+	"context"
+	"github.com/bytedance/sonic"
+	"github.com/segmentio/kafka-go"
+	"strconv"
+)
+
+const TopicArticleCacheInvalidation = "topic-article-cache-invalidation"
+
+// CacheInvalidationEvent 文章写入后通知所有实例删除这篇文章相关的缓存
+type CacheInvalidationEvent struct {
+	ArticleId int64 `json:"article_id"`
+	AuthorId  int64 `json:"author_id"`
+}
+
+type Producer interface {
+	ProduceCacheInvalidationEvent(ctx context.Context, evt CacheInvalidationEvent) error
+}
+
+type KafkaProducer struct {
+	writer *kafka.Writer
+}
+
+func NewKafkaProducer(writer *kafka.Writer) Producer {
+	return &KafkaProducer{writer: writer}
+}
+
+func (k *KafkaProducer) ProduceCacheInvalidationEvent(ctx context.Context, evt CacheInvalidationEvent) error {
+	bytes, err := sonic.Marshal(evt)
+	if err != nil {
+		return err
+	}
+	// 同一篇文章的事件落在同一个分区 保证顺序
+	return k.writer.WriteMessages(ctx, kafka.Message{
+		Topic: TopicArticleCacheInvalidation,
+		Key:   []byte(strconv.FormatInt(evt.ArticleId, 10)),
+		Value: bytes,
+	})
+}
@@ -6,22 +6,55 @@ import (
 	"github.com/cockroachdb/errors"
 	"github.com/samber/lo"
 	"go.uber.org/zap"
+	"golang.org/x/sync/singleflight"
 	"google.golang.org/grpc"
 	"strconv"
 	"time"
 	intrv1 "tinybook/tinybook/api/proto/gen/intr/v1"
 	"tinybook/tinybook/article/domain"
+	"tinybook/tinybook/article/events/invalidation"
 	"tinybook/tinybook/article/repository/cache"
 	"tinybook/tinybook/article/repository/dao"
+	"tinybook/tinybook/article/repository/search"
 	"tinybook/tinybook/internal/repository"
+	"tinybook/tinybook/pkg/feedx"
+	"tinybook/tinybook/pkg/kafkax"
+	"tinybook/tinybook/pkg/mdx"
+	"tinybook/tinybook/pkg/objstore"
 )
 
 const (
-	PubArticleKey       = "article:pub:"
 	ArticleKey          = "article:"
 	ArticleFirstPageKey = "article:first_page:"
+	TagCloudKey         = "article:tag_cloud"
+	// ArticleFeedKey 渲染好的订阅源 作者ID为 0 表示全站
+	ArticleFeedKey = "article:feed:"
 )
 
+var (
+	ErrAuthorMismatch  = dao.ErrAuthorMismatch
+	ErrVersionConflict = dao.ErrVersionConflict
+	ErrArticleDeleted  = dao.ErrArticleDeleted
+	ErrArticleNotFound = dao.ErrArticleNotFound
+	ErrStatusChanged   = dao.ErrStatusChanged
+)
+
+type VersionConflictError = dao.VersionConflictError
+
+// MaxTagCloudSize 标签云最多展示的标签数
+const MaxTagCloudSize = 200
+
+const (
+	// maxProduceInvalidationAttempts 发送失效事件的最大尝试次数 第 n 次失败后等待 produceInvalidationBackoff * 2^(n-1) 再重试
+	maxProduceInvalidationAttempts = 5
+	produceInvalidationBackoff     = 200 * time.Millisecond
+)
+
+// PublishListener 文章发表(同步到线上库)成功后的回调 例如推送到粉丝的 feed
+type PublishListener interface {
+	OnPublish(ctx context.Context, article domain.Article)
+}
+
 type ArticleType int
 
 const (
@@ -30,12 +63,17 @@ const (
 	ArticleReader
 )
 
+// ArticleRepository 修改已有文章时 from 为修改前读到的状态，SyncStatus、UpdateStatus、Delete 以 article.Status 作为修改前的状态
+// 文章已经不是这个状态时返回 ErrStatusChanged
 type ArticleRepository interface {
 	Create(ctx context.Context, article domain.Article) (int64, error)
-	Update(ctx context.Context, article domain.Article) error
-	Sync(ctx context.Context, article domain.Article) (int64, error)
+	Update(ctx context.Context, article domain.Article, from domain.ArticleStatus) error
+	Sync(ctx context.Context, article domain.Article, from domain.ArticleStatus) (int64, error)
 	SyncStatus(ctx context.Context, article domain.Article, articleStatus domain.ArticleStatus) error
-	GetArticlesByAuthor(ctx context.Context, uid int64, limit int, offset int) ([]domain.Article, error)
+	UpdateStatus(ctx context.Context, article domain.Article, articleStatus domain.ArticleStatus) error
+	GetArticlesByAuthor(ctx context.Context, uid int64, cursor domain.Cursor, limit int) ([]domain.Article, error)
+	// ListAllByAuthor 与 GetArticlesByAuthor 一样翻页 但不读写缓存 用于导出等需要遍历全部文章的场景
+	ListAllByAuthor(ctx context.Context, uid int64, cursor domain.Cursor, limit int) ([]domain.Article, error)
 	GetFirstPage(ctx context.Context, uid int64, limit int) ([]domain.Article, error)
 	SetFirstPage(ctx context.Context, uid int64, articles []domain.Article) error
 	DelFirstPage(ctx context.Context, uid int64) error
@@ -44,16 +82,52 @@ type ArticleRepository interface {
 	GetCache(ctx context.Context, key int64, articleType ArticleType) (domain.Article, error)
 	DelCache(ctx context.Context, key int64, articleType ArticleType) error
 	GetPubArticleById(ctx context.Context, id int64) (domain.Article, error)
-	ListPub(ctx context.Context, t time.Time, limit int, offset int) ([]domain.Article, error)
+	// GetAuthorId 文章作者的 id 只查元数据不加载正文，已发表的文章走缓存，撤回发表的文章查制作库
+	GetAuthorId(ctx context.Context, id int64) (int64, error)
+	ListPub(ctx context.Context, cursor domain.Cursor, limit int) ([]domain.Article, error)
+	// ListPubWithAuthor 按更新时间倒序返回已发表的文章并带上作者昵称和渲染后的正文 uid 为 0 时不区分作者
+	ListPubWithAuthor(ctx context.Context, uid int64, cursor domain.Cursor, limit int) ([]domain.Article, error)
+	// ListPubByIds 按 ids 的顺序返回已发表的文章，不存在或未发表的跳过
+	ListPubByIds(ctx context.Context, ids []int64) ([]domain.Article, error)
+	GetRevisions(ctx context.Context, artId int64, limit int, offset int) ([]domain.ArticleRevision, error)
+	GetRevisionById(ctx context.Context, artId int64, revId int64) (domain.ArticleRevision, error)
+	ListPubByTag(ctx context.Context, tag string, limit int, offset int) ([]domain.Article, error)
+	ListPubByCategory(ctx context.Context, category string, limit int, offset int) ([]domain.Article, error)
+	GetTagCloud(ctx context.Context, limit int) ([]domain.TagCount, error)
+	Search(ctx context.Context, query string, limit int, offset int) ([]domain.Article, int, error)
+	RebuildSearchIndex(ctx context.Context) error
+	Delete(ctx context.Context, article domain.Article) error
+	ListTrash(ctx context.Context, uid int64, cursor domain.Cursor, limit int) ([]domain.Article, error)
+	Restore(ctx context.Context, article domain.Article) error
+	// PurgeTrash 彻底删除 before 之前移入回收站的文章，每次最多 limit 篇，返回删除的数量
+	PurgeTrash(ctx context.Context, before time.Time, limit int) (int64, error)
+	// CollectContent 删除对象存储中 before 之前写入、已经没有任何文章或历史版本引用的正文，返回删除的数量
+	CollectContent(ctx context.Context, before time.Time) (int64, error)
+	// GetFeed 获取缓存的订阅源 authorId 为 0 表示全站
+	GetFeed(ctx context.Context, authorId int64, format feedx.Format) (domain.SyndicationDocument, error)
+	SetFeed(ctx context.Context, authorId int64, format feedx.Format, doc domain.SyndicationDocument) error
+	// DelFeed 删除作者以及全站的订阅源缓存
+	DelFeed(ctx context.Context, authorId int64) error
+	// EvictCaches 删除文章相关的全部缓存 收到缓存失效事件时调用
+	EvictCaches(ctx context.Context, articleId int64, authorId int64) error
 	intrv1.InteractiveServiceClient
 }
 
 type CachedArticleRepository struct {
 	dao                dao.ArticleDAO
 	cache              cache.ArticleCache
+	pubCache           cache.PubArticleCache
+	searcher           search.ArticleSearcher
 	userRepo           repository.UserRepository
 	log                *zap.Logger
 	interactiveService intrv1.InteractiveServiceClient
+	listener           PublishListener
+	producer           invalidation.Producer
+	pubGroup           singleflight.Group // 同一篇已发表文章的缓存未命中时只有一个请求回源
+
+	// 正文保存在对象存储中 数据库和 redis 中只保存正文的 key，正文缓存在本地
+	contents     objstore.Store
+	contentCache cache.ArticleContentCache
 }
 
 func (c *CachedArticleRepository) IncreaseReadCount(ctx context.Context, in *intrv1.IncreaseReadCountRequest, opts ...grpc.CallOption) (*intrv1.IncreaseReadCountResponse, error) {
@@ -84,8 +158,72 @@ func (c *CachedArticleRepository) GetByIds(ctx context.Context, in *intrv1.GetBy
 	return c.interactiveService.GetByIds(ctx, in, opts...)
 }
 
-func (c *CachedArticleRepository) ListPub(ctx context.Context, t time.Time, limit int, offset int) ([]domain.Article, error) {
-	list, err := c.dao.GetPubList(ctx, t, limit, offset)
+func (c *CachedArticleRepository) Uncollect(ctx context.Context, in *intrv1.UncollectRequest, opts ...grpc.CallOption) (*intrv1.UncollectResponse, error) {
+	return c.interactiveService.Uncollect(ctx, in, opts...)
+}
+
+func (c *CachedArticleRepository) CreateFolder(ctx context.Context, in *intrv1.CreateFolderRequest, opts ...grpc.CallOption) (*intrv1.CreateFolderResponse, error) {
+	return c.interactiveService.CreateFolder(ctx, in, opts...)
+}
+
+func (c *CachedArticleRepository) RenameFolder(ctx context.Context, in *intrv1.RenameFolderRequest, opts ...grpc.CallOption) (*intrv1.RenameFolderResponse, error) {
+	return c.interactiveService.RenameFolder(ctx, in, opts...)
+}
+
+func (c *CachedArticleRepository) DeleteFolder(ctx context.Context, in *intrv1.DeleteFolderRequest, opts ...grpc.CallOption) (*intrv1.DeleteFolderResponse, error) {
+	return c.interactiveService.DeleteFolder(ctx, in, opts...)
+}
+
+func (c *CachedArticleRepository) ListFolders(ctx context.Context, in *intrv1.ListFoldersRequest, opts ...grpc.CallOption) (*intrv1.ListFoldersResponse, error) {
+	return c.interactiveService.ListFolders(ctx, in, opts...)
+}
+
+func (c *CachedArticleRepository) MoveItem(ctx context.Context, in *intrv1.MoveItemRequest, opts ...grpc.CallOption) (*intrv1.MoveItemResponse, error) {
+	return c.interactiveService.MoveItem(ctx, in, opts...)
+}
+
+func (c *CachedArticleRepository) ListFolderItems(ctx context.Context, in *intrv1.ListFolderItemsRequest, opts ...grpc.CallOption) (*intrv1.ListFolderItemsResponse, error) {
+	return c.interactiveService.ListFolderItems(ctx, in, opts...)
+}
+
+func (c *CachedArticleRepository) ListReadHistory(ctx context.Context, in *intrv1.ListReadHistoryRequest, opts ...grpc.CallOption) (*intrv1.ListReadHistoryResponse, error) {
+	return c.interactiveService.ListReadHistory(ctx, in, opts...)
+}
+
+func (c *CachedArticleRepository) DeleteReadHistory(ctx context.Context, in *intrv1.DeleteReadHistoryRequest, opts ...grpc.CallOption) (*intrv1.DeleteReadHistoryResponse, error) {
+	return c.interactiveService.DeleteReadHistory(ctx, in, opts...)
+}
+
+func (c *CachedArticleRepository) ClearReadHistory(ctx context.Context, in *intrv1.ClearReadHistoryRequest, opts ...grpc.CallOption) (*intrv1.ClearReadHistoryResponse, error) {
+	return c.interactiveService.ClearReadHistory(ctx, in, opts...)
+}
+
+func (c *CachedArticleRepository) GetRevisions(ctx context.Context, artId int64, limit int, offset int) ([]domain.ArticleRevision, error) {
+	revisions, err := c.dao.GetRevisions(ctx, artId, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]domain.ArticleRevision, 0, len(revisions))
+	for _, item := range revisions {
+		revision, er := c.revisionToDomain(ctx, item)
+		if er != nil {
+			return nil, er
+		}
+		res = append(res, revision)
+	}
+	return res, nil
+}
+
+func (c *CachedArticleRepository) GetRevisionById(ctx context.Context, artId int64, revId int64) (domain.ArticleRevision, error) {
+	revision, err := c.dao.GetRevisionById(ctx, artId, revId)
+	if err != nil {
+		return domain.ArticleRevision{}, err
+	}
+	return c.revisionToDomain(ctx, revision)
+}
+
+func (c *CachedArticleRepository) ListPub(ctx context.Context, cursor domain.Cursor, limit int) ([]domain.Article, error) {
+	list, err := c.dao.GetPubList(ctx, dao.Cursor(cursor), limit)
 	if err != nil {
 		return nil, err
 	}
@@ -95,12 +233,278 @@ func (c *CachedArticleRepository) ListPub(ctx context.Context, t time.Time, limi
 	return articles, nil
 }
 
+func (c *CachedArticleRepository) ListPubWithAuthor(ctx context.Context, uid int64, cursor domain.Cursor, limit int) ([]domain.Article, error) {
+	var (
+		list []dao.PublishedArticle
+		err  error
+	)
+	if uid == 0 {
+		list, err = c.dao.GetPubList(ctx, dao.Cursor(cursor), limit)
+	} else {
+		list, err = c.dao.GetPubListByAuthor(ctx, uid, dao.Cursor(cursor), limit)
+	}
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[int64]string, len(list))
+	res := make([]domain.Article, 0, len(list))
+	for _, item := range list {
+		article := c.pubDaoToDomain(item)
+		name, ok := names[item.AuthorId]
+		if !ok {
+			// 用户信息有缓存 找不到作者时不影响文章的展示
+			user, er := c.userRepo.FindById(ctx, item.AuthorId)
+			if er != nil {
+				c.log.Warn("find article author failed", zap.Int64("author_id", item.AuthorId), zap.Error(er))
+			}
+			name = user.Nickname
+			names[item.AuthorId] = name
+		}
+		article.Author.Name = name
+		// 订阅源中要输出渲染后的正文
+		if err = c.fillContent(ctx, &article); err != nil {
+			return nil, err
+		}
+		res = append(res, article)
+	}
+	return res, nil
+}
+
+func (c *CachedArticleRepository) ListPubByIds(ctx context.Context, ids []int64) ([]domain.Article, error) {
+	list, err := c.dao.GetPubListByIds(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	m := lo.SliceToMap(list, func(item dao.PublishedArticle) (int64, dao.PublishedArticle) {
+		return item.ID, item
+	})
+	res := make([]domain.Article, 0, len(list))
+	for _, id := range ids {
+		if article, ok := m[id]; ok {
+			res = append(res, c.pubDaoToDomain(article))
+		}
+	}
+	return res, nil
+}
+
+func (c *CachedArticleRepository) ListPubByTag(ctx context.Context, tag string, limit int, offset int) ([]domain.Article, error) {
+	list, err := c.dao.GetPubListByTag(ctx, tag, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	return lo.Map(list, func(item dao.PublishedArticle, index int) domain.Article {
+		return c.pubDaoToDomain(item)
+	}), nil
+}
+
+func (c *CachedArticleRepository) ListPubByCategory(ctx context.Context, category string, limit int, offset int) ([]domain.Article, error) {
+	list, err := c.dao.GetPubListByCategory(ctx, category, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	return lo.Map(list, func(item dao.PublishedArticle, index int) domain.Article {
+		return c.pubDaoToDomain(item)
+	}), nil
+}
+
+// GetTagCloud 标签云 统计开销较大，缓存最多的 MaxTagCloudSize 个标签，允许几分钟的延迟
+func (c *CachedArticleRepository) GetTagCloud(ctx context.Context, limit int) ([]domain.TagCount, error) {
+	if limit > MaxTagCloudSize {
+		limit = MaxTagCloudSize
+	}
+	var res []domain.TagCount
+	bytes, err := c.cache.Get(ctx, TagCloudKey)
+	if err == nil && sonic.Unmarshal(bytes, &res) == nil {
+		if len(res) > limit {
+			res = res[:limit]
+		}
+		return res, nil
+	}
+	counts, err := c.dao.GetTagCounts(ctx, MaxTagCloudSize)
+	if err != nil {
+		return nil, err
+	}
+	res = lo.Map(counts, func(item dao.TagCount, index int) domain.TagCount {
+		return domain.TagCount{Tag: item.Tag, Count: item.Count}
+	})
+	if marshal, er := sonic.Marshal(res); er == nil {
+		if er = c.cache.Set(ctx, TagCloudKey, marshal, 5*time.Minute); er != nil {
+			c.log.Warn("set tag cloud to cache failed", zap.Error(er))
+		}
+	}
+	if len(res) > limit {
+		res = res[:limit]
+	}
+	return res, nil
+}
+
+func (c *CachedArticleRepository) Search(ctx context.Context, query string, limit int, offset int) ([]domain.Article, int, error) {
+	hits, total, err := c.searcher.Search(ctx, query, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	return lo.Map(hits, func(hit search.ArticleHit, index int) domain.Article {
+		return domain.Article{
+			ID:       hit.ID,
+			Title:    hit.Title,
+			Abstract: hit.Snippet,
+			Author:   domain.Author{ID: hit.AuthorId, Name: hit.AuthorName},
+			Utime:    hit.Utime,
+		}
+	}), total, nil
+}
+
+// RebuildSearchIndex 分批读取全部线上文章，全量重建搜索索引
+func (c *CachedArticleRepository) RebuildSearchIndex(ctx context.Context) error {
+	const batchSize = 500
+	var (
+		cursor  dao.Cursor
+		docs    []search.ArticleDoc
+		authors = make(map[int64]string) // 作者ID -> 昵称
+	)
+	for {
+		list, err := c.dao.GetPubList(ctx, cursor, batchSize)
+		if err != nil {
+			return err
+		}
+		for _, article := range list {
+			name, ok := authors[article.AuthorId]
+			if !ok {
+				name = c.authorName(ctx, article.AuthorId)
+				authors[article.AuthorId] = name
+			}
+			doc, er := c.toSearchDoc(ctx, article, name)
+			if er != nil {
+				return er
+			}
+			docs = append(docs, doc)
+		}
+		if len(list) < batchSize {
+			break
+		}
+		last := list[len(list)-1]
+		cursor = dao.Cursor{Utime: last.Utime, ID: last.ID}
+	}
+	return c.searcher.Replace(ctx, docs)
+}
+
+// refreshSearchIndex 以线上库为准刷新一篇文章的索引，已发表则写入，否则删除
+func (c *CachedArticleRepository) refreshSearchIndex(id int64) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	article, err := c.dao.GetPubArticleById(ctx, id)
+	if err != nil {
+		c.log.Warn("refresh search index failed", zap.Int64("article_id", id), zap.Error(err))
+		return
+	}
+	if domain.ArticleStatus(article.Status) != domain.ArticleStatusPublished {
+		err = c.searcher.Delete(ctx, id)
+	} else {
+		var doc search.ArticleDoc
+		doc, err = c.toSearchDoc(ctx, article, c.authorName(ctx, article.AuthorId))
+		if err == nil {
+			err = c.searcher.Put(ctx, doc)
+		}
+	}
+	if err != nil {
+		c.log.Warn("refresh search index failed", zap.Int64("article_id", id), zap.Error(err))
+	}
+}
+
+// notifyPublish 通知文章已发表 耗时的操作(例如推送给大量粉丝)由 listener 自行处理
+func (c *CachedArticleRepository) notifyPublish(article domain.Article) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	c.listener.OnPublish(ctx, article)
+}
+
+// authorName 获取作者昵称 获取失败时不影响索引，只是不能按作者搜索
+func (c *CachedArticleRepository) authorName(ctx context.Context, uid int64) string {
+	user, err := c.userRepo.FindById(ctx, uid)
+	if err != nil {
+		c.log.Warn("get author for search index failed", zap.Int64("uid", uid), zap.Error(err))
+		return ""
+	}
+	return user.Nickname
+}
+
+func (c *CachedArticleRepository) toSearchDoc(ctx context.Context, article dao.PublishedArticle, authorName string) (search.ArticleDoc, error) {
+	content, err := c.getContent(ctx, article.ContentKey, article.Content)
+	if err != nil {
+		return search.ArticleDoc{}, err
+	}
+	return search.ArticleDoc{
+		ID:         article.ID,
+		AuthorId:   article.AuthorId,
+		AuthorName: authorName,
+		Title:      article.Title,
+		Content:    mdx.PlainText(content), // 只索引纯文本，避免命中 Markdown 标记
+		Utime:      article.Utime,
+	}, nil
+}
+
 func (c *CachedArticleRepository) GetPubArticleById(ctx context.Context, id int64) (domain.Article, error) {
-	getCache, err := c.GetCache(ctx, id, ArticleReader)
-	if err == nil {
-		return getCache, nil
+	art, err := c.pubMeta(ctx, id)
+	if err != nil {
+		return domain.Article{}, err
+	}
+	return art, c.fillContent(ctx, &art)
+}
+
+func (c *CachedArticleRepository) GetAuthorId(ctx context.Context, id int64) (int64, error) {
+	art, err := c.pubMeta(ctx, id)
+	if errors.Is(err, ErrArticleNotFound) {
+		// 撤回发表的文章只在制作库中
+		article, er := c.dao.GetArticleById(ctx, id)
+		if er != nil {
+			return 0, er
+		}
+		return article.AuthorId, nil
+	}
+	if err != nil {
+		return 0, err
 	}
+	return art.Author.ID, nil
+}
+
+// pubMeta 已发表文章的元数据 先查两级缓存再回源 不带正文，撤回发表的文章返回 ErrArticleNotFound
+func (c *CachedArticleRepository) pubMeta(ctx context.Context, id int64) (domain.Article, error) {
+	art, err := c.pubCache.Get(ctx, id)
+	switch {
+	case err == nil && art.Status == domain.ArticleStatusPublished:
+		return art, nil
+	case err == nil:
+		// 撤回发表和写缓存并发时可能缓存了撤回后的状态
+		return domain.Article{}, ErrArticleNotFound
+	case errors.Is(err, cache.ErrPubArticleNotFound):
+		return domain.Article{}, ErrArticleNotFound
+	case !errors.Is(err, cache.ErrPubArticleMiss):
+		// redis 出错时仍然回源 由 singleflight 限制回源的并发
+		c.log.Warn("get published article from cache failed", zap.Int64("article_id", id), zap.Error(err))
+	}
+	val, err, _ := c.pubGroup.Do(strconv.FormatInt(id, 10), func() (any, error) {
+		// 结果由所有等待的请求共享 不能因为第一个请求被取消而失败
+		return c.loadPubArticle(context.WithoutCancel(ctx), id)
+	})
+	if err != nil {
+		return domain.Article{}, err
+	}
+	return val.(domain.Article), nil
+}
+
+// loadPubArticle 从数据库加载已发表的文章并写入缓存 不存在或已撤回的文章写入空缓存 返回的文章不带正文
+func (c *CachedArticleRepository) loadPubArticle(ctx context.Context, id int64) (domain.Article, error) {
 	article, err := c.dao.GetPubArticleById(ctx, id)
+	// 撤回发表的文章仍在线上库中 状态为仅自己可见，对读者来说等同于不存在
+	if err == nil && domain.ArticleStatus(article.Status) != domain.ArticleStatusPublished {
+		err = dao.ErrArticleNotFound
+	}
+	if errors.Is(err, dao.ErrArticleNotFound) {
+		if er := c.pubCache.SetNotFound(ctx, id); er != nil {
+			c.log.Warn("set published article not found to cache failed", zap.Int64("article_id", id), zap.Error(er))
+		}
+		return domain.Article{}, ErrArticleNotFound
+	}
 	if err != nil {
 		return domain.Article{}, err
 	}
@@ -109,28 +513,25 @@ func (c *CachedArticleRepository) GetPubArticleById(ctx context.Context, id int6
 	if err != nil {
 		return domain.Article{}, err
 	}
-	toDomain := c.daoToDomain(dao.Article(article))
+	// 旧数据在数据库中还保存着 HTML 和目录 不写入缓存
+	toDomain := c.stripContent(c.daoToDomain(dao.Article(article)))
 	toDomain.Author.Name = user.Nickname
-	go func() {
-		timeout, cancelFunc := context.WithTimeout(context.Background(), 2*time.Second)
-		defer cancelFunc()
-		err2 := c.SetCache(timeout, article.ID, ArticleReader, toDomain, 3*24*time.Hour)
-		if err2 != nil {
-			c.log.Warn("preset published article to cache failed", zap.Error(err2))
-			return
-		}
-	}()
+	if er := c.pubCache.Set(ctx, toDomain); er != nil {
+		c.log.Warn("preset published article to cache failed", zap.Error(er))
+	}
 	return toDomain, nil
 }
 
 func (c *CachedArticleRepository) SetCache(ctx context.Context, key int64, articleType ArticleType, art domain.Article, expire time.Duration) error {
 	var articleKey string
 	// 根据文章类型，选择不同的缓存key
+	art = c.stripContent(art)
 	switch articleType {
 	case ArticleAuthor:
 		articleKey = c.GetCacheArticleKey(key)
 	case ArticleReader:
-		articleKey = c.GetPubCacheArticleKey(key)
+		// 已发表的文章使用两级缓存 过期时间由缓存决定
+		return c.pubCache.Set(ctx, art)
 	default:
 		return errors.New("unknown article type")
 	}
@@ -148,7 +549,11 @@ func (c *CachedArticleRepository) GetCache(ctx context.Context, key int64, artic
 	case ArticleAuthor:
 		articleKey = c.GetCacheArticleKey(key)
 	case ArticleReader:
-		articleKey = c.GetPubCacheArticleKey(key)
+		res, err := c.pubCache.Get(ctx, key)
+		if err != nil {
+			return domain.Article{}, err
+		}
+		return res, c.fillContent(ctx, &res)
 	default:
 		return domain.Article{}, errors.New("unknown article type")
 	}
@@ -161,7 +566,7 @@ func (c *CachedArticleRepository) GetCache(ctx context.Context, key int64, artic
 	if err != nil {
 		return domain.Article{}, err
 	}
-	return res, nil
+	return res, c.fillContent(ctx, &res)
 }
 
 func (c *CachedArticleRepository) DelCache(ctx context.Context, key int64, articleType ArticleType) error {
@@ -171,7 +576,7 @@ func (c *CachedArticleRepository) DelCache(ctx context.Context, key int64, artic
 	case ArticleAuthor:
 		articleKey = c.GetCacheArticleKey(key)
 	case ArticleReader:
-		articleKey = c.GetPubCacheArticleKey(key)
+		return c.pubCache.Delete(ctx, key)
 	default:
 		return errors.New("unknown article type")
 	}
@@ -187,7 +592,8 @@ func (c *CachedArticleRepository) GetArticleById(ctx context.Context, id int64)
 	if err != nil {
 		return domain.Article{}, err
 	}
-	return c.daoToDomain(article), nil
+	res := c.daoToDomain(article)
+	return res, c.fillContent(ctx, &res)
 }
 
 func (c *CachedArticleRepository) DelFirstPage(ctx context.Context, uid int64) error {
@@ -199,6 +605,7 @@ func (c *CachedArticleRepository) SetFirstPage(ctx context.Context, uid int64, a
 	// 只需要缓存文章的摘要
 	i := lo.Map(articles, func(article domain.Article, index int) domain.Article {
 		article.Content = article.Abstract
+		article.Html, article.Toc = "", nil
 		return article
 	})
 	marshal, err := sonic.Marshal(i)
@@ -208,6 +615,40 @@ func (c *CachedArticleRepository) SetFirstPage(ctx context.Context, uid int64, a
 	return c.cache.Set(ctx, key, marshal, 30*time.Minute)
 }
 
+func (c *CachedArticleRepository) GetFeed(ctx context.Context, authorId int64, format feedx.Format) (domain.SyndicationDocument, error) {
+	var doc domain.SyndicationDocument
+	data, err := c.cache.Get(ctx, c.getFeedKey(authorId, format))
+	if err != nil {
+		return doc, err
+	}
+	err = sonic.Unmarshal(data, &doc)
+	return doc, err
+}
+
+func (c *CachedArticleRepository) SetFeed(ctx context.Context, authorId int64, format feedx.Format, doc domain.SyndicationDocument) error {
+	data, err := sonic.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	return c.cache.Set(ctx, c.getFeedKey(authorId, format), data, time.Hour)
+}
+
+func (c *CachedArticleRepository) DelFeed(ctx context.Context, authorId int64) error {
+	var errs []error
+	for _, id := range lo.Uniq([]int64{authorId, 0}) {
+		for _, format := range []feedx.Format{feedx.FormatRSS, feedx.FormatAtom} {
+			if err := c.cache.Delete(ctx, c.getFeedKey(id, format)); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (c *CachedArticleRepository) getFeedKey(authorId int64, format feedx.Format) string {
+	return ArticleFeedKey + strconv.FormatInt(authorId, 10) + ":" + format.String()
+}
+
 func (c *CachedArticleRepository) GetCachePageKey(uid int64) string {
 	return ArticleFirstPageKey + strconv.FormatInt(uid, 10)
 }
@@ -216,10 +657,6 @@ func (c *CachedArticleRepository) GetCacheArticleKey(id int64) string {
 	return ArticleKey + strconv.FormatInt(id, 10)
 }
 
-func (c *CachedArticleRepository) GetPubCacheArticleKey(id int64) string {
-	return PubArticleKey + strconv.FormatInt(id, 10)
-}
-
 func (c *CachedArticleRepository) GetFirstPage(ctx context.Context, uid int64, limit int) ([]domain.Article, error) {
 	bytes, err := c.cache.Get(ctx, c.GetCachePageKey(uid))
 	if err != nil {
@@ -236,23 +673,23 @@ func (c *CachedArticleRepository) GetFirstPage(ctx context.Context, uid int64, l
 	return res[:limit], nil
 }
 
-func (c *CachedArticleRepository) GetArticlesByAuthor(ctx context.Context, uid int64, limit int, offset int) ([]domain.Article, error) {
-	if offset == 0 && limit <= 100 { //如果是第一页，且limit小于100，从缓存中取
+func (c *CachedArticleRepository) GetArticlesByAuthor(ctx context.Context, uid int64, cursor domain.Cursor, limit int) ([]domain.Article, error) {
+	if cursor.IsZero() && limit <= 100 { //如果是第一页，且limit小于100，从缓存中取
 		firstPage, err := c.GetFirstPage(ctx, uid, limit)
 		if err == nil {
 			return firstPage, nil
 		}
 		c.log.Warn("get first page from cache failed", zap.Error(err))
 	}
-	articles, err := c.dao.GetArticlesByAuthor(ctx, uid, limit, offset)
+	articles, err := c.dao.GetArticlesByAuthor(ctx, uid, dao.Cursor(cursor), limit)
 	if err != nil {
 		return nil, err
 	}
 	go func() { //异步更新缓存
-		if offset != 0 || limit > 100 { //如果不是第一页，或者limit大于100，没有必要更新缓存
+		if !cursor.IsZero() || limit > 100 { //如果不是第一页，或者limit大于100，没有必要更新缓存
 			return
 		}
-		firstPageRes, err2 := c.dao.GetArticlesByAuthor(ctx, uid, 100, 0)
+		firstPageRes, err2 := c.dao.GetArticlesByAuthor(ctx, uid, dao.Cursor{}, 100)
 		if err2 != nil {
 			c.log.Error("get first page from db failed", zap.Error(err2))
 			return
@@ -283,105 +720,412 @@ func (c *CachedArticleRepository) GetArticlesByAuthor(ctx context.Context, uid i
 	}), nil
 }
 
-func NewCachedArticleRepository(dao dao.ArticleDAO, cache cache.ArticleCache,
-	userRepo repository.UserRepository, log *zap.Logger, client intrv1.InteractiveServiceClient) ArticleRepository {
-	return &CachedArticleRepository{dao: dao, cache: cache, userRepo: userRepo, log: log, interactiveService: client}
+// ListAllByAuthor 导出时需要正文 逐篇从对象存储读取
+func (c *CachedArticleRepository) ListAllByAuthor(ctx context.Context, uid int64, cursor domain.Cursor, limit int) ([]domain.Article, error) {
+	articles, err := c.dao.GetArticlesByAuthor(ctx, uid, dao.Cursor(cursor), limit)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]domain.Article, 0, len(articles))
+	for _, article := range articles {
+		art := c.daoToDomain(article)
+		if err = c.fillContent(ctx, &art); err != nil {
+			return nil, err
+		}
+		res = append(res, art)
+	}
+	return res, nil
+}
+
+func NewCachedArticleRepository(dao dao.ArticleDAO, cache cache.ArticleCache, pubCache cache.PubArticleCache,
+	searcher search.ArticleSearcher, userRepo repository.UserRepository, log *zap.Logger,
+	client intrv1.InteractiveServiceClient, listener PublishListener, producer invalidation.Producer,
+	contents objstore.Store, contentCache cache.ArticleContentCache) ArticleRepository {
+	return &CachedArticleRepository{dao: dao, cache: cache, pubCache: pubCache, searcher: searcher, userRepo: userRepo,
+		log: log, interactiveService: client, listener: listener, producer: producer,
+		contents: contents, contentCache: contentCache}
 }
 
 func (c *CachedArticleRepository) SyncStatus(ctx context.Context, article domain.Article, articleStatus domain.ArticleStatus) error {
-	err := c.dao.SyncStatus(ctx, c.domainToDao(article), uint8(articleStatus))
-	delErr := c.DelFirstPage(ctx, article.Author.ID)
-	// 删除读者缓存
-	pubErr := c.DelCache(ctx, article.ID, ArticleReader)
-	if delErr != nil {
-		c.log.Error("delete first page from cache failed", zap.Error(delErr))
-	}
-	if pubErr != nil {
-		c.log.Warn("delete article from cache failed", zap.Error(err))
-	}
+	err := c.dao.SyncStatus(ctx, c.domainToDao(article), uint8(article.Status), uint8(articleStatus))
+	dao.AfterCommit(ctx, func() {
+		if err == nil {
+			go c.refreshSearchIndex(article.ID)
+		}
+		delErr := c.DelFirstPage(ctx, article.Author.ID)
+		// 删除读者缓存
+		pubErr := c.DelCache(ctx, article.ID, ArticleReader)
+		if delErr != nil {
+			c.log.Error("delete first page from cache failed", zap.Error(delErr))
+		}
+		if pubErr != nil {
+			c.log.Warn("delete article from cache failed", zap.Error(err))
+		}
+		c.delFeed(ctx, article.Author.ID)
+		if err == nil {
+			c.publishInvalidation(article.ID, article.Author.ID)
+		}
+	})
 	return err
 }
 
-func (c *CachedArticleRepository) Sync(ctx context.Context, article domain.Article) (int64, error) {
-	sync, err := c.dao.Sync(ctx, c.domainToDao(article))
-	delErr := c.DelFirstPage(ctx, article.Author.ID)
-	// 删除读者缓存
-	pubErr := c.DelCache(ctx, article.ID, ArticleReader)
-	if delErr != nil {
-		c.log.Error("delete first page from cache failed", zap.Error(delErr))
-	}
-	if pubErr != nil {
-		c.log.Warn("delete article from cache failed", zap.Error(err))
+func (c *CachedArticleRepository) UpdateStatus(ctx context.Context, article domain.Article, articleStatus domain.ArticleStatus) error {
+	err := c.dao.UpdateStatus(ctx, c.domainToDao(article), uint8(article.Status), uint8(articleStatus))
+	dao.AfterCommit(ctx, func() {
+		delErr := c.DelFirstPage(ctx, article.Author.ID)
+		// 删除作者缓存
+		authorErr := c.DelCache(ctx, article.ID, ArticleAuthor)
+		if delErr != nil {
+			c.log.Error("delete first page from cache failed", zap.Error(delErr))
+		}
+		if authorErr != nil {
+			c.log.Warn("delete article from cache failed", zap.Error(authorErr))
+		}
+		if err == nil {
+			c.publishInvalidation(article.ID, article.Author.ID)
+		}
+	})
+	return err
+}
+
+func (c *CachedArticleRepository) Sync(ctx context.Context, article domain.Article, from domain.ArticleStatus) (int64, error) {
+	toDao, err := c.putContent(ctx, article)
+	if err != nil {
+		return 0, err
 	}
+	sync, err := c.dao.Sync(ctx, toDao, uint8(from))
+	dao.AfterCommit(ctx, func() {
+		if err == nil {
+			go c.refreshSearchIndex(sync)
+			if article.Status == domain.ArticleStatusPublished {
+				article.ID = sync
+				go c.notifyPublish(article)
+			}
+		}
+		delErr := c.DelFirstPage(ctx, article.Author.ID)
+		// 删除读者缓存
+		pubErr := c.DelCache(ctx, article.ID, ArticleReader)
+		// 删除作者缓存 否则作者拿到的是旧的版本号
+		authorErr := c.DelCache(ctx, article.ID, ArticleAuthor)
+		if delErr != nil {
+			c.log.Error("delete first page from cache failed", zap.Error(delErr))
+		}
+		if pubErr != nil {
+			c.log.Warn("delete article from cache failed", zap.Error(err))
+		}
+		if authorErr != nil {
+			c.log.Warn("delete article from cache failed", zap.Error(authorErr))
+		}
+		c.delFeed(ctx, article.Author.ID)
+		if err == nil {
+			c.publishInvalidation(sync, article.Author.ID)
+		}
+	})
 	return sync, err
 }
 
-func (c *CachedArticleRepository) Update(ctx context.Context, article domain.Article) error {
-	err := c.dao.UpdateById(ctx, c.domainToDao(article))
-	delErr := c.DelFirstPage(ctx, article.Author.ID)
-	if delErr != nil {
-		c.log.Warn("delete first page from cache failed", zap.Error(delErr))
+func (c *CachedArticleRepository) Update(ctx context.Context, article domain.Article, from domain.ArticleStatus) error {
+	toDao, err := c.putContent(ctx, article)
+	if err != nil {
+		return err
 	}
+	err = c.dao.UpdateById(ctx, toDao, uint8(from))
+	dao.AfterCommit(ctx, func() {
+		delErr := c.DelFirstPage(ctx, article.Author.ID)
+		if delErr != nil {
+			c.log.Warn("delete first page from cache failed", zap.Error(delErr))
+		}
+		// 删除作者缓存 否则作者拿到的是旧的版本号
+		authorErr := c.DelCache(ctx, article.ID, ArticleAuthor)
+		if authorErr != nil {
+			c.log.Warn("delete article from cache failed", zap.Error(authorErr))
+		}
+		if err == nil {
+			c.publishInvalidation(article.ID, article.Author.ID)
+		}
+	})
 	return err
 }
 
+// Delete 移入回收站 文章同时下线，需要清理读者、作者缓存与搜索索引
+func (c *CachedArticleRepository) Delete(ctx context.Context, article domain.Article) error {
+	err := c.dao.Delete(ctx, article.ID, article.Author.ID, uint8(article.Status))
+	if err != nil {
+		return err
+	}
+	dao.AfterCommit(ctx, func() {
+		if er := c.searcher.Delete(ctx, article.ID); er != nil {
+			c.log.Warn("delete article from search index failed", zap.Int64("article_id", article.ID), zap.Error(er))
+		}
+		c.delCaches(ctx, article)
+		c.delFeed(ctx, article.Author.ID)
+		c.publishInvalidation(article.ID, article.Author.ID)
+	})
+	return nil
+}
+
+func (c *CachedArticleRepository) ListTrash(ctx context.Context, uid int64, cursor domain.Cursor, limit int) ([]domain.Article, error) {
+	articles, err := c.dao.GetDeletedByAuthor(ctx, uid, dao.Cursor(cursor), limit)
+	if err != nil {
+		return nil, err
+	}
+	return lo.Map(articles, func(article dao.Article, index int) domain.Article {
+		return c.daoToDomain(article)
+	}), nil
+}
+
+// Restore 恢复为未发表的草稿 需要重新发表才会上线
+func (c *CachedArticleRepository) Restore(ctx context.Context, article domain.Article) error {
+	err := c.dao.Restore(ctx, article.ID, article.Author.ID)
+	if err != nil {
+		return err
+	}
+	dao.AfterCommit(ctx, func() {
+		c.delCaches(ctx, article)
+		c.publishInvalidation(article.ID, article.Author.ID)
+	})
+	return nil
+}
+
+func (c *CachedArticleRepository) PurgeTrash(ctx context.Context, before time.Time, limit int) (int64, error) {
+	return c.dao.PurgeDeleted(ctx, before.Unix(), limit)
+}
+
+// CollectContent 标记-清除 正文先写入对象存储再写数据库，重复写入相同的正文也会更新写入时间，
+// 所以只清理 before 之前写入的对象，给正在保存的文章留出时间
+// 遍历到的写入时间可能已经过期：检查引用之前刚好有文章重新写入了相同的正文、还没有写数据库，
+// 因此确认没有引用后重新获取一次写入时间，期间被重新写入的对象留到下一次清理
+func (c *CachedArticleRepository) CollectContent(ctx context.Context, before time.Time) (int64, error) {
+	var deleted int64
+	err := c.contents.Walk(ctx, func(obj objstore.Object) error {
+		if !obj.ModTime.Before(before) {
+			return nil
+		}
+		referenced, err := c.dao.ContentReferenced(ctx, obj.Key)
+		if err != nil || referenced {
+			return err
+		}
+		current, err := c.contents.Stat(ctx, obj.Key)
+		if errors.Is(err, objstore.ErrNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if !current.ModTime.Before(before) {
+			return nil
+		}
+		if err = c.contents.Delete(ctx, obj.Key); err != nil {
+			return err
+		}
+		deleted++
+		return nil
+	})
+	return deleted, err
+}
+
+func (c *CachedArticleRepository) EvictCaches(ctx context.Context, articleId int64, authorId int64) error {
+	return errors.Join(
+		c.DelFirstPage(ctx, authorId),
+		c.DelCache(ctx, articleId, ArticleAuthor),
+		c.DelCache(ctx, articleId, ArticleReader),
+		c.DelFeed(ctx, authorId),
+	)
+}
+
+// publishInvalidation 写入后直接删除缓存只是尽力而为，失败的删除以及其他实例的本地缓存
+// 由所有实例消费失效事件时再删除一次，发送和消费时都会重试
+func (c *CachedArticleRepository) publishInvalidation(articleId int64, authorId int64) {
+	go func() {
+		evt := invalidation.CacheInvalidationEvent{ArticleId: articleId, AuthorId: authorId}
+		err := kafkax.Retry(context.Background(), maxProduceInvalidationAttempts, produceInvalidationBackoff, func() error {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			return c.producer.ProduceCacheInvalidationEvent(ctx, evt)
+		}, func(attempt int, err error) {
+			c.log.Warn("produce article cache invalidation event failed", zap.Int64("article_id", articleId),
+				zap.Int("attempt", attempt), zap.Error(err))
+		})
+		if err != nil {
+			c.log.Error("produce article cache invalidation event failed after retries",
+				zap.Int64("article_id", articleId), zap.Error(err))
+		}
+	}()
+}
+
+// delFeed 文章上线、下线后删除作者和全站的订阅源缓存 失败只记录日志
+func (c *CachedArticleRepository) delFeed(ctx context.Context, authorId int64) {
+	if err := c.DelFeed(ctx, authorId); err != nil {
+		c.log.Warn("delete feed from cache failed", zap.Int64("author_id", authorId), zap.Error(err))
+	}
+}
+
+// delCaches 删除文章的作者缓存、读者缓存以及作者的第一页缓存 失败只记录日志
+func (c *CachedArticleRepository) delCaches(ctx context.Context, article domain.Article) {
+	if err := c.DelFirstPage(ctx, article.Author.ID); err != nil {
+		c.log.Warn("delete first page from cache failed", zap.Error(err))
+	}
+	if err := c.DelCache(ctx, article.ID, ArticleAuthor); err != nil {
+		c.log.Warn("delete article from cache failed", zap.Error(err))
+	}
+	if err := c.DelCache(ctx, article.ID, ArticleReader); err != nil {
+		c.log.Warn("delete published article from cache failed", zap.Error(err))
+	}
+}
+
 func (c *CachedArticleRepository) Create(ctx context.Context, article domain.Article) (int64, error) {
-	insert, err := c.dao.Insert(ctx, c.domainToDao(article))
-	delErr := c.DelFirstPage(ctx, article.Author.ID)
-	if delErr != nil {
-		c.log.Warn("delete first page from cache failed", zap.Error(delErr))
+	toDao, err := c.putContent(ctx, article)
+	if err != nil {
+		return 0, err
 	}
+	insert, err := c.dao.Insert(ctx, toDao)
+	dao.AfterCommit(ctx, func() {
+		delErr := c.DelFirstPage(ctx, article.Author.ID)
+		if delErr != nil {
+			c.log.Warn("delete first page from cache failed", zap.Error(delErr))
+		}
+		if err == nil {
+			c.publishInvalidation(insert, article.Author.ID)
+		}
+	})
 	return insert, err
 }
 
 func (c *CachedArticleRepository) domainToDao(article domain.Article) dao.Article {
+	toc, _ := sonic.MarshalString(article.Toc)
 	return dao.Article{
-		ID:       article.ID,
-		Title:    article.Title,
-		Content:  article.Content,
-		AuthorId: article.Author.ID,
-		Status:   uint8(article.Status),
+		ID:          article.ID,
+		Title:       article.Title,
+		Content:     article.Content,
+		AuthorId:    article.Author.ID,
+		Status:      uint8(article.Status),
+		Version:     article.Version,
+		Category:    article.Category,
+		Tags:        article.Tags,
+		Html:        article.Html,
+		Abstract:    article.Abstract,
+		WordCount:   article.WordCount,
+		ReadingTime: article.ReadingTime,
+		Toc:         toc,
 	}
 }
 
 func (c *CachedArticleRepository) daoToDomain(article dao.Article) domain.Article {
-	var abstract string
-	runeContent := []rune(article.Content)
-	// 取前128个字符作为摘要
-	if len(runeContent) > 128 {
-		abstract = string(runeContent[:128])
-	} else {
-		abstract = string(runeContent)
+	res := domain.Article{
+		ID:          article.ID,
+		Title:       article.Title,
+		Content:     article.Content,
+		ContentKey:  article.ContentKey,
+		Abstract:    article.Abstract,
+		Author:      domain.Author{ID: article.AuthorId},
+		Status:      domain.ArticleStatus(article.Status),
+		RevisionId:  article.RevisionId,
+		Version:     article.Version,
+		Category:    article.Category,
+		Tags:        article.Tags,
+		Html:        article.Html,
+		WordCount:   article.WordCount,
+		ReadingTime: article.ReadingTime,
+		Dtime:       article.Dtime,
+		Ctime:       article.Ctime,
+		Utime:       article.Utime,
 	}
-	return domain.Article{
-		ID:       article.ID,
-		Title:    article.Title,
-		Content:  article.Content,
-		Abstract: abstract,
-		Author:   domain.Author{ID: article.AuthorId},
-		Status:   domain.ArticleStatus(article.Status),
-		Ctime:    article.Ctime,
-		Utime:    article.Utime,
+	if article.Html == "" && article.Content != "" {
+		// 早期的文章没有保存渲染结果，读取时再渲染
+		if err := res.Render(); err != nil {
+			c.log.Warn("render article failed", zap.Int64("article_id", article.ID), zap.Error(err))
+		}
+		return res
+	}
+	if article.Toc != "" {
+		if err := sonic.UnmarshalString(article.Toc, &res.Toc); err != nil {
+			c.log.Warn("unmarshal article toc failed", zap.Int64("article_id", article.ID), zap.Error(err))
+		}
 	}
+	return res
 }
 
 func (c *CachedArticleRepository) pubDaoToDomain(article dao.PublishedArticle) domain.Article {
-	var abstract string
-	runeContent := []rune(article.Content)
-	// 取前128个字符作为摘要
-	if len(runeContent) > 128 {
-		abstract = string(runeContent[:128])
-	} else {
-		abstract = string(runeContent)
-	}
-	return domain.Article{
-		ID:       article.ID,
-		Title:    article.Title,
-		Content:  article.Content,
-		Abstract: abstract,
-		Author:   domain.Author{ID: article.AuthorId},
-		Status:   domain.ArticleStatus(article.Status),
-		Ctime:    article.Ctime,
-		Utime:    article.Utime,
+	return c.daoToDomain(dao.Article(article))
+}
+
+func (c *CachedArticleRepository) revisionToDomain(ctx context.Context, revision dao.ArticleRevision) (domain.ArticleRevision, error) {
+	content, err := c.getContent(ctx, revision.ContentKey, revision.Content)
+	if err != nil {
+		return domain.ArticleRevision{}, err
+	}
+	return domain.ArticleRevision{
+		ID:        revision.ID,
+		ArticleID: revision.ArticleId,
+		Title:     revision.Title,
+		Content:   content,
+		Ctime:     revision.Ctime,
+	}, nil
+}
+
+// putContent 把正文写入对象存储，数据库中只保存正文的 key，HTML 和目录在读取时由正文渲染
+// 相同的正文 key 也相同，重复写入没有副作用
+func (c *CachedArticleRepository) putContent(ctx context.Context, article domain.Article) (dao.Article, error) {
+	res := c.domainToDao(article)
+	if article.Content == "" {
+		return res, nil
+	}
+	data := []byte(article.Content)
+	key := objstore.ContentKey(data)
+	if err := c.contents.Put(ctx, key, data); err != nil {
+		return res, err
+	}
+	if article.Html != "" {
+		c.contentCache.Set(ctx, key, cache.ArticleContent{Content: article.Content, Html: article.Html, Toc: article.Toc})
+	}
+	res.ContentKey, res.Content, res.Html, res.Toc = key, "", "", ""
+	return res, nil
+}
+
+// getContent 按 key 读取正文 迁移到对象存储之前的旧数据没有 key，正文就是 legacy
+func (c *CachedArticleRepository) getContent(ctx context.Context, key string, legacy string) (string, error) {
+	if key == "" {
+		return legacy, nil
+	}
+	body, err := c.getBody(ctx, key)
+	return body.Content, err
+}
+
+// getBody 按 key 读取正文并渲染 渲染结果与正文一起缓存在本地
+func (c *CachedArticleRepository) getBody(ctx context.Context, key string) (cache.ArticleContent, error) {
+	if body, ok := c.contentCache.Get(ctx, key); ok {
+		return body, nil
+	}
+	data, err := c.contents.Get(ctx, key)
+	if err != nil {
+		return cache.ArticleContent{}, errors.Wrapf(err, "读取文章正文 %s 失败", key)
+	}
+	art := domain.Article{Content: string(data)}
+	if err = art.Render(); err != nil {
+		return cache.ArticleContent{}, errors.Wrapf(err, "渲染文章正文 %s 失败", key)
+	}
+	body := cache.ArticleContent{Content: art.Content, Html: art.Html, Toc: art.Toc}
+	c.contentCache.Set(ctx, key, body)
+	return body, nil
+}
+
+// fillContent 补全从数据库或缓存中读到的文章的正文、HTML 和目录
+func (c *CachedArticleRepository) fillContent(ctx context.Context, article *domain.Article) error {
+	if article.ContentKey == "" || article.Content != "" {
+		return nil
+	}
+	body, err := c.getBody(ctx, article.ContentKey)
+	if err != nil {
+		return err
+	}
+	article.Content, article.Html, article.Toc = body.Content, body.Html, body.Toc
+	return nil
+}
+
+// stripContent 正文保存在对象存储中的文章，缓存中只保存 key 正文、HTML 和目录都在读取时补全
+func (c *CachedArticleRepository) stripContent(article domain.Article) domain.Article {
+	if article.ContentKey != "" {
+		article.Content, article.Html, article.Toc = "", "", nil
 	}
+	return article
 }
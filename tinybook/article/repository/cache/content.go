@@ -0,0 +1,57 @@
+package cache
+
+import (
+	"context"
+	"github.com/Yiling-J/theine-go"
+	"time"
+	"tinybook/tinybook/article/domain"
+)
+
+const (
+	// 正文按内容的哈希保存，内容变了 key 也会变，不需要失效 过期只是为了腾出空间
+	articleContentTTL = time.Hour
+)
+
+// ArticleContent 正文以及由正文渲染得到的 HTML 和目录 渲染结果不落库，读取时渲染一次后与正文一起缓存
+type ArticleContent struct {
+	Content string
+	Html    string
+	Toc     []domain.TocItem
+}
+
+// size 缓存按字节数计算容量 目录很小，只粗略估算
+func (a ArticleContent) size() int64 {
+	n := len(a.Content) + len(a.Html)
+	for _, item := range a.Toc {
+		n += len(item.Title) + len(item.Anchor) + 8
+	}
+	return int64(n)
+}
+
+// ArticleContentCache 文章正文的本地缓存 key 为正文在对象存储中的 key
+type ArticleContentCache interface {
+	Get(ctx context.Context, key string) (ArticleContent, bool)
+	Set(ctx context.Context, key string, content ArticleContent)
+}
+
+// LocalArticleContentCache 正文大小差别很大 使用单独的缓存，按字节数限制容量
+type LocalArticleContentCache struct {
+	cli *theine.Cache[string, ArticleContent]
+}
+
+// NewLocalArticleContentCache maxBytes 为缓存的正文和渲染结果的总字节数上限
+func NewLocalArticleContentCache(maxBytes int64) (ArticleContentCache, error) {
+	cli, err := theine.NewBuilder[string, ArticleContent](maxBytes).Build()
+	if err != nil {
+		return nil, err
+	}
+	return &LocalArticleContentCache{cli: cli}, nil
+}
+
+func (l *LocalArticleContentCache) Get(ctx context.Context, key string) (ArticleContent, bool) {
+	return l.cli.Get(key)
+}
+
+func (l *LocalArticleContentCache) Set(ctx context.Context, key string, content ArticleContent) {
+	l.cli.SetWithTTL(key, content, content.size(), jitter(articleContentTTL))
+}
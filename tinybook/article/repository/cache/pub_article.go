@@ -0,0 +1,135 @@
+package cache
+
+import (
+	"context"
+	"github.com/Yiling-J/theine-go"
+	"github.com/bytedance/sonic"
+	"github.com/cockroachdb/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/redis/go-redis/v9"
+	"math/rand"
+	"strconv"
+	"time"
+	"tinybook/tinybook/article/domain"
+)
+
+const (
+	pubArticleKey = "article:pub:"
+	// notFoundValue 不存在的文章在 redis 中的占位值 不是合法的 json
+	notFoundValue = "-"
+
+	// 本地缓存只用来挡住热点文章的并发读取 各实例之间不同步，过期时间要短
+	pubArticleLocalTTL    = 30 * time.Second
+	pubArticleRedisTTL    = 3 * 24 * time.Hour
+	pubArticleNotFoundTTL = time.Minute
+)
+
+var (
+	ErrPubArticleMiss = errors.New("已发表文章缓存未命中")
+	// ErrPubArticleNotFound 命中了不存在的文章的空缓存
+	ErrPubArticleNotFound = errors.New("文章不存在")
+)
+
+// notFound 本地缓存中不存在的文章的占位值
+type notFound struct{}
+
+// PubArticleCache 已发表文章的两级缓存 先查本地缓存，再查 redis
+// 不存在的文章也会缓存一小段时间，避免不存在的ID每次都打到数据库
+type PubArticleCache interface {
+	// Get 两级都未命中时返回 ErrPubArticleMiss，命中空缓存时返回 ErrPubArticleNotFound
+	Get(ctx context.Context, id int64) (domain.Article, error)
+	Set(ctx context.Context, article domain.Article) error
+	SetNotFound(ctx context.Context, id int64) error
+	Delete(ctx context.Context, id int64) error
+}
+
+type MultiLevelPubArticleCache struct {
+	local *theine.Cache[string, any]
+	cli   redis.Cmdable
+	// 按 layer(local/redis) 和 result(hit/miss/not_found) 统计命中情况
+	counter *prometheus.CounterVec
+}
+
+func NewMultiLevelPubArticleCache(local *theine.Cache[string, any], cli redis.Cmdable, counter *prometheus.CounterVec) PubArticleCache {
+	return &MultiLevelPubArticleCache{local: local, cli: cli, counter: counter}
+}
+
+func (m *MultiLevelPubArticleCache) Get(ctx context.Context, id int64) (domain.Article, error) {
+	key := m.key(id)
+	if val, ok := m.local.Get(key); ok {
+		switch v := val.(type) {
+		case domain.Article:
+			m.record("local", "hit")
+			return v, nil
+		case notFound:
+			m.record("local", "not_found")
+			return domain.Article{}, ErrPubArticleNotFound
+		}
+	}
+	m.record("local", "miss")
+	data, err := m.cli.Get(ctx, key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		m.record("redis", "miss")
+		return domain.Article{}, ErrPubArticleMiss
+	}
+	if err != nil {
+		return domain.Article{}, err
+	}
+	if string(data) == notFoundValue {
+		m.record("redis", "not_found")
+		m.local.SetWithTTL(key, notFound{}, 1, jitter(pubArticleLocalTTL))
+		return domain.Article{}, ErrPubArticleNotFound
+	}
+	m.record("redis", "hit")
+	var res domain.Article
+	if err = sonic.Unmarshal(data, &res); err != nil {
+		return domain.Article{}, err
+	}
+	m.local.SetWithTTL(key, res, 1, jitter(pubArticleLocalTTL))
+	return res, nil
+}
+
+func (m *MultiLevelPubArticleCache) Set(ctx context.Context, article domain.Article) error {
+	data, err := sonic.Marshal(article)
+	if err != nil {
+		return err
+	}
+	key := m.key(article.ID)
+	// 过期时间加上随机量 避免同一批写入的缓存同时过期
+	err = m.cli.Set(ctx, key, data, jitter(pubArticleRedisTTL)).Err()
+	if err != nil {
+		return err
+	}
+	m.local.SetWithTTL(key, article, 1, jitter(pubArticleLocalTTL))
+	return nil
+}
+
+func (m *MultiLevelPubArticleCache) SetNotFound(ctx context.Context, id int64) error {
+	key := m.key(id)
+	err := m.cli.Set(ctx, key, notFoundValue, jitter(pubArticleNotFoundTTL)).Err()
+	if err != nil {
+		return err
+	}
+	m.local.SetWithTTL(key, notFound{}, 1, jitter(pubArticleLocalTTL))
+	return nil
+}
+
+// Delete 只能删除当前实例的本地缓存 其他实例在消费缓存失效事件时删除
+func (m *MultiLevelPubArticleCache) Delete(ctx context.Context, id int64) error {
+	key := m.key(id)
+	m.local.Delete(key)
+	return m.cli.Del(ctx, key).Err()
+}
+
+func (m *MultiLevelPubArticleCache) key(id int64) string {
+	return pubArticleKey + strconv.FormatInt(id, 10)
+}
+
+func (m *MultiLevelPubArticleCache) record(layer string, result string) {
+	m.counter.WithLabelValues(layer, result).Inc()
+}
+
+// jitter 在 ttl 的基础上随机增加至多 10%
+func jitter(ttl time.Duration) time.Duration {
+	return ttl + time.Duration(rand.Int63n(int64(ttl)/10+1))
+}
@@ -0,0 +1,71 @@
+package cache
+
+import (
+	"context"
+	"github.com/bytedance/sonic"
+	"github.com/cockroachdb/errors"
+	"github.com/redis/go-redis/v9"
+	"strconv"
+	"time"
+	"tinybook/tinybook/article/domain"
+)
+
+const (
+	articleSeriesKey = "article:series:"
+	articleSeriesTTL = 24 * time.Hour
+)
+
+var ErrSeriesMiss = errors.New("文章所在系列的缓存未命中")
+
+// SeriesCache 按文章缓存文章所在的系列 读者查看每篇文章时都要用到
+// 系列有任何修改时删除其中全部文章的缓存
+type SeriesCache interface {
+	// GetByArticle 未命中时返回 ErrSeriesMiss 文章不属于任何系列时返回 ID 为 0 的系列
+	GetByArticle(ctx context.Context, artId int64) (domain.Series, error)
+	SetByArticle(ctx context.Context, artId int64, series domain.Series) error
+	DelByArticles(ctx context.Context, artIds []int64) error
+}
+
+type RedisSeriesCache struct {
+	cli redis.Cmdable
+}
+
+func NewRedisSeriesCache(cli redis.Cmdable) SeriesCache {
+	return &RedisSeriesCache{cli: cli}
+}
+
+func (r *RedisSeriesCache) GetByArticle(ctx context.Context, artId int64) (domain.Series, error) {
+	data, err := r.cli.Get(ctx, r.key(artId)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return domain.Series{}, ErrSeriesMiss
+	}
+	if err != nil {
+		return domain.Series{}, err
+	}
+	var series domain.Series
+	err = sonic.Unmarshal(data, &series)
+	return series, err
+}
+
+func (r *RedisSeriesCache) SetByArticle(ctx context.Context, artId int64, series domain.Series) error {
+	data, err := sonic.Marshal(series)
+	if err != nil {
+		return err
+	}
+	return r.cli.Set(ctx, r.key(artId), data, jitter(articleSeriesTTL)).Err()
+}
+
+func (r *RedisSeriesCache) DelByArticles(ctx context.Context, artIds []int64) error {
+	if len(artIds) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(artIds))
+	for _, id := range artIds {
+		keys = append(keys, r.key(id))
+	}
+	return r.cli.Del(ctx, keys...).Err()
+}
+
+func (r *RedisSeriesCache) key(artId int64) string {
+	return articleSeriesKey + strconv.FormatInt(artId, 10)
+}
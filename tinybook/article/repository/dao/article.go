@@ -4,37 +4,188 @@ import (
 	"context"
 	"github.com/cockroachdb/errors"
 	"github.com/godruoyi/go-snowflake"
-	"github.com/here-Leslie-Lau/mongo-plus/mongo"
 	"github.com/qiniu/qmgo"
+	"github.com/qiniu/qmgo/options"
 	"go.mongodb.org/mongo-driver/bson"
+	mgoptions "go.mongodb.org/mongo-driver/mongo/options"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
+	"strconv"
+	"sync"
 	"time"
 )
 
+var (
+	ErrAuthorMismatch  = errors.New("作者ID与文章ID不匹配")
+	ErrVersionConflict = errors.New("文章版本冲突")
+	ErrArticleDeleted  = errors.New("文章已删除")
+	ErrArticleNotFound = errors.New("文章不存在")
+	// ErrStatusChanged 文章当前的状态与调用方读到的不一致 并发修改状态时只有一个能成功
+	ErrStatusChanged = errors.New("文章状态已变化")
+)
+
+// 与 domain.ArticleStatus 的取值保持一致
+const (
+	statusUnpublished uint8 = 1
+	statusPublished   uint8 = 2
+	statusDeleted     uint8 = 5
+)
+
+// VersionConflictError 文章已被其他人(或其他设备)修改，携带服务端当前版本号
+type VersionConflictError struct {
+	Current int64
+}
+
+func (e *VersionConflictError) Error() string {
+	return ErrVersionConflict.Error() + ", 当前版本: " + strconv.FormatInt(e.Current, 10)
+}
+
+func (e *VersionConflictError) Is(target error) bool {
+	return target == ErrVersionConflict
+}
+
+// ArticleDAO 修改已有文章时 from 为调用方读到的状态，文章已经不是这个状态时返回 ErrStatusChanged
 type ArticleDAO interface {
 	Insert(ctx context.Context, article Article) (int64, error)
-	UpdateById(ctx context.Context, article Article) error
-	Sync(ctx context.Context, dao Article) (int64, error)
-	SyncStatus(ctx context.Context, dao Article, u uint8) error
-	GetArticlesByAuthor(ctx context.Context, uid int64, limit int, offset int) ([]Article, error)
+	UpdateById(ctx context.Context, article Article, from uint8) error
+	Sync(ctx context.Context, dao Article, from uint8) (int64, error)
+	SyncStatus(ctx context.Context, dao Article, from uint8, to uint8) error
+	UpdateStatus(ctx context.Context, dao Article, from uint8, to uint8) error
+	GetArticlesByAuthor(ctx context.Context, uid int64, cursor Cursor, limit int) ([]Article, error)
+	// GetArticleById 草稿不存在时返回 ErrArticleNotFound
 	GetArticleById(ctx context.Context, id int64) (Article, error)
+	// GetPubArticleById 线上库中没有这篇文章时返回 ErrArticleNotFound
 	GetPubArticleById(ctx context.Context, id int64) (PublishedArticle, error)
-	GetPubList(ctx context.Context, t time.Time, limit int, offset int) ([]PublishedArticle, error)
+	GetPubList(ctx context.Context, cursor Cursor, limit int) ([]PublishedArticle, error)
+	GetPubListByAuthor(ctx context.Context, uid int64, cursor Cursor, limit int) ([]PublishedArticle, error)
+	// GetPubListByIds 批量获取已发表的文章 不保证顺序
+	GetPubListByIds(ctx context.Context, ids []int64) ([]PublishedArticle, error)
+	GetRevisions(ctx context.Context, artId int64, limit int, offset int) ([]ArticleRevision, error)
+	GetRevisionById(ctx context.Context, artId int64, revId int64) (ArticleRevision, error)
+	GetPubListByTag(ctx context.Context, tag string, limit int, offset int) ([]PublishedArticle, error)
+	GetPubListByCategory(ctx context.Context, category string, limit int, offset int) ([]PublishedArticle, error)
+	GetTagCounts(ctx context.Context, limit int) ([]TagCount, error)
+	// Delete 把文章移入回收站 同时下线
+	Delete(ctx context.Context, id int64, authorId int64, from uint8) error
+	// GetDeletedByAuthor 回收站列表 按删除时间倒序
+	GetDeletedByAuthor(ctx context.Context, uid int64, cursor Cursor, limit int) ([]Article, error)
+	// Restore 从回收站恢复为未发表的草稿
+	Restore(ctx context.Context, id int64, authorId int64) error
+	// PurgeDeleted 彻底删除 before 之前移入回收站的文章 返回删除的数量
+	PurgeDeleted(ctx context.Context, before int64, limit int) (int64, error)
+	// ContentReferenced 草稿、线上文章或历史版本中是否还有正文的 key 为 key 的记录
+	ContentReferenced(ctx context.Context, key string) (bool, error)
+}
+
+// Cursor 按 (utime, id) 倒序翻页的游标，取严格排在游标之后的数据 零值表示从头开始
+type Cursor struct {
+	Utime int64
+	ID    int64
+}
+
+func (c Cursor) IsZero() bool {
+	return c.Utime == 0 && c.ID == 0
+}
+
+// newerThan (utime1, id1) 是否排在 (utime2, id2) 之前
+func newerThan(utime1 int64, id1 int64, utime2 int64, id2 int64) bool {
+	return utime1 > utime2 || (utime1 == utime2 && id1 > id2)
+}
+
+// page 与数据库的 OFFSET/LIMIT 一致 limit 不大于 0 时不限制数量
+func page[T any](s []T, limit int, offset int) []T {
+	if offset >= len(s) {
+		return s[:0]
+	}
+	s = s[offset:]
+	if limit > 0 && limit < len(s) {
+		s = s[:limit]
+	}
+	return s
+}
+
+// statusMismatch 按作者、是否删除、状态的顺序检查当前文章 都匹配时返回 nil
+func statusMismatch(current Article, authorId int64, from uint8) error {
+	if current.AuthorId != authorId {
+		return ErrAuthorMismatch
+	}
+	if current.Status == statusDeleted {
+		return ErrArticleDeleted
+	}
+	if current.Status != from {
+		return ErrStatusChanged
+	}
+	return nil
 }
 
 type Article struct {
 	ID       int64  `gorm:"column:id;primaryKey;autoIncrement;not null" json:"id" bson:"id,omitempty"`
 	Title    string `gorm:"column:title;type:varchar(255);not null" json:"title" bson:"title,omitempty"`
-	Content  string `gorm:"column:content;type:BLOB;not null" json:"content" bson:"content,omitempty"`
-	AuthorId int64  `gorm:"index;column:author_id;not null" json:"author_id" bson:"author_id,omitempty"`
-	Status   uint8  `gorm:"column:status;type:tinyint(1);not null" json:"status" bson:"status,omitempty"`
-	Ctime    int64  `gorm:"column:ctime" json:"ctime" bson:"ctime,omitempty"`
-	Utime    int64  `gorm:"column:utime" json:"utime" bson:"utime,omitempty"`
+	Content  string `gorm:"column:content;type:BLOB" json:"content" bson:"content,omitempty"` // 只有迁移到对象存储之前的旧数据才有值
+	AuthorId int64  `gorm:"index;index:idx_author_utime,priority:1;column:author_id;not null" json:"author_id" bson:"author_id,omitempty"`
+	Status   uint8  `gorm:"index:idx_status_utime,priority:1;column:status;type:tinyint(1);not null" json:"status" bson:"status,omitempty"`
+	// 正文保存在对象存储中，key 为正文的 sha256
+	ContentKey string `gorm:"index;column:content_key;type:varchar(64);not null;default:''" json:"content_key" bson:"content_key,omitempty"`
+	// 在 articles 中表示当前草稿对应的版本，在 published_articles 中表示线上版本
+	RevisionId int64 `gorm:"column:revision_id" json:"revision_id" bson:"revision_id,omitempty"`
+	// 乐观锁版本号 每次修改内容加一
+	Version  int64  `gorm:"column:version;not null;default:0" json:"version" bson:"version,omitempty"`
+	Category string `gorm:"index;column:category;type:varchar(64);not null;default:''" json:"category" bson:"category,omitempty"`
+	// MySQL 中标签存放在 article_tags / published_article_tags 关联表，MongoDB 中直接存数组
+	Tags []string `gorm:"-" json:"tags" bson:"tags,omitempty"`
+	// 以下字段由 Content(Markdown) 渲染得到 正文保存在对象存储中时不保存 Html 和 Toc，读取时再渲染
+	Html        string `gorm:"column:html;type:MEDIUMBLOB" json:"html" bson:"html,omitempty"`
+	Abstract    string `gorm:"column:abstract;type:varchar(512)" json:"abstract" bson:"abstract,omitempty"`
+	WordCount   int    `gorm:"column:word_count;not null;default:0" json:"word_count" bson:"word_count,omitempty"`
+	ReadingTime int    `gorm:"column:reading_time;not null;default:0" json:"reading_time" bson:"reading_time,omitempty"`
+	Toc         string `gorm:"column:toc;type:text" json:"toc" bson:"toc,omitempty"` // 目录 JSON
+	// 移入回收站的时间 0 表示未删除
+	Dtime int64 `gorm:"index;column:dtime;not null;default:0" json:"dtime" bson:"dtime,omitempty"`
+	Ctime int64 `gorm:"column:ctime" json:"ctime" bson:"ctime,omitempty"`
+	// 游标分页按 (utime, id) 排序，InnoDB 二级索引自带主键，不需要再把 id 加到索引里
+	Utime int64 `gorm:"column:utime;index:idx_author_utime,priority:2;index:idx_status_utime,priority:2" json:"utime" bson:"utime,omitempty"`
 }
 
 type PublishedArticle Article
 
+// ArticleRevision 文章的历史版本 每次保存或发表都会新增一条，只增不改
+type ArticleRevision struct {
+	ID        int64  `gorm:"column:id;primaryKey;autoIncrement;not null" json:"id" bson:"id,omitempty"`
+	ArticleId int64  `gorm:"index;column:article_id;not null" json:"article_id" bson:"article_id,omitempty"`
+	AuthorId  int64  `gorm:"column:author_id;not null" json:"author_id" bson:"author_id,omitempty"`
+	Title     string `gorm:"column:title;type:varchar(255);not null" json:"title" bson:"title,omitempty"`
+	Content   string `gorm:"column:content;type:BLOB" json:"content" bson:"content,omitempty"` // 只有旧数据才有值
+	Ctime     int64  `gorm:"column:ctime" json:"ctime" bson:"ctime,omitempty"`
+	// 与 Article 一样 正文保存在对象存储中
+	ContentKey string `gorm:"index;column:content_key;type:varchar(64);not null;default:''" json:"content_key" bson:"content_key,omitempty"`
+}
+
+// ArticleTag 文章与标签的关联，与 Article 对应的是草稿的标签
+type ArticleTag struct {
+	ID        int64  `gorm:"column:id;primaryKey;autoIncrement;not null" json:"id"`
+	ArticleId int64  `gorm:"column:article_id;not null;uniqueIndex:uk_article_tag" json:"article_id"`
+	Tag       string `gorm:"column:tag;type:varchar(32);not null;uniqueIndex:uk_article_tag;index" json:"tag"`
+	Ctime     int64  `gorm:"column:ctime" json:"ctime"`
+}
+
+// PublishedArticleTag 线上文章的标签，与 PublishedArticle 对应
+type PublishedArticleTag ArticleTag
+
+const (
+	articleTagTable    = "article_tags"
+	pubArticleTagTable = "published_article_tags"
+)
+
+// TagCount 标签及其下线上文章的数量
+type TagCount struct {
+	Tag   string `gorm:"column:tag" json:"tag" bson:"_id"`
+	Count int64  `gorm:"column:count" json:"count" bson:"count"`
+}
+
+func (r *ArticleRevision) Collection() string {
+	return "article_revisions"
+}
+
 func (p *PublishedArticle) Collection() string {
 	return "published_articles"
 }
@@ -43,99 +194,382 @@ type GormArticleDAO struct {
 	db *gorm.DB
 }
 
-func (g *GormArticleDAO) GetPubList(ctx context.Context, t time.Time, limit int, offset int) ([]PublishedArticle, error) {
+func (g *GormArticleDAO) GetPubList(ctx context.Context, cursor Cursor, limit int) ([]PublishedArticle, error) {
+	var articles []PublishedArticle
+	err := g.afterCursor(g.dbFrom(ctx).Where("status = ?", statusPublished), cursor).
+		Order("utime desc, id desc").
+		Limit(limit).
+		Find(&articles).
+		Error
+	if err != nil {
+		return nil, err
+	}
+	return articles, g.fillPubTags(ctx, articles)
+}
+
+func (g *GormArticleDAO) GetPubListByAuthor(ctx context.Context, uid int64, cursor Cursor, limit int) ([]PublishedArticle, error) {
+	var articles []PublishedArticle
+	err := g.afterCursor(g.dbFrom(ctx).Where("author_id = ? AND status = ?", uid, statusPublished), cursor).
+		Order("utime desc, id desc").
+		Limit(limit).
+		Find(&articles).
+		Error
+	if err != nil {
+		return nil, err
+	}
+	return articles, g.fillPubTags(ctx, articles)
+}
+
+func (g *GormArticleDAO) GetPubListByIds(ctx context.Context, ids []int64) ([]PublishedArticle, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
 	var articles []PublishedArticle
-	err := g.db.WithContext(ctx).
-		Where("utime < ? and status = ?", t.Unix(), 2).
-		Order("utime desc").
+	err := g.dbFrom(ctx).
+		Where("id IN ? AND status = ?", ids, statusPublished).
+		Find(&articles).
+		Error
+	if err != nil {
+		return nil, err
+	}
+	return articles, g.fillPubTags(ctx, articles)
+}
+
+func (g *GormArticleDAO) GetPubListByTag(ctx context.Context, tag string, limit int, offset int) ([]PublishedArticle, error) {
+	var articles []PublishedArticle
+	err := g.dbFrom(ctx).
+		Joins("JOIN published_article_tags t ON t.article_id = published_articles.id").
+		Where("t.tag = ? AND published_articles.status = ?", tag, statusPublished).
+		Order("published_articles.utime desc, published_articles.id desc").
 		Limit(limit).
 		Offset(offset).
 		Find(&articles).
 		Error
-	return articles, err
+	if err != nil {
+		return nil, err
+	}
+	return articles, g.fillPubTags(ctx, articles)
+}
+
+func (g *GormArticleDAO) GetPubListByCategory(ctx context.Context, category string, limit int, offset int) ([]PublishedArticle, error) {
+	var articles []PublishedArticle
+	err := g.dbFrom(ctx).
+		Where("category = ? AND status = ?", category, statusPublished).
+		Order("utime desc, id desc").
+		Limit(limit).
+		Offset(offset).
+		Find(&articles).
+		Error
+	if err != nil {
+		return nil, err
+	}
+	return articles, g.fillPubTags(ctx, articles)
+}
+
+// GetTagCounts 统计每个标签下线上文章的数量，按数量倒序
+func (g *GormArticleDAO) GetTagCounts(ctx context.Context, limit int) ([]TagCount, error) {
+	var res []TagCount
+	err := g.dbFrom(ctx).
+		Table("published_article_tags t").
+		Select("t.tag AS tag, COUNT(*) AS count").
+		Joins("JOIN published_articles p ON p.id = t.article_id").
+		Where("p.status = ?", statusPublished).
+		Group("t.tag").
+		Order("count desc, tag").
+		Limit(limit).
+		Scan(&res).
+		Error
+	return res, err
 }
 
 func (g *GormArticleDAO) GetPubArticleById(ctx context.Context, id int64) (PublishedArticle, error) {
 	var publishedArticle PublishedArticle
-	err := g.db.WithContext(ctx).Where("id = ?", id).First(&publishedArticle).Error
+	err := g.dbFrom(ctx).Where("id = ?", id).First(&publishedArticle).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return PublishedArticle{}, ErrArticleNotFound
+	}
+	if err != nil {
+		return PublishedArticle{}, err
+	}
+	tags, err := g.findTags(ctx, pubArticleTagTable, []int64{id})
+	publishedArticle.Tags = tags[id]
 	return publishedArticle, err
 }
 
 func (g *GormArticleDAO) GetArticleById(ctx context.Context, id int64) (Article, error) {
 	var article Article
-	err := g.db.WithContext(ctx).Where("id = ?", id).First(&article).Error
+	err := g.dbFrom(ctx).Where("id = ?", id).First(&article).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return Article{}, ErrArticleNotFound
+	}
+	if err != nil {
+		return Article{}, err
+	}
+	tags, err := g.findTags(ctx, articleTagTable, []int64{id})
+	article.Tags = tags[id]
 	return article, err
 }
 
-func (g *GormArticleDAO) GetArticlesByAuthor(ctx context.Context, uid int64, limit int, offset int) ([]Article, error) {
+func (g *GormArticleDAO) GetArticlesByAuthor(ctx context.Context, uid int64, cursor Cursor, limit int) ([]Article, error) {
 	var articles []Article
-	err := g.db.WithContext(ctx).
-		Where("author_id = ?", uid).
-		Order("utime desc").
+	err := g.afterCursor(g.dbFrom(ctx).Where("author_id = ? AND status <> ?", uid, statusDeleted), cursor).
+		Order("utime desc, id desc").
 		Limit(limit).
-		Offset(offset).
 		Find(&articles).
 		Error
-	return articles, err
+	if err != nil {
+		return nil, err
+	}
+	return articles, g.fillTags(ctx, articles)
 }
 
-func NewGormArticleDAO(db *gorm.DB) ArticleDAO {
-	return &GormArticleDAO{db: db}
+// GetDeletedByAuthor 删除时会更新 utime，因此沿用 (utime, id) 游标
+func (g *GormArticleDAO) GetDeletedByAuthor(ctx context.Context, uid int64, cursor Cursor, limit int) ([]Article, error) {
+	var articles []Article
+	err := g.afterCursor(g.dbFrom(ctx).Where("author_id = ? AND status = ?", uid, statusDeleted), cursor).
+		Order("utime desc, id desc").
+		Limit(limit).
+		Find(&articles).
+		Error
+	if err != nil {
+		return nil, err
+	}
+	return articles, g.fillTags(ctx, articles)
 }
 
-func (g *GormArticleDAO) SyncStatus(ctx context.Context, dao Article, u uint8) error {
-	return g.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+// Delete 草稿标记为已删除，线上库中的文章及标签直接删掉
+func (g *GormArticleDAO) Delete(ctx context.Context, id int64, authorId int64, from uint8) error {
+	return g.dbFrom(ctx).Transaction(func(tx *gorm.DB) error {
 		now := time.Now().Unix()
 		updates := tx.Model(&Article{}).
-			Where("id = ? and author_id = ?", dao.ID, dao.AuthorId). // 判断作者ID与文章ID是否匹配
+			Where("id = ? AND author_id = ? AND status = ? AND status <> ?", id, authorId, from, statusDeleted).
 			Updates(map[string]any{
-				"status": u,
+				"status": statusDeleted,
+				"dtime":  now,
 				"utime":  now,
 			})
 		if updates.Error != nil {
 			return updates.Error
 		}
 		if updates.RowsAffected == 0 {
-			return errors.New("作者ID与文章ID不匹配")
+			return g.statusFailedReason(tx, id, authorId, from)
+		}
+		if err := tx.Where("id = ?", id).Delete(&PublishedArticle{}).Error; err != nil {
+			return err
+		}
+		return tx.Table(pubArticleTagTable).Where("article_id = ?", id).Delete(&PublishedArticleTag{}).Error
+	})
+}
+
+// statusFailedReason 修改状态没有命中任何行时，区分是作者不匹配、已经删除还是状态已经变化
+func (g *GormArticleDAO) statusFailedReason(tx *gorm.DB, id int64, authorId int64, from uint8) error {
+	var current Article
+	err := tx.Select("id", "author_id", "status").Where("id = ?", id).First(&current).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrAuthorMismatch
+	}
+	if err != nil {
+		return err
+	}
+	return statusMismatch(current, authorId, from)
+}
+
+func (g *GormArticleDAO) Restore(ctx context.Context, id int64, authorId int64) error {
+	updates := g.dbFrom(ctx).Model(&Article{}).
+		Where("id = ? AND author_id = ? AND status = ?", id, authorId, statusDeleted).
+		Updates(map[string]any{
+			"status": statusUnpublished,
+			"dtime":  0,
+			"utime":  time.Now().Unix(),
+		})
+	if updates.Error != nil {
+		return updates.Error
+	}
+	if updates.RowsAffected == 0 {
+		return ErrAuthorMismatch
+	}
+	return nil
+}
+
+// PurgeDeleted 连同标签和历史版本一起物理删除
+// 只删除超过保留期的文章，这些文章已经不允许恢复，不需要担心删除过程中被恢复
+func (g *GormArticleDAO) PurgeDeleted(ctx context.Context, before int64, limit int) (int64, error) {
+	var ids []int64
+	err := g.dbFrom(ctx).Model(&Article{}).
+		Where("status = ? AND dtime < ?", statusDeleted, before).
+		Limit(limit).
+		Pluck("id", &ids).Error
+	if err != nil || len(ids) == 0 {
+		return 0, err
+	}
+	err = g.dbFrom(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("article_id IN ?", ids).Delete(&ArticleRevision{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Table(articleTagTable).Where("article_id IN ?", ids).Delete(&ArticleTag{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("id IN ? AND status = ?", ids, statusDeleted).Delete(&Article{}).Error
+	})
+	if err != nil {
+		return 0, err
+	}
+	return int64(len(ids)), nil
+}
+
+func (g *GormArticleDAO) ContentReferenced(ctx context.Context, key string) (bool, error) {
+	for _, model := range []any{&Article{}, &PublishedArticle{}, &ArticleRevision{}} {
+		var ids []int64
+		err := g.dbFrom(ctx).Model(model).Where("content_key = ?", key).Limit(1).Pluck("id", &ids).Error
+		if err != nil || len(ids) > 0 {
+			return len(ids) > 0, err
+		}
+	}
+	return false, nil
+}
+
+// afterCursor 只取排在游标之后的数据 配合 Order("utime desc, id desc") 使用
+func (g *GormArticleDAO) afterCursor(db *gorm.DB, cursor Cursor) *gorm.DB {
+	if cursor.IsZero() {
+		return db
+	}
+	return db.Where("(utime < ? OR (utime = ? AND id < ?))", cursor.Utime, cursor.Utime, cursor.ID)
+}
+
+// fillTags 批量查询草稿的标签
+func (g *GormArticleDAO) fillTags(ctx context.Context, articles []Article) error {
+	ids := make([]int64, 0, len(articles))
+	for _, article := range articles {
+		ids = append(ids, article.ID)
+	}
+	tags, err := g.findTags(ctx, articleTagTable, ids)
+	for i := range articles {
+		articles[i].Tags = tags[articles[i].ID]
+	}
+	return err
+}
+
+// fillPubTags 批量查询线上文章的标签
+func (g *GormArticleDAO) fillPubTags(ctx context.Context, articles []PublishedArticle) error {
+	ids := make([]int64, 0, len(articles))
+	for _, article := range articles {
+		ids = append(ids, article.ID)
+	}
+	tags, err := g.findTags(ctx, pubArticleTagTable, ids)
+	for i := range articles {
+		articles[i].Tags = tags[articles[i].ID]
+	}
+	return err
+}
+
+// findTags 批量查询文章的标签 table 为 article_tags 或 published_article_tags
+func (g *GormArticleDAO) findTags(ctx context.Context, table string, ids []int64) (map[int64][]string, error) {
+	res := make(map[int64][]string, len(ids))
+	if len(ids) == 0 {
+		return res, nil
+	}
+	var tags []ArticleTag
+	err := g.dbFrom(ctx).Table(table).
+		Where("article_id IN ?", ids).
+		Order("id").
+		Find(&tags).Error
+	if err != nil {
+		return res, err
+	}
+	for _, tag := range tags {
+		res[tag.ArticleId] = append(res[tag.ArticleId], tag.Tag)
+	}
+	return res, nil
+}
+
+func NewGormArticleDAO(db *gorm.DB) ArticleDAO {
+	return &GormArticleDAO{db: db}
+}
+
+func (g *GormArticleDAO) SyncStatus(ctx context.Context, dao Article, from uint8, to uint8) error {
+	return g.dbFrom(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now().Unix()
+		if err := g.setStatus(tx, dao, from, to, now); err != nil {
+			return err
 		}
 		return tx.Model(&PublishedArticle{}).
 			Where("id = ?", dao.ID). // 前面已经判断了author_id，这里不需要再判断
 			Updates(map[string]any{
-				"status": u,
+				"status": to,
 				"utime":  now,
 			}).Error
 	})
 }
 
-func (g *GormArticleDAO) Sync(ctx context.Context, article Article) (int64, error) {
-	txErr := g.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		articleDAO := NewGormArticleDAO(tx) // 事务中的DAO
-		if article.ID > 0 {                 // 更新
-			if err := articleDAO.UpdateById(ctx, article); err != nil {
-				return err
-			}
+// UpdateStatus 只修改草稿的状态，不影响线上库
+func (g *GormArticleDAO) UpdateStatus(ctx context.Context, dao Article, from uint8, to uint8) error {
+	return g.dbFrom(ctx).Transaction(func(tx *gorm.DB) error {
+		return g.setStatus(tx, dao, from, to, time.Now().Unix())
+	})
+}
+
+// setStatus 草稿的状态是 from 时改为 to，需要在事务中调用
+func (g *GormArticleDAO) setStatus(tx *gorm.DB, dao Article, from uint8, to uint8, now int64) error {
+	updates := tx.Model(&Article{}).
+		Where("id = ? AND author_id = ? AND status = ? AND status <> ?", dao.ID, dao.AuthorId, from, statusDeleted). // 已删除的文章不能修改状态
+		Updates(map[string]any{
+			"status": to,
+			"utime":  now,
+		})
+	if updates.Error != nil {
+		return updates.Error
+	}
+	if updates.RowsAffected == 0 {
+		return g.statusFailedReason(tx, dao.ID, dao.AuthorId, from)
+	}
+	return nil
+}
+
+func (g *GormArticleDAO) Sync(ctx context.Context, article Article, from uint8) (int64, error) {
+	return g.sync(ctx, article, from, article.ID == 0)
+}
+
+// sync isNew 为 true 时新增文章，此时 article.ID 可以由调用方预先生成(分库时使用)
+func (g *GormArticleDAO) sync(ctx context.Context, article Article, from uint8, isNew bool) (int64, error) {
+	txErr := g.dbFrom(ctx).Transaction(func(tx *gorm.DB) error {
+		articleDAO := &GormArticleDAO{db: tx} // 事务中的DAO
+		var err error
+		if !isNew { // 更新
+			article.RevisionId, err = articleDAO.update(tx, article, from)
+			article.Version++
 		} else { // 新增
-			id, err := articleDAO.Insert(ctx, article)
-			if err != nil {
-				return err
-			}
-			article.ID = id
+			article, err = articleDAO.insert(tx, article)
+		}
+		if err != nil {
+			return err
 		}
 		now := time.Now().Unix()
 		publishedArticle := PublishedArticle(article)
 		publishedArticle.Ctime, publishedArticle.Utime = now, now // 更新发布时间 & 更新时间
 
-		err := tx.Clauses(clause.OnConflict{
-			// id更新冲突时，只更新title、content、utime字段
+		err = tx.Clauses(clause.OnConflict{
+			// id更新冲突时，只更新title、content、status、revision_id、utime字段
 			Columns: []clause.Column{{Name: "id"}},
 			DoUpdates: clause.Assignments(map[string]any{
-				"title":   publishedArticle.Title,
-				"content": publishedArticle.Content,
-				"status":  publishedArticle.Status,
-				"utime":   now,
+				"title":        publishedArticle.Title,
+				"content":      publishedArticle.Content,
+				"content_key":  publishedArticle.ContentKey,
+				"status":       publishedArticle.Status,
+				"revision_id":  publishedArticle.RevisionId, // 记录线上版本
+				"version":      publishedArticle.Version,
+				"category":     publishedArticle.Category,
+				"html":         publishedArticle.Html,
+				"abstract":     publishedArticle.Abstract,
+				"word_count":   publishedArticle.WordCount,
+				"reading_time": publishedArticle.ReadingTime,
+				"toc":          publishedArticle.Toc,
+				"utime":        now,
 			}),
 		}).Create(&publishedArticle).Error
-		return err
+		if err != nil {
+			return err
+		}
+		return articleDAO.replaceTags(tx, pubArticleTagTable, article.ID, article.Tags, now)
 	})
 	if txErr != nil {
 		return 0, txErr
@@ -143,146 +577,606 @@ func (g *GormArticleDAO) Sync(ctx context.Context, article Article) (int64, erro
 	return article.ID, nil
 }
 
-func (g *GormArticleDAO) UpdateById(ctx context.Context, article Article) error {
-	updates := g.db.WithContext(ctx).Model(&article).
-		Where("id = ? AND author_id = ?", article.ID, article.AuthorId).
+func (g *GormArticleDAO) UpdateById(ctx context.Context, article Article, from uint8) error {
+	return g.dbFrom(ctx).Transaction(func(tx *gorm.DB) error {
+		_, err := g.update(tx, article, from)
+		return err
+	})
+}
+
+func (g *GormArticleDAO) Insert(ctx context.Context, article Article) (int64, error) {
+	err := g.dbFrom(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		article, err = g.insert(tx, article)
+		return err
+	})
+	return article.ID, err
+}
+
+// update 更新文章并记录新版本，返回新版本ID，需要在事务中调用
+func (g *GormArticleDAO) update(tx *gorm.DB, article Article, from uint8) (int64, error) {
+	now := time.Now().Unix()
+	updates := tx.Model(&Article{}).
+		Where("id = ? AND author_id = ? AND version = ? AND status = ? AND status <> ?",
+			article.ID, article.AuthorId, article.Version, from, statusDeleted).
 		Updates(map[string]any{
-			"title":   article.Title,
-			"content": article.Content,
-			"status":  article.Status,
-			"utime":   time.Now().Unix(),
+			"title":        article.Title,
+			"content":      article.Content,
+			"content_key":  article.ContentKey,
+			"status":       article.Status,
+			"category":     article.Category,
+			"html":         article.Html,
+			"abstract":     article.Abstract,
+			"word_count":   article.WordCount,
+			"reading_time": article.ReadingTime,
+			"toc":          article.Toc,
+			"version":      gorm.Expr("version + 1"),
+			"utime":        now,
 		})
 	if updates.Error != nil {
-		return updates.Error
+		return 0, updates.Error
 	}
 	if updates.RowsAffected == 0 {
-		return errors.New("作者ID与文章ID不匹配")
+		return 0, g.updateFailedReason(tx, article, from)
 	}
-	return nil
+	err := g.replaceTags(tx, articleTagTable, article.ID, article.Tags, now)
+	if err != nil {
+		return 0, err
+	}
+	return g.saveRevision(tx, article, now)
 }
 
-func (g *GormArticleDAO) Insert(ctx context.Context, article Article) (int64, error) {
+// updateFailedReason 更新没有命中任何行时，区分是作者不匹配、状态已经变化还是版本冲突
+func (g *GormArticleDAO) updateFailedReason(tx *gorm.DB, article Article, from uint8) error {
+	var current Article
+	err := tx.Select("id", "author_id", "status", "version").Where("id = ?", article.ID).First(&current).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrAuthorMismatch
+	}
+	if err != nil {
+		return err
+	}
+	if er := statusMismatch(current, article.AuthorId, from); er != nil {
+		return er
+	}
+	return &VersionConflictError{Current: current.Version}
+}
+
+// insert 新增文章并记录第一个版本，需要在事务中调用
+func (g *GormArticleDAO) insert(tx *gorm.DB, article Article) (Article, error) {
 	now := time.Now().Unix()
 	article.Ctime, article.Utime = now, now
-	err := g.db.WithContext(ctx).Create(&article).Error
-	return article.ID, err
+	article.Version = 1
+	err := tx.Create(&article).Error
+	if err != nil {
+		return Article{}, err
+	}
+	err = g.replaceTags(tx, articleTagTable, article.ID, article.Tags, now)
+	if err != nil {
+		return Article{}, err
+	}
+	article.RevisionId, err = g.saveRevision(tx, article, now)
+	return article, err
+}
+
+// replaceTags 用新的标签整体替换文章原有的标签，需要在事务中调用
+// table 为 article_tags 或 published_article_tags
+func (g *GormArticleDAO) replaceTags(tx *gorm.DB, table string, artId int64, tags []string, now int64) error {
+	err := tx.Table(table).Where("article_id = ?", artId).Delete(&ArticleTag{}).Error
+	if err != nil || len(tags) == 0 {
+		return err
+	}
+	rows := make([]ArticleTag, 0, len(tags))
+	for _, tag := range tags {
+		rows = append(rows, ArticleTag{ArticleId: artId, Tag: tag, Ctime: now})
+	}
+	return tx.Table(table).Create(&rows).Error
+}
+
+// saveRevision 记录一个不可变的版本，并让文章指向该版本
+func (g *GormArticleDAO) saveRevision(tx *gorm.DB, article Article, now int64) (int64, error) {
+	revision := ArticleRevision{
+		ArticleId:  article.ID,
+		AuthorId:   article.AuthorId,
+		Title:      article.Title,
+		Content:    article.Content,
+		ContentKey: article.ContentKey,
+		Ctime:      now,
+	}
+	err := tx.Create(&revision).Error
+	if err != nil {
+		return 0, err
+	}
+	err = tx.Model(&Article{}).
+		Where("id = ?", article.ID).
+		Update("revision_id", revision.ID).Error
+	return revision.ID, err
+}
+
+func (g *GormArticleDAO) GetRevisions(ctx context.Context, artId int64, limit int, offset int) ([]ArticleRevision, error) {
+	var revisions []ArticleRevision
+	err := g.dbFrom(ctx).
+		Where("article_id = ?", artId).
+		Order("id desc").
+		Limit(limit).
+		Offset(offset).
+		Find(&revisions).
+		Error
+	return revisions, err
+}
+
+func (g *GormArticleDAO) GetRevisionById(ctx context.Context, artId int64, revId int64) (ArticleRevision, error) {
+	var revision ArticleRevision
+	err := g.dbFrom(ctx).
+		Where("id = ? AND article_id = ?", revId, artId).
+		First(&revision).Error
+	return revision, err
 }
 
 type MongoDBArticleDAO struct {
+	// 事务需要通过 client 开启 session，只有副本集或分片集群支持
+	client        *qmgo.Client
 	db            *qmgo.Database
 	coll          *qmgo.Collection
 	publishedColl *qmgo.Collection
-	dbV2          *mongo.Conn
+	revisionColl  *qmgo.Collection
+	// txSupported 第一次成功探测部署方式后缓存的结果 单机部署时不开启事务
+	txMu        sync.Mutex
+	txSupported *bool
 }
 
-func (m *MongoDBArticleDAO) GetPubList(ctx context.Context, t time.Time, limit int, offset int) ([]PublishedArticle, error) {
+func (m *MongoDBArticleDAO) GetPubList(ctx context.Context, cursor Cursor, limit int) ([]PublishedArticle, error) {
 	var articles []PublishedArticle
 	err := m.publishedColl.
-		Find(ctx, bson.M{"utime": bson.M{"$lt": t.Unix()}, "status": 2}).
+		Find(ctx, m.afterCursor(bson.M{"status": statusPublished}, cursor)).
+		Sort("-utime", "-id").
+		Limit(int64(limit)).
+		All(&articles)
+	return articles, err
+}
+
+func (m *MongoDBArticleDAO) GetPubListByAuthor(ctx context.Context, uid int64, cursor Cursor, limit int) ([]PublishedArticle, error) {
+	var articles []PublishedArticle
+	err := m.publishedColl.
+		Find(ctx, m.afterCursor(bson.M{"author_id": uid, "status": statusPublished}, cursor)).
+		Sort("-utime", "-id").
+		Limit(int64(limit)).
+		All(&articles)
+	return articles, err
+}
+
+// afterCursor 在过滤条件中加上游标条件 配合 Sort("-utime", "-id") 使用
+func (m *MongoDBArticleDAO) afterCursor(filter bson.M, cursor Cursor) bson.M {
+	if cursor.IsZero() {
+		return filter
+	}
+	filter["$or"] = bson.A{
+		bson.M{"utime": bson.M{"$lt": cursor.Utime}},
+		bson.M{"utime": cursor.Utime, "id": bson.M{"$lt": cursor.ID}},
+	}
+	return filter
+}
+
+func (m *MongoDBArticleDAO) GetPubListByIds(ctx context.Context, ids []int64) ([]PublishedArticle, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	var articles []PublishedArticle
+	err := m.publishedColl.
+		Find(ctx, bson.M{"id": bson.M{"$in": ids}, "status": statusPublished}).
+		All(&articles)
+	return articles, err
+}
+
+func (m *MongoDBArticleDAO) GetPubListByTag(ctx context.Context, tag string, limit int, offset int) ([]PublishedArticle, error) {
+	var articles []PublishedArticle
+	err := m.publishedColl.
+		Find(ctx, bson.M{"tags": tag, "status": statusPublished}). // 数组字段直接匹配其中的元素
+		Sort("-utime", "-id").
 		Skip(int64(offset)).
 		Limit(int64(limit)).
 		All(&articles)
 	return articles, err
 }
 
+func (m *MongoDBArticleDAO) GetPubListByCategory(ctx context.Context, category string, limit int, offset int) ([]PublishedArticle, error) {
+	var articles []PublishedArticle
+	err := m.publishedColl.
+		Find(ctx, bson.M{"category": category, "status": statusPublished}).
+		Sort("-utime", "-id").
+		Skip(int64(offset)).
+		Limit(int64(limit)).
+		All(&articles)
+	return articles, err
+}
+
+// GetTagCounts 统计每个标签下线上文章的数量，按数量倒序
+func (m *MongoDBArticleDAO) GetTagCounts(ctx context.Context, limit int) ([]TagCount, error) {
+	var res []TagCount
+	err := m.publishedColl.Aggregate(ctx, []bson.M{
+		{"$match": bson.M{"status": statusPublished}},
+		{"$unwind": "$tags"},
+		{"$group": bson.M{"_id": "$tags", "count": bson.M{"$sum": 1}}},
+		{"$sort": bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: 1}}},
+		{"$limit": limit},
+	}).All(&res)
+	return res, err
+}
+
 func (m *MongoDBArticleDAO) GetPubArticleById(ctx context.Context, id int64) (PublishedArticle, error) {
 	var article PublishedArticle
 	err := m.publishedColl.Find(ctx, bson.M{"id": id}).One(&article)
+	if errors.Is(err, qmgo.ErrNoSuchDocuments) {
+		return PublishedArticle{}, ErrArticleNotFound
+	}
 	return article, err
 }
 
 func (m *MongoDBArticleDAO) GetArticleById(ctx context.Context, id int64) (Article, error) {
 	var article Article
 	err := m.coll.Find(ctx, bson.M{"id": id}).One(&article)
+	if errors.Is(err, qmgo.ErrNoSuchDocuments) {
+		return Article{}, ErrArticleNotFound
+	}
 	return article, err
 }
 
-func NewMongoDBArticleDAO(db *qmgo.Database, dbv2 *mongo.Conn) ArticleDAO {
+func (m *MongoDBArticleDAO) ContentReferenced(ctx context.Context, key string) (bool, error) {
+	for _, coll := range []*qmgo.Collection{m.coll, m.publishedColl, m.revisionColl} {
+		n, err := coll.Find(ctx, bson.M{"content_key": key}).Limit(1).Count()
+		if err != nil || n > 0 {
+			return n > 0, err
+		}
+	}
+	return false, nil
+}
+
+func NewMongoDBArticleDAO(client *qmgo.Client, db *qmgo.Database) ArticleDAO {
 	return &MongoDBArticleDAO{
+		client:        client,
 		db:            db,
 		coll:          db.Collection("articles"),
 		publishedColl: db.Collection("published_articles"),
-		dbV2:          dbv2,
+		revisionColl:  db.Collection("article_revisions"),
+	}
+}
+
+// transaction 在同一个事务中执行 fn，fn 中的所有操作都必须使用传入的 sessCtx
+// 事务冲突时驱动会整体重试 fn，因此 fn 不能修改外部的变量
+// 单机部署的 MongoDB 不支持事务 此时直接执行 fn，各个写操作仍然带有状态条件，只是不再保证整体原子
+func (m *MongoDBArticleDAO) transaction(ctx context.Context, fn func(sessCtx context.Context) error) error {
+	if !m.supportsTransaction(ctx) {
+		return fn(ctx)
+	}
+	_, err := m.client.DoTransaction(ctx, func(sessCtx context.Context) (interface{}, error) {
+		return nil, fn(sessCtx)
+	})
+	return err
+}
+
+// supportsTransaction 通过 hello 命令判断是否为副本集或分片集群 探测失败时本次按单机处理，下次再探测
+func (m *MongoDBArticleDAO) supportsTransaction(ctx context.Context) bool {
+	m.txMu.Lock()
+	defer m.txMu.Unlock()
+	if m.txSupported != nil {
+		return *m.txSupported
 	}
+	var res struct {
+		SetName string `bson:"setName"`
+		Msg     string `bson:"msg"`
+	}
+	if err := m.db.RunCommand(ctx, bson.D{{Key: "hello", Value: 1}}).Decode(&res); err != nil {
+		return false
+	}
+	// 副本集成员返回 setName，mongos 返回 msg: isdbgrid
+	supported := res.SetName != "" || res.Msg == "isdbgrid"
+	m.txSupported = &supported
+	return supported
+}
+
+func (m *MongoDBArticleDAO) GetArticlesByAuthor(ctx context.Context, uid int64, cursor Cursor, limit int) ([]Article, error) {
+	var articles []Article
+	err := m.coll.Find(ctx, m.afterCursor(bson.M{"author_id": uid, "status": bson.M{"$ne": statusDeleted}}, cursor)).
+		Sort("-utime", "-id").
+		Limit(int64(limit)).
+		All(&articles)
+	return articles, err
 }
 
-func (m *MongoDBArticleDAO) GetArticlesByAuthor(ctx context.Context, uid int64, limit int, offset int) ([]Article, error) {
+func (m *MongoDBArticleDAO) GetDeletedByAuthor(ctx context.Context, uid int64, cursor Cursor, limit int) ([]Article, error) {
 	var articles []Article
-	err := m.coll.Find(ctx, bson.M{"author_id": uid}).Skip(int64(offset)).Limit(int64(limit)).All(&articles)
+	err := m.coll.Find(ctx, m.afterCursor(bson.M{"author_id": uid, "status": statusDeleted}, cursor)).
+		Sort("-utime", "-id").
+		Limit(int64(limit)).
+		All(&articles)
 	return articles, err
 }
 
+func (m *MongoDBArticleDAO) Delete(ctx context.Context, id int64, authorId int64, from uint8) error {
+	return m.transaction(ctx, func(sessCtx context.Context) error {
+		now := time.Now().Unix()
+		err := m.coll.UpdateOne(sessCtx, m.statusFilter(id, authorId, from),
+			bson.M{"$set": bson.M{
+				"status": statusDeleted,
+				"dtime":  now,
+				"utime":  now,
+			}})
+		if errors.Is(err, qmgo.ErrNoSuchDocuments) {
+			return m.statusFailedReason(sessCtx, id, authorId, from)
+		}
+		if err != nil {
+			return err
+		}
+		// 从未发表过的文章在线上库中没有数据
+		err = m.publishedColl.Remove(sessCtx, bson.M{"id": id})
+		if errors.Is(err, qmgo.ErrNoSuchDocuments) {
+			return nil
+		}
+		return err
+	})
+}
+
+// statusFilter 文章属于作者且状态为 from 已删除的文章不能修改状态
+func (m *MongoDBArticleDAO) statusFilter(id int64, authorId int64, from uint8) bson.M {
+	return bson.M{"id": id, "author_id": authorId, "status": bson.M{"$eq": from, "$ne": statusDeleted}}
+}
+
+// statusFailedReason 修改状态没有命中任何文档时，区分是作者不匹配、已经删除还是状态已经变化
+func (m *MongoDBArticleDAO) statusFailedReason(ctx context.Context, id int64, authorId int64, from uint8) error {
+	var current Article
+	err := m.coll.Find(ctx, bson.M{"id": id}).Select(bson.M{"author_id": 1, "status": 1}).One(&current)
+	if errors.Is(err, qmgo.ErrNoSuchDocuments) {
+		return ErrAuthorMismatch
+	}
+	if err != nil {
+		return err
+	}
+	return statusMismatch(current, authorId, from)
+}
+
+func (m *MongoDBArticleDAO) Restore(ctx context.Context, id int64, authorId int64) error {
+	err := m.coll.UpdateOne(ctx,
+		bson.M{"id": id, "author_id": authorId, "status": statusDeleted},
+		bson.M{
+			"$set": bson.M{
+				"status": statusUnpublished,
+				"utime":  time.Now().Unix(),
+			},
+			"$unset": bson.M{"dtime": ""},
+		})
+	if errors.Is(err, qmgo.ErrNoSuchDocuments) {
+		return ErrAuthorMismatch
+	}
+	return err
+}
+
+// PurgeDeleted 连同历史版本一起物理删除
+func (m *MongoDBArticleDAO) PurgeDeleted(ctx context.Context, before int64, limit int) (int64, error) {
+	var articles []Article
+	err := m.coll.Find(ctx, bson.M{"status": statusDeleted, "dtime": bson.M{"$lt": before}}).
+		Select(bson.M{"id": 1}).
+		Limit(int64(limit)).
+		All(&articles)
+	if err != nil || len(articles) == 0 {
+		return 0, err
+	}
+	ids := make([]int64, 0, len(articles))
+	for _, article := range articles {
+		ids = append(ids, article.ID)
+	}
+	_, err = m.revisionColl.RemoveAll(ctx, bson.M{"article_id": bson.M{"$in": ids}})
+	if err != nil {
+		return 0, err
+	}
+	res, err := m.coll.RemoveAll(ctx, bson.M{"id": bson.M{"$in": ids}, "status": statusDeleted})
+	if err != nil {
+		return 0, err
+	}
+	return res.DeletedCount, nil
+}
+
 func (m *MongoDBArticleDAO) Insert(ctx context.Context, article Article) (int64, error) {
+	article, err := m.insert(ctx, article)
+	return article.ID, err
+}
+
+func (m *MongoDBArticleDAO) UpdateById(ctx context.Context, article Article, from uint8) error {
+	_, err := m.update(ctx, article, from)
+	return err
+}
+
+// insert 新增文章并记录第一个版本
+func (m *MongoDBArticleDAO) insert(ctx context.Context, article Article) (Article, error) {
 	article.ID = int64(snowflake.ID())
 	now := time.Now().Unix()
 	article.Ctime, article.Utime = now, now
+	article.RevisionId = int64(snowflake.ID())
+	article.Version = 1
 	_, err := m.coll.InsertOne(ctx, &article)
-	return article.ID, err
+	if err != nil {
+		return Article{}, err
+	}
+	return article, m.saveRevision(ctx, article, now)
 }
 
-func (m *MongoDBArticleDAO) UpdateById(ctx context.Context, article Article) error {
+// update 更新文章并记录新版本，返回新版本ID
+func (m *MongoDBArticleDAO) update(ctx context.Context, article Article, from uint8) (int64, error) {
 	now := time.Now().Unix()
-	err := m.coll.UpdateOne(ctx,
-		bson.M{"id": article.ID, "author_id": article.AuthorId}, // 判断作者ID与文章ID是否匹配
+	revId := int64(snowflake.ID())
+	filter := m.statusFilter(article.ID, article.AuthorId, from)
+	filter["version"] = m.versionFilter(article.Version) // 版本号是否一致
+	err := m.coll.UpdateOne(ctx, filter,
 		bson.M{
 			"$set": bson.M{
-				"title":   article.Title,
-				"content": article.Content,
-				"status":  article.Status,
-				"utime":   now,
+				"title":        article.Title,
+				"content":      article.Content,
+				"content_key":  article.ContentKey,
+				"status":       article.Status,
+				"category":     article.Category,
+				"tags":         article.Tags,
+				"html":         article.Html,
+				"abstract":     article.Abstract,
+				"word_count":   article.WordCount,
+				"reading_time": article.ReadingTime,
+				"toc":          article.Toc,
+				"revision_id":  revId,
+				"utime":        now,
 			},
+			"$inc": bson.M{"version": 1},
 		})
-	return err
+	if errors.Is(err, qmgo.ErrNoSuchDocuments) {
+		return 0, m.updateFailedReason(ctx, article, from)
+	}
+	if err != nil {
+		return 0, err
+	}
+	article.RevisionId = revId
+	return revId, m.saveRevision(ctx, article, now)
 }
 
-func (m *MongoDBArticleDAO) Sync(ctx context.Context, dao Article) (int64, error) {
-	now := time.Now().Unix()
-	var err error
-	article := PublishedArticle(dao)
-	if dao.ID > 0 { // 更新
-		err = m.UpdateById(ctx, dao)
-	} else {
-		article.Ctime, article.Utime = now, now // 更新发布时间 & 更新时间
-		dao.ID, err = m.Insert(ctx, Article(article))
-	}
-	if err != nil { // 更新或发布文章失败
-		return dao.ID, err
-	}
-	// 更新发布文章
-	//_, err = m.publishedColl.Upsert(ctx, bson.M{"id": dao.ID, "author_id": dao.AuthorId},
-	//	bson.M{
-	//		"id":        dao.ID, // 保证id不变
-	//		"title":     article.Title,
-	//		"content":   article.Content,
-	//		"status":    article.Status,
-	//		"author_id": article.AuthorId,
-	//		"utime":     now,
-	//	})
-	collection := m.dbV2.Collection(&PublishedArticle{})
-	err = collection.Where("id", dao.ID).UpsertOne(map[string]any{
-		"title":     article.Title,
-		"content":   article.Content,
-		"status":    article.Status,
-		"author_id": article.AuthorId,
-		"utime":     now,
-	}, map[string]any{
-		"id":    dao.ID,
-		"ctime": now,
-	})
-	return dao.ID, err
+// versionFilter 加入版本号之前保存的文档没有 version 字段，版本号为 0 时同时匹配字段不存在的文档
+// MySQL 中这些文章的 version 列默认为 0，不需要特殊处理
+func (m *MongoDBArticleDAO) versionFilter(version int64) any {
+	if version == 0 {
+		return bson.M{"$in": bson.A{0, nil}}
+	}
+	return version
 }
 
-func (m *MongoDBArticleDAO) SyncStatus(ctx context.Context, dao Article, u uint8) error {
-	now := time.Now().Unix()
-	err := m.UpdateById(ctx, dao)
+// updateFailedReason 更新没有命中任何文档时，区分是作者不匹配、状态已经变化还是版本冲突
+func (m *MongoDBArticleDAO) updateFailedReason(ctx context.Context, article Article, from uint8) error {
+	var current Article
+	err := m.coll.Find(ctx, bson.M{"id": article.ID}).Select(bson.M{"author_id": 1, "status": 1, "version": 1}).One(&current)
+	if errors.Is(err, qmgo.ErrNoSuchDocuments) {
+		return ErrAuthorMismatch
+	}
 	if err != nil {
 		return err
 	}
-	return m.publishedColl.UpdateOne(ctx, bson.M{"id": dao.ID, "author_id": dao.AuthorId},
+	if er := statusMismatch(current, article.AuthorId, from); er != nil {
+		return er
+	}
+	return &VersionConflictError{Current: current.Version}
+}
+
+// saveRevision 记录一个不可变的版本 版本ID沿用文章上的 RevisionId
+func (m *MongoDBArticleDAO) saveRevision(ctx context.Context, article Article, now int64) error {
+	_, err := m.revisionColl.InsertOne(ctx, &ArticleRevision{
+		ID:         article.RevisionId,
+		ArticleId:  article.ID,
+		AuthorId:   article.AuthorId,
+		Title:      article.Title,
+		Content:    article.Content,
+		ContentKey: article.ContentKey,
+		Ctime:      now,
+	})
+	return err
+}
+
+func (m *MongoDBArticleDAO) GetRevisions(ctx context.Context, artId int64, limit int, offset int) ([]ArticleRevision, error) {
+	var revisions []ArticleRevision
+	err := m.revisionColl.Find(ctx, bson.M{"article_id": artId}).
+		Sort("-id").
+		Skip(int64(offset)).
+		Limit(int64(limit)).
+		All(&revisions)
+	return revisions, err
+}
+
+func (m *MongoDBArticleDAO) GetRevisionById(ctx context.Context, artId int64, revId int64) (ArticleRevision, error) {
+	var revision ArticleRevision
+	err := m.revisionColl.Find(ctx, bson.M{"id": revId, "article_id": artId}).One(&revision)
+	return revision, err
+}
+
+// Sync 保存草稿与更新线上库在同一个事务中完成
+func (m *MongoDBArticleDAO) Sync(ctx context.Context, dao Article, from uint8) (int64, error) {
+	var id int64
+	err := m.transaction(ctx, func(sessCtx context.Context) error {
+		article := dao // 事务重试时从原始数据重新开始
+		var err error
+		if article.ID > 0 { // 更新
+			article.RevisionId, err = m.update(sessCtx, article, from)
+			article.Version++
+		} else {
+			article, err = m.insert(sessCtx, article)
+		}
+		if err != nil { // 更新或发布文章失败
+			return err
+		}
+		now := time.Now().Unix()
+		upsert := options.UpdateOptions{UpdateOptions: mgoptions.Update().SetUpsert(true)}
+		err = m.publishedColl.UpdateOne(sessCtx, bson.M{"id": article.ID},
+			bson.M{
+				"$set": bson.M{
+					"title":        article.Title,
+					"content":      article.Content,
+					"content_key":  article.ContentKey,
+					"status":       article.Status,
+					"author_id":    article.AuthorId,
+					"revision_id":  article.RevisionId, // 记录线上版本
+					"version":      article.Version,
+					"category":     article.Category,
+					"tags":         article.Tags,
+					"html":         article.Html,
+					"abstract":     article.Abstract,
+					"word_count":   article.WordCount,
+					"reading_time": article.ReadingTime,
+					"toc":          article.Toc,
+					"utime":        now,
+				},
+				// 首次发表时才写入发表时间
+				"$setOnInsert": bson.M{"ctime": now},
+			}, upsert)
+		if err != nil {
+			return err
+		}
+		id = article.ID
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+// UpdateStatus 只修改草稿的状态，不影响线上库
+func (m *MongoDBArticleDAO) UpdateStatus(ctx context.Context, dao Article, from uint8, to uint8) error {
+	return m.setStatus(ctx, dao, from, to, time.Now().Unix())
+}
+
+// setStatus 草稿的状态是 from 时改为 to 只修改状态，不产生新版本
+func (m *MongoDBArticleDAO) setStatus(ctx context.Context, dao Article, from uint8, to uint8, now int64) error {
+	err := m.coll.UpdateOne(ctx, m.statusFilter(dao.ID, dao.AuthorId, from),
 		bson.M{
 			"$set": bson.M{
-				"status": u,
+				"status": to,
 				"utime":  now,
 			}},
 	)
+	if errors.Is(err, qmgo.ErrNoSuchDocuments) {
+		return m.statusFailedReason(ctx, dao.ID, dao.AuthorId, from)
+	}
+	return err
+}
+
+func (m *MongoDBArticleDAO) SyncStatus(ctx context.Context, dao Article, from uint8, to uint8) error {
+	return m.transaction(ctx, func(sessCtx context.Context) error {
+		now := time.Now().Unix()
+		if err := m.setStatus(sessCtx, dao, from, to, now); err != nil {
+			return err
+		}
+		err := m.publishedColl.UpdateOne(sessCtx, bson.M{"id": dao.ID}, // 前面已经判断了author_id，这里不需要再判断
+			bson.M{
+				"$set": bson.M{
+					"status": to,
+					"utime":  now,
+				}},
+		)
+		// 从未发表过的文章在线上库中没有数据
+		if errors.Is(err, qmgo.ErrNoSuchDocuments) {
+			return nil
+		}
+		return err
+	})
 }
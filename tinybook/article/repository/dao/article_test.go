@@ -0,0 +1,98 @@
+package dao_test
+
+import (
+	"context"
+	"github.com/qiniu/qmgo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"os"
+	"strings"
+	"testing"
+	"tinybook/tinybook/article/repository/dao"
+	"tinybook/tinybook/article/repository/dao/daotest"
+)
+
+func TestInMemoryArticleDAO(t *testing.T) {
+	daotest.RunArticleDAOContract(t, func(t *testing.T) dao.ArticleDAO {
+		return dao.NewInMemoryArticleDAO()
+	})
+}
+
+// TestGormArticleDAO 需要一个可以随意清空的 MySQL 库，例如
+// TINYBOOK_TEST_MYSQL_DSN="root:root@tcp(localhost:13316)/tinybook_test"
+func TestGormArticleDAO(t *testing.T) {
+	dsn := os.Getenv("TINYBOOK_TEST_MYSQL_DSN")
+	if dsn == "" {
+		t.Skip("未设置 TINYBOOK_TEST_MYSQL_DSN")
+	}
+	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{})
+	require.NoError(t, err)
+	dao.CreateTableForArticle(db)
+	daotest.RunArticleDAOContract(t, func(t *testing.T) dao.ArticleDAO {
+		for _, table := range []string{"articles", "published_articles", "article_revisions", "article_tags", "published_article_tags"} {
+			require.NoError(t, db.Exec("TRUNCATE TABLE "+table).Error)
+		}
+		return dao.NewGormArticleDAO(db)
+	})
+}
+
+// TestShardingArticleDAO 需要多个可以随意清空的 MySQL 库，用逗号分隔，例如
+// TINYBOOK_TEST_MYSQL_SHARD_DSNS="root:root@tcp(localhost:13316)/article_0,root:root@tcp(localhost:13316)/article_1"
+func TestShardingArticleDAO(t *testing.T) {
+	dsns := os.Getenv("TINYBOOK_TEST_MYSQL_SHARD_DSNS")
+	if dsns == "" {
+		t.Skip("未设置 TINYBOOK_TEST_MYSQL_SHARD_DSNS")
+	}
+	var dbs []*gorm.DB
+	for _, dsn := range strings.Split(dsns, ",") {
+		db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{})
+		require.NoError(t, err)
+		dao.CreateTableForArticleShard(db)
+		require.NoError(t, db.Exec("TRUNCATE TABLE article_shards").Error)
+		dbs = append(dbs, db)
+	}
+	ctx := context.Background()
+	require.NoError(t, dao.RecordShards(ctx, dbs))
+	require.NoError(t, dao.VerifyShards(ctx, dbs))
+	// 分库数量变化
+	assert.ErrorIs(t, dao.VerifyShards(ctx, append(dbs[:len(dbs):len(dbs)], dbs[0])), dao.ErrShardMismatch)
+	daotest.RunArticleDAOContract(t, func(t *testing.T) dao.ArticleDAO {
+		for _, db := range dbs {
+			for _, table := range []string{"articles", "published_articles", "article_revisions", "article_tags", "published_article_tags"} {
+				require.NoError(t, db.Exec("TRUNCATE TABLE "+table).Error)
+			}
+		}
+		// 号段表不清空 ID 继续递增即可
+		idGen, err := dao.NewSegmentIDGenerator(dbs[0], "article_test", 10)
+		require.NoError(t, err)
+		d, err := dao.NewShardingArticleDAO(dbs, idGen)
+		require.NoError(t, err)
+		return d
+	})
+}
+
+// TestMongoDBArticleDAO 事务要求 MongoDB 以副本集部署，例如
+// TINYBOOK_TEST_MONGO_URI="mongodb://localhost:27017/?replicaSet=rs0"
+func TestMongoDBArticleDAO(t *testing.T) {
+	uri := os.Getenv("TINYBOOK_TEST_MONGO_URI")
+	if uri == "" {
+		t.Skip("未设置 TINYBOOK_TEST_MONGO_URI")
+	}
+	ctx := context.Background()
+	client, err := qmgo.NewClient(ctx, &qmgo.Config{Uri: uri})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = client.Close(ctx)
+	})
+	db := client.Database("tinybook_test")
+	daotest.RunArticleDAOContract(t, func(t *testing.T) dao.ArticleDAO {
+		require.NoError(t, db.DropDatabase(ctx))
+		// 事务中不能隐式创建集合
+		for _, name := range []string{"articles", "published_articles", "article_revisions"} {
+			require.NoError(t, db.CreateCollection(ctx, name))
+		}
+		return dao.NewMongoDBArticleDAO(client, db)
+	})
+}
@@ -0,0 +1,578 @@
+// Package daotest 所有 dao.ArticleDAO 实现都必须通过的契约测试
+package daotest
+
+import (
+	"context"
+	"github.com/cockroachdb/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+	"tinybook/tinybook/article/repository/dao"
+)
+
+// 与 domain.ArticleStatus 的取值保持一致
+const (
+	statusUnknown     uint8 = 0 // 新文章
+	statusUnpublished uint8 = 1
+	statusPublished   uint8 = 2
+	statusPrivate     uint8 = 3
+	statusDeleted     uint8 = 5
+)
+
+// Factory 每个用例调用一次，返回的 DAO 必须没有任何数据
+type Factory func(t *testing.T) dao.ArticleDAO
+
+// RunArticleDAOContract 对 newDAO 返回的实现执行全部契约测试
+func RunArticleDAOContract(t *testing.T, newDAO Factory) {
+	cases := []struct {
+		name string
+		fn   func(t *testing.T, d dao.ArticleDAO)
+	}{
+		{"Insert", testInsert},
+		{"UpdateById", testUpdateById},
+		{"Sync", testSync},
+		{"SyncIsAtomic", testSyncIsAtomic},
+		{"SyncStatus", testSyncStatus},
+		{"UpdateStatus", testUpdateStatus},
+		{"GetArticlesByAuthor", testGetArticlesByAuthor},
+		{"GetPubList", testGetPubList},
+		{"GetPubListByAuthor", testGetPubListByAuthor},
+		{"GetPubListByIds", testGetPubListByIds},
+		{"GetPubListByTagAndCategory", testGetPubListByTagAndCategory},
+		{"GetTagCounts", testGetTagCounts},
+		{"Revisions", testRevisions},
+		{"DeleteAndRestore", testDeleteAndRestore},
+		{"PurgeDeleted", testPurgeDeleted},
+		{"ContentReferenced", testContentReferenced},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.fn(t, newDAO(t))
+		})
+	}
+}
+
+func testInsert(t *testing.T, d dao.ArticleDAO) {
+	ctx := context.Background()
+	id, err := d.Insert(ctx, dao.Article{
+		Title:      "标题",
+		ContentKey: "key-1",
+		AuthorId:   1,
+		Status:     statusUnpublished,
+		Category:   "go",
+		Tags:       []string{"b", "a"},
+	})
+	require.NoError(t, err)
+	assert.Greater(t, id, int64(0))
+
+	art, err := d.GetArticleById(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, id, art.ID)
+	assert.Equal(t, "标题", art.Title)
+	assert.Equal(t, "key-1", art.ContentKey)
+	assert.Equal(t, int64(1), art.AuthorId)
+	assert.Equal(t, statusUnpublished, art.Status)
+	assert.Equal(t, "go", art.Category)
+	assert.Equal(t, []string{"b", "a"}, art.Tags)
+	assert.Equal(t, int64(1), art.Version)
+	assert.Greater(t, art.RevisionId, int64(0))
+	assert.Greater(t, art.Ctime, int64(0))
+	assert.Greater(t, art.Utime, int64(0))
+
+	rev, err := d.GetRevisionById(ctx, id, art.RevisionId)
+	require.NoError(t, err)
+	assert.Equal(t, "标题", rev.Title)
+	assert.Equal(t, "key-1", rev.ContentKey)
+
+	_, err = d.GetArticleById(ctx, id+1000)
+	assert.ErrorIs(t, err, dao.ErrArticleNotFound)
+	// 没有发表过
+	_, err = d.GetPubArticleById(ctx, id)
+	assert.ErrorIs(t, err, dao.ErrArticleNotFound)
+}
+
+func testUpdateById(t *testing.T, d dao.ArticleDAO) {
+	ctx := context.Background()
+	id := insert(t, d, 1, "v1")
+	before, err := d.GetArticleById(ctx, id)
+	require.NoError(t, err)
+
+	err = d.UpdateById(ctx, dao.Article{ID: id, AuthorId: 1, Title: "v2", ContentKey: "key-2", Status: statusUnpublished, Version: 1, Tags: []string{"x"}}, statusUnpublished)
+	require.NoError(t, err)
+	art, err := d.GetArticleById(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, "v2", art.Title)
+	assert.Equal(t, "key-2", art.ContentKey)
+	assert.Equal(t, int64(2), art.Version)
+	assert.Equal(t, []string{"x"}, art.Tags)
+	assert.NotEqual(t, before.RevisionId, art.RevisionId)
+
+	// 作者不匹配
+	err = d.UpdateById(ctx, dao.Article{ID: id, AuthorId: 2, Title: "v3", Version: 2}, statusUnpublished)
+	assert.ErrorIs(t, err, dao.ErrAuthorMismatch)
+	// 文章不存在时同样视为作者不匹配
+	err = d.UpdateById(ctx, dao.Article{ID: id + 1000, AuthorId: 1, Title: "v3", Version: 1}, statusUnpublished)
+	assert.ErrorIs(t, err, dao.ErrAuthorMismatch)
+	// 版本号过期
+	err = d.UpdateById(ctx, dao.Article{ID: id, AuthorId: 1, Title: "v3", Version: 1}, statusUnpublished)
+	assert.ErrorIs(t, err, dao.ErrVersionConflict)
+	var conflict *dao.VersionConflictError
+	require.True(t, errors.As(err, &conflict))
+	assert.Equal(t, int64(2), conflict.Current)
+
+	art, err = d.GetArticleById(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, "v2", art.Title)
+	assert.Equal(t, int64(2), art.Version)
+
+	// 回收站中的文章不能修改
+	require.NoError(t, d.Delete(ctx, id, 1, statusUnpublished))
+	err = d.UpdateById(ctx, dao.Article{ID: id, AuthorId: 1, Title: "v3", Version: 2}, statusUnpublished)
+	assert.ErrorIs(t, err, dao.ErrArticleDeleted)
+}
+
+func testSync(t *testing.T, d dao.ArticleDAO) {
+	ctx := context.Background()
+	// 新建并直接发表
+	id, err := d.Sync(ctx, dao.Article{Title: "v1", ContentKey: "key-1", AuthorId: 1, Status: statusPublished, Tags: []string{"go"}}, statusUnknown)
+	require.NoError(t, err)
+	assert.Greater(t, id, int64(0))
+
+	art, err := d.GetArticleById(ctx, id)
+	require.NoError(t, err)
+	pub, err := d.GetPubArticleById(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, id, pub.ID)
+	assert.Equal(t, "v1", pub.Title)
+	assert.Equal(t, "key-1", pub.ContentKey)
+	assert.Equal(t, int64(1), pub.AuthorId)
+	assert.Equal(t, statusPublished, pub.Status)
+	assert.Equal(t, []string{"go"}, pub.Tags)
+	assert.Equal(t, art.RevisionId, pub.RevisionId)
+	assert.Equal(t, int64(1), pub.Version)
+	firstCtime := pub.Ctime
+	assert.Greater(t, firstCtime, int64(0))
+
+	// 修改已发表的文章
+	id2, err := d.Sync(ctx, dao.Article{ID: id, Title: "v2", ContentKey: "key-2", AuthorId: 1, Status: statusPublished, Version: 1}, statusPublished)
+	require.NoError(t, err)
+	assert.Equal(t, id, id2)
+	art, err = d.GetArticleById(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, "v2", art.Title)
+	assert.Equal(t, int64(2), art.Version)
+	pub, err = d.GetPubArticleById(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, "v2", pub.Title)
+	assert.Equal(t, "key-2", pub.ContentKey)
+	assert.Equal(t, art.RevisionId, pub.RevisionId)
+	assert.Equal(t, int64(2), pub.Version)
+	assert.Empty(t, pub.Tags)
+	// 再次发表不改变首次发表时间
+	assert.Equal(t, firstCtime, pub.Ctime)
+
+	// 先保存草稿再发表
+	draftId := insert(t, d, 1, "draft")
+	_, err = d.Sync(ctx, dao.Article{ID: draftId, Title: "draft", AuthorId: 1, Status: statusPublished, Version: 1}, statusUnpublished)
+	require.NoError(t, err)
+	pub, err = d.GetPubArticleById(ctx, draftId)
+	require.NoError(t, err)
+	assert.Equal(t, "draft", pub.Title)
+}
+
+// testSyncIsAtomic 发表失败时草稿和线上库都不能有变化
+func testSyncIsAtomic(t *testing.T, d dao.ArticleDAO) {
+	ctx := context.Background()
+	id, err := d.Sync(ctx, dao.Article{Title: "v1", ContentKey: "key-1", AuthorId: 1, Status: statusPublished}, statusUnknown)
+	require.NoError(t, err)
+
+	_, err = d.Sync(ctx, dao.Article{ID: id, Title: "v2", AuthorId: 2, Status: statusPublished, Version: 1}, statusPublished)
+	assert.ErrorIs(t, err, dao.ErrAuthorMismatch)
+	_, err = d.Sync(ctx, dao.Article{ID: id, Title: "v2", AuthorId: 1, Status: statusPublished, Version: 5}, statusPublished)
+	assert.ErrorIs(t, err, dao.ErrVersionConflict)
+	// 读到的状态已经过期
+	_, err = d.Sync(ctx, dao.Article{ID: id, Title: "v2", AuthorId: 1, Status: statusPublished, Version: 1}, statusUnpublished)
+	assert.ErrorIs(t, err, dao.ErrStatusChanged)
+
+	art, err := d.GetArticleById(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, "v1", art.Title)
+	assert.Equal(t, int64(1), art.Version)
+	pub, err := d.GetPubArticleById(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, "v1", pub.Title)
+	revs, err := d.GetRevisions(ctx, id, 10, 0)
+	require.NoError(t, err)
+	assert.Len(t, revs, 1)
+}
+
+func testSyncStatus(t *testing.T, d dao.ArticleDAO) {
+	ctx := context.Background()
+	id, err := d.Sync(ctx, dao.Article{Title: "v1", AuthorId: 1, Status: statusPublished}, statusUnknown)
+	require.NoError(t, err)
+
+	require.NoError(t, d.SyncStatus(ctx, dao.Article{ID: id, AuthorId: 1}, statusPublished, statusPrivate))
+	art, err := d.GetArticleById(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, statusPrivate, art.Status)
+	pub, err := d.GetPubArticleById(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, statusPrivate, pub.Status)
+
+	err = d.SyncStatus(ctx, dao.Article{ID: id, AuthorId: 2}, statusPrivate, statusPublished)
+	assert.ErrorIs(t, err, dao.ErrAuthorMismatch)
+	err = d.SyncStatus(ctx, dao.Article{ID: id + 1000, AuthorId: 1}, statusPrivate, statusPublished)
+	assert.ErrorIs(t, err, dao.ErrAuthorMismatch)
+	// 读到的状态已经过期
+	err = d.SyncStatus(ctx, dao.Article{ID: id, AuthorId: 1}, statusPublished, statusPrivate)
+	assert.ErrorIs(t, err, dao.ErrStatusChanged)
+	pub, err = d.GetPubArticleById(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, statusPrivate, pub.Status)
+
+	// 从未发表过的文章只修改草稿
+	draftId := insert(t, d, 1, "draft")
+	require.NoError(t, d.SyncStatus(ctx, dao.Article{ID: draftId, AuthorId: 1}, statusUnpublished, statusPrivate))
+	_, err = d.GetPubArticleById(ctx, draftId)
+	assert.ErrorIs(t, err, dao.ErrArticleNotFound)
+
+	// 回收站中的文章不能修改状态
+	require.NoError(t, d.Delete(ctx, draftId, 1, statusPrivate))
+	err = d.SyncStatus(ctx, dao.Article{ID: draftId, AuthorId: 1}, statusPrivate, statusPublished)
+	assert.ErrorIs(t, err, dao.ErrArticleDeleted)
+}
+
+func testUpdateStatus(t *testing.T, d dao.ArticleDAO) {
+	ctx := context.Background()
+	id, err := d.Sync(ctx, dao.Article{Title: "v1", AuthorId: 1, Status: statusPublished}, statusUnknown)
+	require.NoError(t, err)
+
+	require.NoError(t, d.UpdateStatus(ctx, dao.Article{ID: id, AuthorId: 1}, statusPublished, statusUnpublished))
+	art, err := d.GetArticleById(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, statusUnpublished, art.Status)
+	// 不影响线上库
+	pub, err := d.GetPubArticleById(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, statusPublished, pub.Status)
+
+	err = d.UpdateStatus(ctx, dao.Article{ID: id, AuthorId: 2}, statusUnpublished, statusPublished)
+	assert.ErrorIs(t, err, dao.ErrAuthorMismatch)
+	err = d.UpdateStatus(ctx, dao.Article{ID: id + 1000, AuthorId: 1}, statusUnpublished, statusPublished)
+	assert.ErrorIs(t, err, dao.ErrAuthorMismatch)
+	// 并发修改状态时只有一个能成功
+	err = d.UpdateStatus(ctx, dao.Article{ID: id, AuthorId: 1}, statusPublished, statusPrivate)
+	assert.ErrorIs(t, err, dao.ErrStatusChanged)
+	art, err = d.GetArticleById(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, statusUnpublished, art.Status)
+}
+
+func testGetArticlesByAuthor(t *testing.T, d dao.ArticleDAO) {
+	ctx := context.Background()
+	var ids []int64
+	for i := 0; i < 5; i++ {
+		ids = append(ids, insert(t, d, 1, "a"))
+	}
+	insert(t, d, 2, "other")
+	require.NoError(t, d.Delete(ctx, ids[2], 1, statusUnpublished))
+	want := []int64{ids[4], ids[3], ids[1], ids[0]}
+
+	got := pageArticles(t, func(cursor dao.Cursor) ([]dao.Article, error) {
+		return d.GetArticlesByAuthor(ctx, 1, cursor, 2)
+	})
+	assertOrder(t, want, got)
+
+	deleted, err := d.GetDeletedByAuthor(ctx, 1, dao.Cursor{}, 10)
+	require.NoError(t, err)
+	require.Len(t, deleted, 1)
+	assert.Equal(t, ids[2], deleted[0].ID)
+}
+
+func testGetPubList(t *testing.T, d dao.ArticleDAO) {
+	ctx := context.Background()
+	var ids []int64
+	for i := 0; i < 5; i++ {
+		ids = append(ids, publish(t, d, int64(i%2+1), "p", nil, ""))
+	}
+	// 下线的文章不在列表中
+	require.NoError(t, d.SyncStatus(ctx, dao.Article{ID: ids[1], AuthorId: 2}, statusPublished, statusPrivate))
+	// 只保存了草稿的文章不在列表中
+	insert(t, d, 1, "draft")
+	want := []int64{ids[4], ids[3], ids[2], ids[0]}
+
+	got := pageArticles(t, func(cursor dao.Cursor) ([]dao.Article, error) {
+		res, err := d.GetPubList(ctx, cursor, 2)
+		return toArticles(res), err
+	})
+	assertOrder(t, want, got)
+}
+
+func testGetPubListByAuthor(t *testing.T, d dao.ArticleDAO) {
+	ctx := context.Background()
+	var ids []int64
+	for i := 0; i < 5; i++ {
+		ids = append(ids, publish(t, d, int64(i%2+1), "p", nil, ""))
+	}
+	want := []int64{ids[4], ids[2], ids[0]}
+
+	got := pageArticles(t, func(cursor dao.Cursor) ([]dao.Article, error) {
+		res, err := d.GetPubListByAuthor(ctx, 1, cursor, 2)
+		return toArticles(res), err
+	})
+	assertOrder(t, want, got)
+}
+
+func testGetPubListByIds(t *testing.T, d dao.ArticleDAO) {
+	ctx := context.Background()
+	id1 := publish(t, d, 1, "p1", nil, "")
+	id2 := publish(t, d, 1, "p2", nil, "")
+	id3 := publish(t, d, 1, "p3", nil, "")
+	require.NoError(t, d.SyncStatus(ctx, dao.Article{ID: id3, AuthorId: 1}, statusPublished, statusPrivate))
+	draft := insert(t, d, 1, "draft")
+
+	res, err := d.GetPubListByIds(ctx, []int64{id1, id2, id3, draft})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []int64{id1, id2}, ids(toArticles(res)))
+
+	res, err = d.GetPubListByIds(ctx, nil)
+	require.NoError(t, err)
+	assert.Empty(t, res)
+}
+
+func testGetPubListByTagAndCategory(t *testing.T, d dao.ArticleDAO) {
+	ctx := context.Background()
+	id1 := publish(t, d, 1, "p1", []string{"go", "db"}, "backend")
+	id2 := publish(t, d, 1, "p2", []string{"go"}, "backend")
+	id3 := publish(t, d, 1, "p3", []string{"go"}, "frontend")
+	publish(t, d, 1, "p4", []string{"js"}, "frontend")
+	require.NoError(t, d.SyncStatus(ctx, dao.Article{ID: id2, AuthorId: 1}, statusPublished, statusPrivate))
+
+	res, err := d.GetPubListByTag(ctx, "go", 10, 0)
+	require.NoError(t, err)
+	assertOrder(t, []int64{id3, id1}, toArticles(res))
+	res, err = d.GetPubListByTag(ctx, "go", 10, 1)
+	require.NoError(t, err)
+	assertOrder(t, []int64{id1}, toArticles(res))
+	assert.ElementsMatch(t, []string{"go", "db"}, res[0].Tags)
+
+	res, err = d.GetPubListByCategory(ctx, "frontend", 1, 0)
+	require.NoError(t, err)
+	require.Len(t, res, 1)
+	assert.Equal(t, "p4", res[0].Title)
+	res, err = d.GetPubListByCategory(ctx, "backend", 10, 0)
+	require.NoError(t, err)
+	assertOrder(t, []int64{id1}, toArticles(res))
+}
+
+func testGetTagCounts(t *testing.T, d dao.ArticleDAO) {
+	ctx := context.Background()
+	publish(t, d, 1, "p1", []string{"go", "db"}, "")
+	publish(t, d, 1, "p2", []string{"go", "mq"}, "")
+	id := publish(t, d, 1, "p3", []string{"go", "db"}, "")
+	require.NoError(t, d.SyncStatus(ctx, dao.Article{ID: id, AuthorId: 1}, statusPublished, statusPrivate))
+
+	res, err := d.GetTagCounts(ctx, 10)
+	require.NoError(t, err)
+	// 数量相同时按标签排序
+	assert.Equal(t, []dao.TagCount{{Tag: "go", Count: 2}, {Tag: "db", Count: 1}, {Tag: "mq", Count: 1}}, res)
+
+	res, err = d.GetTagCounts(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []dao.TagCount{{Tag: "go", Count: 2}}, res)
+}
+
+func testRevisions(t *testing.T, d dao.ArticleDAO) {
+	ctx := context.Background()
+	id := insert(t, d, 1, "v1")
+	require.NoError(t, d.UpdateById(ctx, dao.Article{ID: id, AuthorId: 1, Title: "v2", Status: statusUnpublished, Version: 1}, statusUnpublished))
+	_, err := d.Sync(ctx, dao.Article{ID: id, AuthorId: 1, Title: "v3", Status: statusPublished, Version: 2}, statusUnpublished)
+	require.NoError(t, err)
+
+	revs, err := d.GetRevisions(ctx, id, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, revs, 3)
+	// 最新的版本在前
+	assert.Equal(t, "v3", revs[0].Title)
+	assert.Equal(t, "v2", revs[1].Title)
+	assert.Equal(t, "v1", revs[2].Title)
+	for _, rev := range revs {
+		assert.Equal(t, id, rev.ArticleId)
+		assert.Equal(t, int64(1), rev.AuthorId)
+	}
+
+	page, err := d.GetRevisions(ctx, id, 1, 1)
+	require.NoError(t, err)
+	require.Len(t, page, 1)
+	assert.Equal(t, revs[1].ID, page[0].ID)
+
+	rev, err := d.GetRevisionById(ctx, id, revs[1].ID)
+	require.NoError(t, err)
+	assert.Equal(t, "v2", rev.Title)
+	// 版本不属于这篇文章
+	_, err = d.GetRevisionById(ctx, id+1000, revs[1].ID)
+	assert.Error(t, err)
+}
+
+func testDeleteAndRestore(t *testing.T, d dao.ArticleDAO) {
+	ctx := context.Background()
+	id := publish(t, d, 1, "p", []string{"go"}, "")
+
+	assert.ErrorIs(t, d.Delete(ctx, id, 2, statusPublished), dao.ErrAuthorMismatch)
+	assert.ErrorIs(t, d.Delete(ctx, id+1000, 1, statusPublished), dao.ErrAuthorMismatch)
+	assert.ErrorIs(t, d.Delete(ctx, id, 1, statusUnpublished), dao.ErrStatusChanged)
+	require.NoError(t, d.Delete(ctx, id, 1, statusPublished))
+	assert.ErrorIs(t, d.Delete(ctx, id, 1, statusPublished), dao.ErrArticleDeleted)
+
+	art, err := d.GetArticleById(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, statusDeleted, art.Status)
+	assert.Greater(t, art.Dtime, int64(0))
+	// 同时下线
+	_, err = d.GetPubArticleById(ctx, id)
+	assert.ErrorIs(t, err, dao.ErrArticleNotFound)
+	counts, err := d.GetTagCounts(ctx, 10)
+	require.NoError(t, err)
+	assert.Empty(t, counts)
+
+	// 从未发表过的文章也可以删除
+	draft := insert(t, d, 1, "draft")
+	require.NoError(t, d.Delete(ctx, draft, 1, statusUnpublished))
+
+	assert.ErrorIs(t, d.Restore(ctx, id, 2), dao.ErrAuthorMismatch)
+	require.NoError(t, d.Restore(ctx, id, 1))
+	art, err = d.GetArticleById(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, statusUnpublished, art.Status)
+	assert.Equal(t, int64(0), art.Dtime)
+	// 恢复后仍然是下线状态
+	_, err = d.GetPubArticleById(ctx, id)
+	assert.ErrorIs(t, err, dao.ErrArticleNotFound)
+	// 不在回收站中的文章不能恢复
+	assert.ErrorIs(t, d.Restore(ctx, id, 1), dao.ErrAuthorMismatch)
+}
+
+func testPurgeDeleted(t *testing.T, d dao.ArticleDAO) {
+	ctx := context.Background()
+	id1 := insert(t, d, 1, "a")
+	id2 := insert(t, d, 1, "b")
+	keep := insert(t, d, 1, "c")
+	require.NoError(t, d.Delete(ctx, id1, 1, statusUnpublished))
+	require.NoError(t, d.Delete(ctx, id2, 1, statusUnpublished))
+
+	// 还没有超过保留期
+	n, err := d.PurgeDeleted(ctx, time.Now().Add(-time.Hour).Unix(), 10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), n)
+
+	before := time.Now().Add(time.Hour).Unix()
+	n, err = d.PurgeDeleted(ctx, before, 1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+	n, err = d.PurgeDeleted(ctx, before, 10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+
+	for _, id := range []int64{id1, id2} {
+		_, err = d.GetArticleById(ctx, id)
+		assert.ErrorIs(t, err, dao.ErrArticleNotFound)
+		revs, er := d.GetRevisions(ctx, id, 10, 0)
+		require.NoError(t, er)
+		assert.Empty(t, revs)
+	}
+	_, err = d.GetArticleById(ctx, keep)
+	assert.NoError(t, err)
+}
+
+func testContentReferenced(t *testing.T, d dao.ArticleDAO) {
+	ctx := context.Background()
+	referenced := func(key string) bool {
+		ok, err := d.ContentReferenced(ctx, key)
+		require.NoError(t, err)
+		return ok
+	}
+	id := insert(t, d, 1, "draft-1")
+	require.NoError(t, d.UpdateById(ctx, dao.Article{ID: id, AuthorId: 1, Title: "v2", ContentKey: "draft-2",
+		Status: statusUnpublished, Version: 1}, statusUnpublished))
+	pubId := publish(t, d, 2, "pub-1", nil, "")
+	// 草稿已经换成新的正文 旧的正文还在历史版本中
+	assert.True(t, referenced("draft-1"))
+	assert.True(t, referenced("draft-2"))
+	assert.True(t, referenced("pub-1"))
+	assert.False(t, referenced("unknown"))
+
+	require.NoError(t, d.Delete(ctx, id, 1, statusUnpublished))
+	require.NoError(t, d.Delete(ctx, pubId, 2, statusPublished))
+	// 回收站中的文章还可以恢复
+	assert.True(t, referenced("draft-2"))
+	_, err := d.PurgeDeleted(ctx, time.Now().Add(time.Hour).Unix(), 10)
+	require.NoError(t, err)
+	for _, key := range []string{"draft-1", "draft-2", "pub-1"} {
+		assert.False(t, referenced(key), key)
+	}
+}
+
+func insert(t *testing.T, d dao.ArticleDAO, uid int64, title string) int64 {
+	id, err := d.Insert(context.Background(), dao.Article{Title: title, ContentKey: title, AuthorId: uid, Status: statusUnpublished})
+	require.NoError(t, err)
+	return id
+}
+
+func publish(t *testing.T, d dao.ArticleDAO, uid int64, title string, tags []string, category string) int64 {
+	id, err := d.Sync(context.Background(), dao.Article{
+		Title:      title,
+		ContentKey: title,
+		AuthorId:   uid,
+		Status:     statusPublished,
+		Tags:       tags,
+		Category:   category,
+	}, statusUnknown)
+	require.NoError(t, err)
+	return id
+}
+
+// pageArticles 用游标翻页取出全部数据
+func pageArticles(t *testing.T, next func(cursor dao.Cursor) ([]dao.Article, error)) []dao.Article {
+	var res []dao.Article
+	var cursor dao.Cursor
+	for i := 0; i < 100; i++ {
+		arts, err := next(cursor)
+		require.NoError(t, err)
+		if len(arts) == 0 {
+			return res
+		}
+		res = append(res, arts...)
+		last := arts[len(arts)-1]
+		cursor = dao.Cursor{Utime: last.Utime, ID: last.ID}
+	}
+	t.Fatal("游标翻页没有结束")
+	return nil
+}
+
+// assertOrder 同一秒内写入的数据 utime 相同，要求按 id 倒序
+func assertOrder(t *testing.T, want []int64, got []dao.Article) {
+	t.Helper()
+	assert.Equal(t, want, ids(got))
+	for i := 1; i < len(got); i++ {
+		prev, cur := got[i-1], got[i]
+		assert.True(t, prev.Utime > cur.Utime || (prev.Utime == cur.Utime && prev.ID > cur.ID),
+			"第 %d 条数据没有按 (utime, id) 倒序", i)
+	}
+}
+
+func toArticles(pubs []dao.PublishedArticle) []dao.Article {
+	res := make([]dao.Article, 0, len(pubs))
+	for _, pub := range pubs {
+		res = append(res, dao.Article(pub))
+	}
+	return res
+}
+
+func ids(arts []dao.Article) []int64 {
+	res := make([]int64, 0, len(arts))
+	for _, art := range arts {
+		res = append(res, art.ID)
+	}
+	return res
+}
@@ -0,0 +1,82 @@
+package dao
+
+import (
+	"context"
+	"github.com/cockroachdb/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"sync"
+	"time"
+)
+
+var ErrInvalidIDStep = errors.New("号段步长必须大于0")
+
+// IDGenerator 全局唯一且递增的ID生成器
+type IDGenerator interface {
+	NextID(ctx context.Context) (int64, error)
+}
+
+// IDSegment 号段表 每个业务一行，max_id 为已经分配出去的最大ID
+type IDSegment struct {
+	Biz   string `gorm:"column:biz;primaryKey;type:varchar(64)"`
+	MaxId int64  `gorm:"column:max_id;not null;default:0"`
+	Utime int64  `gorm:"column:utime"`
+}
+
+// SegmentIDGenerator 号段模式 每次从数据库领取 step 个ID在内存中分配，用完再领取
+// 多个实例共用同一张号段表时ID全局唯一，但只保证单个实例内递增
+type SegmentIDGenerator struct {
+	db   *gorm.DB
+	biz  string
+	step int64
+
+	mu  sync.Mutex
+	cur int64 // 上一个分配出去的ID
+	max int64 // 当前号段的最大ID
+}
+
+func NewSegmentIDGenerator(db *gorm.DB, biz string, step int64) (*SegmentIDGenerator, error) {
+	if step <= 0 {
+		return nil, ErrInvalidIDStep
+	}
+	return &SegmentIDGenerator{db: db, biz: biz, step: step}, nil
+}
+
+func (s *SegmentIDGenerator) NextID(ctx context.Context) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cur >= s.max {
+		maxId, err := s.allocate(ctx)
+		if err != nil {
+			return 0, err
+		}
+		s.cur, s.max = maxId-s.step, maxId
+	}
+	s.cur++
+	return s.cur, nil
+}
+
+// allocate 领取一个新的号段 返回号段的最大ID
+func (s *SegmentIDGenerator) allocate(ctx context.Context) (int64, error) {
+	var segment IDSegment
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now().Unix()
+		// 第一次使用时创建
+		err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&IDSegment{Biz: s.biz, Utime: now}).Error
+		if err != nil {
+			return err
+		}
+		// 行锁保证多个实例领取到的号段不重叠
+		err = tx.Model(&IDSegment{}).Where("biz = ?", s.biz).
+			Updates(map[string]any{
+				"max_id": gorm.Expr("max_id + ?", s.step),
+				"utime":  now,
+			}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Where("biz = ?", s.biz).First(&segment).Error
+	})
+	return segment.MaxId, err
+}
@@ -6,6 +6,30 @@ func CreateTableForArticle(db *gorm.DB) {
 	err := db.AutoMigrate(
 		&Article{},
 		&PublishedArticle{},
+		&ArticleRevision{},
+		&ArticleTag{},
+		&PublishedArticleTag{},
+		&ArticleReview{},
+		&ArticleStatusLog{},
+		&ArticleShareLink{},
+		&ArticleSeries{},
+		&SeriesArticle{},
+	)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// CreateTableForArticleShard 分库中只有文章相关的表和分库记录 号段表只在第一个库中使用
+func CreateTableForArticleShard(db *gorm.DB) {
+	err := db.AutoMigrate(
+		&ArticleShard{},
+		&Article{},
+		&PublishedArticle{},
+		&ArticleRevision{},
+		&ArticleTag{},
+		&PublishedArticleTag{},
+		&IDSegment{},
 	)
 	if err != nil {
 		panic(err)
@@ -0,0 +1,399 @@
+package dao
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+)
+
+// InMemoryArticleDAO 基于内存的 ArticleDAO 用于单元测试
+// 行为与 MySQL、MongoDB 实现一致，由 daotest 中的契约测试保证
+type InMemoryArticleDAO struct {
+	mu        sync.RWMutex
+	nextId    int64
+	nextRevId int64
+	articles  map[int64]Article
+	published map[int64]PublishedArticle
+	revisions map[int64]ArticleRevision
+}
+
+func NewInMemoryArticleDAO() ArticleDAO {
+	return &InMemoryArticleDAO{
+		articles:  make(map[int64]Article),
+		published: make(map[int64]PublishedArticle),
+		revisions: make(map[int64]ArticleRevision),
+	}
+}
+
+func (m *InMemoryArticleDAO) Insert(ctx context.Context, article Article) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.insert(article).ID, nil
+}
+
+func (m *InMemoryArticleDAO) UpdateById(ctx context.Context, article Article, from uint8) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, err := m.update(article, from)
+	return err
+}
+
+func (m *InMemoryArticleDAO) Sync(ctx context.Context, article Article, from uint8) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if article.ID > 0 { // 更新
+		revId, err := m.update(article, from)
+		if err != nil {
+			return 0, err
+		}
+		article.RevisionId = revId
+		article.Version++
+	} else { // 新增
+		article = m.insert(article)
+	}
+	now := time.Now().Unix()
+	pub := PublishedArticle(article)
+	pub.Tags = slices.Clone(article.Tags)
+	pub.Ctime, pub.Utime = now, now
+	if old, ok := m.published[article.ID]; ok {
+		pub.Ctime = old.Ctime // 保留首次发表时间
+	}
+	pub.Dtime = 0
+	m.published[article.ID] = pub
+	return article.ID, nil
+}
+
+func (m *InMemoryArticleDAO) SyncStatus(ctx context.Context, article Article, from uint8, to uint8) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now().Unix()
+	if err := m.setStatus(article, from, to, now); err != nil {
+		return err
+	}
+	// 从未发表过的文章在线上库中没有数据
+	if pub, ok := m.published[article.ID]; ok {
+		pub.Status, pub.Utime = to, now
+		m.published[article.ID] = pub
+	}
+	return nil
+}
+
+func (m *InMemoryArticleDAO) UpdateStatus(ctx context.Context, article Article, from uint8, to uint8) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.setStatus(article, from, to, time.Now().Unix())
+}
+
+func (m *InMemoryArticleDAO) GetArticlesByAuthor(ctx context.Context, uid int64, cursor Cursor, limit int) ([]Article, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.findArticles(func(a Article) bool {
+		return a.AuthorId == uid && a.Status != statusDeleted
+	}, cursor, limit), nil
+}
+
+func (m *InMemoryArticleDAO) GetArticleById(ctx context.Context, id int64) (Article, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	article, ok := m.articles[id]
+	if !ok {
+		return Article{}, ErrArticleNotFound
+	}
+	return cloneArticle(article), nil
+}
+
+func (m *InMemoryArticleDAO) GetPubArticleById(ctx context.Context, id int64) (PublishedArticle, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	pub, ok := m.published[id]
+	if !ok {
+		return PublishedArticle{}, ErrArticleNotFound
+	}
+	return PublishedArticle(cloneArticle(Article(pub))), nil
+}
+
+func (m *InMemoryArticleDAO) GetPubList(ctx context.Context, cursor Cursor, limit int) ([]PublishedArticle, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.findPublished(func(p PublishedArticle) bool {
+		return p.Status == statusPublished
+	}, cursor, limit, 0), nil
+}
+
+func (m *InMemoryArticleDAO) GetPubListByAuthor(ctx context.Context, uid int64, cursor Cursor, limit int) ([]PublishedArticle, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.findPublished(func(p PublishedArticle) bool {
+		return p.AuthorId == uid && p.Status == statusPublished
+	}, cursor, limit, 0), nil
+}
+
+func (m *InMemoryArticleDAO) GetPubListByIds(ctx context.Context, ids []int64) ([]PublishedArticle, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	res := make([]PublishedArticle, 0, len(ids))
+	for _, id := range ids {
+		if pub, ok := m.published[id]; ok && pub.Status == statusPublished {
+			res = append(res, PublishedArticle(cloneArticle(Article(pub))))
+		}
+	}
+	return res, nil
+}
+
+func (m *InMemoryArticleDAO) GetRevisions(ctx context.Context, artId int64, limit int, offset int) ([]ArticleRevision, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var res []ArticleRevision
+	for _, rev := range m.revisions {
+		if rev.ArticleId == artId {
+			res = append(res, rev)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].ID > res[j].ID
+	})
+	return page(res, limit, offset), nil
+}
+
+func (m *InMemoryArticleDAO) GetRevisionById(ctx context.Context, artId int64, revId int64) (ArticleRevision, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	rev, ok := m.revisions[revId]
+	if !ok || rev.ArticleId != artId {
+		return ArticleRevision{}, ErrArticleNotFound
+	}
+	return rev, nil
+}
+
+func (m *InMemoryArticleDAO) GetPubListByTag(ctx context.Context, tag string, limit int, offset int) ([]PublishedArticle, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.findPublished(func(p PublishedArticle) bool {
+		return p.Status == statusPublished && slices.Contains(p.Tags, tag)
+	}, Cursor{}, limit, offset), nil
+}
+
+func (m *InMemoryArticleDAO) GetPubListByCategory(ctx context.Context, category string, limit int, offset int) ([]PublishedArticle, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.findPublished(func(p PublishedArticle) bool {
+		return p.Status == statusPublished && p.Category == category
+	}, Cursor{}, limit, offset), nil
+}
+
+func (m *InMemoryArticleDAO) GetTagCounts(ctx context.Context, limit int) ([]TagCount, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	counts := make(map[string]int64)
+	for _, pub := range m.published {
+		if pub.Status != statusPublished {
+			continue
+		}
+		for _, tag := range pub.Tags {
+			counts[tag]++
+		}
+	}
+	res := make([]TagCount, 0, len(counts))
+	for tag, count := range counts {
+		res = append(res, TagCount{Tag: tag, Count: count})
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Count != res[j].Count {
+			return res[i].Count > res[j].Count
+		}
+		return res[i].Tag < res[j].Tag
+	})
+	return page(res, limit, 0), nil
+}
+
+func (m *InMemoryArticleDAO) Delete(ctx context.Context, id int64, authorId int64, from uint8) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	article, ok := m.articles[id]
+	if !ok {
+		return ErrAuthorMismatch
+	}
+	if err := statusMismatch(article, authorId, from); err != nil {
+		return err
+	}
+	now := time.Now().Unix()
+	article.Status, article.Dtime, article.Utime = statusDeleted, now, now
+	m.articles[id] = article
+	delete(m.published, id)
+	return nil
+}
+
+func (m *InMemoryArticleDAO) GetDeletedByAuthor(ctx context.Context, uid int64, cursor Cursor, limit int) ([]Article, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.findArticles(func(a Article) bool {
+		return a.AuthorId == uid && a.Status == statusDeleted
+	}, cursor, limit), nil
+}
+
+func (m *InMemoryArticleDAO) Restore(ctx context.Context, id int64, authorId int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	article, ok := m.articles[id]
+	if !ok || article.AuthorId != authorId || article.Status != statusDeleted {
+		return ErrAuthorMismatch
+	}
+	article.Status, article.Dtime, article.Utime = statusUnpublished, 0, time.Now().Unix()
+	m.articles[id] = article
+	return nil
+}
+
+func (m *InMemoryArticleDAO) PurgeDeleted(ctx context.Context, before int64, limit int) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var ids []int64
+	for id, article := range m.articles {
+		if article.Status == statusDeleted && article.Dtime < before {
+			ids = append(ids, id)
+		}
+	}
+	slices.Sort(ids)
+	ids = page(ids, limit, 0)
+	for _, id := range ids {
+		delete(m.articles, id)
+		for revId, rev := range m.revisions {
+			if rev.ArticleId == id {
+				delete(m.revisions, revId)
+			}
+		}
+	}
+	return int64(len(ids)), nil
+}
+
+func (m *InMemoryArticleDAO) ContentReferenced(ctx context.Context, key string) (bool, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, article := range m.articles {
+		if article.ContentKey == key {
+			return true, nil
+		}
+	}
+	for _, article := range m.published {
+		if article.ContentKey == key {
+			return true, nil
+		}
+	}
+	for _, rev := range m.revisions {
+		if rev.ContentKey == key {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// insert 新增文章并记录第一个版本，调用方需要持有写锁
+func (m *InMemoryArticleDAO) insert(article Article) Article {
+	now := time.Now().Unix()
+	m.nextId++
+	article.ID = m.nextId
+	article.Ctime, article.Utime = now, now
+	article.Version = 1
+	article.Tags = slices.Clone(article.Tags)
+	article.RevisionId = m.saveRevision(article, now)
+	m.articles[article.ID] = article
+	return article
+}
+
+// update 校验通过后才会修改数据，返回新版本ID，调用方需要持有写锁
+func (m *InMemoryArticleDAO) update(article Article, from uint8) (int64, error) {
+	current, ok := m.articles[article.ID]
+	if !ok {
+		return 0, ErrAuthorMismatch
+	}
+	if err := statusMismatch(current, article.AuthorId, from); err != nil {
+		return 0, err
+	}
+	if current.Version != article.Version {
+		return 0, &VersionConflictError{Current: current.Version}
+	}
+	now := time.Now().Unix()
+	current.Title = article.Title
+	current.Content = article.Content
+	current.ContentKey = article.ContentKey
+	current.Status = article.Status
+	current.Category = article.Category
+	current.Tags = slices.Clone(article.Tags)
+	current.Html = article.Html
+	current.Abstract = article.Abstract
+	current.WordCount = article.WordCount
+	current.ReadingTime = article.ReadingTime
+	current.Toc = article.Toc
+	current.Version++
+	current.Utime = now
+	current.RevisionId = m.saveRevision(current, now)
+	m.articles[article.ID] = current
+	return current.RevisionId, nil
+}
+
+// setStatus 草稿的状态是 from 时改为 to，调用方需要持有写锁
+func (m *InMemoryArticleDAO) setStatus(article Article, from uint8, to uint8, now int64) error {
+	current, ok := m.articles[article.ID]
+	if !ok {
+		return ErrAuthorMismatch
+	}
+	if err := statusMismatch(current, article.AuthorId, from); err != nil {
+		return err
+	}
+	current.Status, current.Utime = to, now
+	m.articles[article.ID] = current
+	return nil
+}
+
+func (m *InMemoryArticleDAO) saveRevision(article Article, now int64) int64 {
+	m.nextRevId++
+	m.revisions[m.nextRevId] = ArticleRevision{
+		ID:         m.nextRevId,
+		ArticleId:  article.ID,
+		AuthorId:   article.AuthorId,
+		Title:      article.Title,
+		Content:    article.Content,
+		ContentKey: article.ContentKey,
+		Ctime:      now,
+	}
+	return m.nextRevId
+}
+
+// findArticles 按 (utime, id) 倒序取游标之后的草稿
+func (m *InMemoryArticleDAO) findArticles(match func(Article) bool, cursor Cursor, limit int) []Article {
+	var res []Article
+	for _, article := range m.articles {
+		if match(article) && isAfterCursor(article.Utime, article.ID, cursor) {
+			res = append(res, cloneArticle(article))
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return newerThan(res[i].Utime, res[i].ID, res[j].Utime, res[j].ID)
+	})
+	return page(res, limit, 0)
+}
+
+// findPublished 按 (utime, id) 倒序取游标之后的线上文章
+func (m *InMemoryArticleDAO) findPublished(match func(PublishedArticle) bool, cursor Cursor, limit int, offset int) []PublishedArticle {
+	var res []PublishedArticle
+	for _, pub := range m.published {
+		if match(pub) && isAfterCursor(pub.Utime, pub.ID, cursor) {
+			res = append(res, PublishedArticle(cloneArticle(Article(pub))))
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return newerThan(res[i].Utime, res[i].ID, res[j].Utime, res[j].ID)
+	})
+	return page(res, limit, offset)
+}
+
+func isAfterCursor(utime int64, id int64, cursor Cursor) bool {
+	return cursor.IsZero() || newerThan(cursor.Utime, cursor.ID, utime, id)
+}
+
+// cloneArticle 返回的数据不能与内部保存的数据共用标签切片
+func cloneArticle(article Article) Article {
+	article.Tags = slices.Clone(article.Tags)
+	return article
+}
@@ -0,0 +1,94 @@
+package dao
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"time"
+)
+
+const (
+	ReviewStatusPending  uint8 = 1
+	ReviewStatusCanceled uint8 = 4
+)
+
+var ErrReviewNotFound = gorm.ErrRecordNotFound
+
+// ArticleReview 文章审核记录 只有 MySQL 实现
+type ArticleReview struct {
+	ID        int64  `gorm:"column:id;primaryKey;autoIncrement;not null"`
+	ArticleId int64  `gorm:"column:article_id;not null;index"`
+	AuthorId  int64  `gorm:"column:author_id;not null"`
+	Title     string `gorm:"column:title;type:varchar(256)"`
+	// 命中的敏感词 JSON 数组
+	Words     string `gorm:"column:words;type:varchar(1024)"`
+	PublishAt int64  `gorm:"column:publish_at;not null;default:0"`
+	// 二级索引隐含主键 按状态查询时可以直接按ID排序
+	Status   uint8  `gorm:"column:status;type:tinyint(1);not null;index"`
+	Reason   string `gorm:"column:reason;type:varchar(1024)"`
+	Reviewer int64  `gorm:"column:reviewer;not null;default:0"`
+	Ctime    int64  `gorm:"column:ctime;not null"`
+	Utime    int64  `gorm:"column:utime;not null"`
+}
+
+type ReviewDAO interface {
+	// Insert 提交审核 同一篇文章之前等待审核的记录会被取消
+	Insert(ctx context.Context, review ArticleReview) (int64, error)
+	GetById(ctx context.Context, id int64) (ArticleReview, error)
+	// GetByStatus 按ID正序获取某个状态的审核 minId 为 0 表示第一页
+	GetByStatus(ctx context.Context, status uint8, minId int64, limit int) ([]ArticleReview, error)
+	// Finish 结束一条等待中的审核 已经结束的不会修改，返回是否修改了
+	Finish(ctx context.Context, review ArticleReview) (bool, error)
+}
+
+type GormReviewDAO struct {
+	db *gorm.DB
+}
+
+func NewGormReviewDAO(db *gorm.DB) ReviewDAO {
+	return &GormReviewDAO{db: db}
+}
+
+func (g *GormReviewDAO) Insert(ctx context.Context, review ArticleReview) (int64, error) {
+	now := time.Now().Unix()
+	review.Ctime, review.Utime = now, now
+	review.Status = ReviewStatusPending
+	err := g.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&ArticleReview{}).
+			Where("article_id = ? AND status = ?", review.ArticleId, ReviewStatusPending).
+			Updates(map[string]any{
+				"status": ReviewStatusCanceled,
+				"utime":  now,
+			}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Create(&review).Error
+	})
+	return review.ID, err
+}
+
+func (g *GormReviewDAO) GetById(ctx context.Context, id int64) (ArticleReview, error) {
+	var review ArticleReview
+	err := g.db.WithContext(ctx).Where("id = ?", id).First(&review).Error
+	return review, err
+}
+
+func (g *GormReviewDAO) GetByStatus(ctx context.Context, status uint8, minId int64, limit int) ([]ArticleReview, error) {
+	var reviews []ArticleReview
+	err := g.db.WithContext(ctx).
+		Where("status = ? AND id > ?", status, minId).
+		Order("id ASC").Limit(limit).Find(&reviews).Error
+	return reviews, err
+}
+
+func (g *GormReviewDAO) Finish(ctx context.Context, review ArticleReview) (bool, error) {
+	res := g.db.WithContext(ctx).Model(&ArticleReview{}).
+		Where("id = ? AND status = ?", review.ID, ReviewStatusPending).
+		Updates(map[string]any{
+			"status":   review.Status,
+			"reason":   review.Reason,
+			"reviewer": review.Reviewer,
+			"utime":    time.Now().Unix(),
+		})
+	return res.RowsAffected > 0, res.Error
+}
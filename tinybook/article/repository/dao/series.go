@@ -0,0 +1,260 @@
+package dao
+
+import (
+	"context"
+	"github.com/cockroachdb/errors"
+	"github.com/go-sql-driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+var (
+	ErrSeriesNotFound         = errors.New("系列不存在")
+	ErrArticleInSeries        = errors.New("文章已经属于某个系列")
+	ErrArticleNotInSeries     = errors.New("文章不在系列中")
+	ErrSeriesArticlesMismatch = errors.New("排序的文章与系列中的文章不一致")
+)
+
+// ArticleSeries 作者的系列(专栏) 只有 MySQL 实现
+type ArticleSeries struct {
+	ID          int64  `gorm:"column:id;primaryKey;autoIncrement;not null"`
+	AuthorId    int64  `gorm:"column:author_id;not null;index"`
+	Name        string `gorm:"column:name;type:varchar(255);not null"`
+	Description string `gorm:"column:description;type:varchar(2048);not null;default:''"`
+	Ctime       int64  `gorm:"column:ctime;not null"`
+	Utime       int64  `gorm:"column:utime;not null"`
+}
+
+// SeriesArticle 系列中的一篇文章 文章ID上的唯一索引保证一篇文章只能属于一个系列
+// 不校验文章的状态，展示时再过滤掉未发表的文章
+type SeriesArticle struct {
+	ID        int64 `gorm:"column:id;primaryKey;autoIncrement;not null"`
+	SeriesId  int64 `gorm:"column:series_id;not null;index:idx_series_position,priority:1"`
+	ArticleId int64 `gorm:"column:article_id;not null;uniqueIndex"`
+	Position  int64 `gorm:"column:position;not null;index:idx_series_position,priority:2"`
+	Ctime     int64 `gorm:"column:ctime;not null"`
+}
+
+type SeriesDAO interface {
+	Insert(ctx context.Context, series ArticleSeries) (int64, error)
+	// Update 只更新名称和简介 不是该作者的系列返回 ErrSeriesNotFound
+	Update(ctx context.Context, series ArticleSeries) error
+	// Delete 删除系列以及系列与文章的关系，文章本身不受影响
+	Delete(ctx context.Context, id int64, authorId int64) error
+	GetById(ctx context.Context, id int64) (ArticleSeries, error)
+	// GetByAuthor 按创建时间倒序
+	GetByAuthor(ctx context.Context, authorId int64) ([]ArticleSeries, error)
+	CountByAuthor(ctx context.Context, authorId int64) (int64, error)
+	// GetArticles 这些系列中的文章 按系列、系列中的顺序排列
+	GetArticles(ctx context.Context, seriesIds []int64) ([]SeriesArticle, error)
+	// GetByArticle 文章所在的系列 不属于任何系列时返回 ErrArticleNotInSeries
+	GetByArticle(ctx context.Context, artId int64) (SeriesArticle, error)
+	// AddArticle 追加到系列末尾 文章已经属于某个系列时返回 ErrArticleInSeries
+	AddArticle(ctx context.Context, id int64, authorId int64, artId int64) error
+	RemoveArticle(ctx context.Context, id int64, authorId int64, artId int64) error
+	// Reorder artIds 必须恰好是系列中的全部文章，否则返回 ErrSeriesArticlesMismatch
+	Reorder(ctx context.Context, id int64, authorId int64, artIds []int64) error
+}
+
+type GormSeriesDAO struct {
+	db *gorm.DB
+}
+
+func NewGormSeriesDAO(db *gorm.DB) SeriesDAO {
+	return &GormSeriesDAO{db: db}
+}
+
+func (g *GormSeriesDAO) Insert(ctx context.Context, series ArticleSeries) (int64, error) {
+	now := time.Now().Unix()
+	series.Ctime, series.Utime = now, now
+	err := g.db.WithContext(ctx).Create(&series).Error
+	return series.ID, err
+}
+
+func (g *GormSeriesDAO) Update(ctx context.Context, series ArticleSeries) error {
+	res := g.db.WithContext(ctx).Model(&ArticleSeries{}).
+		Where("id = ? AND author_id = ?", series.ID, series.AuthorId).
+		Updates(map[string]any{
+			"name":        series.Name,
+			"description": series.Description,
+			"utime":       time.Now().Unix(),
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		// 一秒内重复提交相同的内容时 mysql 也会返回0行 需要确认系列是否存在
+		var count int64
+		err := g.db.WithContext(ctx).Model(&ArticleSeries{}).
+			Where("id = ? AND author_id = ?", series.ID, series.AuthorId).
+			Count(&count).Error
+		if err != nil {
+			return err
+		}
+		if count == 0 {
+			return ErrSeriesNotFound
+		}
+	}
+	return nil
+}
+
+func (g *GormSeriesDAO) Delete(ctx context.Context, id int64, authorId int64) error {
+	return g.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Where("id = ? AND author_id = ?", id, authorId).Delete(&ArticleSeries{})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrSeriesNotFound
+		}
+		return tx.Where("series_id = ?", id).Delete(&SeriesArticle{}).Error
+	})
+}
+
+func (g *GormSeriesDAO) GetById(ctx context.Context, id int64) (ArticleSeries, error) {
+	var series ArticleSeries
+	err := g.db.WithContext(ctx).Where("id = ?", id).First(&series).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return series, ErrSeriesNotFound
+	}
+	return series, err
+}
+
+func (g *GormSeriesDAO) GetByAuthor(ctx context.Context, authorId int64) ([]ArticleSeries, error) {
+	var list []ArticleSeries
+	err := g.db.WithContext(ctx).Where("author_id = ?", authorId).
+		Order("id DESC").
+		Find(&list).Error
+	return list, err
+}
+
+func (g *GormSeriesDAO) CountByAuthor(ctx context.Context, authorId int64) (int64, error) {
+	var count int64
+	err := g.db.WithContext(ctx).Model(&ArticleSeries{}).
+		Where("author_id = ?", authorId).
+		Count(&count).Error
+	return count, err
+}
+
+func (g *GormSeriesDAO) GetArticles(ctx context.Context, seriesIds []int64) ([]SeriesArticle, error) {
+	if len(seriesIds) == 0 {
+		return nil, nil
+	}
+	var articles []SeriesArticle
+	err := g.db.WithContext(ctx).Where("series_id IN ?", seriesIds).
+		Order("series_id, position").
+		Find(&articles).Error
+	return articles, err
+}
+
+func (g *GormSeriesDAO) GetByArticle(ctx context.Context, artId int64) (SeriesArticle, error) {
+	var article SeriesArticle
+	err := g.db.WithContext(ctx).Where("article_id = ?", artId).First(&article).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return article, ErrArticleNotInSeries
+	}
+	return article, err
+}
+
+func (g *GormSeriesDAO) AddArticle(ctx context.Context, id int64, authorId int64, artId int64) error {
+	return g.lockSeries(ctx, id, authorId, func(tx *gorm.DB) error {
+		var maxPosition int64
+		err := tx.Model(&SeriesArticle{}).
+			Select("COALESCE(MAX(position), 0)").
+			Where("series_id = ?", id).
+			Scan(&maxPosition).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Create(&SeriesArticle{
+			SeriesId:  id,
+			ArticleId: artId,
+			Position:  maxPosition + 1,
+			Ctime:     time.Now().Unix(),
+		}).Error
+		if isDuplicate(err) {
+			return ErrArticleInSeries
+		}
+		return err
+	})
+}
+
+func (g *GormSeriesDAO) RemoveArticle(ctx context.Context, id int64, authorId int64, artId int64) error {
+	return g.lockSeries(ctx, id, authorId, func(tx *gorm.DB) error {
+		res := tx.Where("series_id = ? AND article_id = ?", id, artId).Delete(&SeriesArticle{})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrArticleNotInSeries
+		}
+		return nil
+	})
+}
+
+func (g *GormSeriesDAO) Reorder(ctx context.Context, id int64, authorId int64, artIds []int64) error {
+	return g.lockSeries(ctx, id, authorId, func(tx *gorm.DB) error {
+		var current []int64
+		err := tx.Model(&SeriesArticle{}).Where("series_id = ?", id).Pluck("article_id", &current).Error
+		if err != nil {
+			return err
+		}
+		if !sameIds(current, artIds) {
+			return ErrSeriesArticlesMismatch
+		}
+		for i, artId := range artIds {
+			err = tx.Model(&SeriesArticle{}).
+				Where("series_id = ? AND article_id = ?", id, artId).
+				Update("position", i+1).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// lockSeries 锁住作者的系列后在事务中执行 fn，同一个系列的修改串行执行，并更新系列的修改时间
+func (g *GormSeriesDAO) lockSeries(ctx context.Context, id int64, authorId int64, fn func(tx *gorm.DB) error) error {
+	return g.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var series ArticleSeries
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND author_id = ?", id, authorId).
+			First(&series).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrSeriesNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if err = fn(tx); err != nil {
+			return err
+		}
+		return tx.Model(&series).Update("utime", time.Now().Unix()).Error
+	})
+}
+
+// sameIds a 和 b 是否包含相同的ID b 中不能有重复
+func sameIds(a []int64, b []int64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	set := make(map[int64]struct{}, len(a))
+	for _, id := range a {
+		set[id] = struct{}{}
+	}
+	for _, id := range b {
+		if _, ok := set[id]; !ok {
+			return false
+		}
+		delete(set, id)
+	}
+	return true
+}
+
+// isDuplicate 唯一索引冲突
+func isDuplicate(err error) bool {
+	var my *mysql.MySQLError
+	return errors.As(err, &my) && my.Number == 1062
+}
@@ -0,0 +1,316 @@
+package dao
+
+import (
+	"context"
+	"github.com/cockroachdb/errors"
+	"golang.org/x/sync/errgroup"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"sort"
+	"time"
+)
+
+// ShardBits 文章ID的低 ShardBits 位记录所在的分库，最多支持 1<<ShardBits 个分库
+const ShardBits = 8
+
+var (
+	ErrInvalidShardCount = errors.New("分库数量不合法")
+	ErrShardMismatch     = errors.New("分库配置与建表时记录的不一致")
+)
+
+// ShardingArticleDAO 按作者ID把文章分到多个 MySQL 库中
+// 同一个作者的文章(连同历史版本、标签和线上文章)都在同一个库里，文章ID的低位记录了分库，
+// 因此只有文章ID时也能直接定位；没有作者ID也没有文章ID的查询需要查询所有分库再合并
+// 分库数量确定后不能再修改，否则已有的作者会被路由到别的库
+type ShardingArticleDAO struct {
+	shards []*GormArticleDAO
+	idGen  IDGenerator
+}
+
+func NewShardingArticleDAO(dbs []*gorm.DB, idGen IDGenerator) (ArticleDAO, error) {
+	if len(dbs) == 0 || len(dbs) > 1<<ShardBits {
+		return nil, ErrInvalidShardCount
+	}
+	shards := make([]*GormArticleDAO, 0, len(dbs))
+	for _, db := range dbs {
+		shards = append(shards, &GormArticleDAO{db: db})
+	}
+	return &ShardingArticleDAO{shards: shards, idGen: idGen}, nil
+}
+
+// ArticleShard 分库记录 每个分库中只有一行，记录自己是第几个库以及分库总数
+// 由 cmd/articleshard 建表时写入，启动时用来检查 articleSharding.dsns 的数量和顺序
+type ArticleShard struct {
+	Id    int64 `gorm:"column:id;primaryKey"` // 固定为 1
+	Index int   `gorm:"column:shard_index;not null"`
+	Count int   `gorm:"column:shard_count;not null"`
+	Ctime int64 `gorm:"column:ctime"`
+}
+
+// RecordShards 记录各个库的分库信息 已经记录过的库保持不变，记录与 dbs 不一致时返回 ErrShardMismatch
+func RecordShards(ctx context.Context, dbs []*gorm.DB) error {
+	now := time.Now().Unix()
+	for i, db := range dbs {
+		shard := ArticleShard{Id: 1, Index: i, Count: len(dbs), Ctime: now}
+		err := db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&shard).Error
+		if err != nil {
+			return err
+		}
+	}
+	return VerifyShards(ctx, dbs)
+}
+
+// VerifyShards 检查 dbs 的数量和顺序与建表时记录的一致 路由依赖分库数量，不一致时已有的作者会被路由到别的库
+func VerifyShards(ctx context.Context, dbs []*gorm.DB) error {
+	for i, db := range dbs {
+		var shard ArticleShard
+		err := db.WithContext(ctx).Where("id = ?", 1).First(&shard).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.Wrapf(ErrShardMismatch, "第 %d 个库没有分库记录，需要先用 cmd/articleshard 建表", i)
+		}
+		if err != nil {
+			return err
+		}
+		if shard.Index != i || shard.Count != len(dbs) {
+			return errors.Wrapf(ErrShardMismatch, "第 %d 个库记录为 %d/%d 个分库，配置为 %d/%d 个分库",
+				i, shard.Index, shard.Count, i, len(dbs))
+		}
+	}
+	return nil
+}
+
+// shardIndexOfAuthor 新文章所在的分库
+func (s *ShardingArticleDAO) shardIndexOfAuthor(uid int64) int64 {
+	return uid % int64(len(s.shards))
+}
+
+func (s *ShardingArticleDAO) byAuthor(uid int64) *GormArticleDAO {
+	return s.shards[s.shardIndexOfAuthor(uid)]
+}
+
+// byId 由文章ID的低位定位分库 不合法的ID落到第一个库，查询结果为不存在
+func (s *ShardingArticleDAO) byId(id int64) *GormArticleDAO {
+	idx := id & (1<<ShardBits - 1)
+	if id <= 0 || idx >= int64(len(s.shards)) {
+		return s.shards[0]
+	}
+	return s.shards[idx]
+}
+
+// newId 生成的ID低位为作者所在的分库
+func (s *ShardingArticleDAO) newId(ctx context.Context, uid int64) (int64, error) {
+	seq, err := s.idGen.NextID(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return seq<<ShardBits | s.shardIndexOfAuthor(uid), nil
+}
+
+func (s *ShardingArticleDAO) Insert(ctx context.Context, article Article) (int64, error) {
+	var err error
+	article.ID, err = s.newId(ctx, article.AuthorId)
+	if err != nil {
+		return 0, err
+	}
+	return s.byAuthor(article.AuthorId).Insert(ctx, article)
+}
+
+// UpdateById 文章ID与作者不在同一个库时，在文章所在的库中必然匹配不上作者
+func (s *ShardingArticleDAO) UpdateById(ctx context.Context, article Article, from uint8) error {
+	return s.byId(article.ID).UpdateById(ctx, article, from)
+}
+
+func (s *ShardingArticleDAO) Sync(ctx context.Context, article Article, from uint8) (int64, error) {
+	if article.ID > 0 {
+		return s.byId(article.ID).sync(ctx, article, from, false)
+	}
+	var err error
+	article.ID, err = s.newId(ctx, article.AuthorId)
+	if err != nil {
+		return 0, err
+	}
+	return s.byAuthor(article.AuthorId).sync(ctx, article, from, true)
+}
+
+func (s *ShardingArticleDAO) SyncStatus(ctx context.Context, article Article, from uint8, to uint8) error {
+	return s.byId(article.ID).SyncStatus(ctx, article, from, to)
+}
+
+func (s *ShardingArticleDAO) UpdateStatus(ctx context.Context, article Article, from uint8, to uint8) error {
+	return s.byId(article.ID).UpdateStatus(ctx, article, from, to)
+}
+
+func (s *ShardingArticleDAO) GetArticlesByAuthor(ctx context.Context, uid int64, cursor Cursor, limit int) ([]Article, error) {
+	return s.byAuthor(uid).GetArticlesByAuthor(ctx, uid, cursor, limit)
+}
+
+func (s *ShardingArticleDAO) GetArticleById(ctx context.Context, id int64) (Article, error) {
+	return s.byId(id).GetArticleById(ctx, id)
+}
+
+func (s *ShardingArticleDAO) GetPubArticleById(ctx context.Context, id int64) (PublishedArticle, error) {
+	return s.byId(id).GetPubArticleById(ctx, id)
+}
+
+// GetPubList 每个库都按游标取 limit 条，合并后再取前 limit 条
+func (s *ShardingArticleDAO) GetPubList(ctx context.Context, cursor Cursor, limit int) ([]PublishedArticle, error) {
+	res, err := s.fanOut(func(shard *GormArticleDAO) ([]PublishedArticle, error) {
+		return shard.GetPubList(ctx, cursor, limit)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return page(res, limit, 0), nil
+}
+
+func (s *ShardingArticleDAO) GetPubListByAuthor(ctx context.Context, uid int64, cursor Cursor, limit int) ([]PublishedArticle, error) {
+	return s.byAuthor(uid).GetPubListByAuthor(ctx, uid, cursor, limit)
+}
+
+func (s *ShardingArticleDAO) GetPubListByIds(ctx context.Context, ids []int64) ([]PublishedArticle, error) {
+	groups := make(map[*GormArticleDAO][]int64)
+	for _, id := range ids {
+		shard := s.byId(id)
+		groups[shard] = append(groups[shard], id)
+	}
+	var res []PublishedArticle
+	for shard, group := range groups {
+		articles, err := shard.GetPubListByIds(ctx, group)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, articles...)
+	}
+	return res, nil
+}
+
+func (s *ShardingArticleDAO) GetRevisions(ctx context.Context, artId int64, limit int, offset int) ([]ArticleRevision, error) {
+	return s.byId(artId).GetRevisions(ctx, artId, limit, offset)
+}
+
+func (s *ShardingArticleDAO) GetRevisionById(ctx context.Context, artId int64, revId int64) (ArticleRevision, error) {
+	return s.byId(artId).GetRevisionById(ctx, artId, revId)
+}
+
+// GetPubListByTag 偏移量分页需要每个库都取 offset+limit 条，翻页越深代价越大
+func (s *ShardingArticleDAO) GetPubListByTag(ctx context.Context, tag string, limit int, offset int) ([]PublishedArticle, error) {
+	res, err := s.fanOut(func(shard *GormArticleDAO) ([]PublishedArticle, error) {
+		return shard.GetPubListByTag(ctx, tag, offset+limit, 0)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return page(res, limit, offset), nil
+}
+
+func (s *ShardingArticleDAO) GetPubListByCategory(ctx context.Context, category string, limit int, offset int) ([]PublishedArticle, error) {
+	res, err := s.fanOut(func(shard *GormArticleDAO) ([]PublishedArticle, error) {
+		return shard.GetPubListByCategory(ctx, category, offset+limit, 0)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return page(res, limit, offset), nil
+}
+
+// GetTagCounts 每个库的前 N 名合并后不一定是全局的前 N 名，因此每个库都统计全部标签
+func (s *ShardingArticleDAO) GetTagCounts(ctx context.Context, limit int) ([]TagCount, error) {
+	counts := make(map[string]int64)
+	for _, shard := range s.shards {
+		res, err := shard.GetTagCounts(ctx, -1) // gorm 中 limit 为负数表示不限制
+		if err != nil {
+			return nil, err
+		}
+		for _, tc := range res {
+			counts[tc.Tag] += tc.Count
+		}
+	}
+	res := make([]TagCount, 0, len(counts))
+	for tag, count := range counts {
+		res = append(res, TagCount{Tag: tag, Count: count})
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Count != res[j].Count {
+			return res[i].Count > res[j].Count
+		}
+		return res[i].Tag < res[j].Tag
+	})
+	return page(res, limit, 0), nil
+}
+
+func (s *ShardingArticleDAO) Delete(ctx context.Context, id int64, authorId int64, from uint8) error {
+	return s.byId(id).Delete(ctx, id, authorId, from)
+}
+
+func (s *ShardingArticleDAO) GetDeletedByAuthor(ctx context.Context, uid int64, cursor Cursor, limit int) ([]Article, error) {
+	return s.byAuthor(uid).GetDeletedByAuthor(ctx, uid, cursor, limit)
+}
+
+func (s *ShardingArticleDAO) Restore(ctx context.Context, id int64, authorId int64) error {
+	return s.byId(id).Restore(ctx, id, authorId)
+}
+
+// PurgeDeleted 依次清理每个库 总数不超过 limit
+func (s *ShardingArticleDAO) PurgeDeleted(ctx context.Context, before int64, limit int) (int64, error) {
+	var total int64
+	for _, shard := range s.shards {
+		if total >= int64(limit) {
+			break
+		}
+		n, err := shard.PurgeDeleted(ctx, before, limit-int(total))
+		total += n
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
+
+// ContentReferenced 相同的正文可能出现在任意分库中 依次检查每个库
+func (s *ShardingArticleDAO) ContentReferenced(ctx context.Context, key string) (bool, error) {
+	for _, shard := range s.shards {
+		ok, err := shard.ContentReferenced(ctx, key)
+		if err != nil || ok {
+			return ok, err
+		}
+	}
+	return false, nil
+}
+
+// fanOut 并发查询所有分库 结果按 (utime, id) 倒序合并
+func (s *ShardingArticleDAO) fanOut(query func(shard *GormArticleDAO) ([]PublishedArticle, error)) ([]PublishedArticle, error) {
+	results := make([][]PublishedArticle, len(s.shards))
+	var eg errgroup.Group
+	for i, shard := range s.shards {
+		eg.Go(func() error {
+			var err error
+			results[i], err = query(shard)
+			return err
+		})
+	}
+	if err := eg.Wait(); err != nil {
+		return nil, err
+	}
+	var res []PublishedArticle
+	for _, articles := range results {
+		res = mergePublished(res, articles)
+	}
+	return res, nil
+}
+
+// mergePublished 归并两个已经按 (utime, id) 倒序排好的列表
+func mergePublished(a []PublishedArticle, b []PublishedArticle) []PublishedArticle {
+	res := make([]PublishedArticle, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		if newerThan(a[i].Utime, a[i].ID, b[j].Utime, b[j].ID) {
+			res = append(res, a[i])
+			i++
+		} else {
+			res = append(res, b[j])
+			j++
+		}
+	}
+	res = append(res, a[i:]...)
+	return append(res, b[j:]...)
+}
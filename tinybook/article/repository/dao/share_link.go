@@ -0,0 +1,87 @@
+package dao
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"time"
+)
+
+var ErrShareLinkNotFound = gorm.ErrRecordNotFound
+
+// ArticleShareLink 文章的分享链接 只有 MySQL 实现
+// 链接中的 token 由记录签名得到，不落库；撤销或过期后 token 随之失效
+type ArticleShareLink struct {
+	ID        int64 `gorm:"column:id;primaryKey;autoIncrement;not null"`
+	ArticleId int64 `gorm:"column:article_id;not null;index:idx_article_expire,priority:1"`
+	AuthorId  int64 `gorm:"column:author_id;not null"`
+	// 为 0 表示分享文章的最新内容
+	RevisionId int64 `gorm:"column:revision_id;not null;default:0"`
+	ExpireAt   int64 `gorm:"column:expire_at;not null;index:idx_article_expire,priority:2"`
+	Revoked    bool  `gorm:"column:revoked;not null;default:false"`
+	Ctime      int64 `gorm:"column:ctime;not null"`
+	Utime      int64 `gorm:"column:utime;not null"`
+}
+
+type ShareLinkDAO interface {
+	Insert(ctx context.Context, link ArticleShareLink) (int64, error)
+	GetById(ctx context.Context, id int64) (ArticleShareLink, error)
+	// GetActiveByArticle 文章未撤销且在 now 之后过期的链接 按创建时间倒序
+	GetActiveByArticle(ctx context.Context, artId int64, now int64) ([]ArticleShareLink, error)
+	CountActiveByArticle(ctx context.Context, artId int64, now int64) (int64, error)
+	// Revoke 撤销作者的一个链接 不存在或不属于该作者时返回 ErrShareLinkNotFound
+	Revoke(ctx context.Context, id int64, authorId int64) error
+}
+
+type GormShareLinkDAO struct {
+	db *gorm.DB
+}
+
+func NewGormShareLinkDAO(db *gorm.DB) ShareLinkDAO {
+	return &GormShareLinkDAO{db: db}
+}
+
+func (g *GormShareLinkDAO) Insert(ctx context.Context, link ArticleShareLink) (int64, error) {
+	now := time.Now().Unix()
+	link.Ctime, link.Utime = now, now
+	err := g.db.WithContext(ctx).Create(&link).Error
+	return link.ID, err
+}
+
+func (g *GormShareLinkDAO) GetById(ctx context.Context, id int64) (ArticleShareLink, error) {
+	var link ArticleShareLink
+	err := g.db.WithContext(ctx).Where("id = ?", id).First(&link).Error
+	return link, err
+}
+
+func (g *GormShareLinkDAO) GetActiveByArticle(ctx context.Context, artId int64, now int64) ([]ArticleShareLink, error) {
+	var links []ArticleShareLink
+	err := g.db.WithContext(ctx).
+		Where("article_id = ? AND expire_at > ? AND revoked = ?", artId, now, false).
+		Order("id DESC").
+		Find(&links).Error
+	return links, err
+}
+
+func (g *GormShareLinkDAO) CountActiveByArticle(ctx context.Context, artId int64, now int64) (int64, error) {
+	var count int64
+	err := g.db.WithContext(ctx).Model(&ArticleShareLink{}).
+		Where("article_id = ? AND expire_at > ? AND revoked = ?", artId, now, false).
+		Count(&count).Error
+	return count, err
+}
+
+func (g *GormShareLinkDAO) Revoke(ctx context.Context, id int64, authorId int64) error {
+	res := g.db.WithContext(ctx).Model(&ArticleShareLink{}).
+		Where("id = ? AND author_id = ?", id, authorId).
+		Updates(map[string]any{
+			"revoked": true,
+			"utime":   time.Now().Unix(),
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrShareLinkNotFound
+	}
+	return nil
+}
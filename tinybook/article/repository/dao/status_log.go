@@ -0,0 +1,93 @@
+package dao
+
+import (
+	"context"
+	"github.com/cockroachdb/errors"
+	"gorm.io/gorm"
+	"time"
+)
+
+// ArticleStatusLog 文章状态变化的审计日志 只有 MySQL 实现，只追加不修改
+// 文章从回收站彻底删除后日志仍然保留
+type ArticleStatusLog struct {
+	ID        int64  `gorm:"column:id;primaryKey;autoIncrement;not null"`
+	ArticleId int64  `gorm:"column:article_id;not null;index"`
+	Operator  int64  `gorm:"column:operator;not null"`
+	From      uint8  `gorm:"column:from_status;type:tinyint(1);not null"`
+	To        uint8  `gorm:"column:to_status;type:tinyint(1);not null"`
+	Action    string `gorm:"column:action;type:varchar(32);not null"`
+	Ctime     int64  `gorm:"column:ctime;not null"`
+}
+
+type StatusLogDAO interface {
+	// Transit 执行 write 并写入日志 write 返回文章ID，log.ArticleId 为 0 时表示新文章，write 执行之后才有文章ID
+	// write 需要使用传入的 ctx，同一个库上的文章修改会加入日志的事务
+	Transit(ctx context.Context, log ArticleStatusLog, write func(ctx context.Context) (int64, error)) (int64, error)
+	// GetByArticle 按时间倒序获取文章最近的状态变化
+	GetByArticle(ctx context.Context, artId int64, limit int) ([]ArticleStatusLog, error)
+}
+
+type GormStatusLogDAO struct {
+	db *gorm.DB
+}
+
+func NewGormStatusLogDAO(db *gorm.DB) StatusLogDAO {
+	return &GormStatusLogDAO{db: db}
+}
+
+// Transit 把事务放进 ctx 传给 write，文章保存在同一个 MySQL 库时，以 status = from 为条件的修改和日志在同一个事务中，
+// 要么都提交要么都回滚，并发的流转只有一个能成功，日志与状态一致
+//
+// 文章保存在 MongoDB 或者其它分库时 write 不在这个事务里，只能尽量保证：
+//   - write 失败时回滚日志；write 同样以 status = from 为条件，并发流转中失败的一方不会留下日志
+//   - write 成功但提交日志失败时，在事务外补写日志，补写也失败才返回错误，此时状态已经修改但没有日志
+//   - 进程在 write 成功之后、日志提交之前退出，同样会缺少这条日志
+//
+// 后两种情况审计日志会缺失记录，不能作为状态变化的唯一依据
+func (g *GormStatusLogDAO) Transit(ctx context.Context, log ArticleStatusLog, write func(ctx context.Context) (int64, error)) (int64, error) {
+	var id int64
+	var written bool
+	var scope *txScope
+	log.Ctime = time.Now().Unix()
+	err := g.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		scope = &txScope{db: g.db, tx: tx}
+		if log.ArticleId > 0 {
+			if err := tx.Create(&log).Error; err != nil {
+				return err
+			}
+		}
+		var err error
+		id, err = write(withTxScope(ctx, scope))
+		if err != nil {
+			return err
+		}
+		written = true
+		if log.ArticleId > 0 {
+			return nil
+		}
+		log.ArticleId = id
+		return tx.Create(&log).Error
+	})
+	if err == nil {
+		scope.committed()
+		return id, nil
+	}
+	if !written || scope.isJoined() {
+		// 文章的修改和日志一起回滚了
+		return 0, err
+	}
+	// 文章已经修改 回滚的只是日志，删除缓存等操作仍然要执行
+	scope.committed()
+	log.ID, log.ArticleId = 0, id
+	if er := g.db.WithContext(ctx).Create(&log).Error; er != nil {
+		return 0, errors.CombineErrors(err, er)
+	}
+	return id, nil
+}
+
+func (g *GormStatusLogDAO) GetByArticle(ctx context.Context, artId int64, limit int) ([]ArticleStatusLog, error) {
+	var logs []ArticleStatusLog
+	err := g.db.WithContext(ctx).Where("article_id = ?", artId).
+		Order("id DESC").Limit(limit).Find(&logs).Error
+	return logs, err
+}
@@ -0,0 +1,81 @@
+package dao_test
+
+import (
+	"context"
+	"github.com/cockroachdb/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"os"
+	"testing"
+	"tinybook/tinybook/article/repository/dao"
+)
+
+const (
+	statusUnpublished uint8 = 1
+	statusPublished   uint8 = 2
+	statusPrivate     uint8 = 3
+)
+
+// TestGormStatusLogDAOTransit 文章与日志在同一个库时，状态修改和日志一起提交或回滚
+func TestGormStatusLogDAOTransit(t *testing.T) {
+	dsn := os.Getenv("TINYBOOK_TEST_MYSQL_DSN")
+	if dsn == "" {
+		t.Skip("未设置 TINYBOOK_TEST_MYSQL_DSN")
+	}
+	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{})
+	require.NoError(t, err)
+	dao.CreateTableForArticle(db)
+	for _, table := range []string{"articles", "published_articles", "article_revisions", "article_tags",
+		"published_article_tags", "article_status_logs"} {
+		require.NoError(t, db.Exec("TRUNCATE TABLE "+table).Error)
+	}
+	ctx := context.Background()
+	artDAO := dao.NewGormArticleDAO(db)
+	logDAO := dao.NewGormStatusLogDAO(db)
+	id, err := artDAO.Insert(ctx, dao.Article{Title: "draft", ContentKey: "draft", AuthorId: 1, Status: statusUnpublished})
+	require.NoError(t, err)
+	art := dao.Article{ID: id, AuthorId: 1}
+	log := dao.ArticleStatusLog{ArticleId: id, Operator: 1, From: statusUnpublished, To: statusPrivate, Action: "withdraw"}
+
+	// 提交之后才执行 AfterCommit
+	committed := false
+	_, err = logDAO.Transit(ctx, log, func(ctx context.Context) (int64, error) {
+		dao.AfterCommit(ctx, func() { committed = true })
+		assert.False(t, committed)
+		return id, artDAO.UpdateStatus(ctx, art, statusUnpublished, statusPrivate)
+	})
+	require.NoError(t, err)
+	assert.True(t, committed)
+	assertStatusLogs(t, logDAO, id, 1)
+
+	// 状态已经变化 没有日志
+	_, err = logDAO.Transit(ctx, log, func(ctx context.Context) (int64, error) {
+		dao.AfterCommit(ctx, func() { t.Error("回滚后不应执行") })
+		return id, artDAO.UpdateStatus(ctx, art, statusUnpublished, statusPrivate)
+	})
+	assert.ErrorIs(t, err, dao.ErrStatusChanged)
+	assertStatusLogs(t, logDAO, id, 1)
+
+	// 修改状态之后失败 状态修改随日志一起回滚
+	errFail := errors.New("fail")
+	log.From, log.To = statusPrivate, statusPublished
+	_, err = logDAO.Transit(ctx, log, func(ctx context.Context) (int64, error) {
+		if err := artDAO.SyncStatus(ctx, art, statusPrivate, statusPublished); err != nil {
+			return 0, err
+		}
+		return 0, errFail
+	})
+	assert.ErrorIs(t, err, errFail)
+	got, err := artDAO.GetArticleById(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, statusPrivate, got.Status)
+	assertStatusLogs(t, logDAO, id, 1)
+}
+
+func assertStatusLogs(t *testing.T, d dao.StatusLogDAO, artId int64, want int) {
+	logs, err := d.GetByArticle(context.Background(), artId, 10)
+	require.NoError(t, err)
+	assert.Len(t, logs, want)
+}
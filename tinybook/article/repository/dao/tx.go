@@ -0,0 +1,87 @@
+package dao
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"sync"
+)
+
+type txScopeKey struct{}
+
+// txScope 放在 ctx 中的 MySQL 事务 同一个库上的 GormArticleDAO 会加入这个事务
+// 文章保存在 MongoDB 或者其它分库时加不进来，joined 保持 false
+type txScope struct {
+	db *gorm.DB // 开启事务的库 只有同一个 *gorm.DB 上的 DAO 可以加入
+	tx *gorm.DB
+
+	mu          sync.Mutex
+	joined      bool
+	done        bool
+	afterCommit []func()
+}
+
+func withTxScope(ctx context.Context, scope *txScope) context.Context {
+	return context.WithValue(ctx, txScopeKey{}, scope)
+}
+
+// join db 与开启事务的库相同并且事务还没有结束时返回事务
+func (s *txScope) join(db *gorm.DB) (*gorm.DB, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.done || s.db != db {
+		return nil, false
+	}
+	s.joined = true
+	return s.tx, true
+}
+
+func (s *txScope) isJoined() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.joined
+}
+
+// addAfterCommit 事务已经结束时直接执行 fn
+func (s *txScope) addAfterCommit(fn func()) {
+	s.mu.Lock()
+	if !s.done {
+		s.afterCommit = append(s.afterCommit, fn)
+		s.mu.Unlock()
+		return
+	}
+	s.mu.Unlock()
+	fn()
+}
+
+// committed 事务已经提交 执行提交后的操作，之后 ctx 中的 DAO 不再加入事务
+func (s *txScope) committed() {
+	s.mu.Lock()
+	s.done = true
+	fns := s.afterCommit
+	s.afterCommit = nil
+	s.mu.Unlock()
+	for _, fn := range fns {
+		fn()
+	}
+}
+
+// AfterCommit ctx 中有 StatusLogDAO.Transit 开启的事务时，在事务提交之后执行 fn，否则立即执行
+// 修改文章后删除缓存、刷新索引要等修改提交，否则并发的读请求可能把旧数据重新写进缓存
+func AfterCommit(ctx context.Context, fn func()) {
+	scope, ok := ctx.Value(txScopeKey{}).(*txScope)
+	if !ok {
+		fn()
+		return
+	}
+	scope.addAfterCommit(fn)
+}
+
+// dbFrom ctx 中有同一个库的事务时加入事务
+func (g *GormArticleDAO) dbFrom(ctx context.Context) *gorm.DB {
+	if scope, ok := ctx.Value(txScopeKey{}).(*txScope); ok {
+		if tx, joined := scope.join(g.db); joined {
+			return tx.WithContext(ctx)
+		}
+	}
+	return g.db.WithContext(ctx)
+}
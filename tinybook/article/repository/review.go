@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+	"github.com/bytedance/sonic"
+	"github.com/samber/lo"
+	"tinybook/tinybook/article/domain"
+	"tinybook/tinybook/article/repository/dao"
+)
+
+var ErrReviewNotFound = dao.ErrReviewNotFound
+
+type ReviewRepository interface {
+	// CreateReview 提交审核 同一篇文章之前等待审核的记录会被取消
+	CreateReview(ctx context.Context, review domain.ArticleReview) (int64, error)
+	GetReview(ctx context.Context, id int64) (domain.ArticleReview, error)
+	// ListPending 等待审核的队列 按提交先后排列
+	ListPending(ctx context.Context, minId int64, limit int) ([]domain.ArticleReview, error)
+	// FinishReview 结束审核 返回 false 表示已经被处理过
+	FinishReview(ctx context.Context, review domain.ArticleReview) (bool, error)
+}
+
+type reviewRepository struct {
+	dao dao.ReviewDAO
+}
+
+func NewReviewRepository(dao dao.ReviewDAO) ReviewRepository {
+	return &reviewRepository{dao: dao}
+}
+
+func (r *reviewRepository) CreateReview(ctx context.Context, review domain.ArticleReview) (int64, error) {
+	words, err := sonic.MarshalString(review.Words)
+	if err != nil {
+		return 0, err
+	}
+	return r.dao.Insert(ctx, dao.ArticleReview{
+		ArticleId: review.ArticleId,
+		AuthorId:  review.AuthorId,
+		Title:     review.Title,
+		Words:     words,
+		PublishAt: review.PublishAt,
+	})
+}
+
+func (r *reviewRepository) GetReview(ctx context.Context, id int64) (domain.ArticleReview, error) {
+	review, err := r.dao.GetById(ctx, id)
+	if err != nil {
+		return domain.ArticleReview{}, err
+	}
+	return r.toDomain(review), nil
+}
+
+func (r *reviewRepository) ListPending(ctx context.Context, minId int64, limit int) ([]domain.ArticleReview, error) {
+	reviews, err := r.dao.GetByStatus(ctx, dao.ReviewStatusPending, minId, limit)
+	if err != nil {
+		return nil, err
+	}
+	return lo.Map(reviews, func(review dao.ArticleReview, index int) domain.ArticleReview {
+		return r.toDomain(review)
+	}), nil
+}
+
+func (r *reviewRepository) FinishReview(ctx context.Context, review domain.ArticleReview) (bool, error) {
+	return r.dao.Finish(ctx, dao.ArticleReview{
+		ID:       review.ID,
+		Status:   uint8(review.Status),
+		Reason:   review.Reason,
+		Reviewer: review.Reviewer,
+	})
+}
+
+func (r *reviewRepository) toDomain(review dao.ArticleReview) domain.ArticleReview {
+	var words []string
+	// 解析失败时按没有命中词处理 不影响审核
+	_ = sonic.UnmarshalString(review.Words, &words)
+	return domain.ArticleReview{
+		ID:        review.ID,
+		ArticleId: review.ArticleId,
+		AuthorId:  review.AuthorId,
+		Title:     review.Title,
+		Words:     words,
+		PublishAt: review.PublishAt,
+		Status:    domain.ReviewStatus(review.Status),
+		Reason:    review.Reason,
+		Reviewer:  review.Reviewer,
+		Ctime:     review.Ctime,
+		Utime:     review.Utime,
+	}
+}
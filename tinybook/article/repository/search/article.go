@@ -0,0 +1,119 @@
+package search
+
+import (
+	"context"
+	"sync"
+	"tinybook/tinybook/pkg/searchx"
+)
+
+// 各字段的权重 标题命中比作者、正文命中更重要
+const (
+	titleBoost   = 3
+	authorBoost  = 2
+	contentBoost = 1
+
+	snippetLength = 120 // 正文高亮片段的字符数
+)
+
+// ArticleDoc 参与检索的线上文章
+type ArticleDoc struct {
+	ID         int64
+	AuthorId   int64
+	AuthorName string
+	Title      string
+	Content    string
+	Utime      int64
+}
+
+// ArticleHit 检索命中的文章 Title/Snippet 为高亮后的 HTML 片段
+type ArticleHit struct {
+	ArticleDoc
+	Score   float64
+	Snippet string
+}
+
+type ArticleSearcher interface {
+	Put(ctx context.Context, doc ArticleDoc) error
+	Delete(ctx context.Context, id int64) error
+	// Replace 用全量文章重建索引
+	Replace(ctx context.Context, docs []ArticleDoc) error
+	Search(ctx context.Context, query string, limit int, offset int) ([]ArticleHit, int, error)
+}
+
+// LocalArticleSearcher 基于进程内倒排索引的检索
+// 每个实例各自维护一份索引：本实例的发表/撤回实时更新，其他实例的变更依赖定时全量重建
+type LocalArticleSearcher struct {
+	mu   sync.RWMutex
+	idx  *searchx.Index
+	docs map[int64]ArticleDoc // 原文 用于高亮
+}
+
+func NewLocalArticleSearcher() ArticleSearcher {
+	return &LocalArticleSearcher{
+		idx:  searchx.NewIndex(),
+		docs: make(map[int64]ArticleDoc),
+	}
+}
+
+func (l *LocalArticleSearcher) Put(ctx context.Context, doc ArticleDoc) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.idx.Put(toDocument(doc))
+	l.docs[doc.ID] = doc
+	return nil
+}
+
+func (l *LocalArticleSearcher) Delete(ctx context.Context, id int64) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.idx.Delete(id)
+	delete(l.docs, id)
+	return nil
+}
+
+func (l *LocalArticleSearcher) Replace(ctx context.Context, docs []ArticleDoc) error {
+	// 先在锁外建好新索引再整体替换，重建期间不影响检索
+	idx := searchx.NewIndex()
+	m := make(map[int64]ArticleDoc, len(docs))
+	for _, doc := range docs {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		idx.Put(toDocument(doc))
+		m[doc.ID] = doc
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.idx, l.docs = idx, m
+	return nil
+}
+
+func (l *LocalArticleSearcher) Search(ctx context.Context, query string, limit int, offset int) ([]ArticleHit, int, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	hits, total := l.idx.Search(query, limit, offset)
+	res := make([]ArticleHit, 0, len(hits))
+	for _, hit := range hits {
+		doc := l.docs[hit.ID]
+		highlighted := doc
+		highlighted.Title = searchx.Highlight(doc.Title, query, 0)
+		highlighted.AuthorName = searchx.Highlight(doc.AuthorName, query, 0)
+		res = append(res, ArticleHit{
+			ArticleDoc: highlighted,
+			Score:      hit.Score,
+			Snippet:    searchx.Highlight(doc.Content, query, snippetLength),
+		})
+	}
+	return res, total, nil
+}
+
+func toDocument(doc ArticleDoc) searchx.Document {
+	return searchx.Document{
+		ID: doc.ID,
+		Fields: []searchx.Field{
+			{Name: "title", Text: doc.Title, Boost: titleBoost},
+			{Name: "author", Text: doc.AuthorName, Boost: authorBoost},
+			{Name: "content", Text: doc.Content, Boost: contentBoost},
+		},
+	}
+}
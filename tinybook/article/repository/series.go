@@ -0,0 +1,198 @@
+package repository
+
+import (
+	"context"
+	"github.com/cockroachdb/errors"
+	"go.uber.org/zap"
+	"tinybook/tinybook/article/domain"
+	"tinybook/tinybook/article/repository/cache"
+	"tinybook/tinybook/article/repository/dao"
+)
+
+var (
+	ErrSeriesNotFound         = dao.ErrSeriesNotFound
+	ErrArticleInSeries        = dao.ErrArticleInSeries
+	ErrArticleNotInSeries     = dao.ErrArticleNotInSeries
+	ErrSeriesArticlesMismatch = dao.ErrSeriesArticlesMismatch
+)
+
+type SeriesRepository interface {
+	Create(ctx context.Context, series domain.Series) (int64, error)
+	Update(ctx context.Context, series domain.Series) error
+	Delete(ctx context.Context, id int64, authorId int64) error
+	// GetById 带上系列中全部文章的ID
+	GetById(ctx context.Context, id int64) (domain.Series, error)
+	// ListByAuthor 带上系列中全部文章的ID
+	ListByAuthor(ctx context.Context, authorId int64) ([]domain.Series, error)
+	CountByAuthor(ctx context.Context, authorId int64) (int64, error)
+	// GetByArticle 文章所在的系列 不属于任何系列时返回 ErrArticleNotInSeries
+	GetByArticle(ctx context.Context, artId int64) (domain.Series, error)
+	AddArticle(ctx context.Context, id int64, authorId int64, artId int64) error
+	RemoveArticle(ctx context.Context, id int64, authorId int64, artId int64) error
+	Reorder(ctx context.Context, id int64, authorId int64, artIds []int64) error
+}
+
+type CachedSeriesRepository struct {
+	dao   dao.SeriesDAO
+	cache cache.SeriesCache
+	log   *zap.Logger
+}
+
+func NewCachedSeriesRepository(dao dao.SeriesDAO, cache cache.SeriesCache, log *zap.Logger) SeriesRepository {
+	return &CachedSeriesRepository{dao: dao, cache: cache, log: log}
+}
+
+func (c *CachedSeriesRepository) Create(ctx context.Context, series domain.Series) (int64, error) {
+	return c.dao.Insert(ctx, dao.ArticleSeries{
+		AuthorId:    series.AuthorId,
+		Name:        series.Name,
+		Description: series.Description,
+	})
+}
+
+func (c *CachedSeriesRepository) Update(ctx context.Context, series domain.Series) error {
+	return c.modify(ctx, series.ID, 0, func() error {
+		return c.dao.Update(ctx, dao.ArticleSeries{
+			ID:          series.ID,
+			AuthorId:    series.AuthorId,
+			Name:        series.Name,
+			Description: series.Description,
+		})
+	})
+}
+
+func (c *CachedSeriesRepository) Delete(ctx context.Context, id int64, authorId int64) error {
+	return c.modify(ctx, id, 0, func() error {
+		return c.dao.Delete(ctx, id, authorId)
+	})
+}
+
+func (c *CachedSeriesRepository) GetById(ctx context.Context, id int64) (domain.Series, error) {
+	series, err := c.dao.GetById(ctx, id)
+	if err != nil {
+		return domain.Series{}, err
+	}
+	articles, err := c.dao.GetArticles(ctx, []int64{id})
+	if err != nil {
+		return domain.Series{}, err
+	}
+	return c.toDomain(series, articles), nil
+}
+
+func (c *CachedSeriesRepository) ListByAuthor(ctx context.Context, authorId int64) ([]domain.Series, error) {
+	list, err := c.dao.GetByAuthor(ctx, authorId)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]int64, 0, len(list))
+	for _, series := range list {
+		ids = append(ids, series.ID)
+	}
+	articles, err := c.dao.GetArticles(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	bySeries := make(map[int64][]dao.SeriesArticle, len(list))
+	for _, article := range articles {
+		bySeries[article.SeriesId] = append(bySeries[article.SeriesId], article)
+	}
+	res := make([]domain.Series, 0, len(list))
+	for _, series := range list {
+		res = append(res, c.toDomain(series, bySeries[series.ID]))
+	}
+	return res, nil
+}
+
+func (c *CachedSeriesRepository) CountByAuthor(ctx context.Context, authorId int64) (int64, error) {
+	return c.dao.CountByAuthor(ctx, authorId)
+}
+
+func (c *CachedSeriesRepository) GetByArticle(ctx context.Context, artId int64) (domain.Series, error) {
+	series, err := c.cache.GetByArticle(ctx, artId)
+	switch {
+	case err == nil:
+		if series.ID == 0 {
+			return domain.Series{}, ErrArticleNotInSeries
+		}
+		return series, nil
+	case !errors.Is(err, cache.ErrSeriesMiss):
+		c.log.Warn("get article series from cache failed", zap.Int64("article_id", artId), zap.Error(err))
+	}
+	article, err := c.dao.GetByArticle(ctx, artId)
+	switch {
+	case err == nil:
+		series, err = c.GetById(ctx, article.SeriesId)
+		if err != nil {
+			return domain.Series{}, err
+		}
+	case errors.Is(err, dao.ErrArticleNotInSeries):
+		// 不属于任何系列的文章也缓存起来 大部分文章都不在系列中
+		series = domain.Series{}
+	default:
+		return domain.Series{}, err
+	}
+	if er := c.cache.SetByArticle(ctx, artId, series); er != nil {
+		c.log.Warn("set article series to cache failed", zap.Int64("article_id", artId), zap.Error(er))
+	}
+	if series.ID == 0 {
+		return domain.Series{}, ErrArticleNotInSeries
+	}
+	return series, nil
+}
+
+func (c *CachedSeriesRepository) AddArticle(ctx context.Context, id int64, authorId int64, artId int64) error {
+	return c.modify(ctx, id, artId, func() error {
+		return c.dao.AddArticle(ctx, id, authorId, artId)
+	})
+}
+
+func (c *CachedSeriesRepository) RemoveArticle(ctx context.Context, id int64, authorId int64, artId int64) error {
+	return c.modify(ctx, id, artId, func() error {
+		return c.dao.RemoveArticle(ctx, id, authorId, artId)
+	})
+}
+
+func (c *CachedSeriesRepository) Reorder(ctx context.Context, id int64, authorId int64, artIds []int64) error {
+	return c.modify(ctx, id, 0, func() error {
+		return c.dao.Reorder(ctx, id, authorId, artIds)
+	})
+}
+
+// modify 修改系列后删除系列中全部文章的缓存 artId 为新加入或移出的文章，为 0 表示没有
+// 修改前先取出系列中的文章，删除系列或移出文章之后就查不到了
+func (c *CachedSeriesRepository) modify(ctx context.Context, id int64, artId int64, fn func() error) error {
+	articles, err := c.dao.GetArticles(ctx, []int64{id})
+	if err != nil {
+		return err
+	}
+	if err = fn(); err != nil {
+		return err
+	}
+	artIds := make([]int64, 0, len(articles)+1)
+	for _, article := range articles {
+		artIds = append(artIds, article.ArticleId)
+	}
+	if artId != 0 {
+		artIds = append(artIds, artId)
+	}
+	if er := c.cache.DelByArticles(ctx, artIds); er != nil {
+		c.log.Warn("delete article series from cache failed", zap.Int64("series_id", id), zap.Error(er))
+	}
+	return nil
+}
+
+func (c *CachedSeriesRepository) toDomain(series dao.ArticleSeries, articles []dao.SeriesArticle) domain.Series {
+	artIds := make([]int64, 0, len(articles))
+	for _, article := range articles {
+		artIds = append(artIds, article.ArticleId)
+	}
+	return domain.Series{
+		ID:          series.ID,
+		AuthorId:    series.AuthorId,
+		Name:        series.Name,
+		Description: series.Description,
+		ArticleIds:  artIds,
+		Ctime:       series.Ctime,
+		Utime:       series.Utime,
+	}
+}
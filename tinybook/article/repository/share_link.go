@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+	"github.com/samber/lo"
+	"tinybook/tinybook/article/domain"
+	"tinybook/tinybook/article/repository/dao"
+)
+
+var ErrShareLinkNotFound = dao.ErrShareLinkNotFound
+
+type ShareLinkRepository interface {
+	Create(ctx context.Context, link domain.ShareLink) (int64, error)
+	GetById(ctx context.Context, id int64) (domain.ShareLink, error)
+	// ListActive 文章未撤销且在 now 之后过期的链接
+	ListActive(ctx context.Context, artId int64, now int64) ([]domain.ShareLink, error)
+	CountActive(ctx context.Context, artId int64, now int64) (int64, error)
+	Revoke(ctx context.Context, id int64, authorId int64) error
+}
+
+type shareLinkRepository struct {
+	dao dao.ShareLinkDAO
+}
+
+func NewShareLinkRepository(dao dao.ShareLinkDAO) ShareLinkRepository {
+	return &shareLinkRepository{dao: dao}
+}
+
+func (s *shareLinkRepository) Create(ctx context.Context, link domain.ShareLink) (int64, error) {
+	return s.dao.Insert(ctx, dao.ArticleShareLink{
+		ArticleId:  link.ArticleId,
+		AuthorId:   link.AuthorId,
+		RevisionId: link.RevisionId,
+		ExpireAt:   link.ExpireAt,
+	})
+}
+
+func (s *shareLinkRepository) GetById(ctx context.Context, id int64) (domain.ShareLink, error) {
+	link, err := s.dao.GetById(ctx, id)
+	if err != nil {
+		return domain.ShareLink{}, err
+	}
+	return s.toDomain(link), nil
+}
+
+func (s *shareLinkRepository) ListActive(ctx context.Context, artId int64, now int64) ([]domain.ShareLink, error) {
+	links, err := s.dao.GetActiveByArticle(ctx, artId, now)
+	if err != nil {
+		return nil, err
+	}
+	return lo.Map(links, func(link dao.ArticleShareLink, index int) domain.ShareLink {
+		return s.toDomain(link)
+	}), nil
+}
+
+func (s *shareLinkRepository) CountActive(ctx context.Context, artId int64, now int64) (int64, error) {
+	return s.dao.CountActiveByArticle(ctx, artId, now)
+}
+
+func (s *shareLinkRepository) Revoke(ctx context.Context, id int64, authorId int64) error {
+	return s.dao.Revoke(ctx, id, authorId)
+}
+
+func (s *shareLinkRepository) toDomain(link dao.ArticleShareLink) domain.ShareLink {
+	return domain.ShareLink{
+		ID:         link.ID,
+		ArticleId:  link.ArticleId,
+		AuthorId:   link.AuthorId,
+		RevisionId: link.RevisionId,
+		ExpireAt:   link.ExpireAt,
+		Revoked:    link.Revoked,
+		Ctime:      link.Ctime,
+	}
+}
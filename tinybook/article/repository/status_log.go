@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"context"
+	"github.com/samber/lo"
+	"tinybook/tinybook/article/domain"
+	"tinybook/tinybook/article/repository/dao"
+)
+
+type StatusLogRepository interface {
+	// Transit 执行 write 并写入日志 write 返回文章ID，新文章的 log.ArticleId 为 0
+	// 文章保存在 MySQL 时两者在同一个事务中，其它存储的限制见 dao.GormStatusLogDAO.Transit
+	Transit(ctx context.Context, log domain.ArticleStatusLog, write func(ctx context.Context) (int64, error)) (int64, error)
+	GetByArticle(ctx context.Context, artId int64, limit int) ([]domain.ArticleStatusLog, error)
+}
+
+type statusLogRepository struct {
+	dao dao.StatusLogDAO
+}
+
+func NewStatusLogRepository(dao dao.StatusLogDAO) StatusLogRepository {
+	return &statusLogRepository{dao: dao}
+}
+
+func (s *statusLogRepository) Transit(ctx context.Context, log domain.ArticleStatusLog, write func(ctx context.Context) (int64, error)) (int64, error) {
+	return s.dao.Transit(ctx, s.toDao(log), write)
+}
+
+func (s *statusLogRepository) toDao(log domain.ArticleStatusLog) dao.ArticleStatusLog {
+	return dao.ArticleStatusLog{
+		ArticleId: log.ArticleId,
+		Operator:  log.Operator,
+		From:      uint8(log.From),
+		To:        uint8(log.To),
+		Action:    log.Action,
+	}
+}
+
+func (s *statusLogRepository) GetByArticle(ctx context.Context, artId int64, limit int) ([]domain.ArticleStatusLog, error) {
+	logs, err := s.dao.GetByArticle(ctx, artId, limit)
+	if err != nil {
+		return nil, err
+	}
+	return lo.Map(logs, func(log dao.ArticleStatusLog, index int) domain.ArticleStatusLog {
+		return domain.ArticleStatusLog{
+			ID:        log.ID,
+			ArticleId: log.ArticleId,
+			Operator:  log.Operator,
+			From:      domain.ArticleStatus(log.From),
+			To:        domain.ArticleStatus(log.To),
+			Action:    log.Action,
+			Ctime:     log.Ctime,
+		}
+	}), nil
+}
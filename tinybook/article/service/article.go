@@ -2,24 +2,100 @@ package service
 
 import (
 	"context"
+	"github.com/bytedance/sonic"
+	"github.com/cockroachdb/errors"
 	"github.com/samber/lo"
 	"go.uber.org/zap"
+	"io"
 	"strconv"
+	"strings"
 	"time"
 	intrv1 "tinybook/tinybook/api/proto/gen/intr/v1"
 	"tinybook/tinybook/article/domain"
 	"tinybook/tinybook/article/events/readcount"
 	"tinybook/tinybook/article/repository"
+	domain2 "tinybook/tinybook/internal/domain"
+	repository2 "tinybook/tinybook/internal/repository"
+	"tinybook/tinybook/pkg/diffx"
+	"tinybook/tinybook/pkg/sensitive"
+	"unicode/utf8"
 )
 
+var (
+	ErrNotArticleAuthor      = errors.New("无权操作该文章")
+	ErrInvalidPublishTime    = errors.New("定时发表时间必须晚于当前时间")
+	ErrArticleNotScheduled   = errors.New("文章不处于定时发表状态")
+	ErrScheduleNotCancelable = repository2.ErrJobNotWaiting
+	ErrVersionConflict       = repository.ErrVersionConflict
+	ErrTooManyTags           = errors.New("标签数量超过上限")
+	ErrInvalidTag            = errors.New("标签或分类过长")
+	ErrArticleDeleted        = repository.ErrArticleDeleted
+	ErrArticleNotFound       = repository.ErrArticleNotFound
+	ErrArticleNotInTrash     = errors.New("文章不在回收站中")
+	ErrTrashExpired          = errors.New("文章已超过回收站保留期限")
+	ErrSensitiveContent      = errors.New("文章包含违禁内容")
+	ErrRevisionTooLarge      = errors.New("版本内容过长，无法对比")
+)
+
+// SensitiveContentError 文章命中了禁止出现的敏感词，携带命中的词
+type SensitiveContentError struct {
+	Words []string
+}
+
+func (e *SensitiveContentError) Error() string {
+	return ErrSensitiveContent.Error() + ": " + strings.Join(e.Words, ", ")
+}
+
+func (e *SensitiveContentError) Is(target error) bool {
+	return target == ErrSensitiveContent
+}
+
+// purgeBatchSize 清理回收站时每批删除的文章数
+const purgeBatchSize = 100
+
+// contentGCGrace 正文写入对象存储后至少保留这么久才会被清理 覆盖从写入对象存储到写入数据库之间的时间
+const contentGCGrace = 24 * time.Hour
+
+type VersionConflictError = repository.VersionConflictError
+
 type ArticleService interface {
 	Save(ctx context.Context, article domain.Article) (int64, error)
-	Publish(ctx context.Context, article domain.Article) (int64, error)
+	// Publish 发表文章 命中需要审核的敏感词时保存为待审核，返回文章最终的状态
+	Publish(ctx context.Context, article domain.Article) (int64, domain.ArticleStatus, error)
 	Withdraw(ctx context.Context, article domain.Article) error
-	GetArticlesByAuthor(ctx context.Context, uid int64, limit int, offset int) ([]domain.ArticleVo, error)
+	// SchedulePublish 与 Publish 一样会先检查敏感词 待审核的文章审核通过后再定时发表
+	SchedulePublish(ctx context.Context, article domain.Article, publishAt time.Time) (int64, domain.ArticleStatus, error)
+	CancelSchedule(ctx context.Context, artId int64, uid int64) error
+	Reschedule(ctx context.Context, artId int64, uid int64, publishAt time.Time) error
+	GetArticlesByAuthor(ctx context.Context, uid int64, cursor domain.Cursor, limit int) (domain.ArticleListVo, error)
 	GetArticleById(ctx context.Context, id int64) (domain.ArticleVo, error)
 	GetPubArticleById(ctx context.Context, id int64, uid int64) (domain.ArticleVo, error)
-	ListPub(ctx context.Context, time time.Time, limit int, offset int) ([]domain.Article, error)
+	ListPub(ctx context.Context, cursor domain.Cursor, limit int) ([]domain.Article, error)
+	// ListPubByIds 按 ids 的顺序返回已发表文章的摘要
+	ListPubByIds(ctx context.Context, ids []int64) ([]domain.ArticleVo, error)
+	GetRevisions(ctx context.Context, artId int64, uid int64, limit int, offset int) ([]domain.ArticleRevisionVo, error)
+	DiffRevisions(ctx context.Context, artId int64, uid int64, from int64, to int64) (domain.ArticleRevisionDiffVo, error)
+	RestoreRevision(ctx context.Context, artId int64, uid int64, revId int64) (int64, error)
+	ListPubByTag(ctx context.Context, tag string, limit int, offset int) ([]domain.ArticleVo, error)
+	ListPubByCategory(ctx context.Context, category string, limit int, offset int) ([]domain.ArticleVo, error)
+	TagCloud(ctx context.Context, limit int) ([]domain.TagCount, error)
+	Search(ctx context.Context, query string, limit int, offset int) (domain.ArticleSearchVo, error)
+	RebuildSearchIndex(ctx context.Context) error
+	Delete(ctx context.Context, artId int64, uid int64) error
+	ListTrash(ctx context.Context, uid int64, cursor domain.Cursor, limit int) (domain.ArticleListVo, error)
+	Restore(ctx context.Context, artId int64, uid int64) error
+	// PurgeTrash 彻底删除超过保留期限的回收站文章，返回删除的数量
+	PurgeTrash(ctx context.Context) (int64, error)
+	// CollectContent 清理对象存储中不再被引用的正文，返回删除的数量
+	CollectContent(ctx context.Context) (int64, error)
+	// PublishScheduled 定时发表任务到期 文章已经不处于定时发表状态时返回 ErrArticleNotScheduled
+	PublishScheduled(ctx context.Context, artId int64, uid int64) error
+	// StatusLogs 作者查看文章最近的状态变化
+	StatusLogs(ctx context.Context, artId int64, uid int64) ([]domain.ArticleStatusLogVo, error)
+	// Export 把作者的全部文章(不含回收站)打包成 zip 写入 w 每篇是一个带 front matter 的 Markdown 文件
+	Export(ctx context.Context, uid int64, w io.Writer) error
+	// Import 读取 Export 格式的 zip 为每篇创建草稿 dryRun 时只校验 返回将要创建的文章
+	Import(ctx context.Context, uid int64, r io.ReaderAt, size int64, dryRun bool) (domain.ImportReport, error)
 	// 以下都是interactive service 的接口
 	GetInteractive(ctx context.Context, request *intrv1.GetInteractiveRequest) (*intrv1.GetInteractiveResponse, error)
 	Like(c context.Context, i *intrv1.LikeRequest) (*intrv1.LikeResponse, error)
@@ -27,17 +103,35 @@ type ArticleService interface {
 	Collect(ctx context.Context, i *intrv1.CollectRequest) (*intrv1.CollectResponse, error)
 	GetLikeRanks(c context.Context, i *intrv1.GetLikeRanksRequest) (*intrv1.GetLikeRanksResponse, error)
 	GetByIds(ctx context.Context, i *intrv1.GetByIdsRequest) (*intrv1.GetByIdsResponse, error)
+	Uncollect(ctx context.Context, i *intrv1.UncollectRequest) (*intrv1.UncollectResponse, error)
+	CreateFolder(ctx context.Context, i *intrv1.CreateFolderRequest) (*intrv1.CreateFolderResponse, error)
+	RenameFolder(ctx context.Context, i *intrv1.RenameFolderRequest) (*intrv1.RenameFolderResponse, error)
+	DeleteFolder(ctx context.Context, i *intrv1.DeleteFolderRequest) (*intrv1.DeleteFolderResponse, error)
+	ListFolders(ctx context.Context, i *intrv1.ListFoldersRequest) (*intrv1.ListFoldersResponse, error)
+	MoveItem(ctx context.Context, i *intrv1.MoveItemRequest) (*intrv1.MoveItemResponse, error)
+	ListFolderItems(ctx context.Context, i *intrv1.ListFolderItemsRequest) (*intrv1.ListFolderItemsResponse, error)
+	ListReadHistory(ctx context.Context, i *intrv1.ListReadHistoryRequest) (*intrv1.ListReadHistoryResponse, error)
+	DeleteReadHistory(ctx context.Context, i *intrv1.DeleteReadHistoryRequest) (*intrv1.DeleteReadHistoryResponse, error)
+	ClearReadHistory(ctx context.Context, i *intrv1.ClearReadHistoryRequest) (*intrv1.ClearReadHistoryResponse, error)
 }
 
 type articleService struct {
-	repo     repository.ArticleRepository
-	producer readcount.ReadEventProducer
-	log      *zap.Logger
+	repo       repository.ArticleRepository
+	reviewRepo repository.ReviewRepository
+	logRepo    repository.StatusLogRepository
+	jobRepo    repository2.CronJobRepository
+	producer   readcount.ReadEventProducer
+	dict       *sensitive.Dict
+	status     statusMachine
+	log        *zap.Logger
 }
 
-func NewArticleService(repo repository.ArticleRepository, producer readcount.ReadEventProducer, log *zap.Logger) ArticleService {
+func NewArticleService(repo repository.ArticleRepository, reviewRepo repository.ReviewRepository,
+	logRepo repository.StatusLogRepository, jobRepo repository2.CronJobRepository,
+	producer readcount.ReadEventProducer, dict *sensitive.Dict, log *zap.Logger) ArticleService {
 	//logger.With(zap.String("type", "articleService"))
-	return &articleService{repo: repo, producer: producer, log: log}
+	return &articleService{repo: repo, reviewRepo: reviewRepo, logRepo: logRepo, jobRepo: jobRepo,
+		producer: producer, dict: dict, status: statusMachine{logRepo: logRepo}, log: log}
 }
 
 func (a *articleService) GetByIds(ctx context.Context, i *intrv1.GetByIdsRequest) (*intrv1.GetByIdsResponse, error) {
@@ -64,8 +158,250 @@ func (a *articleService) GetInteractive(ctx context.Context, request *intrv1.Get
 	return a.repo.GetInteractive(ctx, request)
 }
 
-func (a *articleService) ListPub(ctx context.Context, time time.Time, limit int, offset int) ([]domain.Article, error) {
-	return a.repo.ListPub(ctx, time, limit, offset)
+func (a *articleService) Uncollect(ctx context.Context, i *intrv1.UncollectRequest) (*intrv1.UncollectResponse, error) {
+	return a.repo.Uncollect(ctx, i)
+}
+
+func (a *articleService) CreateFolder(ctx context.Context, i *intrv1.CreateFolderRequest) (*intrv1.CreateFolderResponse, error) {
+	return a.repo.CreateFolder(ctx, i)
+}
+
+func (a *articleService) RenameFolder(ctx context.Context, i *intrv1.RenameFolderRequest) (*intrv1.RenameFolderResponse, error) {
+	return a.repo.RenameFolder(ctx, i)
+}
+
+func (a *articleService) DeleteFolder(ctx context.Context, i *intrv1.DeleteFolderRequest) (*intrv1.DeleteFolderResponse, error) {
+	return a.repo.DeleteFolder(ctx, i)
+}
+
+func (a *articleService) ListFolders(ctx context.Context, i *intrv1.ListFoldersRequest) (*intrv1.ListFoldersResponse, error) {
+	return a.repo.ListFolders(ctx, i)
+}
+
+func (a *articleService) MoveItem(ctx context.Context, i *intrv1.MoveItemRequest) (*intrv1.MoveItemResponse, error) {
+	return a.repo.MoveItem(ctx, i)
+}
+
+func (a *articleService) ListFolderItems(ctx context.Context, i *intrv1.ListFolderItemsRequest) (*intrv1.ListFolderItemsResponse, error) {
+	return a.repo.ListFolderItems(ctx, i)
+}
+
+func (a *articleService) ListReadHistory(ctx context.Context, i *intrv1.ListReadHistoryRequest) (*intrv1.ListReadHistoryResponse, error) {
+	return a.repo.ListReadHistory(ctx, i)
+}
+
+func (a *articleService) DeleteReadHistory(ctx context.Context, i *intrv1.DeleteReadHistoryRequest) (*intrv1.DeleteReadHistoryResponse, error) {
+	return a.repo.DeleteReadHistory(ctx, i)
+}
+
+func (a *articleService) ClearReadHistory(ctx context.Context, i *intrv1.ClearReadHistoryRequest) (*intrv1.ClearReadHistoryResponse, error) {
+	return a.repo.ClearReadHistory(ctx, i)
+}
+
+func (a *articleService) GetRevisions(ctx context.Context, artId int64, uid int64, limit int, offset int) ([]domain.ArticleRevisionVo, error) {
+	art, err := a.checkAuthor(ctx, artId, uid)
+	if err != nil {
+		return nil, err
+	}
+	revisions, err := a.repo.GetRevisions(ctx, artId, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	// 线上版本 未发表过的文章查不到，忽略错误即可
+	var pubRevId int64
+	if pub, er := a.repo.GetPubArticleById(ctx, artId); er == nil {
+		pubRevId = pub.RevisionId
+	}
+	return lo.Map(revisions, func(rev domain.ArticleRevision, index int) domain.ArticleRevisionVo {
+		return domain.ArticleRevisionVo{
+			ID:        rev.ID,
+			ArticleID: rev.ArticleID,
+			Title:     rev.Title,
+			Current:   rev.ID == art.RevisionId,
+			Published: rev.ID == pubRevId,
+			Ctime:     time.Unix(rev.Ctime, 0).Format("2006-01-02 15:04:05"),
+		}
+	}), nil
+}
+
+func (a *articleService) DiffRevisions(ctx context.Context, artId int64, uid int64, from int64, to int64) (domain.ArticleRevisionDiffVo, error) {
+	_, err := a.checkAuthor(ctx, artId, uid)
+	if err != nil {
+		return domain.ArticleRevisionDiffVo{}, err
+	}
+	fromRev, err := a.repo.GetRevisionById(ctx, artId, from)
+	if err != nil {
+		return domain.ArticleRevisionDiffVo{}, err
+	}
+	toRev, err := a.repo.GetRevisionById(ctx, artId, to)
+	if err != nil {
+		return domain.ArticleRevisionDiffVo{}, err
+	}
+	if strings.Count(fromRev.Content, "\n") >= domain.MaxDiffLines ||
+		strings.Count(toRev.Content, "\n") >= domain.MaxDiffLines {
+		return domain.ArticleRevisionDiffVo{}, ErrRevisionTooLarge
+	}
+	return domain.ArticleRevisionDiffVo{
+		ArticleID: artId,
+		From:      from,
+		To:        to,
+		Title:     a.toDiffLines(diffx.Lines(fromRev.Title, toRev.Title)),
+		Content:   a.toDiffLines(diffx.Lines(fromRev.Content, toRev.Content)),
+	}, nil
+}
+
+func (a *articleService) RestoreRevision(ctx context.Context, artId int64, uid int64, revId int64) (int64, error) {
+	art, err := a.checkAuthor(ctx, artId, uid)
+	if err != nil {
+		return 0, err
+	}
+	rev, err := a.repo.GetRevisionById(ctx, artId, revId)
+	if err != nil {
+		return 0, err
+	}
+	// 恢复即以历史版本的内容重新保存一次草稿，会产生一个新版本，历史版本本身不变
+	id, err := a.Save(ctx, domain.Article{
+		ID:      artId,
+		Title:   rev.Title,
+		Content: rev.Content,
+		Author:  domain.Author{ID: uid},
+		Version: art.Version, // 基于当前版本恢复
+		// 历史版本不记录分类和标签，沿用当前的
+		Category: art.Category,
+		Tags:     art.Tags,
+	})
+	if err != nil {
+		return 0, err
+	}
+	// 删除作者缓存，避免详情页仍展示恢复前的内容
+	if er := a.repo.DelCache(ctx, artId, repository.ArticleAuthor); er != nil {
+		a.log.Warn("delete article from cache failed", zap.Error(er))
+	}
+	return id, nil
+}
+
+// checkAuthor 校验文章是否属于该作者
+func (a *articleService) checkAuthor(ctx context.Context, artId int64, uid int64) (domain.Article, error) {
+	art, err := a.repo.GetArticleById(ctx, artId)
+	if err != nil {
+		return domain.Article{}, err
+	}
+	if art.Author.ID != uid {
+		return domain.Article{}, ErrNotArticleAuthor
+	}
+	return art, nil
+}
+
+func (a *articleService) toDiffLines(lines []diffx.Line) []domain.DiffLine {
+	return lo.Map(lines, func(line diffx.Line, index int) domain.DiffLine {
+		return domain.DiffLine{Op: line.Op.String(), Text: line.Text}
+	})
+}
+
+func (a *articleService) ListPub(ctx context.Context, cursor domain.Cursor, limit int) ([]domain.Article, error) {
+	return a.repo.ListPub(ctx, cursor, limit)
+}
+
+func (a *articleService) ListPubByIds(ctx context.Context, ids []int64) ([]domain.ArticleVo, error) {
+	articles, err := a.repo.ListPubByIds(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	return a.toPubListVo(articles), nil
+}
+
+func (a *articleService) ListPubByTag(ctx context.Context, tag string, limit int, offset int) ([]domain.ArticleVo, error) {
+	articles, err := a.repo.ListPubByTag(ctx, strings.TrimSpace(tag), limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	return a.toPubListVo(articles), nil
+}
+
+func (a *articleService) ListPubByCategory(ctx context.Context, category string, limit int, offset int) ([]domain.ArticleVo, error) {
+	articles, err := a.repo.ListPubByCategory(ctx, strings.TrimSpace(category), limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	return a.toPubListVo(articles), nil
+}
+
+func (a *articleService) TagCloud(ctx context.Context, limit int) ([]domain.TagCount, error) {
+	return a.repo.GetTagCloud(ctx, limit)
+}
+
+func (a *articleService) Search(ctx context.Context, query string, limit int, offset int) (domain.ArticleSearchVo, error) {
+	articles, total, err := a.repo.Search(ctx, query, limit, offset)
+	if err != nil {
+		return domain.ArticleSearchVo{}, err
+	}
+	return domain.ArticleSearchVo{
+		Total: total,
+		Articles: lo.Map(articles, func(art domain.Article, index int) domain.ArticleVo {
+			return domain.ArticleVo{
+				ID:         art.ID,
+				Title:      art.Title,
+				Abstract:   art.Abstract,
+				Author:     strconv.FormatInt(art.Author.ID, 10),
+				AuthorName: art.Author.Name,
+				Utime:      time.Unix(art.Utime, 0).Format("2006-01-02 15:04:05"),
+			}
+		}),
+	}, nil
+}
+
+func (a *articleService) RebuildSearchIndex(ctx context.Context) error {
+	return a.repo.RebuildSearchIndex(ctx)
+}
+
+// toPubListVo 读者列表页只展示摘要
+func (a *articleService) toPubListVo(articles []domain.Article) []domain.ArticleVo {
+	return lo.Map(articles, func(art domain.Article, index int) domain.ArticleVo {
+		return domain.ArticleVo{
+			ID:          art.ID,
+			Title:       art.Title,
+			Abstract:    art.Abstract,
+			Author:      strconv.FormatInt(art.Author.ID, 10),
+			Category:    art.Category,
+			Tags:        art.Tags,
+			WordCount:   art.WordCount,
+			ReadingTime: art.ReadingTime,
+			Ctime:       time.Unix(art.Ctime, 0).Format("2006-01-02 15:04:05"),
+			Utime:       time.Unix(art.Utime, 0).Format("2006-01-02 15:04:05"),
+		}
+	})
+}
+
+// prepare 保存前的处理：规范化标签，渲染 Markdown
+func (a *articleService) prepare(article *domain.Article) error {
+	if err := a.normalizeTags(article); err != nil {
+		return err
+	}
+	return article.Render()
+}
+
+// normalizeTags 去掉标签与分类首尾空白，标签去重去空，并校验数量与长度
+func (a *articleService) normalizeTags(article *domain.Article) error {
+	article.Category = strings.TrimSpace(article.Category)
+	if utf8.RuneCountInString(article.Category) > domain.MaxCategoryLength {
+		return ErrInvalidTag
+	}
+	tags := make([]string, 0, len(article.Tags))
+	for _, tag := range article.Tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" || lo.Contains(tags, tag) {
+			continue
+		}
+		if utf8.RuneCountInString(tag) > domain.MaxTagLength {
+			return ErrInvalidTag
+		}
+		tags = append(tags, tag)
+	}
+	if len(tags) > domain.MaxArticleTags {
+		return ErrTooManyTags
+	}
+	article.Tags = tags
+	return nil
 }
 
 func (a *articleService) GetPubArticleById(ctx context.Context, id int64, uid int64) (domain.ArticleVo, error) {
@@ -86,14 +422,20 @@ func (a *articleService) GetPubArticleById(ctx context.Context, id int64, uid in
 		}
 	}()
 	return domain.ArticleVo{
-		ID:         art.ID,
-		Title:      art.Title,
-		Content:    art.Content,
-		Author:     strconv.FormatInt(art.Author.ID, 10),
-		AuthorName: art.Author.Name,
-		Status:     strconv.FormatUint(uint64(art.Status), 10),
-		Ctime:      time.Unix(art.Ctime, 0).Format("2006-01-02 15:04:05"),
-		Utime:      time.Unix(art.Utime, 0).Format("2006-01-02 15:04:05"),
+		ID:          art.ID,
+		Title:       art.Title,
+		Content:     art.Content,
+		Author:      strconv.FormatInt(art.Author.ID, 10),
+		AuthorName:  art.Author.Name,
+		Status:      strconv.FormatUint(uint64(art.Status), 10),
+		Category:    art.Category,
+		Tags:        art.Tags,
+		Html:        art.Html,
+		WordCount:   art.WordCount,
+		ReadingTime: art.ReadingTime,
+		Toc:         art.Toc,
+		Ctime:       time.Unix(art.Ctime, 0).Format("2006-01-02 15:04:05"),
+		Utime:       time.Unix(art.Utime, 0).Format("2006-01-02 15:04:05"),
 	}, nil
 }
 
@@ -103,48 +445,399 @@ func (a *articleService) GetArticleById(ctx context.Context, id int64) (domain.A
 		return domain.ArticleVo{}, err
 	}
 	return domain.ArticleVo{
-		ID:      art.ID,
-		Title:   art.Title,
-		Content: art.Content,
-		Author:  strconv.FormatInt(art.Author.ID, 10),
-		Status:  strconv.FormatUint(uint64(art.Status), 10),
-		Ctime:   time.Unix(art.Ctime, 0).Format("2006-01-02 15:04:05"),
-		Utime:   time.Unix(art.Utime, 0).Format("2006-01-02 15:04:05"),
+		ID:          art.ID,
+		Title:       art.Title,
+		Content:     art.Content,
+		Author:      strconv.FormatInt(art.Author.ID, 10),
+		Status:      strconv.FormatUint(uint64(art.Status), 10),
+		RevisionId:  art.RevisionId,
+		Version:     art.Version,
+		Category:    art.Category,
+		Tags:        art.Tags,
+		Html:        art.Html,
+		WordCount:   art.WordCount,
+		ReadingTime: art.ReadingTime,
+		Toc:         art.Toc,
+		Ctime:       time.Unix(art.Ctime, 0).Format("2006-01-02 15:04:05"),
+		Utime:       time.Unix(art.Utime, 0).Format("2006-01-02 15:04:05"),
 	}, nil
 }
 
-func (a *articleService) GetArticlesByAuthor(ctx context.Context, uid int64, limit int, offset int) ([]domain.ArticleVo, error) {
-	articles, err := a.repo.GetArticlesByAuthor(ctx, uid, limit, offset)
+func (a *articleService) GetArticlesByAuthor(ctx context.Context, uid int64, cursor domain.Cursor, limit int) (domain.ArticleListVo, error) {
+	// 多取一条用于判断是否还有下一页
+	articles, err := a.repo.GetArticlesByAuthor(ctx, uid, cursor, limit+1)
 	if err != nil {
-		return nil, err
+		return domain.ArticleListVo{}, err
 	}
-	return lo.Map(articles, func(arts domain.Article, index int) domain.ArticleVo {
+	var res domain.ArticleListVo
+	if len(articles) > limit {
+		articles = articles[:limit]
+		res.NextCursor = domain.CursorOf(articles[limit-1]).Encode()
+	}
+	res.Articles = lo.Map(articles, func(arts domain.Article, index int) domain.ArticleVo {
 		return domain.ArticleVo{
 			ID:    arts.ID,
 			Title: arts.Title,
 			//Content:  arts.Content,
-			Abstract: arts.Abstract,
-			Author:   strconv.FormatInt(arts.Author.ID, 10),
-			Status:   strconv.FormatUint(uint64(arts.Status), 10),
-			Ctime:    time.Unix(arts.Ctime, 0).Format("2006-01-02 15:04:05"),
-			Utime:    time.Unix(arts.Utime, 0).Format("2006-01-02 15:04:05"),
+			Abstract:    arts.Abstract,
+			Author:      strconv.FormatInt(arts.Author.ID, 10),
+			Status:      strconv.FormatUint(uint64(arts.Status), 10),
+			Category:    arts.Category,
+			Tags:        arts.Tags,
+			WordCount:   arts.WordCount,
+			ReadingTime: arts.ReadingTime,
+			Ctime:       time.Unix(arts.Ctime, 0).Format("2006-01-02 15:04:05"),
+			Utime:       time.Unix(arts.Utime, 0).Format("2006-01-02 15:04:05"),
 		}
-	}), nil
+	})
+	return res, nil
+}
+
+// Delete 移入回收站 在 domain.TrashRetention 内可以恢复
+func (a *articleService) Delete(ctx context.Context, artId int64, uid int64) error {
+	art, err := a.checkAuthor(ctx, artId, uid)
+	if err != nil {
+		return err
+	}
+	if art.Status == domain.ArticleStatusDeleted {
+		return ErrArticleDeleted
+	}
+	if err = a.status.check(art.Status, domain.ArticleStatusDeleted); err != nil {
+		return err
+	}
+	if err = a.leaveScheduled(ctx, art); err != nil {
+		return err
+	}
+	_, err = a.status.transit(ctx, artId, uid, art.Status, domain.ArticleStatusDeleted, domain.StatusActionDelete,
+		func(ctx context.Context) (int64, error) {
+			return artId, a.repo.Delete(ctx, art)
+		})
+	return err
+}
+
+// leaveScheduled 定时发表的文章变成其它状态时取消定时任务
+// 任务已经开始执行时无法取消，执行器发现文章不再处于定时状态会跳过
+func (a *articleService) leaveScheduled(ctx context.Context, art domain.Article) error {
+	if art.Status != domain.ArticleStatusScheduled {
+		return nil
+	}
+	sp := domain.ScheduledPublish{ArticleId: art.ID, AuthorId: art.Author.ID}
+	err := a.jobRepo.CancelJob(ctx, sp.JobName())
+	if err != nil && !errors.Is(err, ErrScheduleNotCancelable) {
+		return err
+	}
+	return nil
+}
+
+func (a *articleService) ListTrash(ctx context.Context, uid int64, cursor domain.Cursor, limit int) (domain.ArticleListVo, error) {
+	articles, err := a.repo.ListTrash(ctx, uid, cursor, limit+1)
+	if err != nil {
+		return domain.ArticleListVo{}, err
+	}
+	var res domain.ArticleListVo
+	if len(articles) > limit {
+		articles = articles[:limit]
+		res.NextCursor = domain.CursorOf(articles[limit-1]).Encode()
+	}
+	res.Articles = lo.Map(articles, func(arts domain.Article, index int) domain.ArticleVo {
+		dtime := time.Unix(arts.Dtime, 0)
+		return domain.ArticleVo{
+			ID:        arts.ID,
+			Title:     arts.Title,
+			Abstract:  arts.Abstract,
+			Author:    strconv.FormatInt(arts.Author.ID, 10),
+			Status:    strconv.FormatUint(uint64(arts.Status), 10),
+			Category:  arts.Category,
+			Tags:      arts.Tags,
+			Dtime:     dtime.Format("2006-01-02 15:04:05"),
+			PurgeTime: dtime.Add(domain.TrashRetention).Format("2006-01-02 15:04:05"),
+			Ctime:     time.Unix(arts.Ctime, 0).Format("2006-01-02 15:04:05"),
+		}
+	})
+	return res, nil
+}
+
+// Restore 从回收站恢复为未发表的草稿
+func (a *articleService) Restore(ctx context.Context, artId int64, uid int64) error {
+	art, err := a.checkAuthor(ctx, artId, uid)
+	if err != nil {
+		return err
+	}
+	if art.Status != domain.ArticleStatusDeleted {
+		return ErrArticleNotInTrash
+	}
+	if time.Since(time.Unix(art.Dtime, 0)) > domain.TrashRetention {
+		return ErrTrashExpired
+	}
+	_, err = a.status.transit(ctx, artId, uid, art.Status, domain.ArticleStatusUnpublished, domain.StatusActionRestore,
+		func(ctx context.Context) (int64, error) {
+			return artId, a.repo.Restore(ctx, art)
+		})
+	return err
+}
+
+func (a *articleService) PurgeTrash(ctx context.Context) (int64, error) {
+	before := time.Now().Add(-domain.TrashRetention)
+	var total int64
+	for {
+		n, err := a.repo.PurgeTrash(ctx, before, purgeBatchSize)
+		total += n
+		if err != nil {
+			return total, err
+		}
+		if n < purgeBatchSize || ctx.Err() != nil {
+			return total, ctx.Err()
+		}
+	}
+}
+
+func (a *articleService) CollectContent(ctx context.Context) (int64, error) {
+	return a.repo.CollectContent(ctx, time.Now().Add(-contentGCGrace))
 }
 
+// Withdraw 已发表的文章改为仅自己可见
 func (a *articleService) Withdraw(ctx context.Context, article domain.Article) error {
-	return a.repo.SyncStatus(ctx, article, domain.ArticleStatusPrivate) // 私有
+	art, err := a.checkAuthor(ctx, article.ID, article.Author.ID)
+	if err != nil {
+		return err
+	}
+	_, err = a.status.transit(ctx, art.ID, art.Author.ID, art.Status, domain.ArticleStatusPrivate, domain.StatusActionWithdraw,
+		func(ctx context.Context) (int64, error) {
+			return art.ID, a.repo.SyncStatus(ctx, art, domain.ArticleStatusPrivate)
+		})
+	return err
+}
+
+// SchedulePublish 保存草稿并创建定时发表任务，到期后由 job.ArticlePublishExecutor 发表
+func (a *articleService) SchedulePublish(ctx context.Context, article domain.Article, publishAt time.Time) (int64, domain.ArticleStatus, error) {
+	if !publishAt.After(time.Now()) {
+		return 0, domain.ArticleStatusUnknown, ErrInvalidPublishTime
+	}
+	if err := a.prepare(&article); err != nil {
+		return 0, domain.ArticleStatusUnknown, err
+	}
+	from, err := a.currentStatus(ctx, article)
+	if err != nil {
+		return 0, domain.ArticleStatusUnknown, err
+	}
+	words, err := a.moderate(article)
+	if err != nil {
+		return 0, domain.ArticleStatusUnknown, err
+	}
+	if len(words) > 0 {
+		id, err := a.submitReview(ctx, article, from, words, publishAt.Unix())
+		return id, domain.ArticleStatusPendingReview, err
+	}
+	article.Status = domain.ArticleStatusScheduled // 定时发表中
+	id, err := a.status.transit(ctx, article.ID, article.Author.ID, from, article.Status, domain.StatusActionSchedule,
+		func(ctx context.Context) (int64, error) {
+			id, err := a.saveDraft(ctx, article, from)
+			if err != nil {
+				return 0, err
+			}
+			return id, addPublishJob(ctx, a.jobRepo, id, article.Author.ID, publishAt)
+		})
+	if err != nil {
+		return 0, domain.ArticleStatusUnknown, err
+	}
+	return id, article.Status, nil
+}
+
+func (a *articleService) CancelSchedule(ctx context.Context, artId int64, uid int64) error {
+	art, err := a.checkAuthor(ctx, artId, uid)
+	if err != nil {
+		return err
+	}
+	if art.Status != domain.ArticleStatusScheduled {
+		return ErrArticleNotScheduled
+	}
+	sp := domain.ScheduledPublish{ArticleId: artId, AuthorId: uid}
+	err = a.jobRepo.CancelJob(ctx, sp.JobName())
+	if err != nil {
+		return err
+	}
+	// 取消后退回到未发表的草稿 任务已经开始执行时，发表和取消只有一个能成功
+	_, err = a.status.transit(ctx, artId, uid, art.Status, domain.ArticleStatusUnpublished, domain.StatusActionCancelSchedule,
+		func(ctx context.Context) (int64, error) {
+			return artId, a.repo.UpdateStatus(ctx, art, domain.ArticleStatusUnpublished)
+		})
+	return err
+}
+
+func (a *articleService) Reschedule(ctx context.Context, artId int64, uid int64, publishAt time.Time) error {
+	if !publishAt.After(time.Now()) {
+		return ErrInvalidPublishTime
+	}
+	art, err := a.checkAuthor(ctx, artId, uid)
+	if err != nil {
+		return err
+	}
+	if art.Status != domain.ArticleStatusScheduled {
+		return ErrArticleNotScheduled
+	}
+	return addPublishJob(ctx, a.jobRepo, artId, uid, publishAt)
+}
+
+// addPublishJob 新增或覆盖文章的定时发表任务
+func addPublishJob(ctx context.Context, jobRepo repository2.CronJobRepository, artId int64, uid int64, publishAt time.Time) error {
+	sp := domain.ScheduledPublish{
+		ArticleId: artId,
+		AuthorId:  uid,
+		PublishAt: publishAt.Unix(),
+	}
+	cfg, err := sonic.MarshalString(sp)
+	if err != nil {
+		return err
+	}
+	return jobRepo.AddJob(ctx, domain2.Job{
+		Name:        sp.JobName(),
+		Executor:    domain.ArticlePublishExecutor,
+		Cfg:         cfg,
+		NextRunTime: publishAt,
+	})
 }
 
-func (a *articleService) Publish(ctx context.Context, article domain.Article) (int64, error) {
+func (a *articleService) Publish(ctx context.Context, article domain.Article) (int64, domain.ArticleStatus, error) {
+	if err := a.prepare(&article); err != nil {
+		return 0, domain.ArticleStatusUnknown, err
+	}
+	from, err := a.currentStatus(ctx, article)
+	if err != nil {
+		return 0, domain.ArticleStatusUnknown, err
+	}
+	words, err := a.moderate(article)
+	if err != nil {
+		return 0, domain.ArticleStatusUnknown, err
+	}
+	if len(words) > 0 {
+		id, err := a.submitReview(ctx, article, from, words, 0)
+		return id, domain.ArticleStatusPendingReview, err
+	}
 	article.Status = domain.ArticleStatusPublished // 已发布
-	return a.repo.Sync(ctx, article)
+	id, err := a.transit(ctx, article, from, domain.StatusActionPublish, func(ctx context.Context) (int64, error) {
+		return a.repo.Sync(ctx, article, from)
+	})
+	return id, article.Status, err
 }
 
-func (a *articleService) Save(ctx context.Context, article domain.Article) (int64, error) {
-	article.Status = domain.ArticleStatusUnpublished // 未发布
+func (a *articleService) PublishScheduled(ctx context.Context, artId int64, uid int64) error {
+	art, err := a.checkAuthor(ctx, artId, uid)
+	if err != nil {
+		return err
+	}
+	if art.Status != domain.ArticleStatusScheduled {
+		return ErrArticleNotScheduled
+	}
+	published := art
+	published.Status = domain.ArticleStatusPublished
+	_, err = a.status.transit(ctx, artId, domain.SystemOperator, art.Status, published.Status, domain.StatusActionScheduledPublish,
+		func(ctx context.Context) (int64, error) {
+			return a.repo.Sync(ctx, published, art.Status)
+		})
+	return err
+}
+
+func (a *articleService) StatusLogs(ctx context.Context, artId int64, uid int64) ([]domain.ArticleStatusLogVo, error) {
+	if _, err := a.checkAuthor(ctx, artId, uid); err != nil {
+		return nil, err
+	}
+	logs, err := a.logRepo.GetByArticle(ctx, artId, maxStatusLogs)
+	if err != nil {
+		return nil, err
+	}
+	return lo.Map(logs, func(log domain.ArticleStatusLog, index int) domain.ArticleStatusLogVo {
+		return domain.ArticleStatusLogVo{
+			Id:       log.ID,
+			Operator: log.Operator,
+			From:     log.From.String(),
+			To:       log.To.String(),
+			Action:   log.Action,
+			Ctime:    time.Unix(log.Ctime, 0).Format("2006-01-02 15:04:05"),
+		}
+	}), nil
+}
+
+// currentStatus 修改文章前获取文章当前的状态 新文章为 ArticleStatusUnknown，回收站中的文章不能修改
+func (a *articleService) currentStatus(ctx context.Context, article domain.Article) (domain.ArticleStatus, error) {
+	if article.ID == 0 {
+		return domain.ArticleStatusUnknown, nil
+	}
+	art, err := a.checkAuthor(ctx, article.ID, article.Author.ID)
+	if err != nil {
+		return domain.ArticleStatusUnknown, err
+	}
+	if art.Status == domain.ArticleStatusDeleted {
+		return domain.ArticleStatusUnknown, ErrArticleDeleted
+	}
+	return art.Status, nil
+}
+
+// transit 离开定时发表状态时先取消定时任务，再由 statusMachine 执行 write 并记录审计日志 article.Status 为目标状态
+func (a *articleService) transit(ctx context.Context, article domain.Article, from domain.ArticleStatus,
+	action string, write func(ctx context.Context) (int64, error)) (int64, error) {
+	if err := a.status.check(from, article.Status); err != nil {
+		return 0, err
+	}
+	if err := a.leaveScheduled(ctx, domain.Article{ID: article.ID, Author: article.Author, Status: from}); err != nil {
+		return 0, err
+	}
+	return a.status.transit(ctx, article.ID, article.Author.ID, from, article.Status, action, write)
+}
+
+// moderate 检查标题、正文、分类和标签 命中禁止的词直接拒绝，返回需要人工审核的词
+func (a *articleService) moderate(article domain.Article) ([]string, error) {
+	res := a.dict.Check(append([]string{article.Title, article.Content, article.Category}, article.Tags...)...)
+	if res.Blocked() {
+		return nil, &SensitiveContentError{Words: res.Block}
+	}
+	return res.Review, nil
+}
+
+// submitReview 保存为待审核的草稿并加入审核队列 线上的旧版本在审核通过前保持不变
+func (a *articleService) submitReview(ctx context.Context, article domain.Article, from domain.ArticleStatus,
+	words []string, publishAt int64) (int64, error) {
+	article.Status = domain.ArticleStatusPendingReview
+	return a.transit(ctx, article, from, domain.StatusActionSubmitReview, func(ctx context.Context) (int64, error) {
+		id, err := a.saveDraft(ctx, article, from)
+		if err != nil {
+			return 0, err
+		}
+		_, err = a.reviewRepo.CreateReview(ctx, domain.ArticleReview{
+			ArticleId: id,
+			AuthorId:  article.Author.ID,
+			Title:     article.Title,
+			Words:     words,
+			PublishAt: publishAt,
+		})
+		return id, err
+	})
+}
+
+// saveDraft 新建或更新草稿 返回文章ID from 为修改前的状态
+func (a *articleService) saveDraft(ctx context.Context, article domain.Article, from domain.ArticleStatus) (int64, error) {
 	if article.ID > 0 {
-		return article.ID, a.repo.Update(ctx, article)
+		return article.ID, a.repo.Update(ctx, article, from)
 	}
 	return a.repo.Create(ctx, article)
 }
+
+// Save 保存草稿 已发表和仅自己可见的文章保持原状态，其它状态退回未发表的草稿
+// 定时发表和待审核的文章保存后需要重新发表
+func (a *articleService) Save(ctx context.Context, article domain.Article) (int64, error) {
+	if err := a.prepare(&article); err != nil {
+		return 0, err
+	}
+	from, err := a.currentStatus(ctx, article)
+	if err != nil {
+		return 0, err
+	}
+	switch from {
+	case domain.ArticleStatusPublished, domain.ArticleStatusPrivate:
+		article.Status = from
+	default:
+		article.Status = domain.ArticleStatusUnpublished // 未发布
+	}
+	return a.transit(ctx, article, from, domain.StatusActionSave, func(ctx context.Context) (int64, error) {
+		return a.saveDraft(ctx, article, from)
+	})
+}
@@ -0,0 +1,229 @@
+package service
+
+import (
+	"context"
+	"github.com/cockroachdb/errors"
+	"github.com/samber/lo"
+	"go.uber.org/zap"
+	"strings"
+	"time"
+	"tinybook/tinybook/article/domain"
+	"tinybook/tinybook/article/repository"
+	repository2 "tinybook/tinybook/internal/repository"
+	"unicode/utf8"
+)
+
+var (
+	ErrReviewNotFound = errors.New("审核记录不存在")
+	ErrReviewFinished = errors.New("审核已处理")
+	ErrReviewOutdated = errors.New("文章已被作者修改，审核已失效")
+	ErrInvalidReason  = errors.New("驳回理由为空或过长")
+)
+
+// ReviewNotifier 通知作者审核结果
+type ReviewNotifier interface {
+	NotifyReviewResult(ctx context.Context, review domain.ArticleReview) error
+}
+
+// ReviewNotifierFunc 函数形式的 ReviewNotifier
+type ReviewNotifierFunc func(ctx context.Context, review domain.ArticleReview) error
+
+func (f ReviewNotifierFunc) NotifyReviewResult(ctx context.Context, review domain.ArticleReview) error {
+	return f(ctx, review)
+}
+
+// ReviewService 管理员处理命中敏感词的文章
+type ReviewService interface {
+	// ListPending 等待审核的队列 cursor 为上一页返回的 NextCursor
+	ListPending(ctx context.Context, cursor int64, limit int) (domain.ArticleReviewListVo, error)
+	// Detail 审核详情 带有待审核的文章内容
+	Detail(ctx context.Context, id int64) (domain.ArticleReviewVo, error)
+	// Approve 审核通过 立即发表，定时发表的时间还没到时继续定时发表
+	Approve(ctx context.Context, id int64, reviewer int64) error
+	// Reject 驳回 文章退回草稿
+	Reject(ctx context.Context, id int64, reviewer int64, reason string) error
+}
+
+type reviewService struct {
+	repo       repository.ArticleRepository
+	reviewRepo repository.ReviewRepository
+	jobRepo    repository2.CronJobRepository
+	notifier   ReviewNotifier
+	status     statusMachine
+	log        *zap.Logger
+}
+
+func NewReviewService(repo repository.ArticleRepository, reviewRepo repository.ReviewRepository,
+	logRepo repository.StatusLogRepository, jobRepo repository2.CronJobRepository,
+	notifier ReviewNotifier, log *zap.Logger) ReviewService {
+	return &reviewService{repo: repo, reviewRepo: reviewRepo, jobRepo: jobRepo, notifier: notifier,
+		status: statusMachine{logRepo: logRepo}, log: log}
+}
+
+func (r *reviewService) ListPending(ctx context.Context, cursor int64, limit int) (domain.ArticleReviewListVo, error) {
+	// 多查一条判断是否还有下一页
+	reviews, err := r.reviewRepo.ListPending(ctx, cursor, limit+1)
+	if err != nil {
+		return domain.ArticleReviewListVo{}, err
+	}
+	var next int64
+	if len(reviews) > limit {
+		reviews = reviews[:limit]
+		next = reviews[limit-1].ID
+	}
+	return domain.ArticleReviewListVo{
+		Reviews: lo.Map(reviews, func(review domain.ArticleReview, index int) domain.ArticleReviewVo {
+			return r.toVo(review)
+		}),
+		NextCursor: next,
+	}, nil
+}
+
+func (r *reviewService) Detail(ctx context.Context, id int64) (domain.ArticleReviewVo, error) {
+	review, err := r.getReview(ctx, id)
+	if err != nil {
+		return domain.ArticleReviewVo{}, err
+	}
+	art, err := r.repo.GetArticleById(ctx, review.ArticleId)
+	if err != nil {
+		return domain.ArticleReviewVo{}, err
+	}
+	vo := r.toVo(review)
+	vo.Title = art.Title
+	vo.Content = art.Content
+	vo.Category = art.Category
+	vo.Tags = art.Tags
+	return vo, nil
+}
+
+func (r *reviewService) Approve(ctx context.Context, id int64, reviewer int64) error {
+	review, art, err := r.prepareFinish(ctx, id)
+	if err != nil {
+		return err
+	}
+	review.Status = domain.ReviewStatusApproved
+	review.Reviewer = reviewer
+	to := domain.ArticleStatusPublished
+	publishAt := time.Unix(review.PublishAt, 0)
+	if review.PublishAt > 0 && publishAt.After(time.Now()) {
+		to = domain.ArticleStatusScheduled
+	}
+	_, err = r.status.transit(ctx, art.ID, reviewer, art.Status, to, domain.StatusActionApprove, func(ctx context.Context) (int64, error) {
+		if to == domain.ArticleStatusScheduled {
+			if err := r.repo.UpdateStatus(ctx, art, to); err != nil {
+				return 0, err
+			}
+			return art.ID, addPublishJob(ctx, r.jobRepo, art.ID, art.Author.ID, publishAt)
+		}
+		published := art
+		published.Status = to
+		return r.repo.Sync(ctx, published, art.Status)
+	})
+	if err != nil {
+		return err
+	}
+	if err = r.finish(ctx, review); err != nil {
+		return err
+	}
+	r.notify(ctx, review)
+	return nil
+}
+
+func (r *reviewService) Reject(ctx context.Context, id int64, reviewer int64, reason string) error {
+	reason = strings.TrimSpace(reason)
+	if reason == "" || utf8.RuneCountInString(reason) > domain.MaxRejectReasonLength {
+		return ErrInvalidReason
+	}
+	review, art, err := r.prepareFinish(ctx, id)
+	if err != nil {
+		return err
+	}
+	review.Status = domain.ReviewStatusRejected
+	review.Reviewer = reviewer
+	review.Reason = reason
+	_, err = r.status.transit(ctx, art.ID, reviewer, art.Status, domain.ArticleStatusUnpublished, domain.StatusActionReject,
+		func(ctx context.Context) (int64, error) {
+			return art.ID, r.repo.UpdateStatus(ctx, art, domain.ArticleStatusUnpublished)
+		})
+	if err != nil {
+		return err
+	}
+	if err = r.finish(ctx, review); err != nil {
+		return err
+	}
+	r.notify(ctx, review)
+	return nil
+}
+
+// prepareFinish 检查审核是否还能处理 作者在审核期间修改、撤回或删除了文章时审核失效
+func (r *reviewService) prepareFinish(ctx context.Context, id int64) (domain.ArticleReview, domain.Article, error) {
+	review, err := r.getReview(ctx, id)
+	if err != nil {
+		return domain.ArticleReview{}, domain.Article{}, err
+	}
+	if review.Status != domain.ReviewStatusPending {
+		return domain.ArticleReview{}, domain.Article{}, ErrReviewFinished
+	}
+	art, err := r.repo.GetArticleById(ctx, review.ArticleId)
+	if err != nil {
+		return domain.ArticleReview{}, domain.Article{}, err
+	}
+	if art.Status != domain.ArticleStatusPendingReview {
+		review.Status = domain.ReviewStatusCanceled
+		if _, err = r.reviewRepo.FinishReview(ctx, review); err != nil {
+			return domain.ArticleReview{}, domain.Article{}, err
+		}
+		return domain.ArticleReview{}, domain.Article{}, ErrReviewOutdated
+	}
+	return review, art, nil
+}
+
+// finish 结束审核 多个管理员同时处理时只有一个能成功
+// 修改完文章之后才结束审核，结束审核失败时审核还是待处理，再次处理时发现文章已经不在审核中会取消这条审核
+// 反过来的话审核已经通过或驳回，文章却一直停在审核中，没有办法再处理
+func (r *reviewService) finish(ctx context.Context, review domain.ArticleReview) error {
+	ok, err := r.reviewRepo.FinishReview(ctx, review)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrReviewFinished
+	}
+	return nil
+}
+
+// notify 通知失败不影响审核结果
+func (r *reviewService) notify(ctx context.Context, review domain.ArticleReview) {
+	err := r.notifier.NotifyReviewResult(ctx, review)
+	if err != nil {
+		r.log.Error("notify review result failed", zap.Error(err),
+			zap.Int64("review_id", review.ID), zap.Int64("article_id", review.ArticleId))
+	}
+}
+
+func (r *reviewService) getReview(ctx context.Context, id int64) (domain.ArticleReview, error) {
+	review, err := r.reviewRepo.GetReview(ctx, id)
+	if errors.Is(err, repository.ErrReviewNotFound) {
+		return domain.ArticleReview{}, ErrReviewNotFound
+	}
+	return review, err
+}
+
+func (r *reviewService) toVo(review domain.ArticleReview) domain.ArticleReviewVo {
+	vo := domain.ArticleReviewVo{
+		Id:        review.ID,
+		ArticleId: review.ArticleId,
+		AuthorId:  review.AuthorId,
+		Title:     review.Title,
+		Words:     review.Words,
+		Status:    uint8(review.Status),
+		Reason:    review.Reason,
+		Reviewer:  review.Reviewer,
+		Ctime:     time.Unix(review.Ctime, 0).Format("2006-01-02 15:04:05"),
+		Utime:     time.Unix(review.Utime, 0).Format("2006-01-02 15:04:05"),
+	}
+	if review.PublishAt > 0 {
+		vo.PublishAt = time.Unix(review.PublishAt, 0).Format("2006-01-02 15:04:05")
+	}
+	return vo
+}
@@ -0,0 +1,271 @@
+package service
+
+import (
+	"context"
+	"github.com/cockroachdb/errors"
+	"github.com/samber/lo"
+	"strconv"
+	"strings"
+	"time"
+	"tinybook/tinybook/article/domain"
+	"tinybook/tinybook/article/repository"
+	"unicode/utf8"
+)
+
+var (
+	ErrSeriesNotFound            = repository.ErrSeriesNotFound
+	ErrArticleInSeries           = repository.ErrArticleInSeries
+	ErrArticleNotInSeries        = repository.ErrArticleNotInSeries
+	ErrSeriesArticlesMismatch    = repository.ErrSeriesArticlesMismatch
+	ErrInvalidSeriesName         = errors.New("系列名称不合法")
+	ErrInvalidSeriesDescription  = errors.New("系列简介过长")
+	ErrTooManySeries             = errors.New("系列数量已达上限")
+	ErrTooManyArticlesInSeries   = errors.New("系列中的文章数量已达上限")
+	ErrDeletedArticleNotAllowed  = errors.New("回收站中的文章不能加入系列")
+	ErrDuplicateArticlesInSeries = errors.New("排序的文章有重复")
+)
+
+// SeriesService 作者把多篇文章组织成系列(专栏) 读者只能看到系列中已发表的文章
+type SeriesService interface {
+	Create(ctx context.Context, series domain.Series) (int64, error)
+	// Rename 修改系列的名称和简介
+	Rename(ctx context.Context, series domain.Series) error
+	// Delete 删除系列 其中的文章不受影响
+	Delete(ctx context.Context, uid int64, id int64) error
+	// AddArticle 把作者自己的文章追加到系列末尾 一篇文章只能属于一个系列
+	AddArticle(ctx context.Context, uid int64, id int64, artId int64) error
+	RemoveArticle(ctx context.Context, uid int64, id int64, artId int64) error
+	// Reorder artIds 为系列中全部文章的新顺序
+	Reorder(ctx context.Context, uid int64, id int64, artIds []int64) error
+	// ListByAuthor 作者的全部系列及文章数 uid 为当前用户，不是作者本人时只统计已发表的文章
+	ListByAuthor(ctx context.Context, authorId int64, uid int64) ([]domain.SeriesVo, error)
+	// Detail 系列以及其中的文章 只返回已发表的文章，作者本人额外返回全部文章的ID
+	Detail(ctx context.Context, id int64, uid int64) (domain.SeriesVo, error)
+	// Nav 已发表的文章所在的系列及前后相邻的已发表文章 不属于任何系列时返回 nil
+	Nav(ctx context.Context, artId int64) (*domain.SeriesNavVo, error)
+}
+
+type seriesService struct {
+	repo    repository.SeriesRepository
+	artRepo repository.ArticleRepository
+}
+
+func NewSeriesService(repo repository.SeriesRepository, artRepo repository.ArticleRepository) SeriesService {
+	return &seriesService{repo: repo, artRepo: artRepo}
+}
+
+func (s *seriesService) Create(ctx context.Context, series domain.Series) (int64, error) {
+	series, err := s.normalize(series)
+	if err != nil {
+		return 0, err
+	}
+	count, err := s.repo.CountByAuthor(ctx, series.AuthorId)
+	if err != nil {
+		return 0, err
+	}
+	if count >= domain.MaxSeriesPerAuthor {
+		return 0, ErrTooManySeries
+	}
+	return s.repo.Create(ctx, series)
+}
+
+func (s *seriesService) Rename(ctx context.Context, series domain.Series) error {
+	series, err := s.normalize(series)
+	if err != nil {
+		return err
+	}
+	return s.repo.Update(ctx, series)
+}
+
+func (s *seriesService) Delete(ctx context.Context, uid int64, id int64) error {
+	return s.repo.Delete(ctx, id, uid)
+}
+
+func (s *seriesService) AddArticle(ctx context.Context, uid int64, id int64, artId int64) error {
+	series, err := s.authorSeries(ctx, uid, id)
+	if err != nil {
+		return err
+	}
+	if len(series.ArticleIds) >= domain.MaxArticlesPerSeries {
+		return ErrTooManyArticlesInSeries
+	}
+	art, err := s.artRepo.GetArticleById(ctx, artId)
+	if err != nil {
+		return err
+	}
+	if art.Author.ID != uid {
+		return ErrNotArticleAuthor
+	}
+	if art.Status == domain.ArticleStatusDeleted {
+		return ErrDeletedArticleNotAllowed
+	}
+	return s.repo.AddArticle(ctx, id, uid, artId)
+}
+
+// RemoveArticle 不检查文章本身，已经被彻底删除的文章也可以移出
+func (s *seriesService) RemoveArticle(ctx context.Context, uid int64, id int64, artId int64) error {
+	return s.repo.RemoveArticle(ctx, id, uid, artId)
+}
+
+func (s *seriesService) Reorder(ctx context.Context, uid int64, id int64, artIds []int64) error {
+	if len(lo.Uniq(artIds)) != len(artIds) {
+		return ErrDuplicateArticlesInSeries
+	}
+	return s.repo.Reorder(ctx, id, uid, artIds)
+}
+
+func (s *seriesService) ListByAuthor(ctx context.Context, authorId int64, uid int64) ([]domain.SeriesVo, error) {
+	list, err := s.repo.ListByAuthor(ctx, authorId)
+	if err != nil {
+		return nil, err
+	}
+	if authorId == uid {
+		return lo.Map(list, func(series domain.Series, index int) domain.SeriesVo {
+			vo := s.toVo(series)
+			vo.ArticleCount = len(series.ArticleIds)
+			return vo
+		}), nil
+	}
+	// 一次查出所有系列中已发表的文章
+	var artIds []int64
+	for _, series := range list {
+		artIds = append(artIds, series.ArticleIds...)
+	}
+	published, err := s.publishedIds(ctx, artIds)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]domain.SeriesVo, 0, len(list))
+	for _, series := range list {
+		vo := s.toVo(series)
+		vo.ArticleCount = lo.CountBy(series.ArticleIds, func(id int64) bool {
+			return published[id]
+		})
+		// 没有已发表文章的系列对读者不可见
+		if vo.ArticleCount > 0 {
+			res = append(res, vo)
+		}
+	}
+	return res, nil
+}
+
+func (s *seriesService) Detail(ctx context.Context, id int64, uid int64) (domain.SeriesVo, error) {
+	series, err := s.repo.GetById(ctx, id)
+	if err != nil {
+		return domain.SeriesVo{}, err
+	}
+	articles, err := s.artRepo.ListPubByIds(ctx, series.ArticleIds)
+	if err != nil {
+		return domain.SeriesVo{}, err
+	}
+	vo := s.toVo(series)
+	vo.ArticleCount = len(articles)
+	vo.Articles = lo.Map(articles, func(art domain.Article, index int) domain.ArticleVo {
+		return s.toArticleVo(art)
+	})
+	if series.AuthorId == uid {
+		vo.ArticleIds = series.ArticleIds
+	} else if len(articles) == 0 {
+		return domain.SeriesVo{}, ErrSeriesNotFound
+	}
+	return vo, nil
+}
+
+func (s *seriesService) Nav(ctx context.Context, artId int64) (*domain.SeriesNavVo, error) {
+	series, err := s.repo.GetByArticle(ctx, artId)
+	if errors.Is(err, ErrArticleNotInSeries) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	// 按系列中的顺序返回 其中未发表的文章已经被跳过
+	articles, err := s.artRepo.ListPubByIds(ctx, series.ArticleIds)
+	if err != nil {
+		return nil, err
+	}
+	idx := lo.IndexOf(lo.Map(articles, func(art domain.Article, index int) int64 {
+		return art.ID
+	}), artId)
+	if idx < 0 {
+		// 文章本身没有发表
+		return nil, nil
+	}
+	nav := &domain.SeriesNavVo{
+		Id:    series.ID,
+		Name:  series.Name,
+		Index: idx + 1,
+		Total: len(articles),
+	}
+	if idx > 0 {
+		prev := s.toArticleVo(articles[idx-1])
+		nav.Prev = &prev
+	}
+	if idx < len(articles)-1 {
+		next := s.toArticleVo(articles[idx+1])
+		nav.Next = &next
+	}
+	return nav, nil
+}
+
+// authorSeries 作者自己的系列 不是该作者的系列返回 ErrSeriesNotFound
+func (s *seriesService) authorSeries(ctx context.Context, uid int64, id int64) (domain.Series, error) {
+	series, err := s.repo.GetById(ctx, id)
+	if err != nil {
+		return domain.Series{}, err
+	}
+	if series.AuthorId != uid {
+		return domain.Series{}, ErrSeriesNotFound
+	}
+	return series, nil
+}
+
+// publishedIds 其中已发表的文章
+func (s *seriesService) publishedIds(ctx context.Context, artIds []int64) (map[int64]bool, error) {
+	articles, err := s.artRepo.ListPubByIds(ctx, artIds)
+	if err != nil {
+		return nil, err
+	}
+	res := make(map[int64]bool, len(articles))
+	for _, art := range articles {
+		res[art.ID] = true
+	}
+	return res, nil
+}
+
+// normalize 校验并整理系列名称和简介
+func (s *seriesService) normalize(series domain.Series) (domain.Series, error) {
+	series.Name = strings.TrimSpace(series.Name)
+	series.Description = strings.TrimSpace(series.Description)
+	if series.Name == "" || utf8.RuneCountInString(series.Name) > domain.MaxSeriesNameLength {
+		return series, ErrInvalidSeriesName
+	}
+	if utf8.RuneCountInString(series.Description) > domain.MaxSeriesDescriptionLength {
+		return series, ErrInvalidSeriesDescription
+	}
+	return series, nil
+}
+
+func (s *seriesService) toVo(series domain.Series) domain.SeriesVo {
+	return domain.SeriesVo{
+		Id:          series.ID,
+		AuthorId:    series.AuthorId,
+		Name:        series.Name,
+		Description: series.Description,
+		Ctime:       time.Unix(series.Ctime, 0).Format("2006-01-02 15:04:05"),
+		Utime:       time.Unix(series.Utime, 0).Format("2006-01-02 15:04:05"),
+	}
+}
+
+func (s *seriesService) toArticleVo(art domain.Article) domain.ArticleVo {
+	return domain.ArticleVo{
+		ID:          art.ID,
+		Title:       art.Title,
+		Abstract:    art.Abstract,
+		Author:      strconv.FormatInt(art.Author.ID, 10),
+		WordCount:   art.WordCount,
+		ReadingTime: art.ReadingTime,
+		Ctime:       time.Unix(art.Ctime, 0).Format("2006-01-02 15:04:05"),
+		Utime:       time.Unix(art.Utime, 0).Format("2006-01-02 15:04:05"),
+	}
+}
@@ -0,0 +1,240 @@
+package service
+
+import (
+	"context"
+	"github.com/cockroachdb/errors"
+	"github.com/golang-jwt/jwt/v5"
+	"go.uber.org/zap"
+	"strconv"
+	"time"
+	"tinybook/tinybook/article/domain"
+	"tinybook/tinybook/article/repository"
+	repository2 "tinybook/tinybook/internal/repository"
+	"tinybook/tinybook/pkg/sensitive"
+)
+
+var (
+	ErrInvalidShareTTL     = errors.New("分享链接的有效期不合法")
+	ErrTooManyShareLinks   = errors.New("文章有效的分享链接数量超过上限")
+	ErrInvalidShareToken   = errors.New("分享链接无效或已失效")
+	ErrShareLinkNotFound   = errors.New("分享链接不存在")
+	ErrArticleNotShareable = errors.New("回收站中的文章不能分享")
+	ErrShareNotModerated   = errors.New("文章内容未通过审核 不能分享")
+)
+
+// ShareService 作者为未公开的文章生成有时效、可撤销的分享链接 读者不需要登录即可查看
+// token 为 HS256 签名的 jwt，记录了链接ID、文章ID和版本ID；每次查看时还会检查链接是否已撤销
+// 草稿保存时不做敏感词检查 因此生成和查看时都要检查分享的内容，等待审核或命中敏感词的内容不能分享
+type ShareService interface {
+	// Create 生成分享链接 revId 为 0 时分享文章的最新内容 ttl 为 0 时使用默认有效期
+	Create(ctx context.Context, uid int64, artId int64, revId int64, ttl time.Duration) (domain.ShareLinkVo, error)
+	// List 文章当前有效的分享链接
+	List(ctx context.Context, uid int64, artId int64) ([]domain.ShareLinkVo, error)
+	Revoke(ctx context.Context, uid int64, id int64) error
+	// View 通过分享链接查看文章
+	View(ctx context.Context, token string) (domain.ArticleVo, error)
+}
+
+type shareClaims struct {
+	jwt.RegisteredClaims
+	ArticleId  int64 `json:"aid"`
+	RevisionId int64 `json:"rid,omitempty"`
+}
+
+type shareService struct {
+	repo      repository.ArticleRepository
+	shareRepo repository.ShareLinkRepository
+	userRepo  repository2.UserRepository
+	dict      *sensitive.Dict
+	key       []byte
+	log       *zap.Logger
+}
+
+func NewShareService(repo repository.ArticleRepository, shareRepo repository.ShareLinkRepository,
+	userRepo repository2.UserRepository, dict *sensitive.Dict, key []byte, log *zap.Logger) ShareService {
+	return &shareService{repo: repo, shareRepo: shareRepo, userRepo: userRepo, dict: dict, key: key, log: log}
+}
+
+func (s *shareService) Create(ctx context.Context, uid int64, artId int64, revId int64, ttl time.Duration) (domain.ShareLinkVo, error) {
+	if ttl == 0 {
+		ttl = domain.DefaultShareTTL
+	}
+	if ttl < time.Minute || ttl > domain.MaxShareTTL {
+		return domain.ShareLinkVo{}, ErrInvalidShareTTL
+	}
+	art, err := s.repo.GetArticleById(ctx, artId)
+	if err != nil {
+		return domain.ShareLinkVo{}, err
+	}
+	if art.Author.ID != uid {
+		return domain.ShareLinkVo{}, ErrNotArticleAuthor
+	}
+	if art.Status == domain.ArticleStatusDeleted {
+		return domain.ShareLinkVo{}, ErrArticleNotShareable
+	}
+	if art, err = s.sharedContent(ctx, art, revId); err != nil {
+		return domain.ShareLinkVo{}, err
+	}
+	now := time.Now()
+	count, err := s.shareRepo.CountActive(ctx, artId, now.Unix())
+	if err != nil {
+		return domain.ShareLinkVo{}, err
+	}
+	if count >= domain.MaxActiveShareLinks {
+		return domain.ShareLinkVo{}, ErrTooManyShareLinks
+	}
+	link := domain.ShareLink{
+		ArticleId:  artId,
+		AuthorId:   uid,
+		RevisionId: revId,
+		ExpireAt:   now.Add(ttl).Unix(),
+		Ctime:      now.Unix(),
+	}
+	link.ID, err = s.shareRepo.Create(ctx, link)
+	if err != nil {
+		return domain.ShareLinkVo{}, err
+	}
+	return s.toVo(link)
+}
+
+func (s *shareService) List(ctx context.Context, uid int64, artId int64) ([]domain.ShareLinkVo, error) {
+	art, err := s.repo.GetArticleById(ctx, artId)
+	if err != nil {
+		return nil, err
+	}
+	if art.Author.ID != uid {
+		return nil, ErrNotArticleAuthor
+	}
+	links, err := s.shareRepo.ListActive(ctx, artId, time.Now().Unix())
+	if err != nil {
+		return nil, err
+	}
+	res := make([]domain.ShareLinkVo, 0, len(links))
+	for _, link := range links {
+		vo, er := s.toVo(link)
+		if er != nil {
+			return nil, er
+		}
+		res = append(res, vo)
+	}
+	return res, nil
+}
+
+func (s *shareService) Revoke(ctx context.Context, uid int64, id int64) error {
+	err := s.shareRepo.Revoke(ctx, id, uid)
+	if errors.Is(err, repository.ErrShareLinkNotFound) {
+		return ErrShareLinkNotFound
+	}
+	return err
+}
+
+func (s *shareService) View(ctx context.Context, token string) (domain.ArticleVo, error) {
+	var claims shareClaims
+	_, err := jwt.ParseWithClaims(token, &claims, func(token *jwt.Token) (interface{}, error) {
+		return s.key, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithExpirationRequired())
+	if err != nil {
+		return domain.ArticleVo{}, ErrInvalidShareToken
+	}
+	id, err := strconv.ParseInt(claims.ID, 10, 64)
+	if err != nil {
+		return domain.ArticleVo{}, ErrInvalidShareToken
+	}
+	link, err := s.shareRepo.GetById(ctx, id)
+	if errors.Is(err, repository.ErrShareLinkNotFound) {
+		return domain.ArticleVo{}, ErrInvalidShareToken
+	}
+	if err != nil {
+		return domain.ArticleVo{}, err
+	}
+	// token 中的信息与记录不一致说明签名密钥泄露或记录被修改过
+	if link.Revoked || link.ExpireAt <= time.Now().Unix() ||
+		link.ArticleId != claims.ArticleId || link.RevisionId != claims.RevisionId {
+		return domain.ArticleVo{}, ErrInvalidShareToken
+	}
+	art, err := s.repo.GetArticleById(ctx, link.ArticleId)
+	if err != nil {
+		return domain.ArticleVo{}, err
+	}
+	if art.Status == domain.ArticleStatusDeleted || art.Author.ID != link.AuthorId {
+		return domain.ArticleVo{}, ErrInvalidShareToken
+	}
+	if art, err = s.sharedContent(ctx, art, link.RevisionId); err != nil {
+		return domain.ArticleVo{}, err
+	}
+	if link.RevisionId != 0 {
+		if err = art.Render(); err != nil {
+			return domain.ArticleVo{}, err
+		}
+	}
+	var authorName string
+	if user, er := s.userRepo.FindById(ctx, art.Author.ID); er == nil {
+		authorName = user.Nickname
+	} else {
+		s.log.Warn("find article author failed", zap.Int64("author_id", art.Author.ID), zap.Error(er))
+	}
+	return domain.ArticleVo{
+		ID:          art.ID,
+		Title:       art.Title,
+		Abstract:    art.Abstract,
+		Author:      strconv.FormatInt(art.Author.ID, 10),
+		AuthorName:  authorName,
+		RevisionId:  art.RevisionId,
+		Category:    art.Category,
+		Tags:        art.Tags,
+		Html:        art.Html,
+		WordCount:   art.WordCount,
+		ReadingTime: art.ReadingTime,
+		Toc:         art.Toc,
+		Ctime:       time.Unix(art.Ctime, 0).Format("2006-01-02 15:04:05"),
+		Utime:       time.Unix(art.Utime, 0).Format("2006-01-02 15:04:05"),
+	}, nil
+}
+
+// sharedContent 返回分享出去的内容 revId 不为 0 时为该版本的标题和正文
+// 词典会更新 所以每次查看都要重新检查，需要人工审核的词也不能通过分享链接绕过审核
+func (s *shareService) sharedContent(ctx context.Context, art domain.Article, revId int64) (domain.Article, error) {
+	if revId != 0 {
+		rev, err := s.repo.GetRevisionById(ctx, art.ID, revId)
+		if err != nil {
+			return domain.Article{}, err
+		}
+		art.Title, art.Content, art.RevisionId, art.Utime = rev.Title, rev.Content, rev.ID, rev.Ctime
+	} else if art.Status == domain.ArticleStatusPendingReview {
+		return domain.Article{}, ErrShareNotModerated
+	}
+	res := s.dict.Check(append([]string{art.Title, art.Content, art.Category}, art.Tags...)...)
+	if res.Blocked() || len(res.Review) > 0 {
+		return domain.Article{}, ErrShareNotModerated
+	}
+	return art, nil
+}
+
+// sign 由记录生成 token 相同的记录总是得到相同的 token，因此不需要保存
+func (s *shareService) sign(link domain.ShareLink) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, shareClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        strconv.FormatInt(link.ID, 10),
+			IssuedAt:  jwt.NewNumericDate(time.Unix(link.Ctime, 0)),
+			ExpiresAt: jwt.NewNumericDate(time.Unix(link.ExpireAt, 0)),
+		},
+		ArticleId:  link.ArticleId,
+		RevisionId: link.RevisionId,
+	})
+	return token.SignedString(s.key)
+}
+
+func (s *shareService) toVo(link domain.ShareLink) (domain.ShareLinkVo, error) {
+	token, err := s.sign(link)
+	if err != nil {
+		return domain.ShareLinkVo{}, err
+	}
+	return domain.ShareLinkVo{
+		Id:         link.ID,
+		ArticleId:  link.ArticleId,
+		RevisionId: link.RevisionId,
+		Token:      token,
+		ExpireAt:   time.Unix(link.ExpireAt, 0).Format("2006-01-02 15:04:05"),
+		Ctime:      time.Unix(link.Ctime, 0).Format("2006-01-02 15:04:05"),
+	}, nil
+}
@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"github.com/cockroachdb/errors"
+	"tinybook/tinybook/article/domain"
+	"tinybook/tinybook/article/repository"
+)
+
+// maxStatusLogs 查询状态变化时最多返回的条数
+const maxStatusLogs = 100
+
+var (
+	ErrIllegalTransition = errors.New("当前文章状态不允许该操作")
+	ErrStatusChanged     = repository.ErrStatusChanged
+)
+
+// IllegalTransitionError 不允许的状态流转 携带流转前后的状态
+type IllegalTransitionError struct {
+	From domain.ArticleStatus
+	To   domain.ArticleStatus
+}
+
+func (e *IllegalTransitionError) Error() string {
+	return ErrIllegalTransition.Error() + ": " + e.From.String() + " -> " + e.To.String()
+}
+
+func (e *IllegalTransitionError) Is(target error) bool {
+	return target == ErrIllegalTransition
+}
+
+// statusMachine 校验文章状态流转并记录审计日志 articleService 与 reviewService 共用
+type statusMachine struct {
+	logRepo repository.StatusLogRepository
+}
+
+func (m statusMachine) check(from domain.ArticleStatus, to domain.ArticleStatus) error {
+	if !from.CanTransitTo(to) {
+		return &IllegalTransitionError{From: from, To: to}
+	}
+	return nil
+}
+
+// transit 校验状态流转后执行 write 并记录审计日志，write 要使用传入的 ctx 才能加入日志的事务
+// write 修改文章时以 from 作为条件，并发修改时只有一个能成功，返回文章ID，新文章的 artId 为 0
+// 状态没变时不记录日志
+func (m statusMachine) transit(ctx context.Context, artId int64, operator int64,
+	from domain.ArticleStatus, to domain.ArticleStatus, action string, write func(ctx context.Context) (int64, error)) (int64, error) {
+	if err := m.check(from, to); err != nil {
+		return 0, err
+	}
+	if from == to {
+		return write(ctx)
+	}
+	return m.logRepo.Transit(ctx, domain.ArticleStatusLog{
+		ArticleId: artId,
+		Operator:  operator,
+		From:      from,
+		To:        to,
+		Action:    action,
+	}, write)
+}
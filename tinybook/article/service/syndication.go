@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"go.uber.org/zap"
+	"strings"
+	"time"
+	"tinybook/tinybook/article/domain"
+	"tinybook/tinybook/article/repository"
+	"tinybook/tinybook/pkg/feedx"
+)
+
+// SyndicationConfig 订阅源中的链接都以 SiteURL 开头
+type SyndicationConfig struct {
+	SiteURL     string
+	Title       string
+	Description string
+}
+
+// SyndicationService 作者与全站的 RSS/Atom 订阅源 渲染结果缓存在 redis 中，文章上线、下线时失效
+type SyndicationService interface {
+	// AuthorFeed 作者最近发表的文章
+	AuthorFeed(ctx context.Context, uid int64, format feedx.Format) (domain.SyndicationDocument, error)
+	// SiteFeed 全站最近发表的文章 与 ListPub 的第一页一致
+	SiteFeed(ctx context.Context, format feedx.Format) (domain.SyndicationDocument, error)
+}
+
+type syndicationService struct {
+	repo repository.ArticleRepository
+	cfg  SyndicationConfig
+	log  *zap.Logger
+}
+
+func NewSyndicationService(repo repository.ArticleRepository, cfg SyndicationConfig, log *zap.Logger) SyndicationService {
+	cfg.SiteURL = strings.TrimSuffix(cfg.SiteURL, "/")
+	return &syndicationService{repo: repo, cfg: cfg, log: log}
+}
+
+func (s *syndicationService) AuthorFeed(ctx context.Context, uid int64, format feedx.Format) (domain.SyndicationDocument, error) {
+	return s.feed(ctx, uid, format)
+}
+
+func (s *syndicationService) SiteFeed(ctx context.Context, format feedx.Format) (domain.SyndicationDocument, error) {
+	return s.feed(ctx, 0, format)
+}
+
+func (s *syndicationService) feed(ctx context.Context, uid int64, format feedx.Format) (domain.SyndicationDocument, error) {
+	doc, err := s.repo.GetFeed(ctx, uid, format)
+	if err == nil {
+		return doc, nil
+	}
+	articles, err := s.repo.ListPubWithAuthor(ctx, uid, domain.Cursor{}, domain.MaxSyndicationItems)
+	if err != nil {
+		return domain.SyndicationDocument{}, err
+	}
+	doc, err = s.render(uid, format, articles)
+	if err != nil {
+		return domain.SyndicationDocument{}, err
+	}
+	if er := s.repo.SetFeed(ctx, uid, format, doc); er != nil {
+		s.log.Warn("set feed to cache failed", zap.Int64("author_id", uid), zap.Error(er))
+	}
+	return doc, nil
+}
+
+func (s *syndicationService) render(uid int64, format feedx.Format, articles []domain.Article) (domain.SyndicationDocument, error) {
+	feed := feedx.Feed{
+		Title:       s.cfg.Title,
+		Link:        s.cfg.SiteURL + "/",
+		SelfLink:    s.cfg.SiteURL + "/feeds/site." + feedExtension(format),
+		Description: s.cfg.Description,
+		Items:       make([]feedx.Item, 0, len(articles)),
+	}
+	if uid != 0 {
+		feed.SelfLink = fmt.Sprintf("%s/feeds/author/%d.%s", s.cfg.SiteURL, uid, feedExtension(format))
+		feed.Description = fmt.Sprintf("%s 上作者 %d 最近发表的文章", s.cfg.Title, uid)
+		if len(articles) > 0 && articles[0].Author.Name != "" {
+			feed.Title = fmt.Sprintf("%s - %s", articles[0].Author.Name, s.cfg.Title)
+			feed.Description = fmt.Sprintf("%s 上 %s 最近发表的文章", s.cfg.Title, articles[0].Author.Name)
+		}
+	}
+	var modified int64
+	for _, article := range articles {
+		modified = max(modified, article.Utime)
+		feed.Items = append(feed.Items, feedx.Item{
+			Title:      article.Title,
+			Link:       fmt.Sprintf("%s/p/%d", s.cfg.SiteURL, article.ID),
+			Author:     article.Author.Name,
+			Summary:    article.Abstract,
+			Content:    article.Html,
+			Categories: articleCategories(article),
+			Published:  time.Unix(article.Ctime, 0),
+			Updated:    time.Unix(article.Utime, 0),
+		})
+	}
+	if modified == 0 {
+		// 还没有文章时以生成时间作为更新时间 缓存失效前保持不变
+		modified = time.Now().Unix()
+	}
+	feed.Updated = time.Unix(modified, 0)
+	data, err := feedx.Render(feed, format)
+	if err != nil {
+		return domain.SyndicationDocument{}, err
+	}
+	return domain.SyndicationDocument{Data: data, ETag: feedx.ETag(data), Modified: modified}, nil
+}
+
+// articleCategories 分类和标签都作为订阅源中的 category
+func articleCategories(article domain.Article) []string {
+	res := make([]string, 0, len(article.Tags)+1)
+	if article.Category != "" {
+		res = append(res, article.Category)
+	}
+	return append(res, article.Tags...)
+}
+
+func feedExtension(format feedx.Format) string {
+	if format == feedx.FormatAtom {
+		return "atom"
+	}
+	return "xml"
+}
@@ -0,0 +1,211 @@
+package service
+
+import (
+	"archive/zip"
+	"context"
+	"github.com/cockroachdb/errors"
+	"go.uber.org/zap"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+	"tinybook/tinybook/article/domain"
+	"tinybook/tinybook/pkg/mdx"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// exportBatchSize 导出时每次从数据库读取的文章数
+	exportBatchSize = 100
+	// exportNameLength 导出的文件名中标题部分的最大字符数
+	exportNameLength = 50
+)
+
+var (
+	ErrInvalidImportFile  = errors.New("导入文件不是有效的 zip")
+	ErrTooManyImportFiles = errors.New("导入的文章数量超过上限")
+)
+
+func (a *articleService) Export(ctx context.Context, uid int64, w io.Writer) error {
+	zw := zip.NewWriter(w)
+	var cursor domain.Cursor
+	for {
+		articles, err := a.repo.ListAllByAuthor(ctx, uid, cursor, exportBatchSize)
+		if err != nil {
+			return err
+		}
+		for _, art := range articles {
+			if err = a.exportArticle(zw, art); err != nil {
+				return err
+			}
+		}
+		if len(articles) < exportBatchSize {
+			break
+		}
+		cursor = domain.CursorOf(articles[len(articles)-1])
+	}
+	return zw.Close()
+}
+
+func (a *articleService) exportArticle(zw *zip.Writer, art domain.Article) error {
+	data, err := mdx.MarshalFrontMatter(domain.ArticleFrontMatter{
+		Title:    art.Title,
+		Status:   art.Status.String(),
+		Category: art.Category,
+		Tags:     art.Tags,
+		Ctime:    time.Unix(art.Ctime, 0),
+		Utime:    time.Unix(art.Utime, 0),
+	}, art.Content)
+	if err != nil {
+		return err
+	}
+	fw, err := zw.CreateHeader(&zip.FileHeader{
+		// 带上文章ID 避免同名标题冲突
+		Name:     strconv.FormatInt(art.ID, 10) + "-" + exportName(art.Title) + ".md",
+		Method:   zip.Deflate,
+		Modified: time.Unix(art.Utime, 0),
+	})
+	if err != nil {
+		return err
+	}
+	_, err = fw.Write(data)
+	return err
+}
+
+func (a *articleService) Import(ctx context.Context, uid int64, r io.ReaderAt, size int64, dryRun bool) (domain.ImportReport, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return domain.ImportReport{}, ErrInvalidImportFile
+	}
+	files := make([]*zip.File, 0, len(zr.File))
+	for _, f := range zr.File {
+		if isImportable(f) {
+			files = append(files, f)
+		}
+	}
+	// 先检查数量 避免只导入了一部分
+	if len(files) > domain.MaxImportFiles {
+		return domain.ImportReport{}, ErrTooManyImportFiles
+	}
+	report := domain.ImportReport{
+		DryRun: dryRun,
+		Total:  len(files),
+		Items:  make([]domain.ImportItem, 0, len(files)),
+	}
+	for _, f := range files {
+		if err = ctx.Err(); err != nil {
+			return report, err
+		}
+		item := a.importFile(ctx, uid, f, dryRun)
+		if item.Error != "" {
+			report.Failed++
+		} else if !dryRun {
+			report.Created++
+		}
+		report.Items = append(report.Items, item)
+	}
+	return report, nil
+}
+
+// importFile 把一个 Markdown 文件保存为草稿 失败原因写在 ImportItem.Error 中
+func (a *articleService) importFile(ctx context.Context, uid int64, f *zip.File, dryRun bool) domain.ImportItem {
+	item := domain.ImportItem{File: f.Name}
+	art, err := a.readImportFile(f)
+	if err != nil {
+		item.Error = err.Error()
+		return item
+	}
+	art.Author = domain.Author{ID: uid}
+	item.Title = art.Title
+	if dryRun {
+		// 与 Save 做同样的校验
+		if err = a.prepare(&art); err != nil {
+			item.Error = a.importErrMsg(err, f.Name)
+		}
+		return item
+	}
+	item.Id, err = a.Save(ctx, art)
+	if err != nil {
+		item.Error = a.importErrMsg(err, f.Name)
+	}
+	return item
+}
+
+func (a *articleService) readImportFile(f *zip.File) (domain.Article, error) {
+	if f.UncompressedSize64 > domain.MaxImportFileSize {
+		return domain.Article{}, errors.New("文件过大")
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return domain.Article{}, ErrInvalidImportFile
+	}
+	defer rc.Close()
+	// 压缩包中记录的大小不可信 读取时再限制一次
+	data, err := io.ReadAll(io.LimitReader(rc, domain.MaxImportFileSize+1))
+	if err != nil {
+		return domain.Article{}, ErrInvalidImportFile
+	}
+	if len(data) > domain.MaxImportFileSize {
+		return domain.Article{}, errors.New("文件过大")
+	}
+	if !utf8.Valid(data) {
+		return domain.Article{}, errors.New("文件不是 UTF-8 编码")
+	}
+	var meta domain.ArticleFrontMatter
+	body, err := mdx.UnmarshalFrontMatter(data, &meta)
+	if err != nil {
+		return domain.Article{}, err
+	}
+	title := strings.TrimSpace(meta.Title)
+	if title == "" {
+		// 没有 front matter 的普通 Markdown 以文件名作为标题
+		title = strings.TrimSuffix(path.Base(f.Name), path.Ext(f.Name))
+	}
+	return domain.Article{
+		Title:    title,
+		Content:  body,
+		Category: meta.Category,
+		Tags:     meta.Tags,
+	}, nil
+}
+
+// importErrMsg 参数类的错误直接返回给作者 其他错误记录日志
+func (a *articleService) importErrMsg(err error, file string) string {
+	if errors.Is(err, ErrTooManyTags) || errors.Is(err, ErrInvalidTag) {
+		return err.Error()
+	}
+	a.log.Error("导入文章失败, 文件: "+file, zap.Error(err))
+	return "保存失败"
+}
+
+// isImportable 只导入 Markdown 文件 跳过目录以及 macOS 压缩时带上的隐藏文件
+func isImportable(f *zip.File) bool {
+	if f.FileInfo().IsDir() || !strings.EqualFold(path.Ext(f.Name), ".md") {
+		return false
+	}
+	for _, part := range strings.Split(f.Name, "/") {
+		if strings.HasPrefix(part, ".") || part == "__MACOSX" {
+			return false
+		}
+	}
+	return true
+}
+
+// exportName 去掉标题中不能出现在文件名里的字符
+func exportName(title string) string {
+	name := strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) || unicode.IsControl(r) || strings.ContainsRune(`/\:*?"<>|`, r) {
+			return '-'
+		}
+		return r
+	}, strings.TrimSpace(title))
+	if utf8.RuneCountInString(name) > exportNameLength {
+		name = string([]rune(name)[:exportNameLength])
+	}
+	if name == "" {
+		return "untitled"
+	}
+	return name
+}
@@ -1,26 +1,40 @@
 package web
 
 import (
+	"github.com/cockroachdb/errors"
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 	"golang.org/x/sync/errgroup"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 	intrv1 "tinybook/tinybook/api/proto/gen/intr/v1"
 	"tinybook/tinybook/article/domain"
 	"tinybook/tinybook/article/service"
+	service2 "tinybook/tinybook/comment/service"
 	"tinybook/tinybook/internal/web/jwt"
+	domain2 "tinybook/tinybook/reward/domain"
+	service3 "tinybook/tinybook/reward/service"
+	"unicode/utf8"
 )
 
 type ArticleHandler struct {
 	articleService service.ArticleService
+	commentService service2.CommentService
+	rewardService  service3.RewardService
+	seriesService  service.SeriesService
 	l              *zap.Logger
 	biz            string
 }
 
-func NewArticleHandler(artService service.ArticleService, l *zap.Logger) *ArticleHandler {
+func NewArticleHandler(artService service.ArticleService, commentService service2.CommentService,
+	rewardService service3.RewardService, seriesService service.SeriesService, l *zap.Logger) *ArticleHandler {
 	return &ArticleHandler{
 		articleService: artService,
+		commentService: commentService,
+		rewardService:  rewardService,
+		seriesService:  seriesService,
 		l:              l,
 		biz:            "article",
 	}
@@ -31,9 +45,13 @@ func (h *ArticleHandler) Edit(ctx *gin.Context) {
 		Id      int64  `json:"id"`
 		Title   string `json:"title"`
 		Content string `json:"content"`
+		// 编辑已有文章时必须携带 为获取详情时返回的版本号，升级前的文章没有版本号时为 0
+		Version  int64    `json:"version"`
+		Category string   `json:"category"`
+		Tags     []string `json:"tags"`
 	}
 	var req Req
-	if err := ctx.Bind(&req); err != nil {
+	if err := ctx.Bind(&req); err != nil || req.Version < 0 {
 		ctx.JSON(http.StatusOK, Result{
 			Code: 400,
 			Msg:  "参数错误",
@@ -42,17 +60,16 @@ func (h *ArticleHandler) Edit(ctx *gin.Context) {
 	}
 	claims := (ctx.MustGet("userClaims")).(jwt.UserClaims)
 	id, err := h.articleService.Save(ctx, domain.Article{
-		ID:      req.Id,
-		Title:   req.Title,
-		Content: req.Content,
-		Author:  domain.Author{ID: claims.Uid},
+		ID:       req.Id,
+		Title:    req.Title,
+		Content:  req.Content,
+		Author:   domain.Author{ID: claims.Uid},
+		Version:  req.Version,
+		Category: req.Category,
+		Tags:     req.Tags,
 	})
 	if err != nil {
-		ctx.JSON(http.StatusOK, Result{
-			Code: 500,
-			Msg:  "服务器错误",
-		})
-		h.l.Error("保存文章失败, 作者ID: "+strconv.FormatInt(claims.Uid, 10), zap.Error(err))
+		h.handleServiceErr(ctx, err, "保存文章失败, 作者ID: "+strconv.FormatInt(claims.Uid, 10))
 		return
 	}
 	ctx.JSON(http.StatusOK, Result{
@@ -67,9 +84,15 @@ func (h *ArticleHandler) Publish(ctx *gin.Context) {
 		Id      int64  `json:"id"`
 		Title   string `json:"title"`
 		Content string `json:"content"`
+		// 定时发表时间 秒级时间戳 为0表示立即发表
+		PublishAt int64 `json:"publishAt"`
+		// 发表已有文章时必须携带 为获取详情时返回的版本号，升级前的文章没有版本号时为 0
+		Version  int64    `json:"version"`
+		Category string   `json:"category"`
+		Tags     []string `json:"tags"`
 	}
 	var req Req
-	if err := ctx.Bind(&req); err != nil {
+	if err := ctx.Bind(&req); err != nil || req.Version < 0 {
 		ctx.JSON(http.StatusOK, Result{
 			Code: 400,
 			Msg:  "参数错误",
@@ -77,24 +100,96 @@ func (h *ArticleHandler) Publish(ctx *gin.Context) {
 		return
 	}
 	claims := (ctx.MustGet("userClaims")).(jwt.UserClaims)
-	id, err := h.articleService.Publish(ctx, domain.Article{
-		ID:      req.Id,
-		Title:   req.Title,
-		Content: req.Content,
-		Author:  domain.Author{ID: claims.Uid},
+	article := domain.Article{
+		ID:       req.Id,
+		Title:    req.Title,
+		Content:  req.Content,
+		Author:   domain.Author{ID: claims.Uid},
+		Version:  req.Version,
+		Category: req.Category,
+		Tags:     req.Tags,
+	}
+	if req.PublishAt > 0 {
+		id, status, err := h.articleService.SchedulePublish(ctx, article, time.Unix(req.PublishAt, 0))
+		if err != nil {
+			h.handleServiceErr(ctx, err, "定时发表文章失败, 作者ID: "+strconv.FormatInt(claims.Uid, 10))
+			return
+		}
+		ctx.JSON(http.StatusOK, Result{
+			Code: 200,
+			Msg:  h.publishMsg(status, "定时发表设置成功"),
+			Data: id,
+		})
+		return
+	}
+	id, status, err := h.articleService.Publish(ctx, article)
+	if err != nil {
+		h.handleServiceErr(ctx, err, "发表文章失败, 作者ID: "+strconv.FormatInt(claims.Uid, 10))
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Code: 200,
+		Msg:  h.publishMsg(status, "发表成功"),
+		Data: id,
 	})
+}
+
+// publishMsg 命中需要审核的敏感词时提示作者等待审核
+func (h *ArticleHandler) publishMsg(status domain.ArticleStatus, msg string) string {
+	if status == domain.ArticleStatusPendingReview {
+		return "文章已提交审核，审核通过后自动发表"
+	}
+	return msg
+}
+
+func (h *ArticleHandler) CancelSchedule(ctx *gin.Context) {
+	type Req struct {
+		Id int64 `json:"id"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 400,
+			Msg:  "参数错误",
+		})
+		return
+	}
+	claims := (ctx.MustGet("userClaims")).(jwt.UserClaims)
+	err := h.articleService.CancelSchedule(ctx, req.Id, claims.Uid)
 	if err != nil {
+		h.handleServiceErr(ctx, err, "取消定时发表失败, 文章ID: "+strconv.FormatInt(req.Id, 10))
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Code: 200,
+		Msg:  "取消成功",
+		Data: req.Id,
+	})
+}
+
+func (h *ArticleHandler) Reschedule(ctx *gin.Context) {
+	type Req struct {
+		Id        int64 `json:"id"`
+		PublishAt int64 `json:"publishAt"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
 		ctx.JSON(http.StatusOK, Result{
-			Code: 500,
-			Msg:  "服务器错误",
+			Code: 400,
+			Msg:  "参数错误",
 		})
-		h.l.Error("发表文章失败, 作者ID: "+strconv.FormatInt(claims.Uid, 10), zap.Error(err))
+		return
+	}
+	claims := (ctx.MustGet("userClaims")).(jwt.UserClaims)
+	err := h.articleService.Reschedule(ctx, req.Id, claims.Uid, time.Unix(req.PublishAt, 0))
+	if err != nil {
+		h.handleServiceErr(ctx, err, "修改定时发表时间失败, 文章ID: "+strconv.FormatInt(req.Id, 10))
 		return
 	}
 	ctx.JSON(http.StatusOK, Result{
 		Code: 200,
-		Msg:  "发表成功",
-		Data: id,
+		Msg:  "修改成功",
+		Data: req.Id,
 	})
 }
 
@@ -118,14 +213,9 @@ func (h *ArticleHandler) Withdraw(ctx *gin.Context) {
 		},
 	})
 	if err != nil {
-		ctx.JSON(http.StatusOK, Result{
-			Code: 500,
-			Msg:  "服务器错误",
-		})
-		h.l.Error("撤回文章失败, 作者ID: "+
+		h.handleServiceErr(ctx, err, "撤回文章失败, 作者ID: "+
 			strconv.FormatInt(claims.Uid, 10)+
-			" 文章ID: "+strconv.FormatInt(req.Id, 10),
-			zap.Error(err))
+			" 文章ID: "+strconv.FormatInt(req.Id, 10))
 		return
 	}
 	ctx.JSON(http.StatusOK, Result{
@@ -172,9 +262,23 @@ func (h *ArticleHandler) Detail(context *gin.Context) {
 	})
 }
 
+// List 作者的文章列表 游标分页，第一页不传 cursor，之后传上一页返回的 nextCursor
 func (h *ArticleHandler) List(context *gin.Context) {
-	var page Page
-	if err := context.Bind(&page); err != nil {
+	type Req struct {
+		Cursor string `json:"cursor"`
+		Limit  int    `json:"limit"`
+	}
+	var req Req
+	if err := context.Bind(&req); err != nil {
+		context.JSON(http.StatusOK, Result{
+			Code: 400,
+			Msg:  "参数错误",
+		})
+		return
+	}
+	cursor, err := domain.ParseCursor(req.Cursor)
+	page := Page{Limit: req.Limit}
+	if err != nil || !h.checkPage(&page) {
 		context.JSON(http.StatusOK, Result{
 			Code: 400,
 			Msg:  "参数错误",
@@ -182,7 +286,7 @@ func (h *ArticleHandler) List(context *gin.Context) {
 		return
 	}
 	claims := (context.MustGet("userClaims")).(jwt.UserClaims)
-	articles, err := h.articleService.GetArticlesByAuthor(context, claims.Uid, page.Limit, page.Offset)
+	articles, err := h.articleService.GetArticlesByAuthor(context, claims.Uid, cursor, page.Limit)
 	if err != nil {
 		context.JSON(http.StatusOK, Result{
 			Code: 500,
@@ -210,9 +314,11 @@ func (h *ArticleHandler) PubDetail(context *gin.Context) {
 	}
 
 	var (
-		eg          errgroup.Group
-		article     domain.ArticleVo
-		interactive *intrv1.GetInteractiveResponse
+		eg           errgroup.Group
+		article      domain.ArticleVo
+		interactive  *intrv1.GetInteractiveResponse
+		commentCount int64
+		seriesNav    *domain.SeriesNavVo
 	)
 	claims := (context.MustGet("userClaims")).(jwt.UserClaims)
 
@@ -233,7 +339,34 @@ func (h *ArticleHandler) PubDetail(context *gin.Context) {
 		return interactiveErr
 	})
 
+	eg.Go(func() error {
+		// 获取评论数失败不影响文章的展示
+		var commentErr error
+		commentCount, commentErr = h.commentService.Count(context, h.biz, id)
+		if commentErr != nil {
+			h.l.Warn("获取文章评论数失败, 文章ID: "+strconv.FormatInt(id, 10), zap.Error(commentErr))
+		}
+		return nil
+	})
+
+	eg.Go(func() error {
+		// 获取系列失败不影响文章的展示
+		var seriesErr error
+		seriesNav, seriesErr = h.seriesService.Nav(context, id)
+		if seriesErr != nil {
+			h.l.Warn("获取文章所在系列失败, 文章ID: "+strconv.FormatInt(id, 10), zap.Error(seriesErr))
+		}
+		return nil
+	})
+
 	err = eg.Wait()
+	if errors.Is(err, service.ErrArticleNotFound) {
+		context.JSON(http.StatusOK, Result{
+			Code: 404,
+			Msg:  "文章不存在",
+		})
+		return
+	}
 	if err != nil {
 		context.JSON(http.StatusOK, Result{
 			Code: 500,
@@ -257,6 +390,8 @@ func (h *ArticleHandler) PubDetail(context *gin.Context) {
 	article.CollectCount = interactive.Interactive.CollectCount
 	article.Liked = interactive.Interactive.Liked
 	article.Collected = interactive.Interactive.Collected
+	article.CommentCount = commentCount
+	article.Series = seriesNav
 
 	context.JSON(http.StatusOK, Result{
 		Code: 200,
@@ -265,6 +400,40 @@ func (h *ArticleHandler) PubDetail(context *gin.Context) {
 	})
 }
 
+// PublicDetail 未登录的读者查看线上文章 订阅源中的文章链接指向这里
+func (h *ArticleHandler) PublicDetail(context *gin.Context) {
+	id, err := strconv.ParseInt(context.Param("id"), 10, 64)
+	if err != nil {
+		context.JSON(http.StatusOK, Result{
+			Code: 400,
+			Msg:  "参数错误",
+		})
+		return
+	}
+	// 未登录的阅读也计入阅读数 uid 为 0
+	article, err := h.articleService.GetPubArticleById(context, id, 0)
+	if errors.Is(err, service.ErrArticleNotFound) {
+		context.JSON(http.StatusOK, Result{
+			Code: 404,
+			Msg:  "文章不存在",
+		})
+		return
+	}
+	if err != nil {
+		context.JSON(http.StatusOK, Result{
+			Code: 500,
+			Msg:  "服务器错误",
+		})
+		h.l.Error("未登录读者获取文章详情失败, 文章ID: "+strconv.FormatInt(id, 10), zap.Error(err))
+		return
+	}
+	context.JSON(http.StatusOK, Result{
+		Code: 200,
+		Msg:  "获取成功",
+		Data: article,
+	})
+}
+
 func (h *ArticleHandler) Like(context *gin.Context) {
 	type Req struct {
 		Id   int64 `json:"id"`
@@ -334,11 +503,7 @@ func (h *ArticleHandler) Collect(ctx *gin.Context) {
 		Uid:   claims.Uid,
 	})
 	if err != nil {
-		ctx.JSON(http.StatusOK, Result{
-			Code: 500,
-			Msg:  "服务器错误",
-		})
-		h.l.Error("收藏失败, 文章ID: "+strconv.FormatInt(req.Id, 10)+" 用户ID: "+strconv.FormatInt(claims.Uid, 10), zap.Error(err))
+		h.handleIntrErr(ctx, err, "收藏失败, 文章ID: "+strconv.FormatInt(req.Id, 10)+" 用户ID: "+strconv.FormatInt(claims.Uid, 10))
 		return
 	}
 	ctx.JSON(http.StatusOK, Result{
@@ -383,8 +548,380 @@ func (h *ArticleHandler) Rank(context *gin.Context) {
 	})
 }
 
-func (h *ArticleHandler) Reward(context *gin.Context) {
+func (h *ArticleHandler) Revisions(ctx *gin.Context) {
+	type Req struct {
+		Id     int64 `json:"id"`
+		Limit  int   `json:"limit"`
+		Offset int   `json:"offset"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 400,
+			Msg:  "参数错误",
+		})
+		return
+	}
+	claims := (ctx.MustGet("userClaims")).(jwt.UserClaims)
+	revisions, err := h.articleService.GetRevisions(ctx, req.Id, claims.Uid, req.Limit, req.Offset)
+	if err != nil {
+		h.handleServiceErr(ctx, err, "获取文章版本列表失败, 文章ID: "+strconv.FormatInt(req.Id, 10))
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Code: 200,
+		Msg:  "获取成功",
+		Data: revisions,
+	})
+}
+
+func (h *ArticleHandler) DiffRevisions(ctx *gin.Context) {
+	type Req struct {
+		Id   int64 `form:"id"`
+		From int64 `form:"from"`
+		To   int64 `form:"to"`
+	}
+	var req Req
+	if err := ctx.BindQuery(&req); err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 400,
+			Msg:  "参数错误",
+		})
+		return
+	}
+	claims := (ctx.MustGet("userClaims")).(jwt.UserClaims)
+	diff, err := h.articleService.DiffRevisions(ctx, req.Id, claims.Uid, req.From, req.To)
+	if err != nil {
+		h.handleServiceErr(ctx, err, "对比文章版本失败, 文章ID: "+strconv.FormatInt(req.Id, 10))
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Code: 200,
+		Msg:  "获取成功",
+		Data: diff,
+	})
+}
+
+func (h *ArticleHandler) RestoreRevision(ctx *gin.Context) {
+	type Req struct {
+		Id         int64 `json:"id"`
+		RevisionId int64 `json:"revisionId"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 400,
+			Msg:  "参数错误",
+		})
+		return
+	}
+	claims := (ctx.MustGet("userClaims")).(jwt.UserClaims)
+	id, err := h.articleService.RestoreRevision(ctx, req.Id, claims.Uid, req.RevisionId)
+	if err != nil {
+		h.handleServiceErr(ctx, err, "恢复文章版本失败, 文章ID: "+strconv.FormatInt(req.Id, 10)+
+			" 版本ID: "+strconv.FormatInt(req.RevisionId, 10))
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Code: 200,
+		Msg:  "恢复成功",
+		Data: id,
+	})
+}
+
+// ListByTag 按标签分页获取线上文章
+func (h *ArticleHandler) ListByTag(ctx *gin.Context) {
+	tag := ctx.Param("tag")
+	var page Page
+	if err := ctx.BindQuery(&page); err != nil || !h.checkPage(&page) {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 400,
+			Msg:  "参数错误",
+		})
+		return
+	}
+	articles, err := h.articleService.ListPubByTag(ctx, tag, page.Limit, page.Offset)
+	if err != nil {
+		h.handleServiceErr(ctx, err, "按标签获取文章列表失败, 标签: "+tag)
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Code: 200,
+		Msg:  "获取成功",
+		Data: articles,
+	})
+}
+
+// ListByCategory 按分类分页获取线上文章
+func (h *ArticleHandler) ListByCategory(ctx *gin.Context) {
+	category := ctx.Param("category")
+	var page Page
+	if err := ctx.BindQuery(&page); err != nil || !h.checkPage(&page) {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 400,
+			Msg:  "参数错误",
+		})
+		return
+	}
+	articles, err := h.articleService.ListPubByCategory(ctx, category, page.Limit, page.Offset)
+	if err != nil {
+		h.handleServiceErr(ctx, err, "按分类获取文章列表失败, 分类: "+category)
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Code: 200,
+		Msg:  "获取成功",
+		Data: articles,
+	})
+}
+
+// TagCloud 标签云 按文章数倒序
+func (h *ArticleHandler) TagCloud(ctx *gin.Context) {
+	limit, err := strconv.Atoi(ctx.DefaultQuery("limit", "50"))
+	if err != nil || limit <= 0 {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 400,
+			Msg:  "参数错误",
+		})
+		return
+	}
+	tags, err := h.articleService.TagCloud(ctx, limit)
+	if err != nil {
+		h.handleServiceErr(ctx, err, "获取标签云失败")
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Code: 200,
+		Msg:  "获取成功",
+		Data: tags,
+	})
+}
+
+// Search 全文搜索线上文章 GET /articles/search?q=关键词&offset=0&limit=10
+func (h *ArticleHandler) Search(ctx *gin.Context) {
+	q := strings.TrimSpace(ctx.Query("q"))
+	var page Page
+	if err := ctx.BindQuery(&page); err != nil || !h.checkPage(&page) ||
+		q == "" || utf8.RuneCountInString(q) > 64 {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 400,
+			Msg:  "参数错误",
+		})
+		return
+	}
+	res, err := h.articleService.Search(ctx, q, page.Limit, page.Offset)
+	if err != nil {
+		h.handleServiceErr(ctx, err, "搜索文章失败, 关键词: "+q)
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Code: 200,
+		Msg:  "获取成功",
+		Data: res,
+	})
+}
+
+// Delete 删除文章 移入回收站
+func (h *ArticleHandler) Delete(ctx *gin.Context) {
+	type Req struct {
+		Id int64 `json:"id" binding:"required"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 400,
+			Msg:  "参数错误",
+		})
+		return
+	}
+	claims := (ctx.MustGet("userClaims")).(jwt.UserClaims)
+	err := h.articleService.Delete(ctx, req.Id, claims.Uid)
+	if err != nil {
+		h.handleServiceErr(ctx, err, "删除文章失败, 文章ID: "+strconv.FormatInt(req.Id, 10))
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Code: 200,
+		Msg:  "已移入回收站",
+		Data: req.Id,
+	})
+}
+
+// Trash 回收站列表
+func (h *ArticleHandler) Trash(ctx *gin.Context) {
+	type Req struct {
+		Cursor string `json:"cursor"`
+		Limit  int    `json:"limit"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 400,
+			Msg:  "参数错误",
+		})
+		return
+	}
+	cursor, err := domain.ParseCursor(req.Cursor)
+	page := Page{Limit: req.Limit}
+	if err != nil || !h.checkPage(&page) {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 400,
+			Msg:  "参数错误",
+		})
+		return
+	}
+	claims := (ctx.MustGet("userClaims")).(jwt.UserClaims)
+	articles, err := h.articleService.ListTrash(ctx, claims.Uid, cursor, page.Limit)
+	if err != nil {
+		h.handleServiceErr(ctx, err, "获取回收站列表失败, 作者ID: "+strconv.FormatInt(claims.Uid, 10))
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Code: 200,
+		Msg:  "获取成功",
+		Data: articles,
+	})
+}
+
+// RestoreTrash 从回收站恢复为草稿
+func (h *ArticleHandler) RestoreTrash(ctx *gin.Context) {
+	type Req struct {
+		Id int64 `json:"id" binding:"required"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 400,
+			Msg:  "参数错误",
+		})
+		return
+	}
+	claims := (ctx.MustGet("userClaims")).(jwt.UserClaims)
+	err := h.articleService.Restore(ctx, req.Id, claims.Uid)
+	if err != nil {
+		h.handleServiceErr(ctx, err, "恢复文章失败, 文章ID: "+strconv.FormatInt(req.Id, 10))
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Code: 200,
+		Msg:  "恢复成功",
+		Data: req.Id,
+	})
+}
 
+// checkPage 校验分页参数 limit 默认为10，最大为100
+func (h *ArticleHandler) checkPage(page *Page) bool {
+	if page.Limit == 0 {
+		page.Limit = 10
+	}
+	return page.Offset >= 0 && page.Limit > 0 && page.Limit <= 100
+}
+
+// handleServiceErr 处理service返回的错误 业务错误直接返回给前端，其余记录日志
+func (h *ArticleHandler) handleServiceErr(ctx *gin.Context, err error, msg string) {
+	var conflict *service.VersionConflictError
+	if errors.As(err, &conflict) {
+		// 返回服务端当前版本号 由前端决定是否刷新后重试
+		ctx.JSON(http.StatusOK, Result{
+			Code: 409,
+			Msg:  "文章已在其他地方被修改，请刷新后重试",
+			Data: conflict.Current,
+		})
+		return
+	}
+	switch {
+	case errors.Is(err, service.ErrNotArticleAuthor):
+		ctx.JSON(http.StatusOK, Result{
+			Code: 401,
+			Msg:  "无权限",
+		})
+		return
+	case errors.Is(err, service.ErrInvalidPublishTime),
+		errors.Is(err, service.ErrArticleNotScheduled),
+		errors.Is(err, service.ErrScheduleNotCancelable),
+		errors.Is(err, service.ErrTooManyTags),
+		errors.Is(err, service.ErrInvalidTag),
+		errors.Is(err, service.ErrArticleDeleted),
+		errors.Is(err, service.ErrArticleNotInTrash),
+		errors.Is(err, service.ErrTrashExpired),
+		errors.Is(err, service.ErrSensitiveContent),
+		errors.Is(err, service.ErrRevisionTooLarge),
+		errors.Is(err, service.ErrIllegalTransition),
+		errors.Is(err, service.ErrStatusChanged),
+		errors.Is(err, service.ErrInvalidImportFile),
+		errors.Is(err, service.ErrTooManyImportFiles),
+		errors.Is(err, service3.ErrInvalidAmount),
+		errors.Is(err, service3.ErrRewardSelf),
+		errors.Is(err, service3.ErrBizNotFound),
+		errors.Is(err, service3.ErrPaymentUnavailable):
+		ctx.JSON(http.StatusOK, Result{
+			Code: 400,
+			Msg:  err.Error(),
+		})
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Code: 500,
+		Msg:  "服务器错误",
+	})
+	h.l.Error(msg, zap.Error(err))
+}
+
+// StatusLogs 作者查看文章的状态变化记录
+func (h *ArticleHandler) StatusLogs(ctx *gin.Context) {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 400,
+			Msg:  "参数错误",
+		})
+		return
+	}
+	claims := (ctx.MustGet("userClaims")).(jwt.UserClaims)
+	logs, err := h.articleService.StatusLogs(ctx, id, claims.Uid)
+	if err != nil {
+		h.handleServiceErr(ctx, err, "获取文章状态记录失败, 文章ID: "+strconv.FormatInt(id, 10))
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Code: 200,
+		Msg:  "获取成功",
+		Data: logs,
+	})
+}
+
+// Reward 打赏线上文章 返回支付二维码链接 金额单位为分
+func (h *ArticleHandler) Reward(ctx *gin.Context) {
+	type Req struct {
+		Id  int64 `json:"id" binding:"required"`
+		Amt int64 `json:"amt" binding:"required"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 400,
+			Msg:  "参数错误",
+		})
+		return
+	}
+	claims := (ctx.MustGet("userClaims")).(jwt.UserClaims)
+	codeURL, err := h.rewardService.PreReward(ctx, domain2.Reward{
+		Uid: claims.Uid,
+		Target: domain2.Target{
+			Biz:   h.biz,
+			BizId: req.Id,
+		},
+		Amt: req.Amt,
+	})
+	if err != nil {
+		h.handleServiceErr(ctx, err, "打赏失败, 文章ID: "+strconv.FormatInt(req.Id, 10))
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Code: 200,
+		Msg:  "下单成功",
+		Data: codeURL,
+	})
 }
 
 func (h *ArticleHandler) RegisterRoutes(engine *gin.Engine) {
@@ -399,4 +936,40 @@ func (h *ArticleHandler) RegisterRoutes(engine *gin.Engine) {
 	group.POST("/collect", h.Collect)   // 收藏
 	group.GET("/rank/:id", h.Rank)      // 点赞排行榜
 	group.POST("/reword", h.Reward)     // 打赏
+
+	group.POST("/schedule/cancel", h.CancelSchedule) // 取消定时发表
+	group.POST("/schedule/reschedule", h.Reschedule) // 修改定时发表时间
+
+	group.POST("/revisions", h.Revisions)               // 文章版本列表
+	group.GET("/revisions/diff", h.DiffRevisions)       // 对比两个版本
+	group.POST("/revisions/restore", h.RestoreRevision) // 恢复某个版本为草稿
+
+	group.GET("/tags", h.TagCloud)                       // 标签云
+	group.GET("/tags/:tag", h.ListByTag)                 // 按标签获取线上文章
+	group.GET("/categories/:category", h.ListByCategory) // 按分类获取线上文章
+
+	group.GET("/search", h.Search) // 全文搜索
+
+	group.POST("/delete", h.Delete)              // 删除文章(移入回收站)
+	group.POST("/trash", h.Trash)                // 回收站列表
+	group.POST("/trash/restore", h.RestoreTrash) // 从回收站恢复
+
+	group.GET("/status_logs/:id", h.StatusLogs) // 文章状态变化记录
+
+	group.POST("/uncollect", h.Uncollect)         // 取消收藏
+	group.GET("/folders", h.ListFolders)          // 收藏夹列表
+	group.POST("/folders/create", h.CreateFolder) // 新建收藏夹
+	group.POST("/folders/rename", h.RenameFolder) // 修改收藏夹名称和描述
+	group.POST("/folders/delete", h.DeleteFolder) // 删除收藏夹
+	group.POST("/folders/move", h.MoveItem)       // 移动收藏到其他收藏夹
+	group.POST("/folders/items", h.FolderItems)   // 收藏夹中的文章
+
+	group.POST("/history", h.ReadHistory)              // 最近阅读
+	group.POST("/history/delete", h.DeleteReadHistory) // 删除一条阅读记录
+	group.POST("/history/clear", h.ClearReadHistory)   // 清空阅读记录
+
+	group.GET("/export", h.Export)  // 导出全部文章为 Markdown 压缩包
+	group.POST("/import", h.Import) // 从压缩包导入为草稿
+
+	engine.GET("/p/:id", h.PublicDetail) // 未登录读者查看线上文章 不需要登录
 }
@@ -0,0 +1,159 @@
+package web
+
+import (
+	"context"
+	"github.com/bytedance/sonic"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+	"tinybook/tinybook/article/domain"
+	"tinybook/tinybook/article/events/invalidation"
+	"tinybook/tinybook/article/events/readcount"
+	"tinybook/tinybook/article/repository"
+	"tinybook/tinybook/article/repository/cache"
+	"tinybook/tinybook/article/repository/dao"
+	"tinybook/tinybook/article/repository/search"
+	"tinybook/tinybook/article/service"
+	domain2 "tinybook/tinybook/internal/domain"
+	repository2 "tinybook/tinybook/internal/repository"
+)
+
+// TestPublicDetailWithdrawn 撤回发表后未登录读者不能再通过 /p/:id 看到文章
+func TestPublicDetailWithdrawn(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctx := context.Background()
+	d := dao.NewInMemoryArticleDAO()
+	repo := repository.NewCachedArticleRepository(d, newMemArticleCache(), newMemPubArticleCache(),
+		search.NewLocalArticleSearcher(), fakeUserRepo{}, zap.NewNop(), nil, nil, fakeInvalidationProducer{},
+		nil, nil)
+	svc := service.NewArticleService(repo, nil, nil, nil, fakeReadProducer{}, nil, zap.NewNop())
+	server := gin.New()
+	NewArticleHandler(svc, nil, nil, nil, zap.NewNop()).RegisterRoutes(server)
+
+	id, err := d.Sync(ctx, dao.Article{Title: "标题", Content: "正文", AuthorId: 1,
+		Status: uint8(domain.ArticleStatusPublished)}, uint8(domain.ArticleStatusUnknown))
+	require.NoError(t, err)
+	// 先读一次 让文章进入缓存
+	assert.Equal(t, 200, getPublicDetail(t, server, id).Code)
+
+	err = repo.SyncStatus(ctx, domain.Article{ID: id, Author: domain.Author{ID: 1},
+		Status: domain.ArticleStatusPublished}, domain.ArticleStatusPrivate)
+	require.NoError(t, err)
+	assert.Equal(t, 404, getPublicDetail(t, server, id).Code)
+	// 第二次命中空缓存
+	assert.Equal(t, 404, getPublicDetail(t, server, id).Code)
+}
+
+func getPublicDetail(t *testing.T, server *gin.Engine, id int64) Result {
+	req := httptest.NewRequest(http.MethodGet, "/p/"+strconv.FormatInt(id, 10), nil)
+	resp := httptest.NewRecorder()
+	server.ServeHTTP(resp, req)
+	require.Equal(t, http.StatusOK, resp.Code)
+	var res Result
+	require.NoError(t, sonic.Unmarshal(resp.Body.Bytes(), &res))
+	return res
+}
+
+type memArticleCache struct {
+	mu   sync.Mutex
+	data map[string][]byte
+}
+
+func newMemArticleCache() *memArticleCache {
+	return &memArticleCache{data: make(map[string][]byte)}
+}
+
+func (m *memArticleCache) Get(ctx context.Context, key string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	val, ok := m.data[key]
+	if !ok {
+		return nil, cache.ErrPubArticleMiss
+	}
+	return val, nil
+}
+
+func (m *memArticleCache) Set(ctx context.Context, key string, value []byte, duration time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.data[key] = value
+	return nil
+}
+
+func (m *memArticleCache) Delete(ctx context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.data, key)
+	return nil
+}
+
+// memPubArticleCache 值为 nil 表示空缓存
+type memPubArticleCache struct {
+	mu   sync.Mutex
+	data map[int64]*domain.Article
+}
+
+func newMemPubArticleCache() *memPubArticleCache {
+	return &memPubArticleCache{data: make(map[int64]*domain.Article)}
+}
+
+func (m *memPubArticleCache) Get(ctx context.Context, id int64) (domain.Article, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	art, ok := m.data[id]
+	switch {
+	case !ok:
+		return domain.Article{}, cache.ErrPubArticleMiss
+	case art == nil:
+		return domain.Article{}, cache.ErrPubArticleNotFound
+	}
+	return *art, nil
+}
+
+func (m *memPubArticleCache) Set(ctx context.Context, article domain.Article) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.data[article.ID] = &article
+	return nil
+}
+
+func (m *memPubArticleCache) SetNotFound(ctx context.Context, id int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.data[id] = nil
+	return nil
+}
+
+func (m *memPubArticleCache) Delete(ctx context.Context, id int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.data, id)
+	return nil
+}
+
+type fakeUserRepo struct {
+	repository2.UserRepository
+}
+
+func (fakeUserRepo) FindById(ctx context.Context, id int64) (domain2.User, error) {
+	return domain2.User{Id: id, Nickname: "作者"}, nil
+}
+
+type fakeReadProducer struct{}
+
+func (fakeReadProducer) ProduceReadEvent(event readcount.ReadEvent) error {
+	return nil
+}
+
+type fakeInvalidationProducer struct{}
+
+func (fakeInvalidationProducer) ProduceCacheInvalidationEvent(ctx context.Context, evt invalidation.CacheInvalidationEvent) error {
+	return nil
+}
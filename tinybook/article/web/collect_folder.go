@@ -0,0 +1,280 @@
+package web
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/samber/lo"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"net/http"
+	"strconv"
+	"time"
+	intrv1 "tinybook/tinybook/api/proto/gen/intr/v1"
+	"tinybook/tinybook/article/domain"
+	"tinybook/tinybook/internal/web/jwt"
+)
+
+func (h *ArticleHandler) Uncollect(ctx *gin.Context) {
+	type Req struct {
+		Id int64 `json:"id"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil || req.Id <= 0 {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 400,
+			Msg:  "参数错误",
+		})
+		return
+	}
+	claims := (ctx.MustGet("userClaims")).(jwt.UserClaims)
+	_, err := h.articleService.Uncollect(ctx, &intrv1.UncollectRequest{
+		Biz:   h.biz,
+		BizId: req.Id,
+		Uid:   claims.Uid,
+	})
+	if err != nil {
+		h.handleIntrErr(ctx, err, "取消收藏失败, 文章ID: "+strconv.FormatInt(req.Id, 10)+" 用户ID: "+strconv.FormatInt(claims.Uid, 10))
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Code: 200,
+		Msg:  "取消收藏成功",
+	})
+}
+
+func (h *ArticleHandler) CreateFolder(ctx *gin.Context) {
+	type Req struct {
+		Name        string `json:"name"`
+		Description string `json:"description"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 400,
+			Msg:  "参数错误",
+		})
+		return
+	}
+	claims := (ctx.MustGet("userClaims")).(jwt.UserClaims)
+	resp, err := h.articleService.CreateFolder(ctx, &intrv1.CreateFolderRequest{
+		Uid:         claims.Uid,
+		Name:        req.Name,
+		Description: req.Description,
+	})
+	if err != nil {
+		h.handleIntrErr(ctx, err, "创建收藏夹失败, 用户ID: "+strconv.FormatInt(claims.Uid, 10))
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Code: 200,
+		Msg:  "创建成功",
+		Data: resp.GetId(),
+	})
+}
+
+func (h *ArticleHandler) RenameFolder(ctx *gin.Context) {
+	type Req struct {
+		Id          int64  `json:"id"`
+		Name        string `json:"name"`
+		Description string `json:"description"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil || req.Id < 0 {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 400,
+			Msg:  "参数错误",
+		})
+		return
+	}
+	claims := (ctx.MustGet("userClaims")).(jwt.UserClaims)
+	_, err := h.articleService.RenameFolder(ctx, &intrv1.RenameFolderRequest{
+		Id:          req.Id,
+		Uid:         claims.Uid,
+		Name:        req.Name,
+		Description: req.Description,
+	})
+	if err != nil {
+		h.handleIntrErr(ctx, err, "修改收藏夹失败, 收藏夹ID: "+strconv.FormatInt(req.Id, 10))
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Code: 200,
+		Msg:  "修改成功",
+	})
+}
+
+// DeleteFolder 删除收藏夹 其中的文章会移回默认收藏夹
+func (h *ArticleHandler) DeleteFolder(ctx *gin.Context) {
+	type Req struct {
+		Id int64 `json:"id"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil || req.Id < 0 {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 400,
+			Msg:  "参数错误",
+		})
+		return
+	}
+	claims := (ctx.MustGet("userClaims")).(jwt.UserClaims)
+	_, err := h.articleService.DeleteFolder(ctx, &intrv1.DeleteFolderRequest{
+		Id:  req.Id,
+		Uid: claims.Uid,
+	})
+	if err != nil {
+		h.handleIntrErr(ctx, err, "删除收藏夹失败, 收藏夹ID: "+strconv.FormatInt(req.Id, 10))
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Code: 200,
+		Msg:  "删除成功",
+	})
+}
+
+func (h *ArticleHandler) ListFolders(ctx *gin.Context) {
+	claims := (ctx.MustGet("userClaims")).(jwt.UserClaims)
+	resp, err := h.articleService.ListFolders(ctx, &intrv1.ListFoldersRequest{
+		Uid: claims.Uid,
+	})
+	if err != nil {
+		h.handleIntrErr(ctx, err, "获取收藏夹列表失败, 用户ID: "+strconv.FormatInt(claims.Uid, 10))
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Code: 200,
+		Msg:  "获取成功",
+		Data: lo.Map(resp.GetFolders(), func(item *intrv1.CollectFolder, index int) domain.CollectFolderVo {
+			return domain.CollectFolderVo{
+				Id:          item.GetId(),
+				Name:        item.GetName(),
+				Description: item.GetDescription(),
+				ItemCount:   item.GetItemCount(),
+				Ctime:       formatFolderTime(item.GetCtime()),
+				Utime:       formatFolderTime(item.GetUtime()),
+			}
+		}),
+	})
+}
+
+// MoveItem 把已收藏的文章移到另一个收藏夹
+func (h *ArticleHandler) MoveItem(ctx *gin.Context) {
+	type Req struct {
+		Id  int64 `json:"id"`
+		Cid int64 `json:"cid"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil || req.Id <= 0 || req.Cid < 0 {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 400,
+			Msg:  "参数错误",
+		})
+		return
+	}
+	claims := (ctx.MustGet("userClaims")).(jwt.UserClaims)
+	_, err := h.articleService.MoveItem(ctx, &intrv1.MoveItemRequest{
+		Biz:   h.biz,
+		BizId: req.Id,
+		Uid:   claims.Uid,
+		Cid:   req.Cid,
+	})
+	if err != nil {
+		h.handleIntrErr(ctx, err, "移动收藏失败, 文章ID: "+strconv.FormatInt(req.Id, 10)+" 收藏夹ID: "+strconv.FormatInt(req.Cid, 10))
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Code: 200,
+		Msg:  "移动成功",
+	})
+}
+
+// FolderItems 收藏夹中的文章 按收藏时间倒序
+func (h *ArticleHandler) FolderItems(ctx *gin.Context) {
+	type Req struct {
+		Cid int64 `json:"cid"`
+		// 上一页返回的 nextCursor 第一页不传
+		Cursor int64 `json:"cursor"`
+		Limit  int64 `json:"limit"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil || req.Cid < 0 || req.Cursor < 0 || req.Limit < 0 || req.Limit > 100 {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 400,
+			Msg:  "参数错误",
+		})
+		return
+	}
+	claims := (ctx.MustGet("userClaims")).(jwt.UserClaims)
+	resp, err := h.articleService.ListFolderItems(ctx, &intrv1.ListFolderItemsRequest{
+		Uid:    claims.Uid,
+		Cid:    req.Cid,
+		Cursor: req.Cursor,
+		Limit:  req.Limit,
+	})
+	if err != nil {
+		h.handleIntrErr(ctx, err, "获取收藏夹文章失败, 收藏夹ID: "+strconv.FormatInt(req.Cid, 10))
+		return
+	}
+	items := resp.GetItems()
+	articles, err := h.articleService.ListPubByIds(ctx, lo.Map(items, func(item *intrv1.CollectItem, index int) int64 {
+		return item.GetBizId()
+	}))
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 500,
+			Msg:  "服务器错误",
+		})
+		h.l.Error("获取收藏的文章失败, 收藏夹ID: "+strconv.FormatInt(req.Cid, 10), zap.Error(err))
+		return
+	}
+	articleMap := lo.SliceToMap(articles, func(item domain.ArticleVo) (int64, domain.ArticleVo) {
+		return item.ID, item
+	})
+	res := domain.CollectItemListVo{
+		Items:      make([]domain.CollectItemVo, 0, len(items)),
+		NextCursor: resp.GetNextCursor(),
+	}
+	for _, item := range items {
+		vo := domain.CollectItemVo{
+			Cid:   item.GetCid(),
+			BizId: item.GetBizId(),
+			Ctime: formatFolderTime(item.GetCtime()),
+		}
+		// 已撤回或删除的文章仍然保留收藏记录 方便用户取消收藏
+		if art, ok := articleMap[item.GetBizId()]; ok {
+			vo.Article = &art
+		}
+		res.Items = append(res.Items, vo)
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Code: 200,
+		Msg:  "获取成功",
+		Data: res,
+	})
+}
+
+// handleIntrErr interactive 服务返回的参数类错误带有 grpc 错误码 本地调用与远程调用一致
+func (h *ArticleHandler) handleIntrErr(ctx *gin.Context, err error, msg string) {
+	if st, ok := status.FromError(err); ok {
+		switch st.Code() {
+		case codes.InvalidArgument, codes.NotFound, codes.AlreadyExists, codes.FailedPrecondition:
+			ctx.JSON(http.StatusOK, Result{
+				Code: 400,
+				Msg:  st.Message(),
+			})
+			return
+		}
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Code: 500,
+		Msg:  "服务器错误",
+	})
+	h.l.Error(msg, zap.Error(err))
+}
+
+// formatFolderTime 默认收藏夹没有创建时间
+func formatFolderTime(t int64) string {
+	if t <= 0 {
+		return ""
+	}
+	return time.Unix(t, 0).Format("2006-01-02 15:04:05")
+}
@@ -0,0 +1,121 @@
+package web
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/samber/lo"
+	"go.uber.org/zap"
+	"net/http"
+	"strconv"
+	"time"
+	intrv1 "tinybook/tinybook/api/proto/gen/intr/v1"
+	"tinybook/tinybook/article/domain"
+	"tinybook/tinybook/internal/web/jwt"
+)
+
+// ReadHistory 最近阅读 按阅读时间倒序
+func (h *ArticleHandler) ReadHistory(ctx *gin.Context) {
+	type Req struct {
+		// 上一页返回的 nextCursor 第一页不传
+		Cursor int64 `json:"cursor"`
+		Limit  int64 `json:"limit"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil || req.Cursor < 0 || req.Limit < 0 || req.Limit > 100 {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 400,
+			Msg:  "参数错误",
+		})
+		return
+	}
+	claims := (ctx.MustGet("userClaims")).(jwt.UserClaims)
+	resp, err := h.articleService.ListReadHistory(ctx, &intrv1.ListReadHistoryRequest{
+		Uid:    claims.Uid,
+		Cursor: req.Cursor,
+		Limit:  req.Limit,
+	})
+	if err != nil {
+		h.handleIntrErr(ctx, err, "获取阅读记录失败, 用户ID: "+strconv.FormatInt(claims.Uid, 10))
+		return
+	}
+	histories := lo.Filter(resp.GetHistories(), func(item *intrv1.ReadHistory, index int) bool {
+		return item.GetBiz() == h.biz
+	})
+	articles, err := h.articleService.ListPubByIds(ctx, lo.Map(histories, func(item *intrv1.ReadHistory, index int) int64 {
+		return item.GetBizId()
+	}))
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 500,
+			Msg:  "服务器错误",
+		})
+		h.l.Error("获取阅读过的文章失败, 用户ID: "+strconv.FormatInt(claims.Uid, 10), zap.Error(err))
+		return
+	}
+	articleMap := lo.SliceToMap(articles, func(item domain.ArticleVo) (int64, domain.ArticleVo) {
+		return item.ID, item
+	})
+	res := domain.ReadHistoryListVo{
+		Items:      make([]domain.ReadHistoryVo, 0, len(histories)),
+		NextCursor: resp.GetNextCursor(),
+	}
+	for _, item := range histories {
+		vo := domain.ReadHistoryVo{
+			BizId: item.GetBizId(),
+			Rtime: time.UnixMilli(item.GetRtime()).Format("2006-01-02 15:04:05"),
+		}
+		if art, ok := articleMap[item.GetBizId()]; ok {
+			vo.Article = &art
+		}
+		res.Items = append(res.Items, vo)
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Code: 200,
+		Msg:  "获取成功",
+		Data: res,
+	})
+}
+
+// DeleteReadHistory 删除一篇文章的阅读记录
+func (h *ArticleHandler) DeleteReadHistory(ctx *gin.Context) {
+	type Req struct {
+		Id int64 `json:"id"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil || req.Id <= 0 {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 400,
+			Msg:  "参数错误",
+		})
+		return
+	}
+	claims := (ctx.MustGet("userClaims")).(jwt.UserClaims)
+	_, err := h.articleService.DeleteReadHistory(ctx, &intrv1.DeleteReadHistoryRequest{
+		Uid:   claims.Uid,
+		Biz:   h.biz,
+		BizId: req.Id,
+	})
+	if err != nil {
+		h.handleIntrErr(ctx, err, "删除阅读记录失败, 文章ID: "+strconv.FormatInt(req.Id, 10)+" 用户ID: "+strconv.FormatInt(claims.Uid, 10))
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Code: 200,
+		Msg:  "删除成功",
+	})
+}
+
+// ClearReadHistory 清空阅读记录
+func (h *ArticleHandler) ClearReadHistory(ctx *gin.Context) {
+	claims := (ctx.MustGet("userClaims")).(jwt.UserClaims)
+	_, err := h.articleService.ClearReadHistory(ctx, &intrv1.ClearReadHistoryRequest{
+		Uid: claims.Uid,
+	})
+	if err != nil {
+		h.handleIntrErr(ctx, err, "清空阅读记录失败, 用户ID: "+strconv.FormatInt(claims.Uid, 10))
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Code: 200,
+		Msg:  "清空成功",
+	})
+}
@@ -0,0 +1,154 @@
+package web
+
+import (
+	"github.com/cockroachdb/errors"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"net/http"
+	"strconv"
+	"tinybook/tinybook/article/service"
+	"tinybook/tinybook/internal/web/jwt"
+	"tinybook/tinybook/internal/web/middleware"
+)
+
+// ReviewHandler 管理员的文章审核队列
+type ReviewHandler struct {
+	reviewService service.ReviewService
+	admin         *middleware.AdminMiddlewareBuilder
+	l             *zap.Logger
+}
+
+func NewReviewHandler(reviewService service.ReviewService, admin *middleware.AdminMiddlewareBuilder, l *zap.Logger) *ReviewHandler {
+	return &ReviewHandler{reviewService: reviewService, admin: admin, l: l}
+}
+
+// List 等待审核的文章 先提交的在前
+func (h *ReviewHandler) List(ctx *gin.Context) {
+	type Req struct {
+		Cursor int64 `json:"cursor"`
+		Limit  int   `json:"limit"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil || req.Cursor < 0 || req.Limit < 0 || req.Limit > 50 {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 400,
+			Msg:  "参数错误",
+		})
+		return
+	}
+	if req.Limit == 0 {
+		req.Limit = 10
+	}
+	reviews, err := h.reviewService.ListPending(ctx, req.Cursor, req.Limit)
+	if err != nil {
+		h.handleServiceErr(ctx, err, "获取审核队列失败")
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Code: 200,
+		Msg:  "获取成功",
+		Data: reviews,
+	})
+}
+
+// Detail 审核详情 带有文章内容
+func (h *ReviewHandler) Detail(ctx *gin.Context) {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 400,
+			Msg:  "参数错误",
+		})
+		return
+	}
+	review, err := h.reviewService.Detail(ctx, id)
+	if err != nil {
+		h.handleServiceErr(ctx, err, "获取审核详情失败, 审核ID: "+strconv.FormatInt(id, 10))
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Code: 200,
+		Msg:  "获取成功",
+		Data: review,
+	})
+}
+
+// Approve 审核通过
+func (h *ReviewHandler) Approve(ctx *gin.Context) {
+	type Req struct {
+		Id int64 `json:"id" binding:"required"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 400,
+			Msg:  "参数错误",
+		})
+		return
+	}
+	claims := (ctx.MustGet("userClaims")).(jwt.UserClaims)
+	err := h.reviewService.Approve(ctx, req.Id, claims.Uid)
+	if err != nil {
+		h.handleServiceErr(ctx, err, "审核通过失败, 审核ID: "+strconv.FormatInt(req.Id, 10))
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Code: 200,
+		Msg:  "审核通过",
+		Data: req.Id,
+	})
+}
+
+// Reject 驳回 必须填写理由
+func (h *ReviewHandler) Reject(ctx *gin.Context) {
+	type Req struct {
+		Id     int64  `json:"id" binding:"required"`
+		Reason string `json:"reason" binding:"required"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 400,
+			Msg:  "参数错误",
+		})
+		return
+	}
+	claims := (ctx.MustGet("userClaims")).(jwt.UserClaims)
+	err := h.reviewService.Reject(ctx, req.Id, claims.Uid, req.Reason)
+	if err != nil {
+		h.handleServiceErr(ctx, err, "驳回失败, 审核ID: "+strconv.FormatInt(req.Id, 10))
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Code: 200,
+		Msg:  "已驳回",
+		Data: req.Id,
+	})
+}
+
+func (h *ReviewHandler) handleServiceErr(ctx *gin.Context, err error, msg string) {
+	switch {
+	case errors.Is(err, service.ErrReviewNotFound),
+		errors.Is(err, service.ErrReviewFinished),
+		errors.Is(err, service.ErrReviewOutdated),
+		errors.Is(err, service.ErrInvalidReason):
+		ctx.JSON(http.StatusOK, Result{
+			Code: 400,
+			Msg:  err.Error(),
+		})
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Code: 500,
+		Msg:  "服务器错误",
+	})
+	h.l.Error(msg, zap.Error(err))
+}
+
+func (h *ReviewHandler) RegisterRoutes(engine *gin.Engine) {
+	group := engine.Group("/admin/reviews", h.admin.Build())
+	group.POST("/list", h.List)       // 审核队列
+	group.GET("/:id", h.Detail)       // 审核详情
+	group.POST("/approve", h.Approve) // 审核通过
+	group.POST("/reject", h.Reject)   // 驳回
+}
@@ -0,0 +1,283 @@
+package web
+
+import (
+	"github.com/cockroachdb/errors"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"net/http"
+	"strconv"
+	"tinybook/tinybook/article/domain"
+	"tinybook/tinybook/article/service"
+	"tinybook/tinybook/internal/web/jwt"
+)
+
+// SeriesHandler 文章系列(专栏) 作者创建系列并调整其中文章的顺序
+type SeriesHandler struct {
+	seriesService service.SeriesService
+	l             *zap.Logger
+}
+
+func NewSeriesHandler(seriesService service.SeriesService, l *zap.Logger) *SeriesHandler {
+	return &SeriesHandler{seriesService: seriesService, l: l}
+}
+
+func (h *SeriesHandler) Create(ctx *gin.Context) {
+	type Req struct {
+		Name        string `json:"name"`
+		Description string `json:"description"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 400,
+			Msg:  "参数错误",
+		})
+		return
+	}
+	claims := (ctx.MustGet("userClaims")).(jwt.UserClaims)
+	id, err := h.seriesService.Create(ctx, domain.Series{
+		AuthorId:    claims.Uid,
+		Name:        req.Name,
+		Description: req.Description,
+	})
+	if err != nil {
+		h.handleServiceErr(ctx, err, "创建系列失败, 用户ID: "+strconv.FormatInt(claims.Uid, 10))
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Code: 200,
+		Msg:  "创建成功",
+		Data: id,
+	})
+}
+
+// Rename 修改系列的名称和简介
+func (h *SeriesHandler) Rename(ctx *gin.Context) {
+	type Req struct {
+		Id          int64  `json:"id"`
+		Name        string `json:"name"`
+		Description string `json:"description"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil || req.Id <= 0 {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 400,
+			Msg:  "参数错误",
+		})
+		return
+	}
+	claims := (ctx.MustGet("userClaims")).(jwt.UserClaims)
+	err := h.seriesService.Rename(ctx, domain.Series{
+		ID:          req.Id,
+		AuthorId:    claims.Uid,
+		Name:        req.Name,
+		Description: req.Description,
+	})
+	if err != nil {
+		h.handleServiceErr(ctx, err, "修改系列失败, 系列ID: "+strconv.FormatInt(req.Id, 10))
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Code: 200,
+		Msg:  "修改成功",
+	})
+}
+
+// Delete 删除系列 其中的文章不受影响
+func (h *SeriesHandler) Delete(ctx *gin.Context) {
+	type Req struct {
+		Id int64 `json:"id"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil || req.Id <= 0 {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 400,
+			Msg:  "参数错误",
+		})
+		return
+	}
+	claims := (ctx.MustGet("userClaims")).(jwt.UserClaims)
+	err := h.seriesService.Delete(ctx, claims.Uid, req.Id)
+	if err != nil {
+		h.handleServiceErr(ctx, err, "删除系列失败, 系列ID: "+strconv.FormatInt(req.Id, 10))
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Code: 200,
+		Msg:  "删除成功",
+	})
+}
+
+// AddArticle 把文章追加到系列末尾
+func (h *SeriesHandler) AddArticle(ctx *gin.Context) {
+	type Req struct {
+		Id        int64 `json:"id"`
+		ArticleId int64 `json:"articleId"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil || req.Id <= 0 || req.ArticleId <= 0 {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 400,
+			Msg:  "参数错误",
+		})
+		return
+	}
+	claims := (ctx.MustGet("userClaims")).(jwt.UserClaims)
+	err := h.seriesService.AddArticle(ctx, claims.Uid, req.Id, req.ArticleId)
+	if err != nil {
+		h.handleServiceErr(ctx, err, "文章加入系列失败, 系列ID: "+strconv.FormatInt(req.Id, 10)+
+			" 文章ID: "+strconv.FormatInt(req.ArticleId, 10))
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Code: 200,
+		Msg:  "添加成功",
+	})
+}
+
+func (h *SeriesHandler) RemoveArticle(ctx *gin.Context) {
+	type Req struct {
+		Id        int64 `json:"id"`
+		ArticleId int64 `json:"articleId"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil || req.Id <= 0 || req.ArticleId <= 0 {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 400,
+			Msg:  "参数错误",
+		})
+		return
+	}
+	claims := (ctx.MustGet("userClaims")).(jwt.UserClaims)
+	err := h.seriesService.RemoveArticle(ctx, claims.Uid, req.Id, req.ArticleId)
+	if err != nil {
+		h.handleServiceErr(ctx, err, "文章移出系列失败, 系列ID: "+strconv.FormatInt(req.Id, 10)+
+			" 文章ID: "+strconv.FormatInt(req.ArticleId, 10))
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Code: 200,
+		Msg:  "移除成功",
+	})
+}
+
+// Reorder articleIds 为系列中全部文章的新顺序
+func (h *SeriesHandler) Reorder(ctx *gin.Context) {
+	type Req struct {
+		Id         int64   `json:"id"`
+		ArticleIds []int64 `json:"articleIds"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil || req.Id <= 0 {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 400,
+			Msg:  "参数错误",
+		})
+		return
+	}
+	claims := (ctx.MustGet("userClaims")).(jwt.UserClaims)
+	err := h.seriesService.Reorder(ctx, claims.Uid, req.Id, req.ArticleIds)
+	if err != nil {
+		h.handleServiceErr(ctx, err, "调整系列文章顺序失败, 系列ID: "+strconv.FormatInt(req.Id, 10))
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Code: 200,
+		Msg:  "调整成功",
+	})
+}
+
+// ListByAuthor 作者的全部系列及文章数
+func (h *SeriesHandler) ListByAuthor(ctx *gin.Context) {
+	authorId, err := strconv.ParseInt(ctx.Param("uid"), 10, 64)
+	if err != nil || authorId <= 0 {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 400,
+			Msg:  "参数错误",
+		})
+		return
+	}
+	claims := (ctx.MustGet("userClaims")).(jwt.UserClaims)
+	list, err := h.seriesService.ListByAuthor(ctx, authorId, claims.Uid)
+	if err != nil {
+		h.handleServiceErr(ctx, err, "获取作者的系列失败, 作者ID: "+strconv.FormatInt(authorId, 10))
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Code: 200,
+		Msg:  "获取成功",
+		Data: list,
+	})
+}
+
+// Detail 系列以及其中已发表的文章
+func (h *SeriesHandler) Detail(ctx *gin.Context) {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 400,
+			Msg:  "参数错误",
+		})
+		return
+	}
+	claims := (ctx.MustGet("userClaims")).(jwt.UserClaims)
+	series, err := h.seriesService.Detail(ctx, id, claims.Uid)
+	if err != nil {
+		h.handleServiceErr(ctx, err, "获取系列详情失败, 系列ID: "+strconv.FormatInt(id, 10))
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Code: 200,
+		Msg:  "获取成功",
+		Data: series,
+	})
+}
+
+func (h *SeriesHandler) handleServiceErr(ctx *gin.Context, err error, msg string) {
+	switch {
+	case errors.Is(err, service.ErrNotArticleAuthor):
+		ctx.JSON(http.StatusOK, Result{
+			Code: 401,
+			Msg:  "无权限",
+		})
+		return
+	case errors.Is(err, service.ErrSeriesNotFound),
+		errors.Is(err, service.ErrArticleNotFound):
+		ctx.JSON(http.StatusOK, Result{
+			Code: 404,
+			Msg:  err.Error(),
+		})
+		return
+	case errors.Is(err, service.ErrInvalidSeriesName),
+		errors.Is(err, service.ErrInvalidSeriesDescription),
+		errors.Is(err, service.ErrTooManySeries),
+		errors.Is(err, service.ErrTooManyArticlesInSeries),
+		errors.Is(err, service.ErrArticleInSeries),
+		errors.Is(err, service.ErrArticleNotInSeries),
+		errors.Is(err, service.ErrSeriesArticlesMismatch),
+		errors.Is(err, service.ErrDuplicateArticlesInSeries),
+		errors.Is(err, service.ErrDeletedArticleNotAllowed):
+		ctx.JSON(http.StatusOK, Result{
+			Code: 400,
+			Msg:  err.Error(),
+		})
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Code: 500,
+		Msg:  "服务器错误",
+	})
+	h.l.Error(msg, zap.Error(err))
+}
+
+func (h *SeriesHandler) RegisterRoutes(engine *gin.Engine) {
+	group := engine.Group("/series")
+	group.POST("/create", h.Create)                 // 创建系列
+	group.POST("/rename", h.Rename)                 // 修改系列名称和简介
+	group.POST("/delete", h.Delete)                 // 删除系列
+	group.POST("/articles/add", h.AddArticle)       // 文章加入系列
+	group.POST("/articles/remove", h.RemoveArticle) // 文章移出系列
+	group.POST("/articles/reorder", h.Reorder)      // 调整系列中文章的顺序
+	group.GET("/author/:uid", h.ListByAuthor)       // 作者的全部系列
+	group.GET("/detail/:id", h.Detail)              // 系列详情
+}
@@ -0,0 +1,157 @@
+package web
+
+import (
+	"github.com/cockroachdb/errors"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"net/http"
+	"strconv"
+	"time"
+	"tinybook/tinybook/article/service"
+	"tinybook/tinybook/internal/web/jwt"
+)
+
+// ShareHandler 未公开文章的分享链接 通过链接查看文章不需要登录
+type ShareHandler struct {
+	shareService service.ShareService
+	l            *zap.Logger
+}
+
+func NewShareHandler(shareService service.ShareService, l *zap.Logger) *ShareHandler {
+	return &ShareHandler{shareService: shareService, l: l}
+}
+
+// Create 生成分享链接 ttlHours 为 0 时使用默认有效期
+func (h *ShareHandler) Create(ctx *gin.Context) {
+	type Req struct {
+		Id         int64 `json:"id" binding:"required"`
+		RevisionId int64 `json:"revisionId"`
+		TtlHours   int64 `json:"ttlHours"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil || req.RevisionId < 0 || req.TtlHours < 0 {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 400,
+			Msg:  "参数错误",
+		})
+		return
+	}
+	claims := (ctx.MustGet("userClaims")).(jwt.UserClaims)
+	link, err := h.shareService.Create(ctx, claims.Uid, req.Id, req.RevisionId, time.Duration(req.TtlHours)*time.Hour)
+	if err != nil {
+		h.handleServiceErr(ctx, err, "生成分享链接失败, 文章ID: "+strconv.FormatInt(req.Id, 10))
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Code: 200,
+		Msg:  "生成成功",
+		Data: link,
+	})
+}
+
+// List 文章当前有效的分享链接
+func (h *ShareHandler) List(ctx *gin.Context) {
+	type Req struct {
+		Id int64 `json:"id" binding:"required"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 400,
+			Msg:  "参数错误",
+		})
+		return
+	}
+	claims := (ctx.MustGet("userClaims")).(jwt.UserClaims)
+	links, err := h.shareService.List(ctx, claims.Uid, req.Id)
+	if err != nil {
+		h.handleServiceErr(ctx, err, "获取分享链接失败, 文章ID: "+strconv.FormatInt(req.Id, 10))
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Code: 200,
+		Msg:  "获取成功",
+		Data: links,
+	})
+}
+
+// Revoke 撤销分享链接 已经打开的页面不受影响
+func (h *ShareHandler) Revoke(ctx *gin.Context) {
+	type Req struct {
+		Id int64 `json:"id" binding:"required"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 400,
+			Msg:  "参数错误",
+		})
+		return
+	}
+	claims := (ctx.MustGet("userClaims")).(jwt.UserClaims)
+	err := h.shareService.Revoke(ctx, claims.Uid, req.Id)
+	if err != nil {
+		h.handleServiceErr(ctx, err, "撤销分享链接失败, 链接ID: "+strconv.FormatInt(req.Id, 10))
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Code: 200,
+		Msg:  "撤销成功",
+		Data: req.Id,
+	})
+}
+
+// View 通过分享链接查看文章
+func (h *ShareHandler) View(ctx *gin.Context) {
+	article, err := h.shareService.View(ctx, ctx.Param("token"))
+	if err != nil {
+		h.handleServiceErr(ctx, err, "通过分享链接查看文章失败")
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Code: 200,
+		Msg:  "获取成功",
+		Data: article,
+	})
+}
+
+func (h *ShareHandler) handleServiceErr(ctx *gin.Context, err error, msg string) {
+	switch {
+	case errors.Is(err, service.ErrNotArticleAuthor):
+		ctx.JSON(http.StatusOK, Result{
+			Code: 401,
+			Msg:  "无权限",
+		})
+		return
+	case errors.Is(err, service.ErrInvalidShareToken):
+		ctx.JSON(http.StatusOK, Result{
+			Code: 404,
+			Msg:  err.Error(),
+		})
+		return
+	case errors.Is(err, service.ErrInvalidShareTTL),
+		errors.Is(err, service.ErrTooManyShareLinks),
+		errors.Is(err, service.ErrShareLinkNotFound),
+		errors.Is(err, service.ErrArticleNotShareable),
+		errors.Is(err, service.ErrShareNotModerated):
+		ctx.JSON(http.StatusOK, Result{
+			Code: 400,
+			Msg:  err.Error(),
+		})
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Code: 500,
+		Msg:  "服务器错误",
+	})
+	h.l.Error(msg, zap.Error(err))
+}
+
+func (h *ShareHandler) RegisterRoutes(engine *gin.Engine) {
+	group := engine.Group("/articles/share")
+	group.POST("/create", h.Create) // 生成分享链接
+	group.POST("/list", h.List)     // 文章有效的分享链接
+	group.POST("/revoke", h.Revoke) // 撤销分享链接
+
+	engine.GET("/share/:token", h.View) // 通过分享链接查看文章 不需要登录
+}
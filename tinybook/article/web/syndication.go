@@ -0,0 +1,113 @@
+package web
+
+import (
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"tinybook/tinybook/article/domain"
+	"tinybook/tinybook/article/service"
+	"tinybook/tinybook/pkg/feedx"
+)
+
+// SyndicationHandler 作者与全站的 RSS/Atom 订阅源 不需要登录
+type SyndicationHandler struct {
+	svc service.SyndicationService
+	l   *zap.Logger
+}
+
+func NewSyndicationHandler(svc service.SyndicationService, l *zap.Logger) *SyndicationHandler {
+	return &SyndicationHandler{svc: svc, l: l}
+}
+
+func (h *SyndicationHandler) RegisterRoutes(engine *gin.Engine) {
+	group := engine.Group("/feeds")
+	group.GET("/site.xml", h.Site)       // 全站最新文章 RSS 2.0, ?format=atom 时为 Atom
+	group.GET("/site.atom", h.Site)      // 全站最新文章 Atom
+	group.GET("/author/:file", h.Author) // 作者最新文章 /feeds/author/:id.xml 或 /feeds/author/:id.atom
+}
+
+func (h *SyndicationHandler) Site(ctx *gin.Context) {
+	format, ok := feedFormat(ctx, ctx.Request.URL.Path)
+	if !ok {
+		ctx.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+	doc, err := h.svc.SiteFeed(ctx, format)
+	if err != nil {
+		ctx.AbortWithStatus(http.StatusInternalServerError)
+		h.l.Error("获取全站订阅源失败", zap.Error(err))
+		return
+	}
+	writeFeed(ctx, format, doc)
+}
+
+func (h *SyndicationHandler) Author(ctx *gin.Context) {
+	file := ctx.Param("file")
+	format, ok := feedFormat(ctx, file)
+	if !ok {
+		ctx.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+	uid, err := strconv.ParseInt(file[:strings.LastIndexByte(file, '.')], 10, 64)
+	if err != nil || uid <= 0 {
+		ctx.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+	doc, err := h.svc.AuthorFeed(ctx, uid, format)
+	if err != nil {
+		ctx.AbortWithStatus(http.StatusInternalServerError)
+		h.l.Error("获取作者订阅源失败, 作者ID: "+strconv.FormatInt(uid, 10), zap.Error(err))
+		return
+	}
+	writeFeed(ctx, format, doc)
+}
+
+// feedFormat 由扩展名决定格式 .xml 默认为 RSS，可以用 ?format=atom 指定为 Atom
+func feedFormat(ctx *gin.Context, name string) (feedx.Format, bool) {
+	switch {
+	case strings.HasSuffix(name, ".atom"):
+		return feedx.FormatAtom, true
+	case strings.HasSuffix(name, ".xml"):
+		if ctx.Query("format") == "atom" {
+			return feedx.FormatAtom, true
+		}
+		return feedx.FormatRSS, true
+	default:
+		return 0, false
+	}
+}
+
+// writeFeed 输出订阅源 客户端带的 If-None-Match 或 If-Modified-Since 命中时返回 304
+func writeFeed(ctx *gin.Context, format feedx.Format, doc domain.SyndicationDocument) {
+	modified := time.Unix(doc.Modified, 0).UTC()
+	ctx.Header("ETag", doc.ETag)
+	ctx.Header("Last-Modified", modified.Format(http.TimeFormat))
+	ctx.Header("Cache-Control", "public, max-age=300")
+	if notModified(ctx.Request, doc.ETag, modified) {
+		ctx.Status(http.StatusNotModified)
+		return
+	}
+	ctx.Data(http.StatusOK, format.ContentType(), doc.Data)
+}
+
+// notModified 同时带了两个条件时以 If-None-Match 为准
+func notModified(req *http.Request, etag string, modified time.Time) bool {
+	if inm := req.Header.Get("If-None-Match"); inm != "" {
+		for _, tag := range strings.Split(inm, ",") {
+			tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+			if tag == "*" || tag == etag {
+				return true
+			}
+		}
+		return false
+	}
+	ims, err := http.ParseTime(req.Header.Get("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+	// http 时间只精确到秒
+	return !modified.Truncate(time.Second).After(ims)
+}
@@ -0,0 +1,72 @@
+package web
+
+import (
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"net/http"
+	"strconv"
+	"time"
+	"tinybook/tinybook/internal/web/jwt"
+)
+
+// maxImportZipSize 导入的 zip 最大字节数
+const maxImportZipSize = 20 << 20
+
+// Export 下载作者全部文章的 Markdown 压缩包
+func (h *ArticleHandler) Export(ctx *gin.Context) {
+	claims := (ctx.MustGet("userClaims")).(jwt.UserClaims)
+	filename := "articles-" + strconv.FormatInt(claims.Uid, 10) + "-" + time.Now().Format("20060102") + ".zip"
+	ctx.Header("Content-Type", "application/zip")
+	ctx.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	ctx.Status(http.StatusOK)
+	// 边查边写 已经开始输出后无法再返回错误信息 只能记录日志 客户端会收到不完整的压缩包
+	err := h.articleService.Export(ctx, claims.Uid, ctx.Writer)
+	if err != nil {
+		h.l.Error("导出文章失败, 作者ID: "+strconv.FormatInt(claims.Uid, 10), zap.Error(err))
+	}
+}
+
+// Import 上传导出格式的 zip 批量创建草稿 dryRun=true 时只返回预演结果
+func (h *ArticleHandler) Import(ctx *gin.Context) {
+	fh, err := ctx.FormFile("file")
+	if err != nil || fh.Size <= 0 {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 400,
+			Msg:  "参数错误",
+		})
+		return
+	}
+	if fh.Size > maxImportZipSize {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 400,
+			Msg:  "文件过大",
+		})
+		return
+	}
+	dryRun, _ := strconv.ParseBool(ctx.DefaultPostForm("dryRun", "false"))
+	file, err := fh.Open()
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 500,
+			Msg:  "服务器错误",
+		})
+		h.l.Error("打开上传文件失败", zap.Error(err))
+		return
+	}
+	defer file.Close()
+	claims := (ctx.MustGet("userClaims")).(jwt.UserClaims)
+	report, err := h.articleService.Import(ctx, claims.Uid, file, fh.Size, dryRun)
+	if err != nil {
+		h.handleServiceErr(ctx, err, "导入文章失败, 作者ID: "+strconv.FormatInt(claims.Uid, 10))
+		return
+	}
+	msg := "导入完成"
+	if dryRun {
+		msg = "预演完成"
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Code: 200,
+		Msg:  msg,
+		Data: report,
+	})
+}
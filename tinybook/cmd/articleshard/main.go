@@ -0,0 +1,35 @@
+// articleshard 为按作者分库的文章库创建表并记录分库信息 参数为各个分库的 DSN，顺序与 articleSharding.dsns 一致
+// 分库数量确定后不能修改，已经记录过的库用不同的数量或顺序再执行会报错
+//
+//	go run ./cmd/articleshard "root:root@tcp(127.0.0.1:3308)/tinybook_article_0?parseTime=True" ...
+package main
+
+import (
+	"context"
+	"fmt"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"os"
+	"tinybook/tinybook/article/repository/dao"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Println("用法: articleshard <dsn>...")
+		os.Exit(1)
+	}
+	dbs := make([]*gorm.DB, 0, len(os.Args)-1)
+	for _, dsn := range os.Args[1:] {
+		db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{})
+		if err != nil {
+			panic(err)
+		}
+		dao.CreateTableForArticleShard(db)
+		fmt.Println("分库表已创建: ", db.Migrator().CurrentDatabase())
+		dbs = append(dbs, db)
+	}
+	if err := dao.RecordShards(context.Background(), dbs); err != nil {
+		panic(err)
+	}
+	fmt.Println("分库信息已记录: ", len(dbs), "个分库")
+}
@@ -0,0 +1,57 @@
+package domain
+
+const (
+	MaxCommentLength = 1000 // 评论最大字符数
+	ReplyPreviewSize = 3    // 根评论下预览的回复数
+	MaxPageSize      = 50   // 每页最多的评论数
+)
+
+// Comment 评论 RootId 为 0 表示根评论，否则为某条根评论下的回复
+type Comment struct {
+	ID          int64  `json:"id"`
+	Biz         string `json:"biz"`
+	BizId       int64  `json:"bizId"`
+	Commentator User   `json:"commentator"`
+	Content     string `json:"content"`
+	RootId      int64  `json:"rootId"`
+	ParentId    int64  `json:"parentId"` // 直接回复的评论ID
+	ReplyTo     User   `json:"replyTo"`  // 被回复的人 根评论为空
+	// 以下两个字段只有根评论有
+	ReplyCount int64     `json:"replyCount"`
+	Replies    []Comment `json:"replies"` // 最早的几条回复 用于预览
+	Ctime      int64     `json:"ctime"`
+	Utime      int64     `json:"utime"`
+}
+
+type User struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+// Target 被评论的资源 例如文章
+type Target struct {
+	OwnerId     int64 // 资源所有者 可以删除其下的任何评论
+	Commentable bool  // 是否允许新增评论 例如文章撤回后不允许再评论
+}
+
+type CommentVo struct {
+	ID              int64       `json:"id"`
+	Biz             string      `json:"biz,omitempty"`
+	BizId           int64       `json:"bizId,omitempty"`
+	Commentator     int64       `json:"commentator"`
+	CommentatorName string      `json:"commentatorName,omitempty"`
+	Content         string      `json:"content"`
+	RootId          int64       `json:"rootId,omitempty"`
+	ParentId        int64       `json:"parentId,omitempty"`
+	ReplyTo         int64       `json:"replyTo,omitempty"`
+	ReplyToName     string      `json:"replyToName,omitempty"`
+	ReplyCount      int64       `json:"replyCount,omitempty"`
+	Replies         []CommentVo `json:"replies,omitempty"`
+	Ctime           string      `json:"ctime,omitempty"`
+}
+
+// CommentListVo 评论列表 NextCursor 为下一页的游标，为 0 表示没有下一页
+type CommentListVo struct {
+	Comments   []CommentVo `json:"comments"`
+	NextCursor int64       `json:"nextCursor,omitempty"`
+}
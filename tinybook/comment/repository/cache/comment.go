@@ -0,0 +1,65 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"github.com/bytedance/sonic"
+	"github.com/redis/go-redis/v9"
+	"time"
+	"tinybook/tinybook/comment/domain"
+)
+
+type CommentCache interface {
+	GetCount(ctx context.Context, biz string, bizId int64) (int64, error)
+	SetCount(ctx context.Context, biz string, bizId int64, count int64) error
+	GetFirstPage(ctx context.Context, biz string, bizId int64) ([]domain.Comment, error)
+	SetFirstPage(ctx context.Context, biz string, bizId int64, comments []domain.Comment) error
+	// Delete 删除资源下的评论数与第一页缓存 评论变更时调用
+	Delete(ctx context.Context, biz string, bizId int64) error
+}
+
+type RedisCommentCache struct {
+	cli redis.Cmdable
+}
+
+func NewRedisCommentCache(cli redis.Cmdable) CommentCache {
+	return &RedisCommentCache{cli: cli}
+}
+
+func (r *RedisCommentCache) GetCount(ctx context.Context, biz string, bizId int64) (int64, error) {
+	return r.cli.Get(ctx, r.countKey(biz, bizId)).Int64()
+}
+
+func (r *RedisCommentCache) SetCount(ctx context.Context, biz string, bizId int64, count int64) error {
+	return r.cli.Set(ctx, r.countKey(biz, bizId), count, 30*time.Minute).Err()
+}
+
+func (r *RedisCommentCache) GetFirstPage(ctx context.Context, biz string, bizId int64) ([]domain.Comment, error) {
+	bytes, err := r.cli.Get(ctx, r.firstPageKey(biz, bizId)).Bytes()
+	if err != nil {
+		return nil, err
+	}
+	var res []domain.Comment
+	err = sonic.Unmarshal(bytes, &res)
+	return res, err
+}
+
+func (r *RedisCommentCache) SetFirstPage(ctx context.Context, biz string, bizId int64, comments []domain.Comment) error {
+	marshal, err := sonic.Marshal(comments)
+	if err != nil {
+		return err
+	}
+	return r.cli.Set(ctx, r.firstPageKey(biz, bizId), marshal, 10*time.Minute).Err()
+}
+
+func (r *RedisCommentCache) Delete(ctx context.Context, biz string, bizId int64) error {
+	return r.cli.Del(ctx, r.countKey(biz, bizId), r.firstPageKey(biz, bizId)).Err()
+}
+
+func (r *RedisCommentCache) countKey(biz string, bizId int64) string {
+	return fmt.Sprintf("comment:count:%s:%d", biz, bizId)
+}
+
+func (r *RedisCommentCache) firstPageKey(biz string, bizId int64) string {
+	return fmt.Sprintf("comment:first_page:%s:%d", biz, bizId)
+}
@@ -0,0 +1,214 @@
+package repository
+
+import (
+	"context"
+	"github.com/samber/lo"
+	"go.uber.org/zap"
+	"tinybook/tinybook/comment/domain"
+	"tinybook/tinybook/comment/repository/cache"
+	"tinybook/tinybook/comment/repository/dao"
+	"tinybook/tinybook/internal/repository"
+)
+
+// FirstPageSize 缓存的第一页根评论数 分页时会多查一条判断是否还有下一页，因此比每页最大条数多一条
+const FirstPageSize = domain.MaxPageSize + 1
+
+var (
+	ErrCommentNotFound = dao.ErrCommentNotFound
+	ErrRootNotFound    = dao.ErrRootNotFound
+)
+
+type CommentRepository interface {
+	Create(ctx context.Context, comment domain.Comment) (int64, error)
+	FindById(ctx context.Context, id int64) (domain.Comment, error)
+	// ListRoots 根评论列表 每条根评论带上最早的几条回复
+	ListRoots(ctx context.Context, biz string, bizId int64, maxId int64, limit int) ([]domain.Comment, error)
+	ListReplies(ctx context.Context, rootId int64, minId int64, limit int) ([]domain.Comment, error)
+	Delete(ctx context.Context, comment domain.Comment) error
+	Count(ctx context.Context, biz string, bizId int64) (int64, error)
+}
+
+type CachedCommentRepository struct {
+	dao      dao.CommentDAO
+	cache    cache.CommentCache
+	userRepo repository.UserRepository
+	log      *zap.Logger
+}
+
+func NewCachedCommentRepository(dao dao.CommentDAO, cache cache.CommentCache,
+	userRepo repository.UserRepository, log *zap.Logger) CommentRepository {
+	return &CachedCommentRepository{dao: dao, cache: cache, userRepo: userRepo, log: log}
+}
+
+func (c *CachedCommentRepository) Create(ctx context.Context, comment domain.Comment) (int64, error) {
+	id, err := c.dao.Insert(ctx, c.domainToDao(comment))
+	if err != nil {
+		return 0, err
+	}
+	c.delCache(ctx, comment.Biz, comment.BizId)
+	return id, nil
+}
+
+func (c *CachedCommentRepository) FindById(ctx context.Context, id int64) (domain.Comment, error) {
+	comment, err := c.dao.GetById(ctx, id)
+	if err != nil {
+		return domain.Comment{}, err
+	}
+	return c.daoToDomain(comment), nil
+}
+
+func (c *CachedCommentRepository) ListRoots(ctx context.Context, biz string, bizId int64, maxId int64, limit int) ([]domain.Comment, error) {
+	if maxId == 0 && limit <= FirstPageSize { // 第一页从缓存中取
+		res, err := c.cache.GetFirstPage(ctx, biz, bizId)
+		if err == nil {
+			if len(res) > limit {
+				res = res[:limit]
+			}
+			return res, nil
+		}
+	}
+	size := limit
+	if maxId == 0 && limit < FirstPageSize {
+		size = FirstPageSize // 第一页直接多查一些，用于回写缓存
+	}
+	roots, err := c.dao.GetRoots(ctx, biz, bizId, maxId, size)
+	if err != nil {
+		return nil, err
+	}
+	res, err := c.withPreview(ctx, roots)
+	if err != nil {
+		return nil, err
+	}
+	if maxId == 0 && size == FirstPageSize {
+		if er := c.cache.SetFirstPage(ctx, biz, bizId, res); er != nil {
+			c.log.Warn("set comment first page to cache failed", zap.Error(er))
+		}
+	}
+	if len(res) > limit {
+		res = res[:limit]
+	}
+	return res, nil
+}
+
+// withPreview 批量查询根评论的预览回复并填充评论人昵称
+func (c *CachedCommentRepository) withPreview(ctx context.Context, roots []dao.Comment) ([]domain.Comment, error) {
+	ids := lo.Map(roots, func(item dao.Comment, index int) int64 {
+		return item.ID
+	})
+	replies, err := c.dao.GetRepliesPreview(ctx, ids, domain.ReplyPreviewSize)
+	if err != nil {
+		return nil, err
+	}
+	grouped := lo.GroupBy(replies, func(item dao.Comment) int64 {
+		return item.RootId
+	})
+	res := make([]domain.Comment, 0, len(roots))
+	for _, root := range roots {
+		comment := c.daoToDomain(root)
+		comment.Replies = lo.Map(grouped[root.ID], func(item dao.Comment, index int) domain.Comment {
+			return c.daoToDomain(item)
+		})
+		res = append(res, comment)
+	}
+	c.fillNames(ctx, res)
+	return res, nil
+}
+
+func (c *CachedCommentRepository) ListReplies(ctx context.Context, rootId int64, minId int64, limit int) ([]domain.Comment, error) {
+	replies, err := c.dao.GetReplies(ctx, rootId, minId, limit)
+	if err != nil {
+		return nil, err
+	}
+	res := lo.Map(replies, func(item dao.Comment, index int) domain.Comment {
+		return c.daoToDomain(item)
+	})
+	c.fillNames(ctx, res)
+	return res, nil
+}
+
+func (c *CachedCommentRepository) Delete(ctx context.Context, comment domain.Comment) error {
+	_, err := c.dao.Delete(ctx, c.domainToDao(comment))
+	if err != nil {
+		return err
+	}
+	c.delCache(ctx, comment.Biz, comment.BizId)
+	return nil
+}
+
+func (c *CachedCommentRepository) Count(ctx context.Context, biz string, bizId int64) (int64, error) {
+	count, err := c.cache.GetCount(ctx, biz, bizId)
+	if err == nil {
+		return count, nil
+	}
+	count, err = c.dao.Count(ctx, biz, bizId)
+	if err != nil {
+		return 0, err
+	}
+	if er := c.cache.SetCount(ctx, biz, bizId, count); er != nil {
+		c.log.Warn("set comment count to cache failed", zap.Error(er))
+	}
+	return count, nil
+}
+
+func (c *CachedCommentRepository) delCache(ctx context.Context, biz string, bizId int64) {
+	if err := c.cache.Delete(ctx, biz, bizId); err != nil {
+		c.log.Warn("delete comment cache failed", zap.String("biz", biz), zap.Int64("biz_id", bizId), zap.Error(err))
+	}
+}
+
+// fillNames 填充评论人与被回复人的昵称 获取失败只记录日志
+func (c *CachedCommentRepository) fillNames(ctx context.Context, comments []domain.Comment) {
+	names := make(map[int64]string)
+	name := func(uid int64) string {
+		if uid == 0 {
+			return ""
+		}
+		if n, ok := names[uid]; ok {
+			return n
+		}
+		user, err := c.userRepo.FindById(ctx, uid)
+		if err != nil {
+			c.log.Warn("get commentator failed", zap.Int64("uid", uid), zap.Error(err))
+		}
+		names[uid] = user.Nickname
+		return user.Nickname
+	}
+	var fill func(comments []domain.Comment)
+	fill = func(comments []domain.Comment) {
+		for i := range comments {
+			comments[i].Commentator.Name = name(comments[i].Commentator.ID)
+			comments[i].ReplyTo.Name = name(comments[i].ReplyTo.ID)
+			fill(comments[i].Replies)
+		}
+	}
+	fill(comments)
+}
+
+func (c *CachedCommentRepository) domainToDao(comment domain.Comment) dao.Comment {
+	return dao.Comment{
+		ID:         comment.ID,
+		Biz:        comment.Biz,
+		BizId:      comment.BizId,
+		RootId:     comment.RootId,
+		ParentId:   comment.ParentId,
+		Uid:        comment.Commentator.ID,
+		ReplyToUid: comment.ReplyTo.ID,
+		Content:    comment.Content,
+	}
+}
+
+func (c *CachedCommentRepository) daoToDomain(comment dao.Comment) domain.Comment {
+	return domain.Comment{
+		ID:          comment.ID,
+		Biz:         comment.Biz,
+		BizId:       comment.BizId,
+		Commentator: domain.User{ID: comment.Uid},
+		Content:     comment.Content,
+		RootId:      comment.RootId,
+		ParentId:    comment.ParentId,
+		ReplyTo:     domain.User{ID: comment.ReplyToUid},
+		ReplyCount:  comment.ReplyCount,
+		Ctime:       comment.Ctime,
+		Utime:       comment.Utime,
+	}
+}
@@ -0,0 +1,170 @@
+package dao
+
+import (
+	"context"
+	"github.com/cockroachdb/errors"
+	"gorm.io/gorm"
+	"time"
+)
+
+var (
+	ErrCommentNotFound = gorm.ErrRecordNotFound
+	ErrRootNotFound    = errors.New("根评论不存在")
+)
+
+type Comment struct {
+	ID int64 `gorm:"column:id;primaryKey;autoIncrement;not null"`
+	// 根评论列表按 (biz, biz_id, root_id=0) 过滤后按 id 倒序，索引自带主键
+	Biz      string `gorm:"column:biz;type:varchar(32);not null;index:idx_biz_root,priority:1"`
+	BizId    int64  `gorm:"column:biz_id;not null;index:idx_biz_root,priority:2"`
+	RootId   int64  `gorm:"column:root_id;not null;default:0;index:idx_biz_root,priority:3;index:idx_root"`
+	ParentId int64  `gorm:"column:parent_id;not null;default:0"`
+	Uid      int64  `gorm:"column:uid;not null;index"`
+	// 被回复人 冗余存储，避免展示时再查父评论
+	ReplyToUid int64  `gorm:"column:reply_to_uid;not null;default:0"`
+	Content    string `gorm:"column:content;type:text;not null"`
+	ReplyCount int64  `gorm:"column:reply_count;not null;default:0"` // 只有根评论维护
+	Ctime      int64  `gorm:"column:ctime;not null"`
+	Utime      int64  `gorm:"column:utime;not null"`
+}
+
+type CommentDAO interface {
+	Insert(ctx context.Context, comment Comment) (int64, error)
+	GetById(ctx context.Context, id int64) (Comment, error)
+	// GetRoots 按 id 倒序获取根评论，maxId 为上一页最后一条评论的ID，0 表示第一页
+	GetRoots(ctx context.Context, biz string, bizId int64, maxId int64, limit int) ([]Comment, error)
+	// GetReplies 按 id 正序获取根评论下的回复，minId 为上一页最后一条回复的ID，0 表示第一页
+	GetReplies(ctx context.Context, rootId int64, minId int64, limit int) ([]Comment, error)
+	// GetRepliesPreview 批量获取每条根评论下最早的 n 条回复
+	GetRepliesPreview(ctx context.Context, rootIds []int64, n int) ([]Comment, error)
+	// Delete 删除评论，根评论连同所有回复一起删除，回复连同回复它的评论一起删除，返回删除的数量
+	Delete(ctx context.Context, comment Comment) (int64, error)
+	Count(ctx context.Context, biz string, bizId int64) (int64, error)
+}
+
+type GormCommentDAO struct {
+	db *gorm.DB
+}
+
+func NewGormCommentDAO(db *gorm.DB) CommentDAO {
+	return &GormCommentDAO{db: db}
+}
+
+func (g *GormCommentDAO) Insert(ctx context.Context, comment Comment) (int64, error) {
+	now := time.Now().Unix()
+	comment.Ctime, comment.Utime = now, now
+	if comment.RootId == 0 {
+		err := g.db.WithContext(ctx).Create(&comment).Error
+		return comment.ID, err
+	}
+	err := g.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		updates := tx.Model(&Comment{}).
+			Where("id = ? AND root_id = 0", comment.RootId).
+			Updates(map[string]any{
+				"reply_count": gorm.Expr("reply_count + 1"),
+				"utime":       now,
+			})
+		if updates.Error != nil {
+			return updates.Error
+		}
+		if updates.RowsAffected == 0 { // 根评论已被删除
+			return ErrRootNotFound
+		}
+		return tx.Create(&comment).Error
+	})
+	return comment.ID, err
+}
+
+func (g *GormCommentDAO) GetById(ctx context.Context, id int64) (Comment, error) {
+	var comment Comment
+	err := g.db.WithContext(ctx).Where("id = ?", id).First(&comment).Error
+	return comment, err
+}
+
+func (g *GormCommentDAO) GetRoots(ctx context.Context, biz string, bizId int64, maxId int64, limit int) ([]Comment, error) {
+	var comments []Comment
+	db := g.db.WithContext(ctx).Where("biz = ? AND biz_id = ? AND root_id = 0", biz, bizId)
+	if maxId > 0 {
+		db = db.Where("id < ?", maxId)
+	}
+	err := db.Order("id desc").Limit(limit).Find(&comments).Error
+	return comments, err
+}
+
+func (g *GormCommentDAO) GetReplies(ctx context.Context, rootId int64, minId int64, limit int) ([]Comment, error) {
+	var comments []Comment
+	err := g.db.WithContext(ctx).
+		Where("root_id = ? AND id > ?", rootId, minId).
+		Order("id").
+		Limit(limit).
+		Find(&comments).Error
+	return comments, err
+}
+
+func (g *GormCommentDAO) GetRepliesPreview(ctx context.Context, rootIds []int64, n int) ([]Comment, error) {
+	if len(rootIds) == 0 {
+		return nil, nil
+	}
+	var comments []Comment
+	// 窗口函数需要 MySQL 8.0
+	err := g.db.WithContext(ctx).Raw(`SELECT * FROM (
+	SELECT *, ROW_NUMBER() OVER (PARTITION BY root_id ORDER BY id) AS rn FROM comments WHERE root_id IN ?
+) t WHERE t.rn <= ? ORDER BY t.root_id, t.id`, rootIds, n).
+		Scan(&comments).Error
+	return comments, err
+}
+
+func (g *GormCommentDAO) Delete(ctx context.Context, comment Comment) (int64, error) {
+	var deleted int64
+	err := g.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if comment.RootId == 0 {
+			res := tx.Where("id = ? OR root_id = ?", comment.ID, comment.ID).Delete(&Comment{})
+			deleted = res.RowsAffected
+			return res.Error
+		}
+		ids, err := g.subtree(tx, comment)
+		if err != nil {
+			return err
+		}
+		res := tx.Where("id IN ?", ids).Delete(&Comment{})
+		if res.Error != nil {
+			return res.Error
+		}
+		deleted = res.RowsAffected
+		return tx.Model(&Comment{}).
+			Where("id = ?", comment.RootId).
+			Updates(map[string]any{
+				"reply_count": gorm.Expr("GREATEST(reply_count - ?, 0)", deleted),
+				"utime":       time.Now().Unix(),
+			}).Error
+	})
+	return deleted, err
+}
+
+// subtree 找出回复本身以及直接或间接回复它的评论ID 同一根评论下的回复数量有限，一次查出后在内存中遍历
+func (g *GormCommentDAO) subtree(tx *gorm.DB, comment Comment) ([]int64, error) {
+	var replies []Comment
+	err := tx.Select("id", "parent_id").
+		Where("root_id = ? AND id > ?", comment.RootId, comment.ID). // 回复一定比被回复的评论晚
+		Find(&replies).Error
+	if err != nil {
+		return nil, err
+	}
+	children := make(map[int64][]int64, len(replies))
+	for _, reply := range replies {
+		children[reply.ParentId] = append(children[reply.ParentId], reply.ID)
+	}
+	ids := []int64{comment.ID}
+	for i := 0; i < len(ids); i++ {
+		ids = append(ids, children[ids[i]]...)
+	}
+	return ids, nil
+}
+
+func (g *GormCommentDAO) Count(ctx context.Context, biz string, bizId int64) (int64, error) {
+	var count int64
+	err := g.db.WithContext(ctx).Model(&Comment{}).
+		Where("biz = ? AND biz_id = ?", biz, bizId).
+		Count(&count).Error
+	return count, err
+}
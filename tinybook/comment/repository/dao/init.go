@@ -0,0 +1,10 @@
+package dao
+
+import "gorm.io/gorm"
+
+func CreateTableForComment(db *gorm.DB) {
+	err := db.AutoMigrate(&Comment{})
+	if err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"github.com/cockroachdb/errors"
+	"github.com/samber/lo"
+	"strings"
+	"time"
+	"tinybook/tinybook/comment/domain"
+	"tinybook/tinybook/comment/repository"
+	"unicode/utf8"
+)
+
+var (
+	ErrInvalidContent     = errors.New("评论内容为空或过长")
+	ErrCommentNotFound    = errors.New("评论不存在")
+	ErrBizNotFound        = errors.New("评论对象不存在")
+	ErrNotCommentable     = errors.New("评论对象不允许评论")
+	ErrNoDeletePermission = errors.New("无权删除该评论")
+)
+
+// BizResolver 查询被评论的资源 资源不存在时返回 ErrBizNotFound
+type BizResolver interface {
+	Resolve(ctx context.Context, biz string, bizId int64) (domain.Target, error)
+}
+
+// BizResolverFunc 函数形式的 BizResolver
+type BizResolverFunc func(ctx context.Context, biz string, bizId int64) (domain.Target, error)
+
+func (f BizResolverFunc) Resolve(ctx context.Context, biz string, bizId int64) (domain.Target, error) {
+	return f(ctx, biz, bizId)
+}
+
+type CommentService interface {
+	// Comment 发表评论 ParentId 大于 0 时为回复
+	Comment(ctx context.Context, comment domain.Comment) (int64, error)
+	// ListRoots 根评论列表 cursor 为上一页返回的 NextCursor
+	ListRoots(ctx context.Context, biz string, bizId int64, cursor int64, limit int) (domain.CommentListVo, error)
+	ListReplies(ctx context.Context, rootId int64, cursor int64, limit int) (domain.CommentListVo, error)
+	// Delete 评论人或被评论资源的所有者可以删除评论
+	Delete(ctx context.Context, id int64, uid int64) error
+	Count(ctx context.Context, biz string, bizId int64) (int64, error)
+}
+
+type commentService struct {
+	repo     repository.CommentRepository
+	resolver BizResolver
+}
+
+func NewCommentService(repo repository.CommentRepository, resolver BizResolver) CommentService {
+	return &commentService{repo: repo, resolver: resolver}
+}
+
+func (c *commentService) Comment(ctx context.Context, comment domain.Comment) (int64, error) {
+	comment.Content = strings.TrimSpace(comment.Content)
+	if comment.Content == "" || utf8.RuneCountInString(comment.Content) > domain.MaxCommentLength {
+		return 0, ErrInvalidContent
+	}
+	if comment.ParentId > 0 {
+		parent, err := c.findById(ctx, comment.ParentId)
+		if err != nil {
+			return 0, err
+		}
+		// 回复以父评论为准，不信任前端传的 biz
+		comment.Biz, comment.BizId = parent.Biz, parent.BizId
+		comment.RootId = parent.RootId
+		if parent.RootId == 0 {
+			comment.RootId = parent.ID
+		}
+		comment.ReplyTo = parent.Commentator
+	}
+	target, err := c.resolver.Resolve(ctx, comment.Biz, comment.BizId)
+	if err != nil {
+		return 0, err
+	}
+	if !target.Commentable {
+		return 0, ErrNotCommentable
+	}
+	id, err := c.repo.Create(ctx, comment)
+	if errors.Is(err, repository.ErrRootNotFound) {
+		return 0, ErrCommentNotFound
+	}
+	return id, err
+}
+
+func (c *commentService) ListRoots(ctx context.Context, biz string, bizId int64, cursor int64, limit int) (domain.CommentListVo, error) {
+	// 多取一条用于判断是否还有下一页
+	comments, err := c.repo.ListRoots(ctx, biz, bizId, cursor, limit+1)
+	if err != nil {
+		return domain.CommentListVo{}, err
+	}
+	return c.toListVo(comments, limit), nil
+}
+
+func (c *commentService) ListReplies(ctx context.Context, rootId int64, cursor int64, limit int) (domain.CommentListVo, error) {
+	comments, err := c.repo.ListReplies(ctx, rootId, cursor, limit+1)
+	if err != nil {
+		return domain.CommentListVo{}, err
+	}
+	return c.toListVo(comments, limit), nil
+}
+
+func (c *commentService) Delete(ctx context.Context, id int64, uid int64) error {
+	comment, err := c.findById(ctx, id)
+	if err != nil {
+		return err
+	}
+	if comment.Commentator.ID != uid {
+		target, err := c.resolver.Resolve(ctx, comment.Biz, comment.BizId)
+		if errors.Is(err, ErrBizNotFound) {
+			return ErrNoDeletePermission
+		}
+		if err != nil {
+			return err
+		}
+		if target.OwnerId != uid {
+			return ErrNoDeletePermission
+		}
+	}
+	return c.repo.Delete(ctx, comment)
+}
+
+func (c *commentService) Count(ctx context.Context, biz string, bizId int64) (int64, error) {
+	return c.repo.Count(ctx, biz, bizId)
+}
+
+func (c *commentService) findById(ctx context.Context, id int64) (domain.Comment, error) {
+	comment, err := c.repo.FindById(ctx, id)
+	if errors.Is(err, repository.ErrCommentNotFound) {
+		return domain.Comment{}, ErrCommentNotFound
+	}
+	return comment, err
+}
+
+// toListVo comments 比 limit 多一条时说明还有下一页，游标为本页最后一条评论的ID
+func (c *commentService) toListVo(comments []domain.Comment, limit int) domain.CommentListVo {
+	var res domain.CommentListVo
+	if len(comments) > limit {
+		comments = comments[:limit]
+		res.NextCursor = comments[limit-1].ID
+	}
+	res.Comments = c.toVos(comments)
+	return res
+}
+
+func (c *commentService) toVos(comments []domain.Comment) []domain.CommentVo {
+	return lo.Map(comments, func(comment domain.Comment, index int) domain.CommentVo {
+		return domain.CommentVo{
+			ID:              comment.ID,
+			Biz:             comment.Biz,
+			BizId:           comment.BizId,
+			Commentator:     comment.Commentator.ID,
+			CommentatorName: comment.Commentator.Name,
+			Content:         comment.Content,
+			RootId:          comment.RootId,
+			ParentId:        comment.ParentId,
+			ReplyTo:         comment.ReplyTo.ID,
+			ReplyToName:     comment.ReplyTo.Name,
+			ReplyCount:      comment.ReplyCount,
+			Replies:         c.toVos(comment.Replies),
+			Ctime:           time.Unix(comment.Ctime, 0).Format("2006-01-02 15:04:05"),
+		}
+	})
+}
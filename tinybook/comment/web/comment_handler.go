@@ -0,0 +1,181 @@
+package web
+
+import (
+	"github.com/cockroachdb/errors"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"net/http"
+	"strconv"
+	"tinybook/tinybook/comment/domain"
+	"tinybook/tinybook/comment/service"
+	"tinybook/tinybook/internal/web/jwt"
+)
+
+type CommentHandler struct {
+	commentService service.CommentService
+	l              *zap.Logger
+}
+
+func NewCommentHandler(commentService service.CommentService, l *zap.Logger) *CommentHandler {
+	return &CommentHandler{commentService: commentService, l: l}
+}
+
+// Create 发表评论或回复
+func (h *CommentHandler) Create(ctx *gin.Context) {
+	type Req struct {
+		Biz      string `json:"biz"`
+		BizId    int64  `json:"bizId"`
+		ParentId int64  `json:"parentId"` // 大于 0 时为回复，biz 以被回复的评论为准
+		Content  string `json:"content" binding:"required"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil || (req.ParentId == 0 && (req.Biz == "" || req.BizId <= 0)) {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 400,
+			Msg:  "参数错误",
+		})
+		return
+	}
+	claims := (ctx.MustGet("userClaims")).(jwt.UserClaims)
+	id, err := h.commentService.Comment(ctx, domain.Comment{
+		Biz:         req.Biz,
+		BizId:       req.BizId,
+		ParentId:    req.ParentId,
+		Content:     req.Content,
+		Commentator: domain.User{ID: claims.Uid},
+	})
+	if err != nil {
+		h.handleServiceErr(ctx, err, "发表评论失败, 用户ID: "+strconv.FormatInt(claims.Uid, 10))
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Code: 200,
+		Msg:  "评论成功",
+		Data: id,
+	})
+}
+
+// List 根评论列表 每条根评论带有最早的几条回复
+func (h *CommentHandler) List(ctx *gin.Context) {
+	type Req struct {
+		Biz    string `json:"biz" binding:"required"`
+		BizId  int64  `json:"bizId" binding:"required"`
+		Cursor int64  `json:"cursor"`
+		Limit  int    `json:"limit"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil || !h.checkPage(req.Cursor, &req.Limit) {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 400,
+			Msg:  "参数错误",
+		})
+		return
+	}
+	comments, err := h.commentService.ListRoots(ctx, req.Biz, req.BizId, req.Cursor, req.Limit)
+	if err != nil {
+		h.handleServiceErr(ctx, err, "获取评论列表失败, 资源ID: "+strconv.FormatInt(req.BizId, 10))
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Code: 200,
+		Msg:  "获取成功",
+		Data: comments,
+	})
+}
+
+// Replies 根评论下的回复列表
+func (h *CommentHandler) Replies(ctx *gin.Context) {
+	type Req struct {
+		RootId int64 `json:"rootId" binding:"required"`
+		Cursor int64 `json:"cursor"`
+		Limit  int   `json:"limit"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil || !h.checkPage(req.Cursor, &req.Limit) {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 400,
+			Msg:  "参数错误",
+		})
+		return
+	}
+	comments, err := h.commentService.ListReplies(ctx, req.RootId, req.Cursor, req.Limit)
+	if err != nil {
+		h.handleServiceErr(ctx, err, "获取回复列表失败, 评论ID: "+strconv.FormatInt(req.RootId, 10))
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Code: 200,
+		Msg:  "获取成功",
+		Data: comments,
+	})
+}
+
+// Delete 评论人或资源所有者删除评论
+func (h *CommentHandler) Delete(ctx *gin.Context) {
+	type Req struct {
+		Id int64 `json:"id" binding:"required"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 400,
+			Msg:  "参数错误",
+		})
+		return
+	}
+	claims := (ctx.MustGet("userClaims")).(jwt.UserClaims)
+	err := h.commentService.Delete(ctx, req.Id, claims.Uid)
+	if err != nil {
+		h.handleServiceErr(ctx, err, "删除评论失败, 评论ID: "+strconv.FormatInt(req.Id, 10))
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Code: 200,
+		Msg:  "删除成功",
+		Data: req.Id,
+	})
+}
+
+// checkPage 游标不能为负数 每页默认10条 最多50条
+func (h *CommentHandler) checkPage(cursor int64, limit *int) bool {
+	if cursor < 0 || *limit < 0 || *limit > domain.MaxPageSize {
+		return false
+	}
+	if *limit == 0 {
+		*limit = 10
+	}
+	return true
+}
+
+func (h *CommentHandler) handleServiceErr(ctx *gin.Context, err error, msg string) {
+	switch {
+	case errors.Is(err, service.ErrNoDeletePermission):
+		ctx.JSON(http.StatusOK, Result{
+			Code: 401,
+			Msg:  "无权限",
+		})
+		return
+	case errors.Is(err, service.ErrInvalidContent),
+		errors.Is(err, service.ErrCommentNotFound),
+		errors.Is(err, service.ErrBizNotFound),
+		errors.Is(err, service.ErrNotCommentable):
+		ctx.JSON(http.StatusOK, Result{
+			Code: 400,
+			Msg:  err.Error(),
+		})
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Code: 500,
+		Msg:  "服务器错误",
+	})
+	h.l.Error(msg, zap.Error(err))
+}
+
+func (h *CommentHandler) RegisterRoutes(engine *gin.Engine) {
+	group := engine.Group("/comments")
+	group.POST("/create", h.Create)   // 发表评论或回复
+	group.POST("/list", h.List)       // 根评论列表
+	group.POST("/replies", h.Replies) // 回复列表
+	group.POST("/delete", h.Delete)   // 删除评论
+}
@@ -0,0 +1,24 @@
+package domain
+
+// FeedItem timeline 中的一篇文章 Ctime 为发表时间
+type FeedItem struct {
+	ArticleId int64
+	AuthorId  int64
+	Ctime     int64
+}
+
+// Cursor 按 (ctime, article_id) 倒序翻页的游标 零值表示第一页
+type Cursor struct {
+	Ctime     int64
+	ArticleId int64
+}
+
+func (c Cursor) IsZero() bool {
+	return c.Ctime == 0 && c.ArticleId == 0
+}
+
+// Timeline 一页 feed Next 为零值表示没有下一页
+type Timeline struct {
+	Items []FeedItem
+	Next  Cursor
+}
@@ -0,0 +1,85 @@
+package dao
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// FeedInbox 收件箱 普通作者发表文章时推送给每个粉丝
+type FeedInbox struct {
+	ID        int64 `gorm:"column:id;primaryKey;autoIncrement;not null"`
+	Uid       int64 `gorm:"column:uid;not null;uniqueIndex:uk_uid_article,priority:1;index:idx_uid_ctime,priority:1"`
+	ArticleId int64 `gorm:"column:article_id;not null;uniqueIndex:uk_uid_article,priority:2;index:idx_uid_ctime,priority:3"`
+	AuthorId  int64 `gorm:"column:author_id;not null"`
+	Ctime     int64 `gorm:"column:ctime;not null;index:idx_uid_ctime,priority:2"`
+}
+
+// FeedOutbox 发件箱 所有作者发表的文章，粉丝很多的作者不推送，由读者读取时拉取
+type FeedOutbox struct {
+	ID        int64 `gorm:"column:id;primaryKey;autoIncrement;not null"`
+	AuthorId  int64 `gorm:"column:author_id;not null;uniqueIndex:uk_author_article,priority:1;index:idx_author_ctime,priority:1"`
+	ArticleId int64 `gorm:"column:article_id;not null;uniqueIndex:uk_author_article,priority:2;index:idx_author_ctime,priority:3"`
+	Ctime     int64 `gorm:"column:ctime;not null;index:idx_author_ctime,priority:2"`
+}
+
+// Cursor 按 (ctime, article_id) 倒序翻页 零值表示第一页
+type Cursor struct {
+	Ctime     int64
+	ArticleId int64
+}
+
+type FeedDAO interface {
+	// InsertOutbox 重复发表同一篇文章时保留第一次的发表时间
+	InsertOutbox(ctx context.Context, item FeedOutbox) error
+	BatchInsertInbox(ctx context.Context, items []FeedInbox) error
+	GetInbox(ctx context.Context, uid int64, cursor Cursor, limit int) ([]FeedInbox, error)
+	GetOutbox(ctx context.Context, authorIds []int64, cursor Cursor, limit int) ([]FeedOutbox, error)
+}
+
+type GormFeedDAO struct {
+	db *gorm.DB
+}
+
+func NewGormFeedDAO(db *gorm.DB) FeedDAO {
+	return &GormFeedDAO{db: db}
+}
+
+func (g *GormFeedDAO) InsertOutbox(ctx context.Context, item FeedOutbox) error {
+	return g.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&item).Error
+}
+
+func (g *GormFeedDAO) BatchInsertInbox(ctx context.Context, items []FeedInbox) error {
+	if len(items) == 0 {
+		return nil
+	}
+	return g.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&items).Error
+}
+
+func (g *GormFeedDAO) GetInbox(ctx context.Context, uid int64, cursor Cursor, limit int) ([]FeedInbox, error) {
+	var items []FeedInbox
+	err := g.afterCursor(g.db.WithContext(ctx).Where("uid = ?", uid), cursor).
+		Order("ctime desc, article_id desc").
+		Limit(limit).
+		Find(&items).Error
+	return items, err
+}
+
+func (g *GormFeedDAO) GetOutbox(ctx context.Context, authorIds []int64, cursor Cursor, limit int) ([]FeedOutbox, error) {
+	if len(authorIds) == 0 {
+		return nil, nil
+	}
+	var items []FeedOutbox
+	err := g.afterCursor(g.db.WithContext(ctx).Where("author_id IN ?", authorIds), cursor).
+		Order("ctime desc, article_id desc").
+		Limit(limit).
+		Find(&items).Error
+	return items, err
+}
+
+func (g *GormFeedDAO) afterCursor(db *gorm.DB, cursor Cursor) *gorm.DB {
+	if cursor.Ctime == 0 && cursor.ArticleId == 0 {
+		return db
+	}
+	return db.Where("(ctime < ? OR (ctime = ? AND article_id < ?))", cursor.Ctime, cursor.Ctime, cursor.ArticleId)
+}
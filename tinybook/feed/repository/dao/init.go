@@ -0,0 +1,10 @@
+package dao
+
+import "gorm.io/gorm"
+
+func CreateTableForFeed(db *gorm.DB) {
+	err := db.AutoMigrate(&FeedInbox{}, &FeedOutbox{})
+	if err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"context"
+	"github.com/samber/lo"
+	"tinybook/tinybook/feed/domain"
+	"tinybook/tinybook/feed/repository/dao"
+)
+
+type FeedRepository interface {
+	AddOutbox(ctx context.Context, item domain.FeedItem) error
+	// PushInbox 把同一篇文章推送到多个用户的收件箱
+	PushInbox(ctx context.Context, uids []int64, item domain.FeedItem) error
+	GetInbox(ctx context.Context, uid int64, cursor domain.Cursor, limit int) ([]domain.FeedItem, error)
+	GetOutbox(ctx context.Context, authorIds []int64, cursor domain.Cursor, limit int) ([]domain.FeedItem, error)
+}
+
+type feedRepository struct {
+	dao dao.FeedDAO
+}
+
+func NewFeedRepository(dao dao.FeedDAO) FeedRepository {
+	return &feedRepository{dao: dao}
+}
+
+func (f *feedRepository) AddOutbox(ctx context.Context, item domain.FeedItem) error {
+	return f.dao.InsertOutbox(ctx, dao.FeedOutbox{
+		AuthorId:  item.AuthorId,
+		ArticleId: item.ArticleId,
+		Ctime:     item.Ctime,
+	})
+}
+
+func (f *feedRepository) PushInbox(ctx context.Context, uids []int64, item domain.FeedItem) error {
+	return f.dao.BatchInsertInbox(ctx, lo.Map(uids, func(uid int64, index int) dao.FeedInbox {
+		return dao.FeedInbox{
+			Uid:       uid,
+			ArticleId: item.ArticleId,
+			AuthorId:  item.AuthorId,
+			Ctime:     item.Ctime,
+		}
+	}))
+}
+
+func (f *feedRepository) GetInbox(ctx context.Context, uid int64, cursor domain.Cursor, limit int) ([]domain.FeedItem, error) {
+	items, err := f.dao.GetInbox(ctx, uid, dao.Cursor(cursor), limit)
+	return lo.Map(items, func(item dao.FeedInbox, index int) domain.FeedItem {
+		return domain.FeedItem{ArticleId: item.ArticleId, AuthorId: item.AuthorId, Ctime: item.Ctime}
+	}), err
+}
+
+func (f *feedRepository) GetOutbox(ctx context.Context, authorIds []int64, cursor domain.Cursor, limit int) ([]domain.FeedItem, error) {
+	items, err := f.dao.GetOutbox(ctx, authorIds, dao.Cursor(cursor), limit)
+	return lo.Map(items, func(item dao.FeedOutbox, index int) domain.FeedItem {
+		return domain.FeedItem{ArticleId: item.ArticleId, AuthorId: item.AuthorId, Ctime: item.Ctime}
+	}), err
+}
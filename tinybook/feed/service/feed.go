@@ -0,0 +1,146 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"go.uber.org/zap"
+	"slices"
+	"time"
+	domain2 "tinybook/tinybook/article/domain"
+	"tinybook/tinybook/feed/domain"
+	"tinybook/tinybook/feed/repository"
+	"tinybook/tinybook/follow/service"
+)
+
+// pushBatchSize 推送时每批读取的粉丝数
+const pushBatchSize = 500
+
+// FeedService 推拉结合的 feed
+// 发表时文章总是写入作者的发件箱；粉丝数不超过 pushThreshold 的作者同时推送到每个粉丝的收件箱，
+// 粉丝更多的作者不推送，读者读取时从这些作者的发件箱拉取，再与收件箱合并
+type FeedService interface {
+	// OnPublish 实现 repository.PublishListener
+	OnPublish(ctx context.Context, article domain2.Article)
+	// Timeline 用户关注的作者发表的文章 按发表时间倒序
+	Timeline(ctx context.Context, uid int64, cursor domain.Cursor, limit int) (domain.Timeline, error)
+}
+
+type feedService struct {
+	repo          repository.FeedRepository
+	followSvc     service.FollowService
+	pushThreshold int64
+	log           *zap.Logger
+}
+
+func NewFeedService(repo repository.FeedRepository, followSvc service.FollowService, pushThreshold int64, log *zap.Logger) FeedService {
+	return &feedService{repo: repo, followSvc: followSvc, pushThreshold: pushThreshold, log: log}
+}
+
+func (f *feedService) OnPublish(ctx context.Context, article domain2.Article) {
+	item := domain.FeedItem{
+		ArticleId: article.ID,
+		AuthorId:  article.Author.ID,
+		Ctime:     time.Now().Unix(),
+	}
+	log := f.log.With(zap.Int64("article_id", item.ArticleId), zap.Int64("author_id", item.AuthorId))
+	if err := f.repo.AddOutbox(ctx, item); err != nil {
+		log.Error("add article to feed outbox failed", zap.Error(err))
+		return
+	}
+	followers, err := f.followSvc.FollowerCount(ctx, item.AuthorId)
+	if err != nil {
+		log.Error("get follower count failed", zap.Error(err))
+		return
+	}
+	if followers > f.pushThreshold { // 粉丝太多，读取时拉取
+		return
+	}
+	var cursor int64
+	for {
+		uids, next, err := f.followSvc.FollowerIds(ctx, item.AuthorId, cursor, pushBatchSize)
+		if err != nil {
+			log.Error("get followers failed", zap.Error(err))
+			return
+		}
+		if err = f.repo.PushInbox(ctx, uids, item); err != nil {
+			log.Error("push article to feed inbox failed", zap.Error(err))
+			return
+		}
+		if next == 0 {
+			return
+		}
+		cursor = next
+	}
+}
+
+func (f *feedService) Timeline(ctx context.Context, uid int64, cursor domain.Cursor, limit int) (domain.Timeline, error) {
+	followees, err := f.followSvc.FolloweeIds(ctx, uid)
+	if err != nil {
+		return domain.Timeline{}, err
+	}
+	if len(followees) == 0 {
+		return domain.Timeline{}, nil
+	}
+	counts, err := f.followSvc.FollowerCounts(ctx, followees)
+	if err != nil {
+		return domain.Timeline{}, err
+	}
+	var pulled []int64 // 需要拉取的作者
+	for _, id := range followees {
+		if counts[id] > f.pushThreshold {
+			pulled = append(pulled, id)
+		}
+	}
+	// 两边都多取一条用于判断是否还有下一页
+	items, err := f.repo.GetInbox(ctx, uid, cursor, limit+1)
+	if err != nil {
+		return domain.Timeline{}, err
+	}
+	if len(pulled) > 0 {
+		outbox, err := f.repo.GetOutbox(ctx, pulled, cursor, limit+1)
+		if err != nil {
+			return domain.Timeline{}, err
+		}
+		items = append(items, outbox...)
+	}
+	items = f.merge(items)
+
+	var res domain.Timeline
+	if len(items) > limit {
+		items = items[:limit]
+		last := items[limit-1]
+		res.Next = domain.Cursor{Ctime: last.Ctime, ArticleId: last.ArticleId}
+	}
+	// 收件箱中可能还有已取消关注的作者的文章
+	following := make(map[int64]struct{}, len(followees))
+	for _, id := range followees {
+		following[id] = struct{}{}
+	}
+	for _, item := range items {
+		if _, ok := following[item.AuthorId]; ok {
+			res.Items = append(res.Items, item)
+		}
+	}
+	return res, nil
+}
+
+// merge 按 (ctime, article_id) 倒序排列并去重
+// 作者粉丝数超过阈值前推送过的文章，既在收件箱中也会从发件箱拉取到
+func (f *feedService) merge(items []domain.FeedItem) []domain.FeedItem {
+	slices.SortFunc(items, func(a, b domain.FeedItem) int {
+		if a.Ctime != b.Ctime {
+			return cmp.Compare(b.Ctime, a.Ctime)
+		}
+		return cmp.Compare(b.ArticleId, a.ArticleId)
+	})
+	seen := make(map[int64]struct{}, len(items))
+	res := items[:0]
+	for _, item := range items {
+		if _, ok := seen[item.ArticleId]; ok {
+			continue
+		}
+		seen[item.ArticleId] = struct{}{}
+		res = append(res, item)
+	}
+	return res
+}
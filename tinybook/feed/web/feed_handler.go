@@ -0,0 +1,88 @@
+package web
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/samber/lo"
+	"go.uber.org/zap"
+	"net/http"
+	"strconv"
+	domain2 "tinybook/tinybook/article/domain"
+	service2 "tinybook/tinybook/article/service"
+	"tinybook/tinybook/feed/domain"
+	"tinybook/tinybook/feed/service"
+	"tinybook/tinybook/internal/web/jwt"
+)
+
+type FeedHandler struct {
+	feedService    service.FeedService
+	articleService service2.ArticleService
+	l              *zap.Logger
+}
+
+func NewFeedHandler(feedService service.FeedService, articleService service2.ArticleService, l *zap.Logger) *FeedHandler {
+	return &FeedHandler{feedService: feedService, articleService: articleService, l: l}
+}
+
+// Timeline 关注的作者发表的文章
+func (h *FeedHandler) Timeline(ctx *gin.Context) {
+	type Req struct {
+		Cursor string `json:"cursor"`
+		Limit  int    `json:"limit"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil || req.Limit < 0 || req.Limit > 100 {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 400,
+			Msg:  "参数错误",
+		})
+		return
+	}
+	if req.Limit == 0 {
+		req.Limit = 10
+	}
+	// 游标与文章列表使用同一种编码
+	c, err := domain2.ParseCursor(req.Cursor)
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 400,
+			Msg:  "参数错误",
+		})
+		return
+	}
+	claims := (ctx.MustGet("userClaims")).(jwt.UserClaims)
+	timeline, err := h.feedService.Timeline(ctx, claims.Uid, domain.Cursor{Ctime: c.Utime, ArticleId: c.ID}, req.Limit)
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 500,
+			Msg:  "服务器错误",
+		})
+		h.l.Error("获取feed失败, 用户ID: "+strconv.FormatInt(claims.Uid, 10), zap.Error(err))
+		return
+	}
+	// 已撤回或删除的文章不会返回，因此一页的数量可能少于 limit
+	articles, err := h.articleService.ListPubByIds(ctx, lo.Map(timeline.Items, func(item domain.FeedItem, index int) int64 {
+		return item.ArticleId
+	}))
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 500,
+			Msg:  "服务器错误",
+		})
+		h.l.Error("获取feed文章失败, 用户ID: "+strconv.FormatInt(claims.Uid, 10), zap.Error(err))
+		return
+	}
+	res := domain2.ArticleListVo{Articles: articles}
+	if !timeline.Next.IsZero() {
+		res.NextCursor = domain2.Cursor{Utime: timeline.Next.Ctime, ID: timeline.Next.ArticleId}.Encode()
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Code: 200,
+		Msg:  "获取成功",
+		Data: res,
+	})
+}
+
+func (h *FeedHandler) RegisterRoutes(engine *gin.Engine) {
+	group := engine.Group("/feed")
+	group.POST("/timeline", h.Timeline) // 关注的作者发表的文章
+}
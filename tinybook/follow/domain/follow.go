@@ -0,0 +1,38 @@
+package domain
+
+// MaxFollowees 每个用户最多能关注的人数
+const MaxFollowees = 2000
+
+// FollowRelation 关注关系 Follower 关注了 Followee
+type FollowRelation struct {
+	ID       int64
+	Follower int64
+	Followee int64
+	Ctime    int64
+}
+
+// FollowStatics 用户的粉丝数与关注数
+type FollowStatics struct {
+	Uid       int64
+	Followers int64
+	Followees int64
+}
+
+type FollowStaticsVo struct {
+	Uid       int64 `json:"uid"`
+	Followers int64 `json:"followers"`
+	Followees int64 `json:"followees"`
+	Followed  bool  `json:"followed"` // 当前用户是否已关注
+}
+
+type FollowUserVo struct {
+	Uid      int64  `json:"uid"`
+	Nickname string `json:"nickname,omitempty"`
+	Ctime    string `json:"ctime,omitempty"` // 关注时间
+}
+
+// FollowListVo 粉丝或关注列表 NextCursor 为 0 表示没有下一页
+type FollowListVo struct {
+	Users      []FollowUserVo `json:"users"`
+	NextCursor int64          `json:"nextCursor,omitempty"`
+}
@@ -0,0 +1,66 @@
+package cache
+
+import (
+	"context"
+	"github.com/cockroachdb/errors"
+	"github.com/redis/go-redis/v9"
+	"strconv"
+	"time"
+	"tinybook/tinybook/follow/domain"
+)
+
+var ErrKeyNotExist = errors.New("缓存不存在")
+
+const (
+	fieldFollowers = "followers"
+	fieldFollowees = "followees"
+)
+
+type FollowCache interface {
+	GetStatics(ctx context.Context, uid int64) (domain.FollowStatics, error)
+	SetStatics(ctx context.Context, statics domain.FollowStatics) error
+	DelStatics(ctx context.Context, uids ...int64) error
+}
+
+type RedisFollowCache struct {
+	cli redis.Cmdable
+}
+
+func NewRedisFollowCache(cli redis.Cmdable) FollowCache {
+	return &RedisFollowCache{cli: cli}
+}
+
+func (r *RedisFollowCache) GetStatics(ctx context.Context, uid int64) (domain.FollowStatics, error) {
+	res, err := r.cli.HGetAll(ctx, r.key(uid)).Result()
+	if err != nil {
+		return domain.FollowStatics{}, err
+	}
+	if len(res) == 0 {
+		return domain.FollowStatics{}, ErrKeyNotExist
+	}
+	statics := domain.FollowStatics{Uid: uid}
+	statics.Followers, _ = strconv.ParseInt(res[fieldFollowers], 10, 64)
+	statics.Followees, _ = strconv.ParseInt(res[fieldFollowees], 10, 64)
+	return statics, nil
+}
+
+func (r *RedisFollowCache) SetStatics(ctx context.Context, statics domain.FollowStatics) error {
+	key := r.key(statics.Uid)
+	pipeline := r.cli.TxPipeline()
+	pipeline.HSet(ctx, key, fieldFollowers, statics.Followers, fieldFollowees, statics.Followees)
+	pipeline.Expire(ctx, key, 30*time.Minute)
+	_, err := pipeline.Exec(ctx)
+	return err
+}
+
+func (r *RedisFollowCache) DelStatics(ctx context.Context, uids ...int64) error {
+	keys := make([]string, 0, len(uids))
+	for _, uid := range uids {
+		keys = append(keys, r.key(uid))
+	}
+	return r.cli.Del(ctx, keys...).Err()
+}
+
+func (r *RedisFollowCache) key(uid int64) string {
+	return "follow:statics:" + strconv.FormatInt(uid, 10)
+}
@@ -0,0 +1,211 @@
+package dao
+
+import (
+	"context"
+	"github.com/cockroachdb/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+const (
+	FollowStatusUnknown  uint8 = iota
+	FollowStatusActive         // 关注中
+	FollowStatusInactive       // 已取消关注
+)
+
+var ErrTooManyFollowees = errors.New("关注数已达上限")
+
+type FollowRelation struct {
+	ID       int64 `gorm:"column:id;primaryKey;autoIncrement;not null"`
+	Follower int64 `gorm:"column:follower;not null;uniqueIndex:uk_follower_followee,priority:1"`
+	// 查询粉丝列表用
+	Followee int64 `gorm:"column:followee;not null;uniqueIndex:uk_follower_followee,priority:2;index"`
+	Status   uint8 `gorm:"column:status;type:tinyint(1);not null"`
+	Ctime    int64 `gorm:"column:ctime;not null"`
+	Utime    int64 `gorm:"column:utime;not null"`
+}
+
+// FollowStatics 粉丝数与关注数 与关注关系在同一个事务中维护
+type FollowStatics struct {
+	ID        int64 `gorm:"column:id;primaryKey;autoIncrement;not null"`
+	Uid       int64 `gorm:"column:uid;not null;uniqueIndex"`
+	Followers int64 `gorm:"column:followers;not null;default:0"`
+	Followees int64 `gorm:"column:followees;not null;default:0"`
+	Ctime     int64 `gorm:"column:ctime;not null"`
+	Utime     int64 `gorm:"column:utime;not null"`
+}
+
+type FollowDAO interface {
+	// Follow 关注 已关注时不做任何修改，返回是否新增了关注
+	// 关注数已经达到 maxFollowees 时返回 ErrTooManyFollowees
+	Follow(ctx context.Context, follower int64, followee int64, maxFollowees int64) (bool, error)
+	// Unfollow 取消关注 未关注时不做任何修改，返回是否取消了关注
+	Unfollow(ctx context.Context, follower int64, followee int64) (bool, error)
+	GetRelation(ctx context.Context, follower int64, followee int64) (FollowRelation, error)
+	// GetFollowees 按关系ID倒序获取关注列表 maxId 为 0 表示第一页
+	GetFollowees(ctx context.Context, follower int64, maxId int64, limit int) ([]FollowRelation, error)
+	// GetFollowers 按关系ID倒序获取粉丝列表 maxId 为 0 表示第一页
+	GetFollowers(ctx context.Context, followee int64, maxId int64, limit int) ([]FollowRelation, error)
+	GetStatics(ctx context.Context, uid int64) (FollowStatics, error)
+	GetStaticsByUids(ctx context.Context, uids []int64) ([]FollowStatics, error)
+}
+
+type GormFollowDAO struct {
+	db *gorm.DB
+}
+
+func NewGormFollowDAO(db *gorm.DB) FollowDAO {
+	return &GormFollowDAO{db: db}
+}
+
+func (g *GormFollowDAO) Follow(ctx context.Context, follower int64, followee int64, maxFollowees int64) (bool, error) {
+	changed := false
+	err := g.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now().Unix()
+		var relation FollowRelation
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("follower = ? AND followee = ?", follower, followee).
+			First(&relation).Error
+		if err == nil && relation.Status == FollowStatusActive {
+			return nil
+		}
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		if er := g.checkFollowees(tx, follower, maxFollowees); er != nil {
+			return er
+		}
+		switch {
+		case err == nil:
+			err = tx.Model(&FollowRelation{}).Where("id = ?", relation.ID).
+				Updates(map[string]any{
+					"status": FollowStatusActive,
+					"ctime":  now, // 重新关注时以最新的关注时间为准
+					"utime":  now,
+				}).Error
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			err = tx.Create(&FollowRelation{
+				Follower: follower,
+				Followee: followee,
+				Status:   FollowStatusActive,
+				Ctime:    now,
+				Utime:    now,
+			}).Error
+		}
+		if err != nil {
+			return err
+		}
+		changed = true
+		return g.incrStatics(tx, follower, followee, 1, now)
+	})
+	return changed, err
+}
+
+func (g *GormFollowDAO) Unfollow(ctx context.Context, follower int64, followee int64) (bool, error) {
+	changed := false
+	err := g.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now().Unix()
+		updates := tx.Model(&FollowRelation{}).
+			Where("follower = ? AND followee = ? AND status = ?", follower, followee, FollowStatusActive).
+			Updates(map[string]any{
+				"status": FollowStatusInactive,
+				"utime":  now,
+			})
+		if updates.Error != nil || updates.RowsAffected == 0 {
+			return updates.Error
+		}
+		changed = true
+		return g.incrStatics(tx, follower, followee, -1, now)
+	})
+	return changed, err
+}
+
+// checkFollowees 锁住关注者的统计数据后检查关注数 并发关注时也不会超过上限，需要在事务中调用
+func (g *GormFollowDAO) checkFollowees(tx *gorm.DB, follower int64, maxFollowees int64) error {
+	var statics FollowStatics
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("uid = ?", follower).
+		First(&statics).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if statics.Followees >= maxFollowees {
+		return ErrTooManyFollowees
+	}
+	return nil
+}
+
+// incrStatics 修改关注者的关注数与被关注者的粉丝数，需要在事务中调用
+func (g *GormFollowDAO) incrStatics(tx *gorm.DB, follower int64, followee int64, delta int64, now int64) error {
+	err := g.upsertStatics(tx, follower, "followees", delta, now)
+	if err != nil {
+		return err
+	}
+	return g.upsertStatics(tx, followee, "followers", delta, now)
+}
+
+func (g *GormFollowDAO) upsertStatics(tx *gorm.DB, uid int64, column string, delta int64, now int64) error {
+	statics := FollowStatics{Uid: uid, Ctime: now, Utime: now}
+	if delta > 0 {
+		if column == "followers" {
+			statics.Followers = delta
+		} else {
+			statics.Followees = delta
+		}
+	}
+	return tx.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "uid"}},
+		DoUpdates: clause.Assignments(map[string]any{
+			column:  gorm.Expr("GREATEST(? + ?, 0)", clause.Column{Name: column}, delta),
+			"utime": now,
+		}),
+	}).Create(&statics).Error
+}
+
+func (g *GormFollowDAO) GetRelation(ctx context.Context, follower int64, followee int64) (FollowRelation, error) {
+	var relation FollowRelation
+	err := g.db.WithContext(ctx).
+		Where("follower = ? AND followee = ? AND status = ?", follower, followee, FollowStatusActive).
+		First(&relation).Error
+	return relation, err
+}
+
+func (g *GormFollowDAO) GetFollowees(ctx context.Context, follower int64, maxId int64, limit int) ([]FollowRelation, error) {
+	return g.list(ctx, g.db.WithContext(ctx).Where("follower = ? AND status = ?", follower, FollowStatusActive), maxId, limit)
+}
+
+func (g *GormFollowDAO) GetFollowers(ctx context.Context, followee int64, maxId int64, limit int) ([]FollowRelation, error) {
+	return g.list(ctx, g.db.WithContext(ctx).Where("followee = ? AND status = ?", followee, FollowStatusActive), maxId, limit)
+}
+
+func (g *GormFollowDAO) list(ctx context.Context, db *gorm.DB, maxId int64, limit int) ([]FollowRelation, error) {
+	if maxId > 0 {
+		db = db.Where("id < ?", maxId)
+	}
+	var relations []FollowRelation
+	err := db.Order("id desc").Limit(limit).Find(&relations).Error
+	return relations, err
+}
+
+// GetStatics 没有关注过别人也没有粉丝的用户没有记录，返回零值
+func (g *GormFollowDAO) GetStatics(ctx context.Context, uid int64) (FollowStatics, error) {
+	var statics FollowStatics
+	err := g.db.WithContext(ctx).Where("uid = ?", uid).First(&statics).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return FollowStatics{Uid: uid}, nil
+	}
+	return statics, err
+}
+
+func (g *GormFollowDAO) GetStaticsByUids(ctx context.Context, uids []int64) ([]FollowStatics, error) {
+	if len(uids) == 0 {
+		return nil, nil
+	}
+	var statics []FollowStatics
+	err := g.db.WithContext(ctx).Where("uid IN ?", uids).Find(&statics).Error
+	return statics, err
+}
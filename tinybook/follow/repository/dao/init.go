@@ -0,0 +1,10 @@
+package dao
+
+import "gorm.io/gorm"
+
+func CreateTableForFollow(db *gorm.DB) {
+	err := db.AutoMigrate(&FollowRelation{}, &FollowStatics{})
+	if err != nil {
+		panic(err)
+	}
+}
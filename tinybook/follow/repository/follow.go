@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"context"
+	"github.com/cockroachdb/errors"
+	"github.com/samber/lo"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"tinybook/tinybook/follow/domain"
+	"tinybook/tinybook/follow/repository/cache"
+	"tinybook/tinybook/follow/repository/dao"
+)
+
+var ErrTooManyFollowees = dao.ErrTooManyFollowees
+
+type FollowRepository interface {
+	Follow(ctx context.Context, follower int64, followee int64) error
+	Unfollow(ctx context.Context, follower int64, followee int64) error
+	IsFollowing(ctx context.Context, follower int64, followee int64) (bool, error)
+	GetFollowees(ctx context.Context, uid int64, maxId int64, limit int) ([]domain.FollowRelation, error)
+	GetFollowers(ctx context.Context, uid int64, maxId int64, limit int) ([]domain.FollowRelation, error)
+	GetStatics(ctx context.Context, uid int64) (domain.FollowStatics, error)
+	// GetStaticsByUids 批量查询 直接查数据库，没有记录的用户不在结果中
+	GetStaticsByUids(ctx context.Context, uids []int64) (map[int64]domain.FollowStatics, error)
+}
+
+type CachedFollowRepository struct {
+	dao   dao.FollowDAO
+	cache cache.FollowCache
+	log   *zap.Logger
+}
+
+func NewCachedFollowRepository(dao dao.FollowDAO, cache cache.FollowCache, log *zap.Logger) FollowRepository {
+	return &CachedFollowRepository{dao: dao, cache: cache, log: log}
+}
+
+func (c *CachedFollowRepository) Follow(ctx context.Context, follower int64, followee int64) error {
+	changed, err := c.dao.Follow(ctx, follower, followee, domain.MaxFollowees)
+	if err != nil || !changed {
+		return err
+	}
+	c.delStatics(ctx, follower, followee)
+	return nil
+}
+
+func (c *CachedFollowRepository) Unfollow(ctx context.Context, follower int64, followee int64) error {
+	changed, err := c.dao.Unfollow(ctx, follower, followee)
+	if err != nil || !changed {
+		return err
+	}
+	c.delStatics(ctx, follower, followee)
+	return nil
+}
+
+func (c *CachedFollowRepository) IsFollowing(ctx context.Context, follower int64, followee int64) (bool, error) {
+	_, err := c.dao.GetRelation(ctx, follower, followee)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (c *CachedFollowRepository) GetFollowees(ctx context.Context, uid int64, maxId int64, limit int) ([]domain.FollowRelation, error) {
+	relations, err := c.dao.GetFollowees(ctx, uid, maxId, limit)
+	return lo.Map(relations, c.toDomain), err
+}
+
+func (c *CachedFollowRepository) GetFollowers(ctx context.Context, uid int64, maxId int64, limit int) ([]domain.FollowRelation, error) {
+	relations, err := c.dao.GetFollowers(ctx, uid, maxId, limit)
+	return lo.Map(relations, c.toDomain), err
+}
+
+func (c *CachedFollowRepository) GetStatics(ctx context.Context, uid int64) (domain.FollowStatics, error) {
+	statics, err := c.cache.GetStatics(ctx, uid)
+	if err == nil {
+		return statics, nil
+	}
+	if !errors.Is(err, cache.ErrKeyNotExist) {
+		c.log.Warn("get follow statics from cache failed", zap.Int64("uid", uid), zap.Error(err))
+	}
+	res, err := c.dao.GetStatics(ctx, uid)
+	if err != nil {
+		return domain.FollowStatics{}, err
+	}
+	statics = c.staticsToDomain(res)
+	if er := c.cache.SetStatics(ctx, statics); er != nil {
+		c.log.Warn("set follow statics to cache failed", zap.Int64("uid", uid), zap.Error(er))
+	}
+	return statics, nil
+}
+
+func (c *CachedFollowRepository) GetStaticsByUids(ctx context.Context, uids []int64) (map[int64]domain.FollowStatics, error) {
+	statics, err := c.dao.GetStaticsByUids(ctx, uids)
+	if err != nil {
+		return nil, err
+	}
+	res := make(map[int64]domain.FollowStatics, len(statics))
+	for _, s := range statics {
+		res[s.Uid] = c.staticsToDomain(s)
+	}
+	return res, nil
+}
+
+func (c *CachedFollowRepository) delStatics(ctx context.Context, uids ...int64) {
+	if err := c.cache.DelStatics(ctx, uids...); err != nil {
+		c.log.Warn("delete follow statics from cache failed", zap.Int64s("uids", uids), zap.Error(err))
+	}
+}
+
+func (c *CachedFollowRepository) toDomain(relation dao.FollowRelation, _ int) domain.FollowRelation {
+	return domain.FollowRelation{
+		ID:       relation.ID,
+		Follower: relation.Follower,
+		Followee: relation.Followee,
+		Ctime:    relation.Ctime,
+	}
+}
+
+func (c *CachedFollowRepository) staticsToDomain(statics dao.FollowStatics) domain.FollowStatics {
+	return domain.FollowStatics{
+		Uid:       statics.Uid,
+		Followers: statics.Followers,
+		Followees: statics.Followees,
+	}
+}
@@ -0,0 +1,168 @@
+package service
+
+import (
+	"context"
+	"github.com/cockroachdb/errors"
+	"github.com/samber/lo"
+	"go.uber.org/zap"
+	"time"
+	"tinybook/tinybook/follow/domain"
+	"tinybook/tinybook/follow/repository"
+	repository2 "tinybook/tinybook/internal/repository"
+)
+
+// MaxFollowees 组装 feed 时最多读取的关注数 与关注时的上限一致，能读到全部关注的人
+const MaxFollowees = domain.MaxFollowees
+
+var (
+	ErrFollowSelf       = errors.New("不能关注自己")
+	ErrUserNotFound     = errors.New("用户不存在")
+	ErrTooManyFollowees = repository.ErrTooManyFollowees
+)
+
+type FollowService interface {
+	Follow(ctx context.Context, follower int64, followee int64) error
+	Unfollow(ctx context.Context, follower int64, followee int64) error
+	// Followees 关注列表 cursor 为上一页返回的 NextCursor
+	Followees(ctx context.Context, uid int64, cursor int64, limit int) (domain.FollowListVo, error)
+	Followers(ctx context.Context, uid int64, cursor int64, limit int) (domain.FollowListVo, error)
+	// Statics 用户的粉丝数、关注数，以及 viewer 是否已关注该用户
+	Statics(ctx context.Context, uid int64, viewer int64) (domain.FollowStaticsVo, error)
+
+	// 以下供 feed 使用
+
+	// FolloweeIds 用户关注的人 最多 MaxFollowees 个
+	FolloweeIds(ctx context.Context, uid int64) ([]int64, error)
+	// FollowerIds 分批获取粉丝 返回下一批的游标，为 0 表示没有了
+	FollowerIds(ctx context.Context, uid int64, cursor int64, limit int) ([]int64, int64, error)
+	FollowerCount(ctx context.Context, uid int64) (int64, error)
+	FollowerCounts(ctx context.Context, uids []int64) (map[int64]int64, error)
+}
+
+type followService struct {
+	repo     repository.FollowRepository
+	userRepo repository2.UserRepository
+	log      *zap.Logger
+}
+
+func NewFollowService(repo repository.FollowRepository, userRepo repository2.UserRepository, log *zap.Logger) FollowService {
+	return &followService{repo: repo, userRepo: userRepo, log: log}
+}
+
+func (f *followService) Follow(ctx context.Context, follower int64, followee int64) error {
+	if follower == followee {
+		return ErrFollowSelf
+	}
+	_, err := f.userRepo.FindById(ctx, followee)
+	if err != nil {
+		if err.Error() == repository2.ErrUserNotFound {
+			return ErrUserNotFound
+		}
+		return err
+	}
+	return f.repo.Follow(ctx, follower, followee)
+}
+
+func (f *followService) Unfollow(ctx context.Context, follower int64, followee int64) error {
+	return f.repo.Unfollow(ctx, follower, followee)
+}
+
+func (f *followService) Followees(ctx context.Context, uid int64, cursor int64, limit int) (domain.FollowListVo, error) {
+	relations, err := f.repo.GetFollowees(ctx, uid, cursor, limit+1)
+	if err != nil {
+		return domain.FollowListVo{}, err
+	}
+	return f.toListVo(ctx, relations, limit, func(r domain.FollowRelation) int64 {
+		return r.Followee
+	}), nil
+}
+
+func (f *followService) Followers(ctx context.Context, uid int64, cursor int64, limit int) (domain.FollowListVo, error) {
+	relations, err := f.repo.GetFollowers(ctx, uid, cursor, limit+1)
+	if err != nil {
+		return domain.FollowListVo{}, err
+	}
+	return f.toListVo(ctx, relations, limit, func(r domain.FollowRelation) int64 {
+		return r.Follower
+	}), nil
+}
+
+func (f *followService) Statics(ctx context.Context, uid int64, viewer int64) (domain.FollowStaticsVo, error) {
+	statics, err := f.repo.GetStatics(ctx, uid)
+	if err != nil {
+		return domain.FollowStaticsVo{}, err
+	}
+	res := domain.FollowStaticsVo{
+		Uid:       uid,
+		Followers: statics.Followers,
+		Followees: statics.Followees,
+	}
+	if viewer > 0 && viewer != uid {
+		res.Followed, err = f.repo.IsFollowing(ctx, viewer, uid)
+	}
+	return res, err
+}
+
+func (f *followService) FolloweeIds(ctx context.Context, uid int64) ([]int64, error) {
+	relations, err := f.repo.GetFollowees(ctx, uid, 0, MaxFollowees)
+	if err != nil {
+		return nil, err
+	}
+	return lo.Map(relations, func(r domain.FollowRelation, index int) int64 {
+		return r.Followee
+	}), nil
+}
+
+func (f *followService) FollowerIds(ctx context.Context, uid int64, cursor int64, limit int) ([]int64, int64, error) {
+	relations, err := f.repo.GetFollowers(ctx, uid, cursor, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	var next int64
+	if len(relations) == limit {
+		next = relations[len(relations)-1].ID
+	}
+	return lo.Map(relations, func(r domain.FollowRelation, index int) int64 {
+		return r.Follower
+	}), next, nil
+}
+
+func (f *followService) FollowerCount(ctx context.Context, uid int64) (int64, error) {
+	statics, err := f.repo.GetStatics(ctx, uid)
+	return statics.Followers, err
+}
+
+func (f *followService) FollowerCounts(ctx context.Context, uids []int64) (map[int64]int64, error) {
+	statics, err := f.repo.GetStaticsByUids(ctx, uids)
+	if err != nil {
+		return nil, err
+	}
+	return lo.MapValues(statics, func(s domain.FollowStatics, uid int64) int64 {
+		return s.Followers
+	}), nil
+}
+
+// toListVo relations 比 limit 多一条时说明还有下一页 uidOf 取出列表中要展示的用户
+func (f *followService) toListVo(ctx context.Context, relations []domain.FollowRelation, limit int,
+	uidOf func(r domain.FollowRelation) int64) domain.FollowListVo {
+	var res domain.FollowListVo
+	if len(relations) > limit {
+		relations = relations[:limit]
+		res.NextCursor = relations[limit-1].ID
+	}
+	res.Users = lo.Map(relations, func(r domain.FollowRelation, index int) domain.FollowUserVo {
+		uid := uidOf(r)
+		vo := domain.FollowUserVo{
+			Uid:   uid,
+			Ctime: time.Unix(r.Ctime, 0).Format("2006-01-02 15:04:05"),
+		}
+		user, err := f.userRepo.FindById(ctx, uid)
+		if err != nil {
+			f.log.Warn("get follow user failed", zap.Int64("uid", uid), zap.Error(err))
+			return vo
+		}
+		vo.Nickname = user.Nickname
+		return vo
+	})
+	return res
+}
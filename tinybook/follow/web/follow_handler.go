@@ -0,0 +1,179 @@
+package web
+
+import (
+	"github.com/cockroachdb/errors"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"net/http"
+	"strconv"
+	"tinybook/tinybook/follow/service"
+	"tinybook/tinybook/internal/web/jwt"
+)
+
+type FollowHandler struct {
+	followService service.FollowService
+	l             *zap.Logger
+}
+
+func NewFollowHandler(followService service.FollowService, l *zap.Logger) *FollowHandler {
+	return &FollowHandler{followService: followService, l: l}
+}
+
+// Follow 关注用户
+func (h *FollowHandler) Follow(ctx *gin.Context) {
+	type Req struct {
+		Followee int64 `json:"followee" binding:"required"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 400,
+			Msg:  "参数错误",
+		})
+		return
+	}
+	claims := (ctx.MustGet("userClaims")).(jwt.UserClaims)
+	err := h.followService.Follow(ctx, claims.Uid, req.Followee)
+	if err != nil {
+		h.handleServiceErr(ctx, err, "关注失败, 被关注者ID: "+strconv.FormatInt(req.Followee, 10))
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Code: 200,
+		Msg:  "关注成功",
+	})
+}
+
+// Unfollow 取消关注
+func (h *FollowHandler) Unfollow(ctx *gin.Context) {
+	type Req struct {
+		Followee int64 `json:"followee" binding:"required"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 400,
+			Msg:  "参数错误",
+		})
+		return
+	}
+	claims := (ctx.MustGet("userClaims")).(jwt.UserClaims)
+	err := h.followService.Unfollow(ctx, claims.Uid, req.Followee)
+	if err != nil {
+		h.handleServiceErr(ctx, err, "取消关注失败, 被关注者ID: "+strconv.FormatInt(req.Followee, 10))
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Code: 200,
+		Msg:  "已取消关注",
+	})
+}
+
+type listReq struct {
+	Uid    int64 `json:"uid"` // 为 0 时查询自己
+	Cursor int64 `json:"cursor"`
+	Limit  int   `json:"limit"`
+}
+
+// Followees 关注列表
+func (h *FollowHandler) Followees(ctx *gin.Context) {
+	req, ok := h.bindList(ctx)
+	if !ok {
+		return
+	}
+	res, err := h.followService.Followees(ctx, req.Uid, req.Cursor, req.Limit)
+	if err != nil {
+		h.handleServiceErr(ctx, err, "获取关注列表失败, 用户ID: "+strconv.FormatInt(req.Uid, 10))
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Code: 200,
+		Msg:  "获取成功",
+		Data: res,
+	})
+}
+
+// Followers 粉丝列表
+func (h *FollowHandler) Followers(ctx *gin.Context) {
+	req, ok := h.bindList(ctx)
+	if !ok {
+		return
+	}
+	res, err := h.followService.Followers(ctx, req.Uid, req.Cursor, req.Limit)
+	if err != nil {
+		h.handleServiceErr(ctx, err, "获取粉丝列表失败, 用户ID: "+strconv.FormatInt(req.Uid, 10))
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Code: 200,
+		Msg:  "获取成功",
+		Data: res,
+	})
+}
+
+// Statics 粉丝数、关注数以及当前用户是否已关注
+func (h *FollowHandler) Statics(ctx *gin.Context) {
+	uid, err := strconv.ParseInt(ctx.Param("uid"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 400,
+			Msg:  "参数错误",
+		})
+		return
+	}
+	claims := (ctx.MustGet("userClaims")).(jwt.UserClaims)
+	res, err := h.followService.Statics(ctx, uid, claims.Uid)
+	if err != nil {
+		h.handleServiceErr(ctx, err, "获取关注统计失败, 用户ID: "+strconv.FormatInt(uid, 10))
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Code: 200,
+		Msg:  "获取成功",
+		Data: res,
+	})
+}
+
+// bindList 解析列表参数 每页默认10条 最多100条
+func (h *FollowHandler) bindList(ctx *gin.Context) (listReq, bool) {
+	var req listReq
+	if err := ctx.Bind(&req); err != nil || req.Cursor < 0 || req.Limit < 0 || req.Limit > 100 {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 400,
+			Msg:  "参数错误",
+		})
+		return req, false
+	}
+	if req.Limit == 0 {
+		req.Limit = 10
+	}
+	if req.Uid == 0 {
+		req.Uid = (ctx.MustGet("userClaims")).(jwt.UserClaims).Uid
+	}
+	return req, true
+}
+
+func (h *FollowHandler) handleServiceErr(ctx *gin.Context, err error, msg string) {
+	if errors.Is(err, service.ErrFollowSelf) || errors.Is(err, service.ErrUserNotFound) ||
+		errors.Is(err, service.ErrTooManyFollowees) {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 400,
+			Msg:  err.Error(),
+		})
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Code: 500,
+		Msg:  "服务器错误",
+	})
+	h.l.Error(msg, zap.Error(err))
+}
+
+func (h *FollowHandler) RegisterRoutes(engine *gin.Engine) {
+	group := engine.Group("/follow")
+	group.POST("/follow", h.Follow)       // 关注
+	group.POST("/unfollow", h.Unfollow)   // 取消关注
+	group.POST("/followees", h.Followees) // 关注列表
+	group.POST("/followers", h.Followers) // 粉丝列表
+	group.GET("/statics/:uid", h.Statics) // 粉丝数、关注数
+}
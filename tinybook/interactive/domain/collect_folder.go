@@ -0,0 +1,33 @@
+package domain
+
+const (
+	// DefaultFolderId 默认收藏夹 每个用户都有 不落库 不能重命名和删除
+	DefaultFolderId int64 = 0
+	// DefaultFolderName 默认收藏夹名称
+	DefaultFolderName = "默认收藏夹"
+	// MaxFolderNameLength 收藏夹名称最大长度(字符数)
+	MaxFolderNameLength = 32
+	// MaxFolderDescriptionLength 收藏夹描述最大长度(字符数)
+	MaxFolderDescriptionLength = 200
+	// MaxFoldersPerUser 每个用户最多创建的收藏夹数量 不含默认收藏夹
+	MaxFoldersPerUser = 50
+)
+
+type CollectFolder struct {
+	Id          int64
+	Uid         int64
+	Name        string
+	Description string
+	ItemCount   int64
+	Ctime       int64
+	Utime       int64
+}
+
+// CollectItem 收藏夹中的一条收藏
+type CollectItem struct {
+	Id    int64
+	Cid   int64
+	Biz   string
+	BizId int64
+	Ctime int64
+}
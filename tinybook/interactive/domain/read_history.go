@@ -0,0 +1,19 @@
+package domain
+
+import "time"
+
+const (
+	// MaxReadHistory 每个用户最多保留的阅读记录数
+	MaxReadHistory = 1000
+	// ReadHistoryRetention 阅读记录的保留时间 超过的不再返回 并在下次写入时清理
+	ReadHistoryRetention = 90 * 24 * time.Hour
+)
+
+// ReadHistory 用户阅读记录 同一篇文章只保留最近一次阅读
+type ReadHistory struct {
+	Uid   int64
+	Biz   string
+	BizId int64
+	// 最近一次阅读的时间 毫秒
+	Rtime int64
+}
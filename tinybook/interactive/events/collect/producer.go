@@ -0,0 +1,39 @@
+package collect
+
+import (
+	"context"
+	"github.com/bytedance/sonic"
+	"github.com/segmentio/kafka-go"
+)
+
+const TopicArticleCollect = "topic-article-collect"
+
+// CollectEvent 收藏/取消收藏 Delta 收藏为 1 取消收藏为 -1
+type CollectEvent struct {
+	ArticleID int64 `json:"article_id"`
+	Uid       int64 `json:"uid"`
+	Delta     int64 `json:"delta"`
+}
+
+type CollectEventProducer interface {
+	ProduceCollectEvent(event CollectEvent) error
+}
+
+type KafkaCollectProducer struct {
+	writer *kafka.Writer
+}
+
+func NewKafkaCollectProducer(writer *kafka.Writer) CollectEventProducer {
+	return &KafkaCollectProducer{writer: writer}
+}
+
+func (k *KafkaCollectProducer) ProduceCollectEvent(event CollectEvent) error {
+	bytes, err := sonic.Marshal(event)
+	if err != nil {
+		return err
+	}
+	return k.writer.WriteMessages(context.Background(), kafka.Message{
+		Topic: TopicArticleCollect,
+		Value: bytes,
+	})
+}
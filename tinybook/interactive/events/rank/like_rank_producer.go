@@ -14,6 +14,10 @@ type LikeRankEvent struct {
 	ArticleID int64 `json:"article_id"`
 	LikeCount int64 `json:"like_count"`
 	Change    bool  `json:"change"`
+
+	// 点赞/取消点赞时带上用户和变化量 点赞为 1 取消点赞为 -1，只刷新榜单的事件为 0
+	Uid   int64 `json:"uid,omitempty"`
+	Delta int64 `json:"delta,omitempty"`
 }
 
 type LikeRankEventProducer interface {
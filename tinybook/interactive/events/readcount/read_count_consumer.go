@@ -9,6 +9,7 @@ import (
 	"go.uber.org/zap"
 	"strings"
 	"time"
+	"tinybook/tinybook/interactive/domain"
 	"tinybook/tinybook/interactive/events"
 	"tinybook/tinybook/interactive/events/rank"
 	"tinybook/tinybook/interactive/repository"
@@ -25,17 +26,20 @@ type ReadEvent struct {
 }
 
 type ReadCountKafkaConsumer struct {
-	reader *kafka.Reader
-	repo   repository.InteractiveRepository
-	log    *zap.Logger
+	reader      *kafka.Reader
+	repo        repository.InteractiveRepository
+	historyRepo repository.ReadHistoryRepository
+	log         *zap.Logger
 }
 
-func NewKafkaReadCountConsumer(repo repository.InteractiveRepository, log *zap.Logger) *ReadCountKafkaConsumer {
+func NewKafkaReadCountConsumer(repo repository.InteractiveRepository, historyRepo repository.ReadHistoryRepository,
+	log *zap.Logger) *ReadCountKafkaConsumer {
 	reader := initReader(GroupArticleRead, TopicArticleRead)
 	return &ReadCountKafkaConsumer{
-		repo:   repo,
-		log:    log,
-		reader: reader,
+		repo:        repo,
+		historyRepo: historyRepo,
+		log:         log,
+		reader:      reader,
 	}
 }
 
@@ -74,6 +78,9 @@ func (k *ReadCountKafkaConsumer) Consume(ctx context.Context) {
 			k.log.Error("consumer increase read count failed", zap.Error(err))
 			continue
 		}
+		if event.UserID > 0 {
+			k.recordHistory(ctx, []domain.ReadHistory{k.toHistory(event, message)})
+		}
 	}
 }
 
@@ -85,6 +92,7 @@ func (k *ReadCountKafkaConsumer) BatchConsume(ctx context.Context) {
 	// 业务逻辑
 	fn := func(ms []kafka.Message) error {
 		artIds := make([]int64, 0, len(ms))
+		histories := make([]domain.ReadHistory, 0, len(ms))
 		for i := range ms {
 			if ms[i].Value == nil {
 				continue
@@ -97,6 +105,9 @@ func (k *ReadCountKafkaConsumer) BatchConsume(ctx context.Context) {
 				continue
 			}
 			artIds = append(artIds, event.ArticleID)
+			if event.UserID > 0 { // 未登录用户没有阅读记录
+				histories = append(histories, k.toHistory(event, ms[i]))
+			}
 		}
 		// 业务逻辑 批量增加阅读数
 		err := k.repo.BatchIncreaseReadCount(ctx, "article", artIds)
@@ -104,6 +115,7 @@ func (k *ReadCountKafkaConsumer) BatchConsume(ctx context.Context) {
 			k.log.Error("consumer batch increase read count failed", zap.Error(err))
 			return err
 		}
+		k.recordHistory(ctx, histories)
 		return nil
 	}
 
@@ -144,6 +156,31 @@ func (k *ReadCountKafkaConsumer) BatchConsume(ctx context.Context) {
 	}
 }
 
+// recordHistory 记录阅读历史 失败只记录日志 不能因此重复消费导致阅读数重复增加
+func (k *ReadCountKafkaConsumer) recordHistory(ctx context.Context, histories []domain.ReadHistory) {
+	if len(histories) == 0 {
+		return
+	}
+	err := k.historyRepo.AddReadHistory(ctx, histories)
+	if err != nil {
+		k.log.Error("consumer add read history failed", zap.Error(err))
+	}
+}
+
+// toHistory 以消息写入kafka的时间作为阅读时间
+func (k *ReadCountKafkaConsumer) toHistory(event ReadEvent, msg kafka.Message) domain.ReadHistory {
+	rtime := msg.Time
+	if rtime.IsZero() {
+		rtime = time.Now()
+	}
+	return domain.ReadHistory{
+		Uid:   event.UserID,
+		Biz:   "article",
+		BizId: event.ArticleID,
+		Rtime: rtime.UnixMilli(),
+	}
+}
+
 func CollectConsumer(consumer *ReadCountKafkaConsumer, likeRankConsumer *rank.LikeRankKafkaConsumer) []events.Consumer {
 	return []events.Consumer{consumer, likeRankConsumer}
 }
@@ -0,0 +1,29 @@
+package grpc
+
+import (
+	"github.com/cockroachdb/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"tinybook/tinybook/interactive/service"
+)
+
+// ToStatusError 把业务错误转换为带错误码的 grpc status 远程调用时调用方才能区分是参数问题还是系统错误
+// 其他错误原样返回
+func ToStatusError(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, service.ErrFolderNotFound),
+		errors.Is(err, service.ErrCollectNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, service.ErrFolderNameDuplicate):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, service.ErrInvalidFolderName),
+		errors.Is(err, service.ErrInvalidFolderDescription):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, service.ErrTooManyFolders),
+		errors.Is(err, service.ErrDefaultFolderReadOnly):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	}
+	return err
+}
@@ -12,10 +12,13 @@ import (
 type InteractiveServiceServer struct {
 	intrv1.UnimplementedInteractiveServiceServer
 	interactiveSvc service.InteractiveService
+	folderSvc      service.CollectFolderService
+	historySvc     service.ReadHistoryService
 }
 
-func NewInteractiveServiceServer(interactiveSvc service.InteractiveService) *InteractiveServiceServer {
-	return &InteractiveServiceServer{interactiveSvc: interactiveSvc}
+func NewInteractiveServiceServer(interactiveSvc service.InteractiveService, folderSvc service.CollectFolderService,
+	historySvc service.ReadHistoryService) *InteractiveServiceServer {
+	return &InteractiveServiceServer{interactiveSvc: interactiveSvc, folderSvc: folderSvc, historySvc: historySvc}
 }
 
 // Register 注册服务
@@ -40,7 +43,12 @@ func (i *InteractiveServiceServer) Unlike(ctx context.Context, request *intrv1.U
 
 func (i *InteractiveServiceServer) Collect(ctx context.Context, request *intrv1.CollectRequest) (*intrv1.CollectResponse, error) {
 	err := i.interactiveSvc.Collect(ctx, request.GetBiz(), request.GetBizId(), request.GetCid(), request.GetUid())
-	return &intrv1.CollectResponse{}, err
+	return &intrv1.CollectResponse{}, ToStatusError(err)
+}
+
+func (i *InteractiveServiceServer) Uncollect(ctx context.Context, request *intrv1.UncollectRequest) (*intrv1.UncollectResponse, error) {
+	err := i.interactiveSvc.Uncollect(ctx, request.GetBiz(), request.GetBizId(), request.GetUid())
+	return &intrv1.UncollectResponse{}, ToStatusError(err)
 }
 
 func (i *InteractiveServiceServer) GetInteractive(ctx context.Context, request *intrv1.GetInteractiveRequest) (*intrv1.GetInteractiveResponse, error) {
@@ -80,6 +88,112 @@ func (i *InteractiveServiceServer) GetByIds(ctx context.Context, request *intrv1
 	}, nil
 }
 
+func (i *InteractiveServiceServer) CreateFolder(ctx context.Context, request *intrv1.CreateFolderRequest) (*intrv1.CreateFolderResponse, error) {
+	id, err := i.folderSvc.CreateFolder(ctx, domain.CollectFolder{
+		Uid:         request.GetUid(),
+		Name:        request.GetName(),
+		Description: request.GetDescription(),
+	})
+	if err != nil {
+		return nil, ToStatusError(err)
+	}
+	return &intrv1.CreateFolderResponse{Id: id}, nil
+}
+
+func (i *InteractiveServiceServer) RenameFolder(ctx context.Context, request *intrv1.RenameFolderRequest) (*intrv1.RenameFolderResponse, error) {
+	err := i.folderSvc.RenameFolder(ctx, domain.CollectFolder{
+		Id:          request.GetId(),
+		Uid:         request.GetUid(),
+		Name:        request.GetName(),
+		Description: request.GetDescription(),
+	})
+	return &intrv1.RenameFolderResponse{}, ToStatusError(err)
+}
+
+func (i *InteractiveServiceServer) DeleteFolder(ctx context.Context, request *intrv1.DeleteFolderRequest) (*intrv1.DeleteFolderResponse, error) {
+	err := i.folderSvc.DeleteFolder(ctx, request.GetId(), request.GetUid())
+	return &intrv1.DeleteFolderResponse{}, ToStatusError(err)
+}
+
+func (i *InteractiveServiceServer) ListFolders(ctx context.Context, request *intrv1.ListFoldersRequest) (*intrv1.ListFoldersResponse, error) {
+	folders, err := i.folderSvc.ListFolders(ctx, request.GetUid())
+	if err != nil {
+		return nil, ToStatusError(err)
+	}
+	return &intrv1.ListFoldersResponse{
+		Folders: lo.Map(folders, func(item domain.CollectFolder, index int) *intrv1.CollectFolder {
+			return i.folderToDTO(item)
+		}),
+	}, nil
+}
+
+func (i *InteractiveServiceServer) MoveItem(ctx context.Context, request *intrv1.MoveItemRequest) (*intrv1.MoveItemResponse, error) {
+	err := i.folderSvc.MoveItem(ctx, request.GetBiz(), request.GetBizId(), request.GetUid(), request.GetCid())
+	return &intrv1.MoveItemResponse{}, ToStatusError(err)
+}
+
+func (i *InteractiveServiceServer) ListFolderItems(ctx context.Context, request *intrv1.ListFolderItemsRequest) (*intrv1.ListFolderItemsResponse, error) {
+	items, next, err := i.folderSvc.ListFolderItems(ctx, request.GetUid(), request.GetCid(), request.GetCursor(), int(request.GetLimit()))
+	if err != nil {
+		return nil, ToStatusError(err)
+	}
+	return &intrv1.ListFolderItemsResponse{
+		Items: lo.Map(items, func(item domain.CollectItem, index int) *intrv1.CollectItem {
+			return i.collectItemToDTO(item)
+		}),
+		NextCursor: next,
+	}, nil
+}
+
+func (i *InteractiveServiceServer) ListReadHistory(ctx context.Context, request *intrv1.ListReadHistoryRequest) (*intrv1.ListReadHistoryResponse, error) {
+	histories, next, err := i.historySvc.List(ctx, request.GetUid(), request.GetCursor(), int(request.GetLimit()))
+	if err != nil {
+		return nil, err
+	}
+	return &intrv1.ListReadHistoryResponse{
+		Histories: lo.Map(histories, func(item domain.ReadHistory, index int) *intrv1.ReadHistory {
+			return &intrv1.ReadHistory{
+				Biz:   item.Biz,
+				BizId: item.BizId,
+				Rtime: item.Rtime,
+			}
+		}),
+		NextCursor: next,
+	}, nil
+}
+
+func (i *InteractiveServiceServer) DeleteReadHistory(ctx context.Context, request *intrv1.DeleteReadHistoryRequest) (*intrv1.DeleteReadHistoryResponse, error) {
+	err := i.historySvc.Delete(ctx, request.GetUid(), request.GetBiz(), request.GetBizId())
+	return &intrv1.DeleteReadHistoryResponse{}, err
+}
+
+func (i *InteractiveServiceServer) ClearReadHistory(ctx context.Context, request *intrv1.ClearReadHistoryRequest) (*intrv1.ClearReadHistoryResponse, error) {
+	err := i.historySvc.Clear(ctx, request.GetUid())
+	return &intrv1.ClearReadHistoryResponse{}, err
+}
+
+func (i *InteractiveServiceServer) folderToDTO(folder domain.CollectFolder) *intrv1.CollectFolder {
+	return &intrv1.CollectFolder{
+		Id:          folder.Id,
+		Uid:         folder.Uid,
+		Name:        folder.Name,
+		Description: folder.Description,
+		ItemCount:   folder.ItemCount,
+		Ctime:       folder.Ctime,
+		Utime:       folder.Utime,
+	}
+}
+
+func (i *InteractiveServiceServer) collectItemToDTO(item domain.CollectItem) *intrv1.CollectItem {
+	return &intrv1.CollectItem{
+		Id:    item.Id,
+		Cid:   item.Cid,
+		Biz:   item.Biz,
+		BizId: item.BizId,
+		Ctime: item.Ctime,
+	}
+}
+
 func (i *InteractiveServiceServer) interactiveToDTO(interactive domain.Interactive) *intrv1.Interactive {
 	return &intrv1.Interactive{
 		Biz:          interactive.Biz,
@@ -25,6 +25,7 @@ type InteractiveCache interface {
 	IncreaseLikeCountIfPresent(ctx context.Context, biz string, id int64, uid int64) error
 	DecreaseLikeCountIfPresent(ctx context.Context, biz string, id int64, uid int64) error
 	IncreaseCollectCountIfPresent(ctx context.Context, biz string, id int64, uid int64) error
+	DecreaseCollectCountIfPresent(ctx context.Context, biz string, id int64, uid int64) error
 	GetInteractive(ctx context.Context, biz string, id int64) (domain.Interactive, error)
 	IsLiked(ctx context.Context, biz string, id int64, uid int64) (bool, error)
 	IsCollected(ctx context.Context, biz string, id int64, uid int64) (bool, error)
@@ -165,6 +166,11 @@ func (r *RedisInteractiveCache) IncreaseCollectCountIfPresent(ctx context.Contex
 	return r.cli.SAdd(ctx, key, uid).Err()
 }
 
+func (r *RedisInteractiveCache) DecreaseCollectCountIfPresent(ctx context.Context, biz string, id int64, uid int64) error {
+	key := r.key(biz, id, CollectCountKey)
+	return r.cli.SRem(ctx, key, uid).Err()
+}
+
 func (r *RedisInteractiveCache) IncreaseLikeCountIfPresent(ctx context.Context, biz string, id int64, uid int64) error {
 	key := r.key(biz, id, LikeCountKey)
 	// zincrby article:like_count 1 id
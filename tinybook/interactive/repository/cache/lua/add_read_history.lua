@@ -0,0 +1,18 @@
+-- 缓存存在时才追加阅读记录 避免不完整的缓存被当作全部记录
+local key = KEYS[1]
+local since = ARGV[1]
+local keep = tonumber(ARGV[2])
+local ttl = tonumber(ARGV[3])
+
+if redis.call("exists", key) == 0 then
+    return 0
+end
+-- ARGV[4] 之后是成对的 阅读时间 和 member GT 保证只会往后更新阅读时间
+for i = 4, #ARGV, 2 do
+    redis.call("zadd", key, "GT", ARGV[i], ARGV[i + 1])
+end
+-- 清理超过保留时间和超过数量的记录
+redis.call("zremrangebyscore", key, "-inf", since)
+redis.call("zremrangebyrank", key, 0, -(keep + 1))
+redis.call("expire", key, ttl)
+return 1
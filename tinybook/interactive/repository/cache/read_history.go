@@ -0,0 +1,133 @@
+package cache
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"github.com/cockroachdb/errors"
+	"github.com/redis/go-redis/v9"
+	"strconv"
+	"strings"
+	"time"
+	"tinybook/tinybook/interactive/domain"
+)
+
+const (
+	readHistoryExpiration = 7 * 24 * time.Hour
+	// readHistoryPlaceholder 没有阅读记录的用户也写入一个占位 避免每次都查数据库 分数为0 不会被查出来
+	readHistoryPlaceholder = "-"
+)
+
+var (
+	//go:embed lua/add_read_history.lua
+	luaAddReadHistory string
+
+	ErrReadHistoryMiss = errors.New("阅读记录缓存不存在")
+)
+
+type ReadHistoryCache interface {
+	// AddIfPresent 缓存存在时追加阅读记录 并清理 since 之前和超过 keep 条的记录
+	AddIfPresent(ctx context.Context, uid int64, histories []domain.ReadHistory, since int64, keep int) error
+	// Get 按阅读时间倒序 返回阅读时间在 (since, before) 之间的记录 before 为0表示不限制 缓存不存在返回 ErrReadHistoryMiss
+	Get(ctx context.Context, uid int64, since int64, before int64, limit int) ([]domain.ReadHistory, error)
+	// Set 用数据库中的全部记录重建缓存
+	Set(ctx context.Context, uid int64, histories []domain.ReadHistory) error
+	Delete(ctx context.Context, uid int64, biz string, bizId int64) error
+	Clear(ctx context.Context, uid int64) error
+}
+
+type RedisReadHistoryCache struct {
+	cli redis.Cmdable
+}
+
+func NewRedisReadHistoryCache(cli redis.Cmdable) ReadHistoryCache {
+	return &RedisReadHistoryCache{cli: cli}
+}
+
+func (r *RedisReadHistoryCache) AddIfPresent(ctx context.Context, uid int64, histories []domain.ReadHistory, since int64, keep int) error {
+	if len(histories) == 0 {
+		return nil
+	}
+	args := make([]any, 0, 3+2*len(histories))
+	args = append(args, since, keep, int64(readHistoryExpiration.Seconds()))
+	for _, h := range histories {
+		args = append(args, h.Rtime, r.member(h.Biz, h.BizId))
+	}
+	return r.cli.Eval(ctx, luaAddReadHistory, []string{r.key(uid)}, args...).Err()
+}
+
+func (r *RedisReadHistoryCache) Get(ctx context.Context, uid int64, since int64, before int64, limit int) ([]domain.ReadHistory, error) {
+	key := r.key(uid)
+	maxScore := "+inf"
+	if before > 0 {
+		maxScore = "(" + strconv.FormatInt(before, 10)
+	}
+	// 放在一个事务里 保证判断存在和读取之间缓存没有过期
+	pipe := r.cli.TxPipeline()
+	exists := pipe.Exists(ctx, key)
+	res := pipe.ZRevRangeByScoreWithScores(ctx, key, &redis.ZRangeBy{
+		Max:   maxScore,
+		Min:   "(" + strconv.FormatInt(since, 10),
+		Count: int64(limit),
+	})
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+	if exists.Val() == 0 {
+		return nil, ErrReadHistoryMiss
+	}
+	histories := make([]domain.ReadHistory, 0, len(res.Val()))
+	for _, z := range res.Val() {
+		biz, bizId, ok := r.parseMember(z.Member.(string))
+		if !ok {
+			continue
+		}
+		histories = append(histories, domain.ReadHistory{
+			Uid:   uid,
+			Biz:   biz,
+			BizId: bizId,
+			Rtime: int64(z.Score),
+		})
+	}
+	return histories, nil
+}
+
+func (r *RedisReadHistoryCache) Set(ctx context.Context, uid int64, histories []domain.ReadHistory) error {
+	key := r.key(uid)
+	members := make([]redis.Z, 0, len(histories)+1)
+	members = append(members, redis.Z{Score: 0, Member: readHistoryPlaceholder})
+	for _, h := range histories {
+		members = append(members, redis.Z{Score: float64(h.Rtime), Member: r.member(h.Biz, h.BizId)})
+	}
+	pipe := r.cli.TxPipeline()
+	pipe.Del(ctx, key)
+	pipe.ZAdd(ctx, key, members...)
+	pipe.Expire(ctx, key, readHistoryExpiration)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+func (r *RedisReadHistoryCache) Delete(ctx context.Context, uid int64, biz string, bizId int64) error {
+	return r.cli.ZRem(ctx, r.key(uid), r.member(biz, bizId)).Err()
+}
+
+func (r *RedisReadHistoryCache) Clear(ctx context.Context, uid int64) error {
+	return r.cli.Del(ctx, r.key(uid)).Err()
+}
+
+func (r *RedisReadHistoryCache) key(uid int64) string {
+	return fmt.Sprintf("read_history:%d", uid)
+}
+
+func (r *RedisReadHistoryCache) member(biz string, bizId int64) string {
+	return biz + ":" + strconv.FormatInt(bizId, 10)
+}
+
+func (r *RedisReadHistoryCache) parseMember(member string) (string, int64, bool) {
+	biz, id, ok := strings.Cut(member, ":")
+	if !ok {
+		return "", 0, false
+	}
+	bizId, err := strconv.ParseInt(id, 10, 64)
+	return biz, bizId, err == nil
+}
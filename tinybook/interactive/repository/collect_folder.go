@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"context"
+	"github.com/samber/lo"
+	"tinybook/tinybook/interactive/domain"
+	"tinybook/tinybook/interactive/repository/dao"
+)
+
+var (
+	ErrFolderNotFound      = dao.ErrFolderNotFound
+	ErrFolderNameDuplicate = dao.ErrFolderNameDuplicate
+	ErrCollectNotFound     = dao.ErrCollectNotFound
+)
+
+type CollectFolderRepository interface {
+	Create(ctx context.Context, folder domain.CollectFolder) (int64, error)
+	Update(ctx context.Context, folder domain.CollectFolder) error
+	Delete(ctx context.Context, id int64, uid int64) error
+	GetById(ctx context.Context, id int64) (domain.CollectFolder, error)
+	// GetByUid 用户创建的收藏夹 不含默认收藏夹
+	GetByUid(ctx context.Context, uid int64) ([]domain.CollectFolder, error)
+	CountByUid(ctx context.Context, uid int64) (int64, error)
+	// CountItems 用户每个收藏夹的收藏数 key为收藏夹ID
+	CountItems(ctx context.Context, uid int64) (map[int64]int64, error)
+	MoveItem(ctx context.Context, biz string, bizId int64, uid int64, cid int64) error
+	GetItems(ctx context.Context, uid int64, cid int64, maxId int64, limit int) ([]domain.CollectItem, error)
+}
+
+type collectFolderRepository struct {
+	dao dao.CollectFolderDAO
+}
+
+func NewCollectFolderRepository(dao dao.CollectFolderDAO) CollectFolderRepository {
+	return &collectFolderRepository{dao: dao}
+}
+
+func (c *collectFolderRepository) Create(ctx context.Context, folder domain.CollectFolder) (int64, error) {
+	return c.dao.Insert(ctx, c.domainToEntity(folder))
+}
+
+func (c *collectFolderRepository) Update(ctx context.Context, folder domain.CollectFolder) error {
+	return c.dao.Update(ctx, c.domainToEntity(folder))
+}
+
+func (c *collectFolderRepository) Delete(ctx context.Context, id int64, uid int64) error {
+	return c.dao.Delete(ctx, id, uid)
+}
+
+func (c *collectFolderRepository) GetById(ctx context.Context, id int64) (domain.CollectFolder, error) {
+	folder, err := c.dao.GetById(ctx, id)
+	if err != nil {
+		return domain.CollectFolder{}, err
+	}
+	return c.entityToDomain(folder), nil
+}
+
+func (c *collectFolderRepository) GetByUid(ctx context.Context, uid int64) ([]domain.CollectFolder, error) {
+	folders, err := c.dao.GetByUid(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+	return lo.Map(folders, func(item dao.CollectFolder, index int) domain.CollectFolder {
+		return c.entityToDomain(item)
+	}), nil
+}
+
+func (c *collectFolderRepository) CountByUid(ctx context.Context, uid int64) (int64, error) {
+	return c.dao.CountByUid(ctx, uid)
+}
+
+func (c *collectFolderRepository) CountItems(ctx context.Context, uid int64) (map[int64]int64, error) {
+	return c.dao.CountItems(ctx, uid)
+}
+
+func (c *collectFolderRepository) MoveItem(ctx context.Context, biz string, bizId int64, uid int64, cid int64) error {
+	return c.dao.MoveItem(ctx, biz, bizId, uid, cid)
+}
+
+func (c *collectFolderRepository) GetItems(ctx context.Context, uid int64, cid int64, maxId int64, limit int) ([]domain.CollectItem, error) {
+	records, err := c.dao.GetItems(ctx, uid, cid, maxId, limit)
+	if err != nil {
+		return nil, err
+	}
+	return lo.Map(records, func(item dao.CollectRecord, index int) domain.CollectItem {
+		return domain.CollectItem{
+			Id:    item.Id,
+			Cid:   item.Cid,
+			Biz:   item.Biz,
+			BizId: item.BizId,
+			Ctime: item.Ctime,
+		}
+	}), nil
+}
+
+func (c *collectFolderRepository) domainToEntity(folder domain.CollectFolder) dao.CollectFolder {
+	return dao.CollectFolder{
+		Id:          folder.Id,
+		Uid:         folder.Uid,
+		Name:        folder.Name,
+		Description: folder.Description,
+	}
+}
+
+func (c *collectFolderRepository) entityToDomain(folder dao.CollectFolder) domain.CollectFolder {
+	return domain.CollectFolder{
+		Id:          folder.Id,
+		Uid:         folder.Uid,
+		Name:        folder.Name,
+		Description: folder.Description,
+		Ctime:       folder.Ctime,
+		Utime:       folder.Utime,
+	}
+}
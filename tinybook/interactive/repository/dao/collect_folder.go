@@ -0,0 +1,187 @@
+package dao
+
+import (
+	"context"
+	"github.com/cockroachdb/errors"
+	"github.com/go-sql-driver/mysql"
+	"gorm.io/gorm"
+	"time"
+)
+
+var (
+	ErrFolderNotFound      = errors.New("收藏夹不存在")
+	ErrFolderNameDuplicate = errors.New("收藏夹名称已存在")
+	ErrCollectNotFound     = errors.New("未收藏")
+)
+
+// CollectFolder 收藏夹 默认收藏夹(id为0)不落库
+type CollectFolder struct {
+	Id          int64  `gorm:"column:id;primaryKey;autoIncrement;not null"`
+	Uid         int64  `gorm:"column:uid;not null;uniqueIndex:idx_uid_name"`
+	Name        string `gorm:"column:name;not null;type:varchar(128);uniqueIndex:idx_uid_name"`
+	Description string `gorm:"column:description;type:varchar(1024)"`
+	Utime       int64  `gorm:"column:utime;not null"`
+	Ctime       int64  `gorm:"column:ctime;not null"`
+}
+
+type CollectFolderDAO interface {
+	Insert(ctx context.Context, folder CollectFolder) (int64, error)
+	// Update 只更新名称和描述 不是该用户的收藏夹返回 ErrFolderNotFound
+	Update(ctx context.Context, folder CollectFolder) error
+	// Delete 删除收藏夹 并把其中的收藏移回默认收藏夹
+	Delete(ctx context.Context, id int64, uid int64) error
+	GetById(ctx context.Context, id int64) (CollectFolder, error)
+	GetByUid(ctx context.Context, uid int64) ([]CollectFolder, error)
+	CountByUid(ctx context.Context, uid int64) (int64, error)
+	// CountItems 统计用户每个收藏夹中的收藏数 key为收藏夹ID
+	CountItems(ctx context.Context, uid int64) (map[int64]int64, error)
+	// MoveItem 把一条收藏移动到另一个收藏夹 没有收藏返回 ErrCollectNotFound
+	MoveItem(ctx context.Context, biz string, bizId int64, uid int64, cid int64) error
+	// GetItems 按收藏时间倒序 maxId 为 0 表示从最新的开始
+	GetItems(ctx context.Context, uid int64, cid int64, maxId int64, limit int) ([]CollectRecord, error)
+}
+
+type GormCollectFolderDAO struct {
+	db *gorm.DB
+}
+
+func NewGormCollectFolderDAO(db *gorm.DB) CollectFolderDAO {
+	return &GormCollectFolderDAO{db: db}
+}
+
+func (g *GormCollectFolderDAO) Insert(ctx context.Context, folder CollectFolder) (int64, error) {
+	now := time.Now().Unix()
+	folder.Ctime = now
+	folder.Utime = now
+	err := g.db.WithContext(ctx).Create(&folder).Error
+	if isDuplicate(err) {
+		return 0, ErrFolderNameDuplicate
+	}
+	return folder.Id, err
+}
+
+func (g *GormCollectFolderDAO) Update(ctx context.Context, folder CollectFolder) error {
+	res := g.db.WithContext(ctx).Model(&CollectFolder{}).
+		Where("id = ? and uid = ?", folder.Id, folder.Uid).
+		Updates(map[string]any{
+			"name":        folder.Name,
+			"description": folder.Description,
+			"utime":       time.Now().Unix(),
+		})
+	if isDuplicate(res.Error) {
+		return ErrFolderNameDuplicate
+	}
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		// 名称和描述都没有变化时 mysql 也会返回0行 需要确认收藏夹是否存在
+		var count int64
+		err := g.db.WithContext(ctx).Model(&CollectFolder{}).
+			Where("id = ? and uid = ?", folder.Id, folder.Uid).
+			Count(&count).Error
+		if err != nil {
+			return err
+		}
+		if count == 0 {
+			return ErrFolderNotFound
+		}
+	}
+	return nil
+}
+
+func (g *GormCollectFolderDAO) Delete(ctx context.Context, id int64, uid int64) error {
+	return g.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Where("id = ? and uid = ?", id, uid).Delete(&CollectFolder{})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrFolderNotFound
+		}
+		return tx.Model(&CollectRecord{}).
+			Where("uid = ? and cid = ?", uid, id).
+			Updates(map[string]any{
+				"cid":   0,
+				"utime": time.Now().Unix(),
+			}).Error
+	})
+}
+
+func (g *GormCollectFolderDAO) GetById(ctx context.Context, id int64) (CollectFolder, error) {
+	var folder CollectFolder
+	err := g.db.WithContext(ctx).Where("id = ?", id).First(&folder).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return folder, ErrFolderNotFound
+	}
+	return folder, err
+}
+
+func (g *GormCollectFolderDAO) GetByUid(ctx context.Context, uid int64) ([]CollectFolder, error) {
+	var folders []CollectFolder
+	err := g.db.WithContext(ctx).Where("uid = ?", uid).
+		Order("id asc").
+		Find(&folders).Error
+	return folders, err
+}
+
+func (g *GormCollectFolderDAO) CountByUid(ctx context.Context, uid int64) (int64, error) {
+	var count int64
+	err := g.db.WithContext(ctx).Model(&CollectFolder{}).
+		Where("uid = ?", uid).
+		Count(&count).Error
+	return count, err
+}
+
+func (g *GormCollectFolderDAO) CountItems(ctx context.Context, uid int64) (map[int64]int64, error) {
+	type row struct {
+		Cid   int64
+		Count int64
+	}
+	var rows []row
+	err := g.db.WithContext(ctx).Model(&CollectRecord{}).
+		Select("cid, count(*) as count").
+		Where("uid = ?", uid).
+		Group("cid").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	m := make(map[int64]int64, len(rows))
+	for _, r := range rows {
+		m[r.Cid] = r.Count
+	}
+	return m, nil
+}
+
+func (g *GormCollectFolderDAO) MoveItem(ctx context.Context, biz string, bizId int64, uid int64, cid int64) error {
+	res := g.db.WithContext(ctx).Model(&CollectRecord{}).
+		Where("uid = ? and biz_id = ? and biz = ?", uid, bizId, biz).
+		Updates(map[string]any{
+			"cid":   cid,
+			"utime": time.Now().Unix(),
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrCollectNotFound
+	}
+	return nil
+}
+
+func (g *GormCollectFolderDAO) GetItems(ctx context.Context, uid int64, cid int64, maxId int64, limit int) ([]CollectRecord, error) {
+	var records []CollectRecord
+	query := g.db.WithContext(ctx).Where("uid = ? and cid = ?", uid, cid)
+	if maxId > 0 {
+		query = query.Where("id < ?", maxId)
+	}
+	err := query.Order("id desc").Limit(limit).Find(&records).Error
+	return records, err
+}
+
+// isDuplicate 唯一索引冲突
+func isDuplicate(err error) bool {
+	var my *mysql.MySQLError
+	return errors.As(err, &my) && my.Number == 1062
+}
@@ -7,6 +7,8 @@ func CreateTableForInteractive(db *gorm.DB) {
 		&Interactive{},
 		&LikeRecord{},
 		&CollectRecord{},
+		&CollectFolder{},
+		&ReadHistory{},
 	)
 	if err != nil {
 		panic(err)
@@ -50,6 +50,8 @@ type InteractiveDAO interface {
 	InsertLikeRecord(ctx context.Context, biz string, id int64, uid int64) error
 	DeleteLikeRecord(ctx context.Context, biz string, id int64, uid int64) error
 	InsertCollectRecord(ctx context.Context, biz string, id int64, cid int64, uid int64) error
+	// DeleteCollectRecord 取消收藏 没有收藏返回 ErrCollectNotFound
+	DeleteCollectRecord(ctx context.Context, biz string, id int64, uid int64) error
 	GetInteractive(ctx context.Context, biz string, id int64) (Interactive, error)
 	IsLiked(ctx context.Context, biz string, id int64, uid int64) (bool, error)
 	IsCollected(ctx context.Context, biz string, id int64, uid int64) (bool, error)
@@ -147,6 +149,25 @@ func (g *GormInteractiveDAO) InsertCollectRecord(ctx context.Context, biz string
 	})
 }
 
+func (g *GormInteractiveDAO) DeleteCollectRecord(ctx context.Context, biz string, id int64, uid int64) error {
+	now := time.Now().Unix()
+	return g.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Where("uid = ? and biz_id = ? and biz = ?", uid, id, biz).Delete(&CollectRecord{})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrCollectNotFound
+		}
+		return tx.Model(&Interactive{}).
+			Where("biz_id = ? and biz = ? and collect_count > ?", id, biz, 0).
+			Updates(map[string]interface{}{
+				"collect_count": gorm.Expr("collect_count - ?", 1),
+				"utime":         now,
+			}).Error
+	})
+}
+
 func (g *GormInteractiveDAO) InsertLikeRecord(ctx context.Context, biz string, id int64, uid int64) error {
 	now := time.Now().Unix()
 	return g.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
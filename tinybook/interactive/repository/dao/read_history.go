@@ -0,0 +1,97 @@
+package dao
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+// ReadHistory 阅读记录 (uid, biz, biz_id) 唯一 重复阅读只更新时间
+type ReadHistory struct {
+	Id    int64  `gorm:"column:id;primaryKey;autoIncrement;not null"`
+	Uid   int64  `gorm:"column:uid;not null;uniqueIndex:idx_uid_biz_id;index:idx_uid_rtime,priority:1"`
+	BizId int64  `gorm:"column:biz_id;not null;uniqueIndex:idx_uid_biz_id"`
+	Biz   string `gorm:"column:biz;not null;type:varchar(32);uniqueIndex:idx_uid_biz_id"`
+	// 最近一次阅读时间 毫秒
+	Rtime int64 `gorm:"column:rtime;not null;index:idx_uid_rtime,priority:2"`
+	Utime int64 `gorm:"column:utime;not null"`
+	Ctime int64 `gorm:"column:ctime;not null"`
+}
+
+type ReadHistoryDAO interface {
+	// Upsert 批量写入阅读记录 已存在的只会把时间往后更新
+	Upsert(ctx context.Context, histories []ReadHistory) error
+	// GetByUid 按阅读时间倒序 只返回 since 之后的记录
+	GetByUid(ctx context.Context, uid int64, since int64, limit int) ([]ReadHistory, error)
+	Delete(ctx context.Context, uid int64, biz string, bizId int64) error
+	DeleteByUid(ctx context.Context, uid int64) error
+	// Trim 删除 before 之前的记录 并且只保留最近的 keep 条
+	Trim(ctx context.Context, uid int64, before int64, keep int) error
+}
+
+type GormReadHistoryDAO struct {
+	db *gorm.DB
+}
+
+func NewGormReadHistoryDAO(db *gorm.DB) ReadHistoryDAO {
+	return &GormReadHistoryDAO{db: db}
+}
+
+func (g *GormReadHistoryDAO) Upsert(ctx context.Context, histories []ReadHistory) error {
+	if len(histories) == 0 {
+		return nil
+	}
+	now := time.Now().Unix()
+	for i := range histories {
+		histories[i].Ctime = now
+		histories[i].Utime = now
+	}
+	return g.db.WithContext(ctx).Clauses(clause.OnConflict{
+		DoUpdates: clause.Assignments(map[string]any{
+			// 消息可能乱序到达 以最近的阅读时间为准
+			"rtime": gorm.Expr("GREATEST(rtime, VALUES(rtime))"),
+			"utime": now,
+		}),
+	}).Create(&histories).Error
+}
+
+func (g *GormReadHistoryDAO) GetByUid(ctx context.Context, uid int64, since int64, limit int) ([]ReadHistory, error) {
+	var histories []ReadHistory
+	err := g.db.WithContext(ctx).
+		Where("uid = ? and rtime > ?", uid, since).
+		Order("rtime desc, id desc").
+		Limit(limit).
+		Find(&histories).Error
+	return histories, err
+}
+
+func (g *GormReadHistoryDAO) Delete(ctx context.Context, uid int64, biz string, bizId int64) error {
+	return g.db.WithContext(ctx).
+		Where("uid = ? and biz_id = ? and biz = ?", uid, bizId, biz).
+		Delete(&ReadHistory{}).Error
+}
+
+func (g *GormReadHistoryDAO) DeleteByUid(ctx context.Context, uid int64) error {
+	return g.db.WithContext(ctx).Where("uid = ?", uid).Delete(&ReadHistory{}).Error
+}
+
+func (g *GormReadHistoryDAO) Trim(ctx context.Context, uid int64, before int64, keep int) error {
+	return g.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("uid = ? and rtime <= ?", uid, before).Delete(&ReadHistory{}).Error
+		if err != nil {
+			return err
+		}
+		// 找到第 keep+1 条记录的阅读时间 早于等于它的都删掉
+		var edge []int64
+		err = tx.Model(&ReadHistory{}).
+			Where("uid = ?", uid).
+			Order("rtime desc").
+			Offset(keep).Limit(1).
+			Pluck("rtime", &edge).Error
+		if err != nil || len(edge) == 0 {
+			return err
+		}
+		return tx.Where("uid = ? and rtime <= ?", uid, edge[0]).Delete(&ReadHistory{}).Error
+	})
+}
@@ -16,6 +16,7 @@ type InteractiveRepository interface {
 	IncreaseLikeCount(ctx context.Context, biz string, id int64, uid int64) error
 	DecreaseLikeCount(ctx context.Context, biz string, id int64, uid int64) error
 	Collect(ctx context.Context, biz string, id int64, cid int64, uid int64) error
+	Uncollect(ctx context.Context, biz string, id int64, uid int64) error
 	GetInteractive(ctx context.Context, biz string, id int64) (domain.Interactive, error)
 	Liked(ctx context.Context, biz string, id int64, uid int64) (bool, error)
 	Collected(ctx context.Context, biz string, id int64, uid int64) (bool, error)
@@ -118,6 +119,14 @@ func (c *CachedInteractiveRepository) Collect(ctx context.Context, biz string, i
 	return c.cache.IncreaseCollectCountIfPresent(ctx, biz, id, uid)
 }
 
+func (c *CachedInteractiveRepository) Uncollect(ctx context.Context, biz string, id int64, uid int64) error {
+	err := c.dao.DeleteCollectRecord(ctx, biz, id, uid)
+	if err != nil {
+		return err
+	}
+	return c.cache.DecreaseCollectCountIfPresent(ctx, biz, id, uid)
+}
+
 func (c *CachedInteractiveRepository) IncreaseLikeCount(ctx context.Context, biz string, id int64, uid int64) error {
 	err := c.dao.InsertLikeRecord(ctx, biz, id, uid)
 	if err != nil {
@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"context"
+	"github.com/cockroachdb/errors"
+	"github.com/samber/lo"
+	"go.uber.org/zap"
+	"time"
+	"tinybook/tinybook/interactive/domain"
+	"tinybook/tinybook/interactive/repository/cache"
+	"tinybook/tinybook/interactive/repository/dao"
+)
+
+type ReadHistoryRepository interface {
+	// AddReadHistory 批量记录阅读 可以包含多个用户
+	AddReadHistory(ctx context.Context, histories []domain.ReadHistory) error
+	// GetReadHistory 按阅读时间倒序 before 为上一页最后一条的阅读时间 0表示第一页
+	GetReadHistory(ctx context.Context, uid int64, before int64, limit int) ([]domain.ReadHistory, error)
+	DeleteReadHistory(ctx context.Context, uid int64, biz string, bizId int64) error
+	ClearReadHistory(ctx context.Context, uid int64) error
+}
+
+// CachedReadHistoryRepository mysql 为准 redis 缓存每个用户保留期内的全部阅读记录
+type CachedReadHistoryRepository struct {
+	dao   dao.ReadHistoryDAO
+	cache cache.ReadHistoryCache
+	log   *zap.Logger
+}
+
+func NewCachedReadHistoryRepository(dao dao.ReadHistoryDAO, cache cache.ReadHistoryCache, log *zap.Logger) ReadHistoryRepository {
+	return &CachedReadHistoryRepository{dao: dao, cache: cache, log: log}
+}
+
+func (c *CachedReadHistoryRepository) AddReadHistory(ctx context.Context, histories []domain.ReadHistory) error {
+	err := c.dao.Upsert(ctx, lo.Map(histories, func(item domain.ReadHistory, index int) dao.ReadHistory {
+		return dao.ReadHistory{
+			Uid:   item.Uid,
+			Biz:   item.Biz,
+			BizId: item.BizId,
+			Rtime: item.Rtime,
+		}
+	}))
+	if err != nil {
+		return err
+	}
+	since := c.since()
+	for uid, items := range lo.GroupBy(histories, func(item domain.ReadHistory) int64 {
+		return item.Uid
+	}) {
+		// 清理失败不影响本次记录 下次写入时还会再清理
+		if er := c.dao.Trim(ctx, uid, since, domain.MaxReadHistory); er != nil {
+			c.log.Error("trim read history failed", zap.Int64("uid", uid), zap.Error(er))
+		}
+		if er := c.cache.AddIfPresent(ctx, uid, items, since, domain.MaxReadHistory); er != nil {
+			// 缓存没有更新 直接删掉 下次读取时从数据库重建
+			c.log.Error("add read history to cache failed", zap.Int64("uid", uid), zap.Error(er))
+			_ = c.cache.Clear(ctx, uid)
+		}
+	}
+	return nil
+}
+
+func (c *CachedReadHistoryRepository) GetReadHistory(ctx context.Context, uid int64, before int64, limit int) ([]domain.ReadHistory, error) {
+	since := c.since()
+	histories, err := c.cache.Get(ctx, uid, since, before, limit)
+	if err == nil {
+		return histories, nil
+	}
+	if !errors.Is(err, cache.ErrReadHistoryMiss) {
+		c.log.Error("get read history from cache failed", zap.Int64("uid", uid), zap.Error(err))
+	}
+	// 缓存不存在 从数据库加载保留期内的全部记录重建缓存 再在内存中分页
+	entities, err := c.dao.GetByUid(ctx, uid, since, domain.MaxReadHistory)
+	if err != nil {
+		return nil, err
+	}
+	all := lo.Map(entities, func(item dao.ReadHistory, index int) domain.ReadHistory {
+		return domain.ReadHistory{
+			Uid:   item.Uid,
+			Biz:   item.Biz,
+			BizId: item.BizId,
+			Rtime: item.Rtime,
+		}
+	})
+	if er := c.cache.Set(ctx, uid, all); er != nil {
+		c.log.Error("set read history to cache failed", zap.Int64("uid", uid), zap.Error(er))
+	}
+	if before > 0 {
+		all = lo.Filter(all, func(item domain.ReadHistory, index int) bool {
+			return item.Rtime < before
+		})
+	}
+	return all[:min(limit, len(all))], nil
+}
+
+func (c *CachedReadHistoryRepository) DeleteReadHistory(ctx context.Context, uid int64, biz string, bizId int64) error {
+	err := c.dao.Delete(ctx, uid, biz, bizId)
+	if err != nil {
+		return err
+	}
+	return c.cache.Delete(ctx, uid, biz, bizId)
+}
+
+func (c *CachedReadHistoryRepository) ClearReadHistory(ctx context.Context, uid int64) error {
+	err := c.dao.DeleteByUid(ctx, uid)
+	if err != nil {
+		return err
+	}
+	return c.cache.Clear(ctx, uid)
+}
+
+// since 保留期的起点 毫秒
+func (c *CachedReadHistoryRepository) since() int64 {
+	return time.Now().Add(-domain.ReadHistoryRetention).UnixMilli()
+}
@@ -0,0 +1,159 @@
+package service
+
+import (
+	"context"
+	"github.com/cockroachdb/errors"
+	"strings"
+	"tinybook/tinybook/interactive/domain"
+	"tinybook/tinybook/interactive/repository"
+	"unicode/utf8"
+)
+
+const (
+	defaultFolderItemsLimit = 20
+	maxFolderItemsLimit     = 100
+)
+
+var (
+	ErrFolderNotFound           = repository.ErrFolderNotFound
+	ErrFolderNameDuplicate      = repository.ErrFolderNameDuplicate
+	ErrCollectNotFound          = repository.ErrCollectNotFound
+	ErrInvalidFolderName        = errors.New("收藏夹名称不合法")
+	ErrInvalidFolderDescription = errors.New("收藏夹描述过长")
+	ErrTooManyFolders           = errors.New("收藏夹数量已达上限")
+	ErrDefaultFolderReadOnly    = errors.New("默认收藏夹不能修改或删除")
+)
+
+type CollectFolderService interface {
+	CreateFolder(ctx context.Context, folder domain.CollectFolder) (int64, error)
+	// RenameFolder 修改收藏夹名称和描述
+	RenameFolder(ctx context.Context, folder domain.CollectFolder) error
+	// DeleteFolder 删除收藏夹 其中的收藏移回默认收藏夹
+	DeleteFolder(ctx context.Context, id int64, uid int64) error
+	// ListFolders 用户的全部收藏夹 默认收藏夹排在第一个
+	ListFolders(ctx context.Context, uid int64) ([]domain.CollectFolder, error)
+	MoveItem(ctx context.Context, biz string, bizId int64, uid int64, cid int64) error
+	// ListFolderItems 按收藏时间倒序 返回下一页的游标 为0表示没有更多了
+	ListFolderItems(ctx context.Context, uid int64, cid int64, cursor int64, limit int) ([]domain.CollectItem, int64, error)
+}
+
+type collectFolderService struct {
+	repo repository.CollectFolderRepository
+}
+
+func NewCollectFolderService(repo repository.CollectFolderRepository) CollectFolderService {
+	return &collectFolderService{repo: repo}
+}
+
+func (c *collectFolderService) CreateFolder(ctx context.Context, folder domain.CollectFolder) (int64, error) {
+	folder, err := c.normalize(folder)
+	if err != nil {
+		return 0, err
+	}
+	count, err := c.repo.CountByUid(ctx, folder.Uid)
+	if err != nil {
+		return 0, err
+	}
+	if count >= domain.MaxFoldersPerUser {
+		return 0, ErrTooManyFolders
+	}
+	return c.repo.Create(ctx, folder)
+}
+
+func (c *collectFolderService) RenameFolder(ctx context.Context, folder domain.CollectFolder) error {
+	if folder.Id == domain.DefaultFolderId {
+		return ErrDefaultFolderReadOnly
+	}
+	folder, err := c.normalize(folder)
+	if err != nil {
+		return err
+	}
+	return c.repo.Update(ctx, folder)
+}
+
+func (c *collectFolderService) DeleteFolder(ctx context.Context, id int64, uid int64) error {
+	if id == domain.DefaultFolderId {
+		return ErrDefaultFolderReadOnly
+	}
+	return c.repo.Delete(ctx, id, uid)
+}
+
+func (c *collectFolderService) ListFolders(ctx context.Context, uid int64) ([]domain.CollectFolder, error) {
+	folders, err := c.repo.GetByUid(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+	counts, err := c.repo.CountItems(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]domain.CollectFolder, 0, len(folders)+1)
+	res = append(res, domain.CollectFolder{
+		Id:        domain.DefaultFolderId,
+		Uid:       uid,
+		Name:      domain.DefaultFolderName,
+		ItemCount: counts[domain.DefaultFolderId],
+	})
+	for _, folder := range folders {
+		folder.ItemCount = counts[folder.Id]
+		res = append(res, folder)
+	}
+	return res, nil
+}
+
+func (c *collectFolderService) MoveItem(ctx context.Context, biz string, bizId int64, uid int64, cid int64) error {
+	if err := checkFolder(ctx, c.repo, uid, cid); err != nil {
+		return err
+	}
+	return c.repo.MoveItem(ctx, biz, bizId, uid, cid)
+}
+
+func (c *collectFolderService) ListFolderItems(ctx context.Context, uid int64, cid int64, cursor int64, limit int) ([]domain.CollectItem, int64, error) {
+	if err := checkFolder(ctx, c.repo, uid, cid); err != nil {
+		return nil, 0, err
+	}
+	if limit <= 0 {
+		limit = defaultFolderItemsLimit
+	}
+	limit = min(limit, maxFolderItemsLimit)
+	// 多查一条 用来判断是否还有下一页
+	items, err := c.repo.GetItems(ctx, uid, cid, cursor, limit+1)
+	if err != nil {
+		return nil, 0, err
+	}
+	var next int64
+	if len(items) > limit {
+		items = items[:limit]
+		next = items[limit-1].Id
+	}
+	return items, next, nil
+}
+
+// normalize 校验并整理收藏夹名称和描述
+func (c *collectFolderService) normalize(folder domain.CollectFolder) (domain.CollectFolder, error) {
+	folder.Name = strings.TrimSpace(folder.Name)
+	folder.Description = strings.TrimSpace(folder.Description)
+	if folder.Name == "" || folder.Name == domain.DefaultFolderName ||
+		utf8.RuneCountInString(folder.Name) > domain.MaxFolderNameLength {
+		return folder, ErrInvalidFolderName
+	}
+	if utf8.RuneCountInString(folder.Description) > domain.MaxFolderDescriptionLength {
+		return folder, ErrInvalidFolderDescription
+	}
+	return folder, nil
+}
+
+// checkFolder 确认收藏夹属于该用户 默认收藏夹所有用户都有
+func checkFolder(ctx context.Context, repo repository.CollectFolderRepository, uid int64, cid int64) error {
+	if cid == domain.DefaultFolderId {
+		return nil
+	}
+	folder, err := repo.GetById(ctx, cid)
+	if err != nil {
+		return err
+	}
+	if folder.Uid != uid {
+		return ErrFolderNotFound
+	}
+	return nil
+}
@@ -8,6 +8,7 @@ import (
 	"strconv"
 	"tinybook/tinybook/article/domain"
 	domain2 "tinybook/tinybook/interactive/domain"
+	"tinybook/tinybook/interactive/events/collect"
 	"tinybook/tinybook/interactive/events/rank"
 	"tinybook/tinybook/interactive/repository"
 )
@@ -16,16 +17,21 @@ type InteractiveService interface {
 	IncreaseReadCount(ctx context.Context, biz string, bizId int64) error
 	Like(ctx context.Context, biz string, id int64, uid int64) error
 	Unlike(ctx context.Context, biz string, id int64, uid int64) error
+	// Collect 收藏到指定收藏夹 收藏夹不属于该用户时返回 ErrFolderNotFound
 	Collect(ctx context.Context, biz string, id int64, cid int64, uid int64) error
+	// Uncollect 取消收藏 没有收藏时返回 ErrCollectNotFound
+	Uncollect(ctx context.Context, biz string, id int64, uid int64) error
 	GetInteractive(ctx context.Context, biz string, id int64, uid int64) (domain2.Interactive, error)
 	GetLikeRanks(ctx context.Context, biz string, num int64) ([]domain2.ArticleVo, error)
 	GetByIds(ctx context.Context, biz string, ids []int64) (map[int64]domain2.Interactive, error)
 }
 
 type interactiveService struct {
-	repo repository.InteractiveRepository
+	repo       repository.InteractiveRepository
+	folderRepo repository.CollectFolderRepository
 	//articleRepo   repository.ArticleRepository
 	likeRankEvent rank.LikeRankEventProducer
+	collectEvent  collect.CollectEventProducer
 	log           *zap.Logger
 }
 
@@ -99,7 +105,40 @@ func (i *interactiveService) GetInteractive(ctx context.Context, biz string, id
 }
 
 func (i *interactiveService) Collect(ctx context.Context, biz string, id int64, cid int64, uid int64) error {
-	return i.repo.Collect(ctx, biz, id, cid, uid)
+	if err := checkFolder(ctx, i.folderRepo, uid, cid); err != nil {
+		return err
+	}
+	err := i.repo.Collect(ctx, biz, id, cid, uid)
+	if err != nil {
+		return err
+	}
+	i.produceCollectEvent(id, uid, 1)
+	return nil
+}
+
+func (i *interactiveService) Uncollect(ctx context.Context, biz string, id int64, uid int64) error {
+	err := i.repo.Uncollect(ctx, biz, id, uid)
+	if err != nil {
+		return err
+	}
+	i.produceCollectEvent(id, uid, -1)
+	return nil
+}
+
+// produceCollectEvent 异步发送收藏事件 用于作者的数据统计
+func (i *interactiveService) produceCollectEvent(id int64, uid int64, delta int64) {
+	go func() {
+		err := i.collectEvent.ProduceCollectEvent(collect.CollectEvent{
+			ArticleID: id,
+			Uid:       uid,
+			Delta:     delta,
+		})
+		if err != nil {
+			i.log.Error("produce collect event failed, article id: "+
+				strconv.FormatInt(id, 10)+" user id: "+
+				strconv.FormatInt(uid, 10), zap.Error(err))
+		}
+	}()
 }
 
 func (i *interactiveService) Like(ctx context.Context, biz string, id int64, uid int64) error {
@@ -112,6 +151,8 @@ func (i *interactiveService) Like(ctx context.Context, biz string, id int64, uid
 		err2 := i.likeRankEvent.ProduceLikeRankEvent(rank.LikeRankEvent{
 			ArticleID: id,
 			Change:    true,
+			Uid:       uid,
+			Delta:     1,
 		})
 		if err2 != nil {
 			i.log.Error("produce like rank event failed, article id: "+
@@ -132,6 +173,8 @@ func (i *interactiveService) Unlike(ctx context.Context, biz string, id int64, u
 		err2 := i.likeRankEvent.ProduceLikeRankEvent(rank.LikeRankEvent{
 			ArticleID: id,
 			Change:    true,
+			Uid:       uid,
+			Delta:     -1,
 		})
 		if err2 != nil {
 			i.log.Error("produce unlike rank event failed, article id: "+
@@ -142,11 +185,14 @@ func (i *interactiveService) Unlike(ctx context.Context, biz string, id int64, u
 	return nil
 }
 
-func NewInteractiveService(repo repository.InteractiveRepository, event rank.LikeRankEventProducer, logger *zap.Logger) InteractiveService {
+func NewInteractiveService(repo repository.InteractiveRepository, folderRepo repository.CollectFolderRepository,
+	event rank.LikeRankEventProducer, collectEvent collect.CollectEventProducer, logger *zap.Logger) InteractiveService {
 	return &interactiveService{
-		repo: repo,
+		repo:       repo,
+		folderRepo: folderRepo,
 		//articleRepo:   articleRepository,
 		likeRankEvent: event,
+		collectEvent:  collectEvent,
 		log:           logger,
 	}
 }
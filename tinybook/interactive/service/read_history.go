@@ -0,0 +1,54 @@
+package service
+
+import (
+	"context"
+	"tinybook/tinybook/interactive/domain"
+	"tinybook/tinybook/interactive/repository"
+)
+
+const (
+	defaultReadHistoryLimit = 20
+	maxReadHistoryLimit     = 100
+)
+
+type ReadHistoryService interface {
+	// List 最近阅读 按阅读时间倒序 cursor 为上一页返回的游标 返回下一页的游标 为0表示没有更多了
+	List(ctx context.Context, uid int64, cursor int64, limit int) ([]domain.ReadHistory, int64, error)
+	Delete(ctx context.Context, uid int64, biz string, bizId int64) error
+	Clear(ctx context.Context, uid int64) error
+}
+
+type readHistoryService struct {
+	repo repository.ReadHistoryRepository
+}
+
+func NewReadHistoryService(repo repository.ReadHistoryRepository) ReadHistoryService {
+	return &readHistoryService{repo: repo}
+}
+
+func (r *readHistoryService) List(ctx context.Context, uid int64, cursor int64, limit int) ([]domain.ReadHistory, int64, error) {
+	if limit <= 0 {
+		limit = defaultReadHistoryLimit
+	}
+	limit = min(limit, maxReadHistoryLimit)
+	// 多查一条 用来判断是否还有下一页
+	histories, err := r.repo.GetReadHistory(ctx, uid, cursor, limit+1)
+	if err != nil {
+		return nil, 0, err
+	}
+	var next int64
+	if len(histories) > limit {
+		histories = histories[:limit]
+		// 阅读时间精确到毫秒 同一用户同一毫秒读两篇的情况可以忽略
+		next = histories[limit-1].Rtime
+	}
+	return histories, next, nil
+}
+
+func (r *readHistoryService) Delete(ctx context.Context, uid int64, biz string, bizId int64) error {
+	return r.repo.DeleteReadHistory(ctx, uid, biz, bizId)
+}
+
+func (r *readHistoryService) Clear(ctx context.Context, uid int64) error {
+	return r.repo.ClearReadHistory(ctx, uid)
+}
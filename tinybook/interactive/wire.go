@@ -4,6 +4,7 @@ package main
 
 import (
 	"github.com/google/wire"
+	"tinybook/tinybook/interactive/events/collect"
 	"tinybook/tinybook/interactive/events/rank"
 	"tinybook/tinybook/interactive/events/readcount"
 	"tinybook/tinybook/interactive/grpc"
@@ -21,6 +22,11 @@ var thirdPartySet = wire.NewSet(
 var interactiveServiceSet = wire.NewSet(
 	dao.NewGormInteractiveDAO, cache.NewRedisInteractiveCache,
 	repository.NewCachedInteractiveRepository, service.NewInteractiveService,
+	// 收藏夹
+	dao.NewGormCollectFolderDAO, repository.NewCollectFolderRepository, service.NewCollectFolderService,
+	// 阅读记录
+	dao.NewGormReadHistoryDAO, cache.NewRedisReadHistoryCache,
+	repository.NewCachedReadHistoryRepository, service.NewReadHistoryService,
 )
 
 func InitInteractiveApp() *App {
@@ -33,6 +39,8 @@ func InitInteractiveApp() *App {
 		readcount.NewKafkaReadCountConsumer,
 		// 初始化点赞榜 like rank kafka
 		rank.NewKafkaLikeRankProducer, rank.NewKafkaLikeRankConsumer,
+		// 初始化收藏事件 用于作者的数据统计
+		collect.NewKafkaCollectProducer,
 		// 收集所有的consumer
 		readcount.CollectConsumer,
 		// 初始化 grpc server
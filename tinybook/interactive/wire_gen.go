@@ -8,6 +8,7 @@ package main
 
 import (
 	"github.com/google/wire"
+	"tinybook/tinybook/interactive/events/collect"
 	"tinybook/tinybook/interactive/events/rank"
 	"tinybook/tinybook/interactive/events/readcount"
 	"tinybook/tinybook/interactive/grpc"
@@ -34,11 +35,19 @@ func InitInteractiveApp() *App {
 	likeRankEventProducer := rank.NewKafkaLikeRankProducer(writer)
 	interactiveCache := cache.NewRedisInteractiveCache(cmdable, logger, theineCache, likeRankEventProducer)
 	interactiveRepository := repository.NewCachedInteractiveRepository(interactiveDAO, interactiveCache, logger)
-	readCountKafkaConsumer := readcount.NewKafkaReadCountConsumer(interactiveRepository, logger)
+	readHistoryDAO := dao.NewGormReadHistoryDAO(db)
+	readHistoryCache := cache.NewRedisReadHistoryCache(cmdable)
+	readHistoryRepository := repository.NewCachedReadHistoryRepository(readHistoryDAO, readHistoryCache, logger)
+	readCountKafkaConsumer := readcount.NewKafkaReadCountConsumer(interactiveRepository, readHistoryRepository, logger)
 	likeRankKafkaConsumer := rank.NewKafkaLikeRankConsumer(logger, theineCache, cmdable)
 	v := readcount.CollectConsumer(readCountKafkaConsumer, likeRankKafkaConsumer)
-	interactiveService := service.NewInteractiveService(interactiveRepository, likeRankEventProducer, logger)
-	interactiveServiceServer := grpc.NewInteractiveServiceServer(interactiveService)
+	collectFolderDAO := dao.NewGormCollectFolderDAO(db)
+	collectFolderRepository := repository.NewCollectFolderRepository(collectFolderDAO)
+	collectEventProducer := collect.NewKafkaCollectProducer(writer)
+	interactiveService := service.NewInteractiveService(interactiveRepository, collectFolderRepository, likeRankEventProducer, collectEventProducer, logger)
+	collectFolderService := service.NewCollectFolderService(collectFolderRepository)
+	readHistoryService := service.NewReadHistoryService(readHistoryRepository)
+	interactiveServiceServer := grpc.NewInteractiveServiceServer(interactiveService, collectFolderService, readHistoryService)
 	server := ioc.InitGrpcServer(interactiveServiceServer, logger)
 	app := &App{
 		consumers: v,
@@ -51,4 +60,4 @@ func InitInteractiveApp() *App {
 
 var thirdPartySet = wire.NewSet(ioc.InitDB, ioc.InitRedis, ioc.InitLogger, ioc.InitLocalCache)
 
-var interactiveServiceSet = wire.NewSet(dao.NewGormInteractiveDAO, cache.NewRedisInteractiveCache, repository.NewCachedInteractiveRepository, service.NewInteractiveService)
+var interactiveServiceSet = wire.NewSet(dao.NewGormInteractiveDAO, cache.NewRedisInteractiveCache, repository.NewCachedInteractiveRepository, service.NewInteractiveService, dao.NewGormCollectFolderDAO, repository.NewCollectFolderRepository, service.NewCollectFolderService, dao.NewGormReadHistoryDAO, cache.NewRedisReadHistoryCache, repository.NewCachedReadHistoryRepository, service.NewReadHistoryService)
@@ -65,3 +65,43 @@ func (i *InteractiveClient) GetLikeRanks(ctx context.Context, in *intrv1.GetLike
 func (i *InteractiveClient) GetByIds(ctx context.Context, in *intrv1.GetByIdsRequest, opts ...grpc.CallOption) (*intrv1.GetByIdsResponse, error) {
 	return i.selectClient().GetByIds(ctx, in, opts...)
 }
+
+func (i *InteractiveClient) Uncollect(ctx context.Context, in *intrv1.UncollectRequest, opts ...grpc.CallOption) (*intrv1.UncollectResponse, error) {
+	return i.selectClient().Uncollect(ctx, in, opts...)
+}
+
+func (i *InteractiveClient) CreateFolder(ctx context.Context, in *intrv1.CreateFolderRequest, opts ...grpc.CallOption) (*intrv1.CreateFolderResponse, error) {
+	return i.selectClient().CreateFolder(ctx, in, opts...)
+}
+
+func (i *InteractiveClient) RenameFolder(ctx context.Context, in *intrv1.RenameFolderRequest, opts ...grpc.CallOption) (*intrv1.RenameFolderResponse, error) {
+	return i.selectClient().RenameFolder(ctx, in, opts...)
+}
+
+func (i *InteractiveClient) DeleteFolder(ctx context.Context, in *intrv1.DeleteFolderRequest, opts ...grpc.CallOption) (*intrv1.DeleteFolderResponse, error) {
+	return i.selectClient().DeleteFolder(ctx, in, opts...)
+}
+
+func (i *InteractiveClient) ListFolders(ctx context.Context, in *intrv1.ListFoldersRequest, opts ...grpc.CallOption) (*intrv1.ListFoldersResponse, error) {
+	return i.selectClient().ListFolders(ctx, in, opts...)
+}
+
+func (i *InteractiveClient) MoveItem(ctx context.Context, in *intrv1.MoveItemRequest, opts ...grpc.CallOption) (*intrv1.MoveItemResponse, error) {
+	return i.selectClient().MoveItem(ctx, in, opts...)
+}
+
+func (i *InteractiveClient) ListFolderItems(ctx context.Context, in *intrv1.ListFolderItemsRequest, opts ...grpc.CallOption) (*intrv1.ListFolderItemsResponse, error) {
+	return i.selectClient().ListFolderItems(ctx, in, opts...)
+}
+
+func (i *InteractiveClient) ListReadHistory(ctx context.Context, in *intrv1.ListReadHistoryRequest, opts ...grpc.CallOption) (*intrv1.ListReadHistoryResponse, error) {
+	return i.selectClient().ListReadHistory(ctx, in, opts...)
+}
+
+func (i *InteractiveClient) DeleteReadHistory(ctx context.Context, in *intrv1.DeleteReadHistoryRequest, opts ...grpc.CallOption) (*intrv1.DeleteReadHistoryResponse, error) {
+	return i.selectClient().DeleteReadHistory(ctx, in, opts...)
+}
+
+func (i *InteractiveClient) ClearReadHistory(ctx context.Context, in *intrv1.ClearReadHistoryRequest, opts ...grpc.CallOption) (*intrv1.ClearReadHistoryResponse, error) {
+	return i.selectClient().ClearReadHistory(ctx, in, opts...)
+}
@@ -5,15 +5,19 @@ import (
 	"google.golang.org/grpc"
 	intrv1 "tinybook/tinybook/api/proto/gen/intr/v1"
 	"tinybook/tinybook/interactive/domain"
+	intrgrpc "tinybook/tinybook/interactive/grpc"
 	"tinybook/tinybook/interactive/service"
 )
 
 type LocalInteractiveServiceAdapter struct {
-	svc service.InteractiveService
+	svc        service.InteractiveService
+	folderSvc  service.CollectFolderService
+	historySvc service.ReadHistoryService
 }
 
-func NewLocalInteractiveServiceAdapter(svc service.InteractiveService) *LocalInteractiveServiceAdapter {
-	return &LocalInteractiveServiceAdapter{svc: svc}
+func NewLocalInteractiveServiceAdapter(svc service.InteractiveService, folderSvc service.CollectFolderService,
+	historySvc service.ReadHistoryService) *LocalInteractiveServiceAdapter {
+	return &LocalInteractiveServiceAdapter{svc: svc, folderSvc: folderSvc, historySvc: historySvc}
 }
 
 func (l *LocalInteractiveServiceAdapter) IncreaseReadCount(ctx context.Context, in *intrv1.IncreaseReadCountRequest, opts ...grpc.CallOption) (*intrv1.IncreaseReadCountResponse, error) {
@@ -33,7 +37,13 @@ func (l *LocalInteractiveServiceAdapter) Unlike(ctx context.Context, in *intrv1.
 
 func (l *LocalInteractiveServiceAdapter) Collect(ctx context.Context, in *intrv1.CollectRequest, opts ...grpc.CallOption) (*intrv1.CollectResponse, error) {
 	err := l.svc.Collect(ctx, in.GetBiz(), in.GetBizId(), in.GetCid(), in.GetUid())
-	return &intrv1.CollectResponse{}, err
+	// 与远程调用返回一样的错误码
+	return &intrv1.CollectResponse{}, intrgrpc.ToStatusError(err)
+}
+
+func (l *LocalInteractiveServiceAdapter) Uncollect(ctx context.Context, in *intrv1.UncollectRequest, opts ...grpc.CallOption) (*intrv1.UncollectResponse, error) {
+	err := l.svc.Uncollect(ctx, in.GetBiz(), in.GetBizId(), in.GetUid())
+	return &intrv1.UncollectResponse{}, intrgrpc.ToStatusError(err)
 }
 
 func (l *LocalInteractiveServiceAdapter) GetInteractive(ctx context.Context, in *intrv1.GetInteractiveRequest, opts ...grpc.CallOption) (*intrv1.GetInteractiveResponse, error) {
@@ -74,6 +84,114 @@ func (l *LocalInteractiveServiceAdapter) GetByIds(ctx context.Context, in *intrv
 	}, nil
 }
 
+func (l *LocalInteractiveServiceAdapter) CreateFolder(ctx context.Context, in *intrv1.CreateFolderRequest, opts ...grpc.CallOption) (*intrv1.CreateFolderResponse, error) {
+	id, err := l.folderSvc.CreateFolder(ctx, domain.CollectFolder{
+		Uid:         in.GetUid(),
+		Name:        in.GetName(),
+		Description: in.GetDescription(),
+	})
+	if err != nil {
+		return nil, intrgrpc.ToStatusError(err)
+	}
+	return &intrv1.CreateFolderResponse{Id: id}, nil
+}
+
+func (l *LocalInteractiveServiceAdapter) RenameFolder(ctx context.Context, in *intrv1.RenameFolderRequest, opts ...grpc.CallOption) (*intrv1.RenameFolderResponse, error) {
+	err := l.folderSvc.RenameFolder(ctx, domain.CollectFolder{
+		Id:          in.GetId(),
+		Uid:         in.GetUid(),
+		Name:        in.GetName(),
+		Description: in.GetDescription(),
+	})
+	return &intrv1.RenameFolderResponse{}, intrgrpc.ToStatusError(err)
+}
+
+func (l *LocalInteractiveServiceAdapter) DeleteFolder(ctx context.Context, in *intrv1.DeleteFolderRequest, opts ...grpc.CallOption) (*intrv1.DeleteFolderResponse, error) {
+	err := l.folderSvc.DeleteFolder(ctx, in.GetId(), in.GetUid())
+	return &intrv1.DeleteFolderResponse{}, intrgrpc.ToStatusError(err)
+}
+
+func (l *LocalInteractiveServiceAdapter) ListFolders(ctx context.Context, in *intrv1.ListFoldersRequest, opts ...grpc.CallOption) (*intrv1.ListFoldersResponse, error) {
+	folders, err := l.folderSvc.ListFolders(ctx, in.GetUid())
+	if err != nil {
+		return nil, intrgrpc.ToStatusError(err)
+	}
+	dtos := make([]*intrv1.CollectFolder, 0, len(folders))
+	for _, folder := range folders {
+		dtos = append(dtos, l.folderToDTO(folder))
+	}
+	return &intrv1.ListFoldersResponse{
+		Folders: dtos,
+	}, nil
+}
+
+func (l *LocalInteractiveServiceAdapter) MoveItem(ctx context.Context, in *intrv1.MoveItemRequest, opts ...grpc.CallOption) (*intrv1.MoveItemResponse, error) {
+	err := l.folderSvc.MoveItem(ctx, in.GetBiz(), in.GetBizId(), in.GetUid(), in.GetCid())
+	return &intrv1.MoveItemResponse{}, intrgrpc.ToStatusError(err)
+}
+
+func (l *LocalInteractiveServiceAdapter) ListFolderItems(ctx context.Context, in *intrv1.ListFolderItemsRequest, opts ...grpc.CallOption) (*intrv1.ListFolderItemsResponse, error) {
+	items, next, err := l.folderSvc.ListFolderItems(ctx, in.GetUid(), in.GetCid(), in.GetCursor(), int(in.GetLimit()))
+	if err != nil {
+		return nil, intrgrpc.ToStatusError(err)
+	}
+	dtos := make([]*intrv1.CollectItem, 0, len(items))
+	for _, item := range items {
+		dtos = append(dtos, &intrv1.CollectItem{
+			Id:    item.Id,
+			Cid:   item.Cid,
+			Biz:   item.Biz,
+			BizId: item.BizId,
+			Ctime: item.Ctime,
+		})
+	}
+	return &intrv1.ListFolderItemsResponse{
+		Items:      dtos,
+		NextCursor: next,
+	}, nil
+}
+
+func (l *LocalInteractiveServiceAdapter) ListReadHistory(ctx context.Context, in *intrv1.ListReadHistoryRequest, opts ...grpc.CallOption) (*intrv1.ListReadHistoryResponse, error) {
+	histories, next, err := l.historySvc.List(ctx, in.GetUid(), in.GetCursor(), int(in.GetLimit()))
+	if err != nil {
+		return nil, err
+	}
+	dtos := make([]*intrv1.ReadHistory, 0, len(histories))
+	for _, history := range histories {
+		dtos = append(dtos, &intrv1.ReadHistory{
+			Biz:   history.Biz,
+			BizId: history.BizId,
+			Rtime: history.Rtime,
+		})
+	}
+	return &intrv1.ListReadHistoryResponse{
+		Histories:  dtos,
+		NextCursor: next,
+	}, nil
+}
+
+func (l *LocalInteractiveServiceAdapter) DeleteReadHistory(ctx context.Context, in *intrv1.DeleteReadHistoryRequest, opts ...grpc.CallOption) (*intrv1.DeleteReadHistoryResponse, error) {
+	err := l.historySvc.Delete(ctx, in.GetUid(), in.GetBiz(), in.GetBizId())
+	return &intrv1.DeleteReadHistoryResponse{}, err
+}
+
+func (l *LocalInteractiveServiceAdapter) ClearReadHistory(ctx context.Context, in *intrv1.ClearReadHistoryRequest, opts ...grpc.CallOption) (*intrv1.ClearReadHistoryResponse, error) {
+	err := l.historySvc.Clear(ctx, in.GetUid())
+	return &intrv1.ClearReadHistoryResponse{}, err
+}
+
+func (l *LocalInteractiveServiceAdapter) folderToDTO(folder domain.CollectFolder) *intrv1.CollectFolder {
+	return &intrv1.CollectFolder{
+		Id:          folder.Id,
+		Uid:         folder.Uid,
+		Name:        folder.Name,
+		Description: folder.Description,
+		ItemCount:   folder.ItemCount,
+		Ctime:       folder.Ctime,
+		Utime:       folder.Utime,
+	}
+}
+
 func (l *LocalInteractiveServiceAdapter) interactiveToDTO(interactive domain.Interactive) *intrv1.Interactive {
 	return &intrv1.Interactive{
 		Biz:          interactive.Biz,
@@ -11,12 +11,37 @@ type Job struct {
 	// cron 表达式
 	Expression string
 	Executor   string
+	// 任务参数 由 executor 自行解析
+	Cfg string
+	// 下次执行时间 为空表达式的一次性任务使用
+	NextRunTime time.Time
+	// 一次性任务连续失败的次数
+	Retries    int
 	CancelFunc func()
 }
 
+// OneShot 没有 cron 表达式的任务只执行一次
+func (j *Job) OneShot() bool {
+	return j.Expression == ""
+}
+
 // NextTime 计算下次执行时间
 func (j *Job) NextTime() time.Time {
 	parser := cron.NewParser(cron.Second | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
 	schedule, _ := parser.Parse(j.Expression)
 	return schedule.Next(time.Now())
 }
+
+const (
+	retryBaseInterval = 10 * time.Second
+	retryMaxInterval  = time.Hour
+)
+
+// RetryTime 一次性任务失败后的重试时间 每失败一次等待时间翻倍，最长一小时
+func (j *Job) RetryTime() time.Time {
+	interval := retryMaxInterval
+	if j.Retries < 9 { // 10s * 2^9 已经超过一小时
+		interval = min(retryBaseInterval<<j.Retries, retryMaxInterval)
+	}
+	return time.Now().Add(interval)
+}
@@ -1,9 +1,14 @@
 package consumer
 
 import (
+	events3 "tinybook/tinybook/analytics/events"
+	"tinybook/tinybook/article/events/invalidation"
 	"tinybook/tinybook/internal/events"
+	events2 "tinybook/tinybook/reward/events"
 )
 
-func CollectConsumer() []events.Consumer {
-	return []events.Consumer{}
+func CollectConsumer(paymentConsumer *events2.PaymentEventConsumer,
+	invalidationConsumer *invalidation.CacheInvalidationConsumer,
+	analyticsConsumer *events3.AnalyticsConsumer) []events.Consumer {
+	return []events.Consumer{paymentConsumer, invalidationConsumer, analyticsConsumer}
 }
@@ -0,0 +1,40 @@
+package job
+
+import (
+	"context"
+	"github.com/bytedance/sonic"
+	"github.com/cockroachdb/errors"
+	"go.uber.org/zap"
+	"tinybook/tinybook/article/domain"
+	"tinybook/tinybook/article/service"
+	domain2 "tinybook/tinybook/internal/domain"
+)
+
+// ArticlePublishExecutor 定时发表文章的执行器 到期后把草稿同步到线上库
+type ArticlePublishExecutor struct {
+	svc service.ArticleService
+	log *zap.Logger
+}
+
+func NewArticlePublishExecutor(svc service.ArticleService, log *zap.Logger) *ArticlePublishExecutor {
+	return &ArticlePublishExecutor{svc: svc, log: log}
+}
+
+func (a *ArticlePublishExecutor) Name() string {
+	return domain.ArticlePublishExecutor
+}
+
+func (a *ArticlePublishExecutor) Execute(ctx context.Context, job domain2.Job) error {
+	var cfg domain.ScheduledPublish
+	err := sonic.UnmarshalString(job.Cfg, &cfg)
+	if err != nil {
+		return err
+	}
+	err = a.svc.PublishScheduled(ctx, cfg.ArticleId, cfg.AuthorId)
+	// 作者在到期前修改了文章状态(取消定时或直接发表)，不再处理
+	if errors.Is(err, service.ErrArticleNotScheduled) || errors.Is(err, service.ErrNotArticleAuthor) {
+		a.log.Info("scheduled article status changed, skip", zap.Int64("article_id", cfg.ArticleId))
+		return nil
+	}
+	return err
+}
@@ -10,6 +10,8 @@ import (
 	"tinybook/tinybook/internal/service"
 )
 
+var errUnknownExecutor = errors.New("unknown executor")
+
 type Executor interface {
 	Name() string
 	Execute(ctx context.Context, job domain.Job) error
@@ -47,20 +49,22 @@ func (l *LocalFuncExecutor) RegisterFunc(name string, f func(ctx context.Context
 }
 
 type Scheduler struct {
-	dbTimeout time.Duration
-	service   service.CronJobService
-	executors map[string]Executor
-	log       *zap.Logger
-	limiter   *semaphore.Weighted
+	dbTimeout    time.Duration
+	idleInterval time.Duration // 没有任务时的轮询间隔
+	service      service.CronJobService
+	executors    map[string]Executor
+	log          *zap.Logger
+	limiter      *semaphore.Weighted
 }
 
 func NewScheduler(jobService service.CronJobService, log *zap.Logger) *Scheduler {
 	return &Scheduler{
-		service:   jobService,
-		log:       log,
-		dbTimeout: time.Second,
-		limiter:   semaphore.NewWeighted(100), // 限制并发数
-		executors: make(map[string]Executor),
+		service:      jobService,
+		log:          log,
+		dbTimeout:    time.Second,
+		idleInterval: time.Second,
+		limiter:      semaphore.NewWeighted(100), // 限制并发数
+		executors:    make(map[string]Executor),
 	}
 }
 
@@ -82,13 +86,18 @@ func (s *Scheduler) Schedule(ctx context.Context) {
 		// 从数据库中获取任务
 		job, err := s.service.Preempt(timeout)
 		cancelFunc()
-		if err != nil {
+		if err != nil { // 没有可执行的任务，稍后再试
+			s.limiter.Release(1)
+			time.Sleep(s.idleInterval)
 			continue
 		}
 		// 找到对应的 executor
 		executor, ok := s.executors[job.Executor]
-		if !ok { // 未知的 executor，直接跳过
+		if !ok { // 未知的 executor，按执行失败处理 避免马上又被抢占
 			s.log.Error("unknown executor", zap.String("executor", job.Executor), zap.Int64("job_id", job.Id))
+			s.afterExecute(ctx, job, errUnknownExecutor)
+			s.limiter.Release(1)
+			job.CancelFunc()
 			continue
 		}
 		go func() {
@@ -99,10 +108,28 @@ func (s *Scheduler) Schedule(ctx context.Context) {
 			}()
 			// 执行任务
 			err2 := executor.Execute(ctx, job)
-			if err != nil {
+			if err2 != nil {
 				s.log.Error("execute job failed", zap.Error(err2), zap.Int64("job_id", job.Id))
 			}
-			return
+			s.afterExecute(ctx, job, err2)
 		}()
 	}
 }
+
+// afterExecute 周期任务计算下次执行时间，一次性任务成功后标记为完成，失败后按退避时间重试
+func (s *Scheduler) afterExecute(ctx context.Context, job domain.Job, execErr error) {
+	timeout, cancelFunc := context.WithTimeout(ctx, s.dbTimeout)
+	defer cancelFunc()
+	var err error
+	switch {
+	case !job.OneShot():
+		err = s.service.ResetNextRunTime(timeout, job)
+	case execErr != nil:
+		err = s.service.Retry(timeout, job)
+	default:
+		err = s.service.Complete(timeout, job)
+	}
+	if err != nil {
+		s.log.Error("update job after execute failed", zap.Error(err), zap.Int64("job_id", job.Id))
+	}
+}
@@ -0,0 +1,90 @@
+package job
+
+import (
+	"context"
+	"github.com/bsm/redislock"
+	"github.com/cockroachdb/errors"
+	"go.uber.org/zap"
+	"time"
+	"tinybook/tinybook/payment/service/wechat"
+)
+
+// PaymentSyncJob 和微信对账超时仍未完成的支付 收不到支付通知的订单靠它更新状态
+// 仍未支付的订单会被关闭并标记为失败，对应的打赏也随之失败
+type PaymentSyncJob struct {
+	svc     *wechat.NativePaymentService
+	lock    *redislock.Client
+	key     string
+	timeout time.Duration
+	// 下单后超过 expire 仍是初始状态的支付才对账 比订单的过期时间多留一点余量
+	expire    time.Duration
+	batchSize int
+	log       *zap.Logger
+}
+
+// NewPaymentSyncJob svc 为 nil 表示没有配置微信支付 任务什么也不做
+func NewPaymentSyncJob(svc *wechat.NativePaymentService, lock *redislock.Client, l *zap.Logger) *PaymentSyncJob {
+	return &PaymentSyncJob{
+		svc:       svc,
+		lock:      lock,
+		key:       "job:payment_sync",
+		timeout:   time.Minute * 5,
+		expire:    wechat.PaymentTimeout + time.Minute,
+		batchSize: 100,
+		log:       l,
+	}
+}
+
+func (p *PaymentSyncJob) Name() string {
+	return "payment_sync"
+}
+
+func (p *PaymentSyncJob) Run() error {
+	if p.svc == nil {
+		return nil
+	}
+	timeout, c := context.WithTimeout(context.Background(), time.Second*3)
+	defer c()
+	// 不重试 拿不到锁说明其他实例正在对账
+	lock, err := p.lock.Obtain(timeout, p.key, p.timeout, nil)
+	if errors.Is(err, redislock.ErrNotObtained) {
+		return nil
+	}
+	if err != nil {
+		p.log.Error("payment sync job lock failed", zap.Error(err))
+		return err
+	}
+	defer func() {
+		withTimeout, cancelFunc := context.WithTimeout(context.Background(), time.Second*3)
+		defer cancelFunc()
+		if err2 := lock.Release(withTimeout); err2 != nil {
+			p.log.Error("payment sync job unlock failed", zap.Error(err2))
+		}
+	}()
+	ctx, cancel := context.WithTimeout(context.Background(), p.timeout)
+	defer cancel()
+	deadline := time.Now().Add(-p.expire)
+	// 对账成功的支付不再是初始状态，会从结果中消失 只有对账失败的支付需要跳过
+	offset, synced := 0, 0
+	for {
+		pmts, err := p.svc.FindExpiredPayment(ctx, offset, p.batchSize, deadline)
+		if err != nil {
+			p.log.Error("find expired payment failed", zap.Error(err))
+			return err
+		}
+		for _, pmt := range pmts {
+			err = p.svc.SyncExpiredPayment(ctx, pmt.BizTradeNO)
+			if err != nil {
+				p.log.Error("sync expired payment failed", zap.String("biz_trade_no", pmt.BizTradeNO), zap.Error(err))
+				offset++
+				continue
+			}
+			synced++
+		}
+		if len(pmts) < p.batchSize {
+			break
+		}
+	}
+	p.log.Info("payment sync finished", zap.Int("synced", synced), zap.Int("failed", offset))
+	return nil
+}
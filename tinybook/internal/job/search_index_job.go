@@ -0,0 +1,34 @@
+package job
+
+import (
+	"context"
+	"go.uber.org/zap"
+	"time"
+	"tinybook/tinybook/article/service"
+)
+
+// SearchIndexJob 全量重建本实例的文章搜索索引
+// 索引在进程内，每个实例都需要执行，因此不需要分布式锁
+type SearchIndexJob struct {
+	articleSvc service.ArticleService
+	timeout    time.Duration
+	log        *zap.Logger
+}
+
+func NewSearchIndexJob(articleSvc service.ArticleService, l *zap.Logger) *SearchIndexJob {
+	return &SearchIndexJob{articleSvc: articleSvc, timeout: time.Minute * 5, log: l}
+}
+
+func (s *SearchIndexJob) Name() string {
+	return "search_index"
+}
+
+func (s *SearchIndexJob) Run() error {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+	err := s.articleSvc.RebuildSearchIndex(ctx)
+	if err != nil {
+		s.log.Error("rebuild search index failed", zap.Error(err))
+	}
+	return err
+}
@@ -0,0 +1,71 @@
+package job
+
+import (
+	"context"
+	"github.com/bsm/redislock"
+	"github.com/cockroachdb/errors"
+	"go.uber.org/zap"
+	"time"
+	"tinybook/tinybook/article/service"
+)
+
+// TrashPurgeJob 彻底删除回收站中超过保留期限的文章，然后清理对象存储中不再被引用的正文
+// 多个实例同时执行没有意义，用分布式锁保证同一时间只有一个实例在清理
+type TrashPurgeJob struct {
+	articleSvc service.ArticleService
+	lock       *redislock.Client
+	key        string
+	timeout    time.Duration
+	log        *zap.Logger
+}
+
+func NewTrashPurgeJob(articleSvc service.ArticleService, lock *redislock.Client, l *zap.Logger) *TrashPurgeJob {
+	return &TrashPurgeJob{
+		articleSvc: articleSvc,
+		lock:       lock,
+		key:        "job:trash_purge",
+		timeout:    time.Minute * 30,
+		log:        l,
+	}
+}
+
+func (t *TrashPurgeJob) Name() string {
+	return "trash_purge"
+}
+
+func (t *TrashPurgeJob) Run() error {
+	timeout, c := context.WithTimeout(context.Background(), time.Second*3)
+	defer c()
+	// 不重试 拿不到锁说明其他实例正在清理
+	lock, err := t.lock.Obtain(timeout, t.key, t.timeout, nil)
+	if errors.Is(err, redislock.ErrNotObtained) {
+		return nil
+	}
+	if err != nil {
+		t.log.Error("trash purge job lock failed", zap.Error(err))
+		return err
+	}
+	defer func() {
+		withTimeout, cancelFunc := context.WithTimeout(context.Background(), time.Second*3)
+		defer cancelFunc()
+		if err2 := lock.Release(withTimeout); err2 != nil {
+			t.log.Error("trash purge job unlock failed", zap.Error(err2))
+		}
+	}()
+	ctx, cancel := context.WithTimeout(context.Background(), t.timeout)
+	defer cancel()
+	n, err := t.articleSvc.PurgeTrash(ctx)
+	if err != nil {
+		t.log.Error("purge trash failed", zap.Int64("purged", n), zap.Error(err))
+		return err
+	}
+	t.log.Info("purge trash finished", zap.Int64("purged", n))
+	// 彻底删除的文章及其历史版本引用的正文 以及写入对象存储后保存文章失败留下的正文，都在这里清理
+	n, err = t.articleSvc.CollectContent(ctx)
+	if err != nil {
+		t.log.Error("collect article content failed", zap.Int64("deleted", n), zap.Error(err))
+		return err
+	}
+	t.log.Info("collect article content finished", zap.Int64("deleted", n))
+	return nil
+}
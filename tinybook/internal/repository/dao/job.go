@@ -4,19 +4,27 @@ import (
 	"context"
 	"errors"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"time"
 )
 
+var ErrJobNotWaiting = errors.New("任务不处于等待状态")
+
 type Job struct {
-	Id       int64
-	Status   int
-	Version  int
-	Name     string `gorm:"column:name;type:varchar(255);not null;uniqueIndex"`
-	Executor string
+	Id         int64
+	Status     int
+	Version    int
+	Name       string `gorm:"column:name;type:varchar(255);not null;uniqueIndex"`
+	Executor   string
+	Expression string // cron 表达式 为空表示一次性任务
+	Cfg        string `gorm:"column:cfg;type:text"` // 任务参数
 
 	NextRunTime int64 `gorm:"column:next_run_time;index"` // 下次执行时间
 	Ctime       int64
 	Utime       int64
+
+	// 一次性任务连续失败的次数 用于计算重试的退避时间
+	Retries int `gorm:"column:retries;not null;default:0"`
 }
 
 const (
@@ -31,6 +39,12 @@ type CronJobDao interface {
 	Release(ctx context.Context, id int64) error
 	UpdateUTime(ctx context.Context, id int64) error
 	UpdateNextTime(ctx context.Context, id int64, t time.Time) error
+	// Upsert 任务正在执行时返回 ErrJobNotWaiting
+	Upsert(ctx context.Context, job Job) error
+	Cancel(ctx context.Context, name string) error
+	Complete(ctx context.Context, id int64) error
+	// Retry 执行失败后在 t 时刻重试 失败次数加一
+	Retry(ctx context.Context, id int64, t time.Time) error
 }
 
 type GormCronJobDao struct {
@@ -46,6 +60,7 @@ func (g *GormCronJobDao) UpdateNextTime(ctx context.Context, id int64, t time.Ti
 	db := g.db.WithContext(ctx)
 	return db.Model(&Job{}).Where("id = ?", id).Updates(map[string]any{
 		"next_run_time": t.UnixMilli(),
+		"retries":       0,
 		"utime":         now.UnixMilli(),
 	}).Error
 }
@@ -66,17 +81,17 @@ func (g *GormCronJobDao) Preempt(ctx context.Context) (Job, error) {
 		if err != nil {
 			return Job{}, err
 		}
-		err = db.Model(&job).Where("version = ?", job.Version).
+		res := db.Model(&job).Where("version = ?", job.Version).
 			Updates(map[string]any{
 				"status":  JobStatusRunning,
 				"version": job.Version + 1,
 				"utime":   now,
-			}).Error
-		if err != nil {
-			if errors.Is(err, gorm.ErrRecordNotFound) { // 乐观锁失败，说明已经被其他协程抢占
-				continue
-			}
-			return Job{}, err
+			})
+		if res.Error != nil {
+			return Job{}, res.Error
+		}
+		if res.RowsAffected == 0 { // 乐观锁失败，说明已经被其他协程抢占
+			continue
 		}
 		return job, nil
 	}
@@ -85,7 +100,8 @@ func (g *GormCronJobDao) Preempt(ctx context.Context) (Job, error) {
 func (g *GormCronJobDao) Release(ctx context.Context, id int64) error {
 	db := g.db.WithContext(ctx)
 	now := time.Now().UnixMilli()
-	err := db.Model(&Job{}).Where("id = ?", id).Updates(map[string]any{
+	// 只释放运行中的任务，已完成的一次性任务保持完成状态
+	err := db.Model(&Job{}).Where("id = ? and status = ?", id, JobStatusRunning).Updates(map[string]any{
 		"status": JobStatusWaiting,
 		"utime":  now,
 	}).Error
@@ -100,3 +116,71 @@ func (g *GormCronJobDao) UpdateUTime(ctx context.Context, id int64) error {
 	}).Error
 	return err
 }
+
+// Upsert 按任务名新增任务，任务已存在时重新置为等待状态
+// 正在执行的任务不能覆盖，否则执行完毕后会把新的任务标记为完成
+func (g *GormCronJobDao) Upsert(ctx context.Context, job Job) error {
+	now := time.Now().UnixMilli()
+	job.Status = JobStatusWaiting
+	job.Ctime, job.Utime = now, now
+	return g.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var old Job
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("name = ?", job.Name).
+			First(&old).Error
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return tx.Create(&job).Error
+		case err != nil:
+			return err
+		case old.Status == JobStatusRunning:
+			return ErrJobNotWaiting
+		}
+		return tx.Model(&old).Updates(map[string]any{
+			"status":        JobStatusWaiting,
+			"version":       old.Version + 1,
+			"executor":      job.Executor,
+			"expression":    job.Expression,
+			"cfg":           job.Cfg,
+			"next_run_time": job.NextRunTime,
+			"retries":       0,
+			"utime":         now,
+		}).Error
+	})
+}
+
+// Cancel 取消等待中的任务 运行中的任务无法取消
+func (g *GormCronJobDao) Cancel(ctx context.Context, name string) error {
+	res := g.db.WithContext(ctx).Model(&Job{}).
+		Where("name = ? and status = ?", name, JobStatusWaiting).
+		Updates(map[string]any{
+			"status": JobStatusDone,
+			"utime":  time.Now().UnixMilli(),
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrJobNotWaiting
+	}
+	return nil
+}
+
+func (g *GormCronJobDao) Complete(ctx context.Context, id int64) error {
+	return g.db.WithContext(ctx).Model(&Job{}).Where("id = ?", id).Updates(map[string]any{
+		"status": JobStatusDone,
+		"utime":  time.Now().UnixMilli(),
+	}).Error
+}
+
+// Retry 只处理运行中的任务 重新置为等待状态，不会被随后的 Release 改回
+func (g *GormCronJobDao) Retry(ctx context.Context, id int64, t time.Time) error {
+	return g.db.WithContext(ctx).Model(&Job{}).
+		Where("id = ? and status = ?", id, JobStatusRunning).
+		Updates(map[string]any{
+			"status":        JobStatusWaiting,
+			"next_run_time": t.UnixMilli(),
+			"retries":       gorm.Expr("retries + 1"),
+			"utime":         time.Now().UnixMilli(),
+		}).Error
+}
@@ -12,8 +12,14 @@ type CronJobRepository interface {
 	Release(ctx context.Context, jId int64) error
 	UpdateUTime(ctx context.Context, id int64) error
 	UpdateNextTime(ctx context.Context, id int64, time time.Time) error
+	AddJob(ctx context.Context, j domain.Job) error
+	CancelJob(ctx context.Context, name string) error
+	Complete(ctx context.Context, id int64) error
+	Retry(ctx context.Context, id int64, t time.Time) error
 }
 
+var ErrJobNotWaiting = dao.ErrJobNotWaiting
+
 type cronJobRepository struct {
 	dao dao.CronJobDao
 }
@@ -37,10 +43,38 @@ func (c *cronJobRepository) Preempt(ctx context.Context) (domain.Job, error) {
 		return domain.Job{}, err
 	}
 	return domain.Job{
-		Id: job.Id,
+		Id:          job.Id,
+		Name:        job.Name,
+		Expression:  job.Expression,
+		Executor:    job.Executor,
+		Cfg:         job.Cfg,
+		NextRunTime: time.UnixMilli(job.NextRunTime),
+		Retries:     job.Retries,
 	}, nil
 }
 
+func (c *cronJobRepository) AddJob(ctx context.Context, j domain.Job) error {
+	return c.dao.Upsert(ctx, dao.Job{
+		Name:        j.Name,
+		Executor:    j.Executor,
+		Expression:  j.Expression,
+		Cfg:         j.Cfg,
+		NextRunTime: j.NextRunTime.UnixMilli(),
+	})
+}
+
+func (c *cronJobRepository) CancelJob(ctx context.Context, name string) error {
+	return c.dao.Cancel(ctx, name)
+}
+
+func (c *cronJobRepository) Retry(ctx context.Context, id int64, t time.Time) error {
+	return c.dao.Retry(ctx, id, t)
+}
+
+func (c *cronJobRepository) Complete(ctx context.Context, id int64) error {
+	return c.dao.Complete(ctx, id)
+}
+
 func (c *cronJobRepository) Release(ctx context.Context, jId int64) error {
 	return c.dao.Release(ctx, jId)
 }
@@ -11,6 +11,9 @@ import (
 type CronJobService interface {
 	Preempt(ctx context.Context) (domain.Job, error)
 	ResetNextRunTime(ctx context.Context, j domain.Job) error
+	Complete(ctx context.Context, j domain.Job) error
+	// Retry 一次性任务执行失败 按退避时间重新等待执行
+	Retry(ctx context.Context, j domain.Job) error
 }
 
 type cronJobService struct {
@@ -33,6 +36,15 @@ func (c *cronJobService) ResetNextRunTime(ctx context.Context, j domain.Job) err
 	return c.repo.UpdateNextTime(ctx, j.Id, nextTime)
 }
 
+// Complete 一次性任务执行完毕后标记为完成
+func (c *cronJobService) Complete(ctx context.Context, j domain.Job) error {
+	return c.repo.Complete(ctx, j.Id)
+}
+
+func (c *cronJobService) Retry(ctx context.Context, j domain.Job) error {
+	return c.repo.Retry(ctx, j.Id, j.RetryTime())
+}
+
 func (c *cronJobService) Preempt(ctx context.Context) (domain.Job, error) {
 	job, err := c.repo.Preempt(ctx)
 	if err != nil {
@@ -22,7 +22,7 @@ type BatchRankingService struct {
 	BatchSize   int // 每次获取的文章数量
 	topNum      int // 排行榜数量
 	ScoreFunc   func(likeCount int64, utime time.Time) float64
-	queue       priorityqueue.PriorityQueue[domain.Article, float64] // 优先队列
+	queue       priorityqueue.PriorityQueue[int64, float64] // 优先队列 存放文章ID(domain.Article 含切片，不可比较)
 	rankingRepo repository.RankingRepository
 }
 
@@ -39,7 +39,7 @@ func NewBatchRankingService(articleSvc service.ArticleService, repo repository.R
 		ScoreFunc: func(likeCount int64, utime time.Time) float64 {
 			return float64(likeCount-1) / math.Pow(time.Now().Sub(utime).Seconds()+2, 1.8)
 		},
-		queue: priorityqueue.New[domain.Article, float64](priorityqueue.MinHeap),
+		queue: priorityqueue.New[int64, float64](priorityqueue.MinHeap),
 	}
 }
 
@@ -55,10 +55,11 @@ func (b *BatchRankingService) TopN(ctx context.Context) error {
 func (b *BatchRankingService) topN(ctx context.Context) ([]domain.Article, error) {
 	now := time.Now()
 	ddl := now.Add(-time.Hour * 24 * 7) // 一周前
-	offset := 0
+	var cursor domain.Cursor
+	candidates := make(map[int64]domain.Article) // 文章ID -> 文章
 	for {
 		// 获取article
-		listPub, err := b.ArticleSvc.ListPub(ctx, now, b.BatchSize, offset)
+		listPub, err := b.ArticleSvc.ListPub(ctx, cursor, b.BatchSize)
 		if err != nil {
 			return nil, err
 		}
@@ -79,6 +80,7 @@ func (b *BatchRankingService) topN(ctx context.Context) ([]domain.Article, error
 		}
 		interactives := byIds.GetInteractives()
 		for _, article := range listPub {
+			candidates[article.ID] = article
 			intr := interactives[article.ID]
 			score := b.ScoreFunc(intr.LikeCount, time.Unix(article.Utime, 0))
 			if b.queue.Len() > b.topNum {
@@ -90,18 +92,18 @@ func (b *BatchRankingService) topN(ctx context.Context) ([]domain.Article, error
 				if score < queueMin.Priority { // 当前元素小于最小元素
 					continue
 				}
-				b.queue.Put(article, score)
+				b.queue.Put(article.ID, score)
 			}
-			b.queue.Put(article, score)
+			b.queue.Put(article.ID, score)
 		}
-		offset += len(listPub)
+		cursor = domain.CursorOf(listPub[len(listPub)-1])
 		if len(listPub) < b.BatchSize || listPub[len(listPub)-1].Utime < ddl.Unix() { // 如果最后一条数据的时间超过了ddl，就不再继续获取
 			break
 		}
 	}
 	articles := make([]domain.Article, b.queue.Len())
 	for b.queue.Len() > 0 {
-		articles = append(articles, b.queue.Get().Value)
+		articles = append(articles, candidates[b.queue.Get().Value])
 	}
 	return articles, nil
 }
@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	jwt2 "tinybook/tinybook/internal/web/jwt"
+)
+
+// AdminMiddlewareBuilder 只允许管理员访问 需要放在登录中间件之后
+type AdminMiddlewareBuilder struct {
+	isAdmin func(uid int64) bool
+}
+
+func NewAdminMiddlewareBuilder(isAdmin func(uid int64) bool) *AdminMiddlewareBuilder {
+	return &AdminMiddlewareBuilder{isAdmin: isAdmin}
+}
+
+func (builder *AdminMiddlewareBuilder) Build() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		claims, ok := ctx.Get("userClaims")
+		if !ok {
+			ctx.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+		if !builder.isAdmin(claims.(jwt2.UserClaims).Uid) {
+			ctx.AbortWithStatus(http.StatusForbidden)
+			return
+		}
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/samber/lo"
 	"net/http"
+	"strings"
 	jwt2 "tinybook/tinybook/internal/web/jwt"
 )
 
@@ -24,13 +25,19 @@ func (builder *LoginJWTMiddlewareBuilder) Build() gin.HandlerFunc {
 	pathList = append(pathList,
 		"/users/login", "/users/signup",
 		"/users/login_sms/code/send", "/users/login_sms",
-		"/oauth2/wechat/authurl", "/oauth2/wechat/callback")
+		"/oauth2/wechat/authurl", "/oauth2/wechat/callback",
+		// 微信支付结果通知 由微信服务器调用，靠签名校验身份
+		"/payment/wechat/notify")
 	return func(ctx *gin.Context) {
 		path := ctx.Request.URL.Path
 		// 登录和注册不需要经过登录中间件, 可以在未经认证的情况下访问
 		if lo.Contains(pathList, path) {
 			return
 		}
+		// 订阅源给 RSS 阅读器使用 分享链接和公开的文章页给未登录的读者使用 不需要登录
+		if strings.HasPrefix(path, "/feeds/") || strings.HasPrefix(path, "/share/") || strings.HasPrefix(path, "/p/") {
+			return
+		}
 		// 从header中提取jwt token
 		jwtToken := builder.jwtHandler.ExtractAuthorization(ctx)
 		var claims jwt2.UserClaims
@@ -0,0 +1,19 @@
+package ioc
+
+import (
+	"context"
+	"github.com/cockroachdb/errors"
+	"tinybook/tinybook/analytics/service"
+	repository3 "tinybook/tinybook/article/repository"
+)
+
+// InitAnalyticsAuthorResolver 只查文章的元数据 不从对象存储加载正文
+func InitAnalyticsAuthorResolver(repo repository3.ArticleRepository) service.AuthorResolver {
+	return service.AuthorResolverFunc(func(ctx context.Context, artId int64) (int64, error) {
+		authorId, err := repo.GetAuthorId(ctx, artId)
+		if errors.Is(err, repository3.ErrArticleNotFound) {
+			return 0, service.ErrArticleNotFound
+		}
+		return authorId, err
+	})
+}
@@ -0,0 +1,20 @@
+package ioc
+
+import (
+	"github.com/Yiling-J/theine-go"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/redis/go-redis/v9"
+	"tinybook/tinybook/article/repository/cache"
+)
+
+// InitPubArticleCache 已发表文章的两级缓存 按层统计命中率
+func InitPubArticleCache(local *theine.Cache[string, any], cli redis.Cmdable) cache.PubArticleCache {
+	counter := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "tinybook",
+		Subsystem: "article",
+		Name:      "pub_cache",
+		Help:      "已发表文章各级缓存的命中情况",
+	}, []string{"layer", "result"})
+	prometheus.MustRegister(counter)
+	return cache.NewMultiLevelPubArticleCache(local, cli, counter)
+}
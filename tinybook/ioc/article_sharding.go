@@ -0,0 +1,67 @@
+package ioc
+
+import (
+	"context"
+	"github.com/qiniu/qmgo"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"time"
+	"tinybook/tinybook/article/repository/dao"
+)
+
+type articleShardingConfig struct {
+	DSNs   []string `yaml:"dsns"`
+	IDStep int64    `yaml:"idStep"`
+}
+
+// InitArticleDAO 配置了 articleSharding.dsns 时文章按作者分库存放在 MySQL 中，否则存放在 MongoDB 中
+func InitArticleDAO(client *qmgo.Client, db *qmgo.Database, zipLog *zap.Logger) dao.ArticleDAO {
+	cfg := articleShardingConfig{IDStep: 1000}
+	err := viper.UnmarshalKey("articleSharding", &cfg)
+	if err != nil {
+		panic(err)
+	}
+	if len(cfg.DSNs) == 0 {
+		return dao.NewMongoDBArticleDAO(client, db)
+	}
+	return initShardingArticleDAO(cfg, zipLog)
+}
+
+// initShardingArticleDAO 按作者分库的文章DAO，作者按 author_id % 分库数量 路由，
+// 上线后分库的数量和顺序都不能修改，追加分库同样会让已有的作者路由到别的库，需要迁移数据才能扩容
+// 文章ID由第一个库中的号段表统一分配 表结构和分库记录需要事先用 cmd/articleshard 创建，
+// 启动时配置的分库与记录不一致直接退出
+func initShardingArticleDAO(cfg articleShardingConfig, zipLog *zap.Logger) dao.ArticleDAO {
+	dbs := make([]*gorm.DB, 0, len(cfg.DSNs))
+	for _, dsn := range cfg.DSNs {
+		db, er := gorm.Open(mysql.Open(dsn), &gorm.Config{
+			Logger: logger.New(gormLoggerFunc(func(msg string, data ...any) {
+				zipLog.Info(msg, zap.Any("gorm", data))
+			}), logger.Config{
+				SlowThreshold: 200 * time.Millisecond,
+				LogLevel:      logger.Warn,
+			}),
+		})
+		if er != nil {
+			panic(er)
+		}
+		dbs = append(dbs, db)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := dao.VerifyShards(ctx, dbs); err != nil {
+		panic(err)
+	}
+	idGen, err := dao.NewSegmentIDGenerator(dbs[0], "article", cfg.IDStep)
+	if err != nil {
+		panic(err)
+	}
+	articleDAO, err := dao.NewShardingArticleDAO(dbs, idGen)
+	if err != nil {
+		panic(err)
+	}
+	return articleDAO
+}
@@ -0,0 +1,30 @@
+package ioc
+
+import (
+	"context"
+	"github.com/cockroachdb/errors"
+	domain2 "tinybook/tinybook/article/domain"
+	repository3 "tinybook/tinybook/article/repository"
+	"tinybook/tinybook/comment/domain"
+	"tinybook/tinybook/comment/service"
+)
+
+// InitCommentBizResolver 目前只有文章可以评论，且只有已发表的文章允许新增评论
+func InitCommentBizResolver(repo repository3.ArticleRepository) service.BizResolver {
+	return service.BizResolverFunc(func(ctx context.Context, biz string, bizId int64) (domain.Target, error) {
+		if biz != "article" {
+			return domain.Target{}, service.ErrBizNotFound
+		}
+		art, err := repo.GetPubArticleById(ctx, bizId)
+		if errors.Is(err, repository3.ErrArticleNotFound) {
+			return domain.Target{}, service.ErrBizNotFound
+		}
+		if err != nil {
+			return domain.Target{}, err
+		}
+		return domain.Target{
+			OwnerId:     art.Author.ID,
+			Commentable: art.Status == domain2.ArticleStatusPublished,
+		}, nil
+	})
+}
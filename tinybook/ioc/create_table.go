@@ -2,8 +2,15 @@ package ioc
 
 import (
 	"gorm.io/gorm"
+	dao9 "tinybook/tinybook/analytics/repository/dao"
 	dao2 "tinybook/tinybook/article/repository/dao"
+	dao3 "tinybook/tinybook/comment/repository/dao"
+	dao5 "tinybook/tinybook/feed/repository/dao"
+	dao4 "tinybook/tinybook/follow/repository/dao"
 	"tinybook/tinybook/internal/repository/dao"
+	dao8 "tinybook/tinybook/notification/repository/dao"
+	dao6 "tinybook/tinybook/payment/repository/dao"
+	dao7 "tinybook/tinybook/reward/repository/dao"
 )
 
 func CreateTable(db *gorm.DB) {
@@ -12,7 +19,27 @@ func CreateTable(db *gorm.DB) {
 		&dao.SMS{},
 		&dao2.Article{},
 		&dao2.PublishedArticle{},
+		&dao2.ArticleRevision{},
+		&dao2.ArticleTag{},
+		&dao2.PublishedArticleTag{},
+		&dao2.ArticleReview{},
+		&dao2.ArticleStatusLog{},
+		&dao2.ArticleShareLink{},
+		&dao2.ArticleSeries{},
+		&dao2.SeriesArticle{},
 		&dao.Job{},
+		&dao3.Comment{},
+		&dao4.FollowRelation{},
+		&dao4.FollowStatics{},
+		&dao5.FeedInbox{},
+		&dao5.FeedOutbox{},
+		&dao6.Payment{},
+		&dao7.Reward{},
+		&dao8.Notification{},
+		&dao9.ArticleDailyStats{},
+		&dao9.AuthorDailyStats{},
+		&dao9.ArticleDailyReader{},
+		&dao9.AuthorDailyReader{},
 	)
 	if err != nil {
 		panic(err)
@@ -0,0 +1,22 @@
+package ioc
+
+import (
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+	"tinybook/tinybook/feed/repository"
+	"tinybook/tinybook/feed/service"
+	service2 "tinybook/tinybook/follow/service"
+)
+
+// InitFeedService 粉丝数超过 feed.pushThreshold 的作者发表文章时不推送，由读者拉取
+func InitFeedService(repo repository.FeedRepository, followSvc service2.FollowService, log *zap.Logger) service.FeedService {
+	type Config struct {
+		PushThreshold int64 `yaml:"pushThreshold"`
+	}
+	cfg := Config{PushThreshold: 1000}
+	err := viper.UnmarshalKey("feed", &cfg)
+	if err != nil {
+		panic(err)
+	}
+	return service.NewFeedService(repo, followSvc, cfg.PushThreshold, log)
+}
@@ -40,7 +40,8 @@ func InitIntrClientV1(client3 *etcdv3.Client) intrv1.InteractiveServiceClient {
 }
 
 // InitIntrClient 初始化交互服务客户端 用于本地和远程服务的联合调用
-func InitIntrClient(service service.InteractiveService) intrv1.InteractiveServiceClient {
+func InitIntrClient(service service.InteractiveService, folderService service.CollectFolderService,
+	historyService service.ReadHistoryService) intrv1.InteractiveServiceClient {
 	type Config struct {
 		Addr      string `yaml:"addr"`
 		Threshold int32  `yaml:"threshold"` // 阈值 用于判断是调用本地服务还是远程服务
@@ -57,7 +58,7 @@ func InitIntrClient(service service.InteractiveService) intrv1.InteractiveServic
 		panic(err)
 	}
 	remote := intrv1.NewInteractiveServiceClient(conn)
-	local := client2.NewLocalInteractiveServiceAdapter(service)
+	local := client2.NewLocalInteractiveServiceAdapter(service, folderService, historyService)
 	interactiveClient := client2.NewInteractiveClient(remote, local, cfg.Threshold)
 
 	// 监听配置变化
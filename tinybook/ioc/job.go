@@ -14,7 +14,8 @@ func InitRankingJob(svc service.RankingService, client *redislock.Client, logger
 	return job.NewRankingJob(svc, time.Second*30, client, logger)
 }
 
-func InitJobs(log *zap.Logger, rankJob *job.RankingJob) *cron.Cron {
+func InitJobs(log *zap.Logger, rankJob *job.RankingJob, searchJob *job.SearchIndexJob, purgeJob *job.TrashPurgeJob,
+	paymentJob *job.PaymentSyncJob) *cron.Cron {
 	builder := job.NewCronJobBuilder(log, prometheus.SummaryOpts{
 		Namespace: "tinybook",
 		Subsystem: "job",
@@ -33,5 +34,24 @@ func InitJobs(log *zap.Logger, rankJob *job.RankingJob) *cron.Cron {
 	if err != nil {
 		panic(err)
 	}
+	_, err = c.AddJob("@every 10m", builder.Build(searchJob)) // 定时重建搜索索引，同步其他实例上的变更
+	if err != nil {
+		panic(err)
+	}
+	_, err = c.AddJob("0 0 3 * * *", builder.Build(purgeJob)) // 每天凌晨3点清理回收站
+	if err != nil {
+		panic(err)
+	}
+	_, err = c.AddJob("@every 5m", builder.Build(paymentJob)) // 对账超时未完成的支付
+	if err != nil {
+		panic(err)
+	}
 	return c
 }
+
+// InitScheduler 初始化基于数据库抢占的任务调度器，并注册所有执行器
+func InitScheduler(svc service.CronJobService, log *zap.Logger, publishExecutor *job.ArticlePublishExecutor) *job.Scheduler {
+	scheduler := job.NewScheduler(svc, log)
+	scheduler.RegisterExecutor(publishExecutor) // 定时发表文章
+	return scheduler
+}
@@ -5,8 +5,22 @@ import (
 	"github.com/spf13/viper"
 	"strings"
 	"time"
+	"tinybook/tinybook/pkg/kafkax"
 )
 
+// InitKafkaBrokers kafka.brokers 中以逗号分隔的地址
+func InitKafkaBrokers() kafkax.Brokers {
+	type config struct {
+		Brokers string `yaml:"brokers"`
+	}
+	var cfg config
+	err := viper.UnmarshalKey("kafka", &cfg)
+	if err != nil {
+		panic(err)
+	}
+	return strings.Split(cfg.Brokers, ",")
+}
+
 func InitWriter() *kafka.Writer {
 	type config struct {
 		Brokers string `yaml:"brokers"`
@@ -0,0 +1,68 @@
+package ioc
+
+import (
+	"context"
+	"fmt"
+	"github.com/samber/lo"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+	"time"
+	"tinybook/tinybook/article/domain"
+	service3 "tinybook/tinybook/article/service"
+	"tinybook/tinybook/internal/web/middleware"
+	domain2 "tinybook/tinybook/notification/domain"
+	"tinybook/tinybook/notification/service"
+	"tinybook/tinybook/pkg/sensitive"
+)
+
+// InitSensitiveDict 加载敏感词词典 并定时检查词典文件，修改后自动重新加载
+func InitSensitiveDict(l *zap.Logger) *sensitive.Dict {
+	type Config struct {
+		DictPath       string        `yaml:"dictPath"`
+		ReloadInterval time.Duration `yaml:"reloadInterval"`
+	}
+	cfg := Config{DictPath: "./config/sensitive_words.txt", ReloadInterval: 30 * time.Second}
+	err := viper.UnmarshalKey("moderation", &cfg)
+	if err != nil {
+		panic(err)
+	}
+	dict := sensitive.NewDict()
+	err = dict.LoadFile(cfg.DictPath)
+	if err != nil {
+		panic(err)
+	}
+	go dict.WatchFile(context.Background(), cfg.DictPath, cfg.ReloadInterval, func(err error) {
+		l.Error("reload sensitive dict failed", zap.Error(err), zap.String("path", cfg.DictPath))
+	})
+	return dict
+}
+
+// InitAdminMiddlewareBuilder 管理员名单为 moderation.admins 每次请求时读取，修改配置后立即生效
+func InitAdminMiddlewareBuilder() *middleware.AdminMiddlewareBuilder {
+	return middleware.NewAdminMiddlewareBuilder(func(uid int64) bool {
+		return lo.Contains(viper.GetIntSlice("moderation.admins"), int(uid))
+	})
+}
+
+// InitReviewNotifier 审核结果以站内通知的形式发给作者
+func InitReviewNotifier(svc service.NotificationService) service3.ReviewNotifier {
+	return service3.ReviewNotifierFunc(func(ctx context.Context, review domain.ArticleReview) error {
+		n := domain2.Notification{
+			Uid:   review.AuthorId,
+			Type:  domain2.TypeArticleReview,
+			Biz:   "article",
+			BizId: review.ArticleId,
+		}
+		switch review.Status {
+		case domain.ReviewStatusApproved:
+			n.Title = "文章审核通过"
+			n.Content = fmt.Sprintf("你的文章《%s》已通过审核", review.Title)
+		case domain.ReviewStatusRejected:
+			n.Title = "文章审核未通过"
+			n.Content = fmt.Sprintf("你的文章《%s》未通过审核，已退回草稿。理由：%s", review.Title, review.Reason)
+		default:
+			return nil
+		}
+		return svc.Send(ctx, n)
+	})
+}
@@ -14,7 +14,8 @@ import (
 	"sync"
 )
 
-func InitMongoDB(zipLog *zap.Logger) *qmgo.Database {
+// InitMongoClient 事务需要用到 client，因此与 InitMongoDB 分开
+func InitMongoClient(zipLog *zap.Logger) *qmgo.Client {
 	//const (
 	//	Ip         = "127.0.0.1"
 	//	Port       = "27017"
@@ -107,8 +108,12 @@ func InitMongoDB(zipLog *zap.Logger) *qmgo.Database {
 		err = errors.New("MongoDB连接异常：" + err.Error())
 		panic(err)
 	}
+	return client
+}
+
+func InitMongoDB(client *qmgo.Client) *qmgo.Database {
 	// 选择数据库
-	db := client.Database(cfg.DBName)
+	db := client.Database(viper.GetString("mongodb.dbname"))
 	// 在初始化成功后，测试使用完毕请defer来关闭连接
 	//defer func() {
 	//	if err = client.Close(ctx); err != nil {
@@ -116,7 +121,7 @@ func InitMongoDB(zipLog *zap.Logger) *qmgo.Database {
 	//	}
 	//}()
 	// 创建索引
-	err = createIndex(db)
+	_ = createIndex(db)
 	return db
 }
 
@@ -131,6 +136,18 @@ func createIndex(db *qmgo.Database) error {
 		{
 			Key: []string{"author_id"},
 		},
+		{
+			// 作者文章列表游标分页
+			Key: []string{"author_id", "-utime", "-id"},
+		},
+		{
+			// 清理回收站
+			Key: []string{"status", "dtime"},
+		},
+		{
+			// 清理不再引用的正文
+			Key: []string{"content_key"},
+		},
 	})
 	if err != nil {
 		return err
@@ -144,6 +161,36 @@ func createIndex(db *qmgo.Database) error {
 		{
 			Key: []string{"author_id"},
 		},
+		{
+			// 线上文章列表游标分页
+			Key: []string{"status", "-utime", "-id"},
+		},
+		{
+			// 按标签查询线上文章 多键索引
+			Key: []string{"tags", "-utime"},
+		},
+		{
+			Key: []string{"category", "-utime"},
+		},
+		{
+			Key: []string{"content_key"},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	revisionColl := db.Collection("article_revisions")
+	err = revisionColl.CreateIndexes(context.Background(), []options.IndexModel{
+		{
+			Key:          []string{"id"},
+			IndexOptions: mgoptions.Index().SetUnique(true),
+		},
+		{
+			Key: []string{"article_id", "-id"},
+		},
+		{
+			Key: []string{"content_key"},
+		},
 	})
 	if err != nil {
 		return err
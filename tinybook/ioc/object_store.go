@@ -0,0 +1,54 @@
+package ioc
+
+import (
+	"github.com/spf13/viper"
+	"net/http"
+	"time"
+	"tinybook/tinybook/article/repository/cache"
+	"tinybook/tinybook/pkg/objstore"
+)
+
+// InitArticleContentStore 保存文章正文的对象存储 单实例部署时可以用本地目录，多实例需要 S3 兼容的存储
+func InitArticleContentStore() objstore.Store {
+	type Config struct {
+		Type  string `yaml:"type"` // local 或 s3
+		Local struct {
+			Root string `yaml:"root"`
+		} `yaml:"local"`
+		S3 objstore.S3Config `yaml:"s3"`
+	}
+	var cfg Config
+	err := viper.UnmarshalKey("objectStore", &cfg)
+	if err != nil {
+		panic(err)
+	}
+	switch cfg.Type {
+	case "s3":
+		return objstore.NewS3Store(cfg.S3, &http.Client{Timeout: 10 * time.Second})
+	case "local", "":
+		root := cfg.Local.Root
+		if root == "" {
+			root = "./data/objects"
+		}
+		store, er := objstore.NewLocalStore(root)
+		if er != nil {
+			panic(er)
+		}
+		return store
+	default:
+		panic("objectStore.type 只能是 local 或 s3")
+	}
+}
+
+// InitArticleContentCache 文章正文和渲染结果的本地缓存 容量为 objectStore.cacheMB，默认 64MB
+func InitArticleContentCache() cache.ArticleContentCache {
+	cacheMB := viper.GetInt64("objectStore.cacheMB")
+	if cacheMB <= 0 {
+		cacheMB = 64
+	}
+	res, err := cache.NewLocalArticleContentCache(cacheMB << 20)
+	if err != nil {
+		panic(err)
+	}
+	return res
+}
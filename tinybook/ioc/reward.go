@@ -0,0 +1,120 @@
+package ioc
+
+import (
+	"context"
+	"github.com/cockroachdb/errors"
+	"github.com/spf13/viper"
+	"github.com/wechatpay-apiv3/wechatpay-go/core"
+	"github.com/wechatpay-apiv3/wechatpay-go/core/auth/verifiers"
+	"github.com/wechatpay-apiv3/wechatpay-go/core/downloader"
+	"github.com/wechatpay-apiv3/wechatpay-go/core/notify"
+	"github.com/wechatpay-apiv3/wechatpay-go/core/option"
+	"github.com/wechatpay-apiv3/wechatpay-go/services/payments/native"
+	"github.com/wechatpay-apiv3/wechatpay-go/utils"
+	"go.uber.org/zap"
+	domain2 "tinybook/tinybook/article/domain"
+	repository3 "tinybook/tinybook/article/repository"
+	paydomain "tinybook/tinybook/payment/domain"
+	"tinybook/tinybook/payment/events"
+	"tinybook/tinybook/payment/repository"
+	"tinybook/tinybook/payment/service/wechat"
+	"tinybook/tinybook/payment/web"
+	"tinybook/tinybook/reward/domain"
+	"tinybook/tinybook/reward/service"
+)
+
+type wechatPayConfig struct {
+	AppId          string `yaml:"appId"`
+	MchId          string `yaml:"mchId"`
+	MchSerialNum   string `yaml:"mchSerialNum"`   // 商户证书序列号
+	MchKey         string `yaml:"mchKey"`         // APIv3 密钥
+	PrivateKeyPath string `yaml:"privateKeyPath"` // 商户私钥文件
+	NotifyURL      string `yaml:"notifyUrl"`      // 支付结果通知地址 指向 web.NotifyPath
+}
+
+// loadWechatPayConfig 没有配置商户号和密钥时返回 false
+func loadWechatPayConfig() (wechatPayConfig, bool) {
+	var cfg wechatPayConfig
+	err := viper.UnmarshalKey("wechatPay", &cfg)
+	if err != nil {
+		panic(err)
+	}
+	ok := cfg.MchId != "" && cfg.MchSerialNum != "" && cfg.MchKey != "" && cfg.PrivateKeyPath != ""
+	return cfg, ok
+}
+
+// InitNativePaymentService 打赏使用微信 native 支付 启动时会自动下载平台证书
+// 没有配置商户号和密钥时返回 nil，不开启支付
+func InitNativePaymentService(repo repository.PaymentRepository, producer events.Producer, l *zap.Logger) *wechat.NativePaymentService {
+	cfg, ok := loadWechatPayConfig()
+	if !ok {
+		l.Warn("wechatPay is not configured, reward payment is disabled")
+		return nil
+	}
+	if cfg.NotifyURL == "" {
+		panic("wechatPay.notifyUrl 未配置")
+	}
+	privateKey, err := utils.LoadPrivateKeyWithPath(cfg.PrivateKeyPath)
+	if err != nil {
+		panic(err)
+	}
+	client, err := core.NewClient(context.Background(),
+		option.WithWechatPayAutoAuthCipher(cfg.MchId, cfg.MchSerialNum, privateKey, cfg.MchKey))
+	if err != nil {
+		panic(err)
+	}
+	return wechat.NewNativePaymentService(cfg.AppId, cfg.MchId, cfg.NotifyURL, repo,
+		&native.NativeApiService{Client: client}, producer, l)
+}
+
+// InitWechatNotifyHandler 支付结果通知用自动下载的平台证书验签，用 APIv3 密钥解密
+// 平台证书由 InitNativePaymentService 创建 client 时注册的下载器维护
+func InitWechatNotifyHandler(svc *wechat.NativePaymentService, l *zap.Logger) *web.WechatNotifyHandler {
+	if svc == nil {
+		return web.NewWechatNotifyHandler(nil, nil, l)
+	}
+	cfg, _ := loadWechatPayConfig()
+	visitor := downloader.MgrInstance().GetCertificateVisitor(cfg.MchId)
+	handler := notify.NewNotifyHandler(cfg.MchKey, verifiers.NewSHA256WithRSAVerifier(visitor))
+	return web.NewWechatNotifyHandler(svc, handler, l)
+}
+
+// InitRewardPaymentService 没有配置微信支付时下单直接返回 ErrPaymentUnavailable
+func InitRewardPaymentService(svc *wechat.NativePaymentService) service.PaymentService {
+	if svc == nil {
+		return unavailablePaymentService{}
+	}
+	return svc
+}
+
+// unavailablePaymentService 没有配置微信支付时使用 所有下单都失败
+type unavailablePaymentService struct{}
+
+func (unavailablePaymentService) Prepay(ctx context.Context, pmt paydomain.Payment) (string, error) {
+	return "", service.ErrPaymentUnavailable
+}
+
+// InitRewardTargetResolver 目前只能打赏已发表的文章
+func InitRewardTargetResolver(repo repository3.ArticleRepository) service.TargetResolver {
+	return service.TargetResolverFunc(func(ctx context.Context, biz string, bizId int64) (domain.Target, error) {
+		if biz != "article" {
+			return domain.Target{}, service.ErrBizNotFound
+		}
+		art, err := repo.GetPubArticleById(ctx, bizId)
+		if errors.Is(err, repository3.ErrArticleNotFound) {
+			return domain.Target{}, service.ErrBizNotFound
+		}
+		if err != nil {
+			return domain.Target{}, err
+		}
+		if art.Status != domain2.ArticleStatusPublished {
+			return domain.Target{}, service.ErrBizNotFound
+		}
+		return domain.Target{
+			Biz:     biz,
+			BizId:   bizId,
+			BizName: art.Title,
+			Uid:     art.Author.ID,
+		}, nil
+	})
+}
@@ -0,0 +1,32 @@
+package ioc
+
+import (
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+	"os"
+	"tinybook/tinybook/article/repository"
+	"tinybook/tinybook/article/service"
+	repository2 "tinybook/tinybook/internal/repository"
+	"tinybook/tinybook/pkg/sensitive"
+)
+
+// InitShareService 分享链接的签名密钥优先读取环境变量 SHARE_SIGN_KEY，其次为 share.signKey 修改后之前生成的链接全部失效
+// 只有本地开发的配置里写了密钥 线上环境由 secret 注入环境变量，没有配置时直接启动失败
+func InitShareService(repo repository.ArticleRepository, shareRepo repository.ShareLinkRepository,
+	userRepo repository2.UserRepository, dict *sensitive.Dict, log *zap.Logger) service.ShareService {
+	type Config struct {
+		SignKey string `yaml:"signKey"`
+	}
+	var cfg Config
+	err := viper.UnmarshalKey("share", &cfg)
+	if err != nil {
+		panic(err)
+	}
+	if key := os.Getenv("SHARE_SIGN_KEY"); key != "" {
+		cfg.SignKey = key
+	}
+	if cfg.SignKey == "" {
+		panic("分享链接签名密钥未配置 请设置环境变量 SHARE_SIGN_KEY 或 share.signKey")
+	}
+	return service.NewShareService(repo, shareRepo, userRepo, dict, []byte(cfg.SignKey), log)
+}
@@ -0,0 +1,27 @@
+package ioc
+
+import (
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+	"tinybook/tinybook/article/repository"
+	"tinybook/tinybook/article/service"
+)
+
+// InitSyndicationService 订阅源中文章链接的前缀为 syndication.siteUrl
+func InitSyndicationService(repo repository.ArticleRepository, log *zap.Logger) service.SyndicationService {
+	type Config struct {
+		SiteURL     string `yaml:"siteUrl"`
+		Title       string `yaml:"title"`
+		Description string `yaml:"description"`
+	}
+	cfg := Config{SiteURL: "http://localhost:8080", Title: "tinybook", Description: "tinybook 最新发表的文章"}
+	err := viper.UnmarshalKey("syndication", &cfg)
+	if err != nil {
+		panic(err)
+	}
+	return service.NewSyndicationService(repo, service.SyndicationConfig{
+		SiteURL:     cfg.SiteURL,
+		Title:       cfg.Title,
+		Description: cfg.Description,
+	}, log)
+}
@@ -12,17 +12,29 @@ import (
 	"go.uber.org/zap"
 	"strings"
 	"time"
+	web8 "tinybook/tinybook/analytics/web"
 	web2 "tinybook/tinybook/article/web"
+	web3 "tinybook/tinybook/comment/web"
+	web5 "tinybook/tinybook/feed/web"
+	web4 "tinybook/tinybook/follow/web"
 	"tinybook/tinybook/internal/web"
 	"tinybook/tinybook/internal/web/jwt"
 	"tinybook/tinybook/internal/web/middleware"
+	web7 "tinybook/tinybook/notification/web"
+	web9 "tinybook/tinybook/payment/web"
 	"tinybook/tinybook/pkg/ginx/middleware/prometheus"
 	"tinybook/tinybook/pkg/ginx/middleware/ratelimit"
 	"tinybook/tinybook/pkg/limiter"
+	web6 "tinybook/tinybook/reward/web"
 )
 
 func InitWebServer(handlerFunc []gin.HandlerFunc, userHandler *web.UserHandler,
-	wechatHandler *web.OAuth2WechatHandler, articleHandler *web2.ArticleHandler) *gin.Engine {
+	wechatHandler *web.OAuth2WechatHandler, articleHandler *web2.ArticleHandler, commentHandler *web3.CommentHandler,
+	followHandler *web4.FollowHandler, feedHandler *web5.FeedHandler, rewardHandler *web6.RewardHandler,
+	reviewHandler *web2.ReviewHandler, notificationHandler *web7.NotificationHandler,
+	syndicationHandler *web2.SyndicationHandler, shareHandler *web2.ShareHandler,
+	seriesHandler *web2.SeriesHandler, analyticsHandler *web8.AnalyticsHandler,
+	wechatNotifyHandler *web9.WechatNotifyHandler) *gin.Engine {
 	engine := gin.Default()
 	// 注册中间件
 	engine.Use(handlerFunc...)
@@ -33,6 +45,27 @@ func InitWebServer(handlerFunc []gin.HandlerFunc, userHandler *web.UserHandler,
 	userHandler.RegisterRoutes(engine)
 	// 注册文章路由
 	articleHandler.RegisterRoutes(engine)
+	// 注册文章审核路由 只有管理员可以访问
+	reviewHandler.RegisterRoutes(engine)
+	// 注册 RSS/Atom 订阅源路由
+	syndicationHandler.RegisterRoutes(engine)
+	// 注册文章分享链接路由
+	shareHandler.RegisterRoutes(engine)
+	// 注册文章系列路由
+	seriesHandler.RegisterRoutes(engine)
+	// 注册作者数据看板路由
+	analyticsHandler.RegisterRoutes(engine)
+	// 注册评论路由
+	commentHandler.RegisterRoutes(engine)
+	// 注册关注与feed路由
+	followHandler.RegisterRoutes(engine)
+	feedHandler.RegisterRoutes(engine)
+	// 注册打赏路由
+	rewardHandler.RegisterRoutes(engine)
+	// 注册微信支付结果通知路由
+	wechatNotifyHandler.RegisterRoutes(engine)
+	// 注册站内通知路由
+	notificationHandler.RegisterRoutes(engine)
 	// 注册wechat oauth2路由
 	wechatHandler.RegisterRoutes(engine)
 	return engine
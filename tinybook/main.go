@@ -45,6 +45,14 @@ func main() {
 		<-app.cron.Stop().Done()
 	}()
 
+	// 启动时构建搜索索引
+	go app.searchJob.Run()
+
+	// 启动任务调度器
+	scheduleCtx, cancelSchedule := context.WithCancel(context.Background())
+	defer cancelSchedule()
+	go app.scheduler.Schedule(scheduleCtx)
+
 	// 启动web服务
 	server := &http.Server{
 		Addr:    ":8081",
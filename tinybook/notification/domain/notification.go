@@ -0,0 +1,35 @@
+package domain
+
+const (
+	// TypeArticleReview 文章审核结果
+	TypeArticleReview = "article_review"
+)
+
+// Notification 站内通知 Uid 为接收人，Biz 和 BizId 指向通知相关的资源
+type Notification struct {
+	ID      int64
+	Uid     int64
+	Type    string
+	Title   string
+	Content string
+	Biz     string
+	BizId   int64
+	Read    bool
+	Ctime   int64
+}
+
+type NotificationVo struct {
+	Id      int64  `json:"id"`
+	Type    string `json:"type"`
+	Title   string `json:"title"`
+	Content string `json:"content"`
+	Biz     string `json:"biz,omitempty"`
+	BizId   int64  `json:"bizId,omitempty"`
+	Read    bool   `json:"read"`
+	Ctime   string `json:"ctime"`
+}
+
+type NotificationListVo struct {
+	Notifications []NotificationVo `json:"notifications"`
+	NextCursor    int64            `json:"nextCursor"` // 为 0 表示没有下一页
+}
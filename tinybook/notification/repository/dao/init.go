@@ -0,0 +1,10 @@
+package dao
+
+import "gorm.io/gorm"
+
+func CreateTableForNotification(db *gorm.DB) {
+	err := db.AutoMigrate(&Notification{})
+	if err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,80 @@
+package dao
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"time"
+)
+
+const (
+	NotificationStatusUnread uint8 = 1
+	NotificationStatusRead   uint8 = 2
+)
+
+type Notification struct {
+	ID      int64  `gorm:"column:id;primaryKey;autoIncrement;not null"`
+	Uid     int64  `gorm:"column:uid;not null;index:idx_uid_status,priority:1"`
+	Type    string `gorm:"column:type;type:varchar(64);not null"`
+	Title   string `gorm:"column:title;type:varchar(256);not null"`
+	Content string `gorm:"column:content;type:varchar(1024)"`
+	Biz     string `gorm:"column:biz;type:varchar(64)"`
+	BizId   int64  `gorm:"column:biz_id"`
+	Status  uint8  `gorm:"column:status;type:tinyint(1);not null;index:idx_uid_status,priority:2"`
+	Ctime   int64  `gorm:"column:ctime;not null"`
+	Utime   int64  `gorm:"column:utime;not null"`
+}
+
+type NotificationDAO interface {
+	Insert(ctx context.Context, n Notification) (int64, error)
+	// GetByUid 按ID倒序获取用户的通知 maxId 为 0 表示第一页
+	GetByUid(ctx context.Context, uid int64, maxId int64, limit int) ([]Notification, error)
+	// MarkRead ids 为空时把用户的所有通知标记为已读
+	MarkRead(ctx context.Context, uid int64, ids []int64) error
+	CountUnread(ctx context.Context, uid int64) (int64, error)
+}
+
+type GormNotificationDAO struct {
+	db *gorm.DB
+}
+
+func NewGormNotificationDAO(db *gorm.DB) NotificationDAO {
+	return &GormNotificationDAO{db: db}
+}
+
+func (g *GormNotificationDAO) Insert(ctx context.Context, n Notification) (int64, error) {
+	now := time.Now().Unix()
+	n.Ctime, n.Utime = now, now
+	n.Status = NotificationStatusUnread
+	err := g.db.WithContext(ctx).Create(&n).Error
+	return n.ID, err
+}
+
+func (g *GormNotificationDAO) GetByUid(ctx context.Context, uid int64, maxId int64, limit int) ([]Notification, error) {
+	var notifications []Notification
+	query := g.db.WithContext(ctx).Where("uid = ?", uid)
+	if maxId > 0 {
+		query = query.Where("id < ?", maxId)
+	}
+	err := query.Order("id DESC").Limit(limit).Find(&notifications).Error
+	return notifications, err
+}
+
+func (g *GormNotificationDAO) MarkRead(ctx context.Context, uid int64, ids []int64) error {
+	query := g.db.WithContext(ctx).Model(&Notification{}).
+		Where("uid = ? AND status = ?", uid, NotificationStatusUnread)
+	if len(ids) > 0 {
+		query = query.Where("id IN ?", ids)
+	}
+	return query.Updates(map[string]any{
+		"status": NotificationStatusRead,
+		"utime":  time.Now().Unix(),
+	}).Error
+}
+
+func (g *GormNotificationDAO) CountUnread(ctx context.Context, uid int64) (int64, error) {
+	var cnt int64
+	err := g.db.WithContext(ctx).Model(&Notification{}).
+		Where("uid = ? AND status = ?", uid, NotificationStatusUnread).
+		Count(&cnt).Error
+	return cnt, err
+}
@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"context"
+	"github.com/samber/lo"
+	"tinybook/tinybook/notification/domain"
+	"tinybook/tinybook/notification/repository/dao"
+)
+
+type NotificationRepository interface {
+	Create(ctx context.Context, n domain.Notification) (int64, error)
+	GetByUid(ctx context.Context, uid int64, maxId int64, limit int) ([]domain.Notification, error)
+	MarkRead(ctx context.Context, uid int64, ids []int64) error
+	CountUnread(ctx context.Context, uid int64) (int64, error)
+}
+
+type notificationRepository struct {
+	dao dao.NotificationDAO
+}
+
+func NewNotificationRepository(dao dao.NotificationDAO) NotificationRepository {
+	return &notificationRepository{dao: dao}
+}
+
+func (n *notificationRepository) Create(ctx context.Context, notification domain.Notification) (int64, error) {
+	return n.dao.Insert(ctx, dao.Notification{
+		Uid:     notification.Uid,
+		Type:    notification.Type,
+		Title:   notification.Title,
+		Content: notification.Content,
+		Biz:     notification.Biz,
+		BizId:   notification.BizId,
+	})
+}
+
+func (n *notificationRepository) GetByUid(ctx context.Context, uid int64, maxId int64, limit int) ([]domain.Notification, error) {
+	notifications, err := n.dao.GetByUid(ctx, uid, maxId, limit)
+	if err != nil {
+		return nil, err
+	}
+	return lo.Map(notifications, func(notification dao.Notification, index int) domain.Notification {
+		return domain.Notification{
+			ID:      notification.ID,
+			Uid:     notification.Uid,
+			Type:    notification.Type,
+			Title:   notification.Title,
+			Content: notification.Content,
+			Biz:     notification.Biz,
+			BizId:   notification.BizId,
+			Read:    notification.Status == dao.NotificationStatusRead,
+			Ctime:   notification.Ctime,
+		}
+	}), nil
+}
+
+func (n *notificationRepository) MarkRead(ctx context.Context, uid int64, ids []int64) error {
+	return n.dao.MarkRead(ctx, uid, ids)
+}
+
+func (n *notificationRepository) CountUnread(ctx context.Context, uid int64) (int64, error) {
+	return n.dao.CountUnread(ctx, uid)
+}
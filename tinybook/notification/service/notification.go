@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"github.com/samber/lo"
+	"time"
+	"tinybook/tinybook/notification/domain"
+	"tinybook/tinybook/notification/repository"
+)
+
+type NotificationService interface {
+	Send(ctx context.Context, n domain.Notification) error
+	// List 用户的通知 cursor 为上一页返回的 NextCursor
+	List(ctx context.Context, uid int64, cursor int64, limit int) (domain.NotificationListVo, error)
+	// MarkRead ids 为空时全部标记为已读
+	MarkRead(ctx context.Context, uid int64, ids []int64) error
+	UnreadCount(ctx context.Context, uid int64) (int64, error)
+}
+
+type notificationService struct {
+	repo repository.NotificationRepository
+}
+
+func NewNotificationService(repo repository.NotificationRepository) NotificationService {
+	return &notificationService{repo: repo}
+}
+
+func (n *notificationService) Send(ctx context.Context, notification domain.Notification) error {
+	_, err := n.repo.Create(ctx, notification)
+	return err
+}
+
+func (n *notificationService) List(ctx context.Context, uid int64, cursor int64, limit int) (domain.NotificationListVo, error) {
+	// 多查一条判断是否还有下一页
+	notifications, err := n.repo.GetByUid(ctx, uid, cursor, limit+1)
+	if err != nil {
+		return domain.NotificationListVo{}, err
+	}
+	var next int64
+	if len(notifications) > limit {
+		notifications = notifications[:limit]
+		next = notifications[limit-1].ID
+	}
+	return domain.NotificationListVo{
+		Notifications: lo.Map(notifications, func(notification domain.Notification, index int) domain.NotificationVo {
+			return domain.NotificationVo{
+				Id:      notification.ID,
+				Type:    notification.Type,
+				Title:   notification.Title,
+				Content: notification.Content,
+				Biz:     notification.Biz,
+				BizId:   notification.BizId,
+				Read:    notification.Read,
+				Ctime:   time.Unix(notification.Ctime, 0).Format("2006-01-02 15:04:05"),
+			}
+		}),
+		NextCursor: next,
+	}, nil
+}
+
+func (n *notificationService) MarkRead(ctx context.Context, uid int64, ids []int64) error {
+	return n.repo.MarkRead(ctx, uid, ids)
+}
+
+func (n *notificationService) UnreadCount(ctx context.Context, uid int64) (int64, error) {
+	return n.repo.CountUnread(ctx, uid)
+}
@@ -0,0 +1,107 @@
+package web
+
+import (
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"net/http"
+	"strconv"
+	"tinybook/tinybook/internal/web/jwt"
+	"tinybook/tinybook/notification/service"
+)
+
+// maxMarkReadIds 一次最多标记的通知数
+const maxMarkReadIds = 100
+
+type NotificationHandler struct {
+	notificationService service.NotificationService
+	l                   *zap.Logger
+}
+
+func NewNotificationHandler(notificationService service.NotificationService, l *zap.Logger) *NotificationHandler {
+	return &NotificationHandler{notificationService: notificationService, l: l}
+}
+
+// List 当前用户的通知
+func (h *NotificationHandler) List(ctx *gin.Context) {
+	type Req struct {
+		Cursor int64 `json:"cursor"`
+		Limit  int   `json:"limit"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil || req.Cursor < 0 || req.Limit < 0 || req.Limit > 50 {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 400,
+			Msg:  "参数错误",
+		})
+		return
+	}
+	if req.Limit == 0 {
+		req.Limit = 10
+	}
+	claims := (ctx.MustGet("userClaims")).(jwt.UserClaims)
+	notifications, err := h.notificationService.List(ctx, claims.Uid, req.Cursor, req.Limit)
+	if err != nil {
+		h.serverErr(ctx, err, "获取通知失败, 用户ID: "+strconv.FormatInt(claims.Uid, 10))
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Code: 200,
+		Msg:  "获取成功",
+		Data: notifications,
+	})
+}
+
+// Read 标记已读 不传 ids 时全部标记为已读
+func (h *NotificationHandler) Read(ctx *gin.Context) {
+	type Req struct {
+		Ids []int64 `json:"ids"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil || len(req.Ids) > maxMarkReadIds {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 400,
+			Msg:  "参数错误",
+		})
+		return
+	}
+	claims := (ctx.MustGet("userClaims")).(jwt.UserClaims)
+	err := h.notificationService.MarkRead(ctx, claims.Uid, req.Ids)
+	if err != nil {
+		h.serverErr(ctx, err, "标记通知已读失败, 用户ID: "+strconv.FormatInt(claims.Uid, 10))
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Code: 200,
+		Msg:  "操作成功",
+	})
+}
+
+// Unread 未读通知数
+func (h *NotificationHandler) Unread(ctx *gin.Context) {
+	claims := (ctx.MustGet("userClaims")).(jwt.UserClaims)
+	cnt, err := h.notificationService.UnreadCount(ctx, claims.Uid)
+	if err != nil {
+		h.serverErr(ctx, err, "获取未读通知数失败, 用户ID: "+strconv.FormatInt(claims.Uid, 10))
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Code: 200,
+		Msg:  "获取成功",
+		Data: cnt,
+	})
+}
+
+func (h *NotificationHandler) serverErr(ctx *gin.Context, err error, msg string) {
+	ctx.JSON(http.StatusOK, Result{
+		Code: 500,
+		Msg:  "服务器错误",
+	})
+	h.l.Error(msg, zap.Error(err))
+}
+
+func (h *NotificationHandler) RegisterRoutes(engine *gin.Engine) {
+	group := engine.Group("/notifications")
+	group.POST("/list", h.List)    // 通知列表
+	group.POST("/read", h.Read)    // 标记已读
+	group.GET("/unread", h.Unread) // 未读数
+}
@@ -0,0 +1,40 @@
+package events
+
+import (
+	"context"
+	"github.com/bytedance/sonic"
+	"github.com/segmentio/kafka-go"
+)
+
+const TopicPaymentEvents = "payment_events"
+
+// PaymentEvent 支付状态变更通知 业务方根据 BizTradeNO 找到自己的订单
+type PaymentEvent struct {
+	BizTradeNO string `json:"biz_trade_no"`
+	Status     uint8  `json:"status"`
+}
+
+type Producer interface {
+	ProducePaymentEvent(ctx context.Context, evt PaymentEvent) error
+}
+
+type KafkaProducer struct {
+	writer *kafka.Writer
+}
+
+func NewKafkaProducer(writer *kafka.Writer) Producer {
+	return &KafkaProducer{writer: writer}
+}
+
+func (k *KafkaProducer) ProducePaymentEvent(ctx context.Context, evt PaymentEvent) error {
+	bytes, err := sonic.Marshal(evt)
+	if err != nil {
+		return err
+	}
+	// 同一笔交易的事件落在同一个分区 保证顺序
+	return k.writer.WriteMessages(ctx, kafka.Message{
+		Topic: TopicPaymentEvents,
+		Key:   []byte(evt.BizTradeNO),
+		Value: bytes,
+	})
+}
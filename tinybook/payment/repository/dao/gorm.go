@@ -33,11 +33,19 @@ func (p *PaymentGORMDAO) UpdateTxnIDAndStatus(ctx context.Context,
 	txnID string, status domain.PaymentStatus) error {
 	return p.db.WithContext(ctx).Model(&Payment{}).
 		Where("biz_trade_no = ?", bizTradeNo).
-		Updates(map[string]any{
-			"txn_id": txnID,
-			"status": status.AsUint8(),
-			"utime":  time.Now().UnixMilli(),
-		}).Error
+		Updates(p.statusUpdates(txnID, status)).Error
+}
+
+// statusUpdates txn_id 有唯一索引 关闭的未支付订单没有 txn_id，不能写成空字符串
+func (p *PaymentGORMDAO) statusUpdates(txnID string, status domain.PaymentStatus) map[string]any {
+	updates := map[string]any{
+		"status": status.AsUint8(),
+		"utime":  time.Now().UnixMilli(),
+	}
+	if txnID != "" {
+		updates["txn_id"] = txnID
+	}
+	return updates
 }
 
 func NewPaymentGORMDAO(db *gorm.DB) PaymentDAO {
@@ -8,15 +8,17 @@ import (
 	"github.com/wechatpay-apiv3/wechatpay-go/services/payments"
 	"github.com/wechatpay-apiv3/wechatpay-go/services/payments/native"
 	"go.uber.org/zap"
-	"gorm.io/gorm/logger"
 	"time"
 	"tinybook/tinybook/payment/domain"
+	"tinybook/tinybook/payment/events"
 	"tinybook/tinybook/payment/repository"
-	"tinybook/tinybook/pkg/migrator/events"
 )
 
 var errUnknownTransactionState = errors.New("未知的微信事务状态")
 
+// PaymentTimeout 下单后多久没有支付订单就过期
+const PaymentTimeout = 30 * time.Minute
+
 type NativePaymentService struct {
 	appID string
 	mchID string
@@ -41,11 +43,11 @@ type NativePaymentService struct {
 	nativeCBTypeToStatus map[string]domain.PaymentStatus
 }
 
-func NewNativePaymentService(appID string, mchID string,
+func NewNativePaymentService(appID string, mchID string, notifyURL string,
 	repo repository.PaymentRepository, svc *native.NativeApiService,
-	l *zap.Logger) *NativePaymentService {
-	return &NativePaymentService{appID: appID, mchID: mchID, notifyURL: "http://wechat.meoying.com/pay/callback",
-		repo: repo, svc: svc, l: l,
+	producer events.Producer, l *zap.Logger) *NativePaymentService {
+	return &NativePaymentService{appID: appID, mchID: mchID, notifyURL: notifyURL,
+		repo: repo, svc: svc, producer: producer, l: l,
 		nativeCBTypeToStatus: map[string]domain.PaymentStatus{
 			"SUCCESS":  domain.PaymentStatusSuccess,
 			"PAYERROR": domain.PaymentStatusFailed,
@@ -70,8 +72,9 @@ func (n *NativePaymentService) Prepay(ctx context.Context, pmt domain.Payment) (
 		Mchid:       core.String(n.mchID),
 		Description: core.String(pmt.Description),
 		OutTradeNo:  core.String(pmt.BizTradeNO),
-		// 最好这个要带上
-		TimeExpire: core.Time(time.Now().Add(time.Minute * 30)),
+		NotifyUrl:   core.String(n.notifyURL),
+		// 最好这个要带上 超时未支付的订单由 SyncExpiredPayment 关闭
+		TimeExpire: core.Time(time.Now().Add(PaymentTimeout)),
 		Amount: &native.Amount{
 			Total:    core.Int64(pmt.Amt.Total),
 			Currency: core.String(pmt.Amt.Currency),
@@ -86,14 +89,39 @@ func (n *NativePaymentService) Prepay(ctx context.Context, pmt domain.Payment) (
 
 func (n *NativePaymentService) SyncWechatInfo(ctx context.Context, bizTradeNO string) error {
 	// 对账
-	txn, _, err := n.svc.QueryOrderByOutTradeNo(ctx, native.QueryOrderByOutTradeNoRequest{
+	txn, err := n.queryOrder(ctx, bizTradeNO)
+	if err != nil {
+		return err
+	}
+	return n.updateByTxn(ctx, txn)
+}
+
+// SyncExpiredPayment 对账已经过期的订单 微信那边仍然是未支付时关闭订单，并把支付标记为失败
+// 关单之后用户不能再支付，不会出现标记失败后又支付成功的情况
+func (n *NativePaymentService) SyncExpiredPayment(ctx context.Context, bizTradeNO string) error {
+	txn, err := n.queryOrder(ctx, bizTradeNO)
+	if err != nil {
+		return err
+	}
+	if txn.TradeState == nil || *txn.TradeState != "NOTPAY" {
+		return n.updateByTxn(ctx, txn)
+	}
+	_, err = n.svc.CloseOrder(ctx, native.CloseOrderRequest{
 		OutTradeNo: core.String(bizTradeNO),
 		Mchid:      core.String(n.mchID),
 	})
 	if err != nil {
 		return err
 	}
-	return n.updateByTxn(ctx, txn)
+	return n.updateStatus(ctx, bizTradeNO, "", domain.PaymentStatusFailed)
+}
+
+func (n *NativePaymentService) queryOrder(ctx context.Context, bizTradeNO string) (*payments.Transaction, error) {
+	txn, _, err := n.svc.QueryOrderByOutTradeNo(ctx, native.QueryOrderByOutTradeNoRequest{
+		OutTradeNo: core.String(bizTradeNO),
+		Mchid:      core.String(n.mchID),
+	})
+	return txn, err
 }
 
 func (n *NativePaymentService) FindExpiredPayment(ctx context.Context, offset, limit int, t time.Time) ([]domain.Payment, error) {
@@ -109,15 +137,32 @@ func (n *NativePaymentService) HandleCallback(ctx context.Context, txn *payments
 }
 
 func (n *NativePaymentService) updateByTxn(ctx context.Context, txn *payments.Transaction) error {
+	if txn.TradeState == nil || txn.OutTradeNo == nil {
+		return fmt.Errorf("%w, 缺少交易状态或者商户订单号", errUnknownTransactionState)
+	}
 	status, ok := n.nativeCBTypeToStatus[*txn.TradeState]
 	if !ok {
 		return fmt.Errorf("%w, 微信的状态是 %s", errUnknownTransactionState, *txn.TradeState)
 	}
+	// 还没有支付 不需要更新，也不用通知业务方
+	if status == domain.PaymentStatusInit {
+		return nil
+	}
+	// 未支付的订单没有微信的 transaction id
+	var txnID string
+	if txn.TransactionId != nil {
+		txnID = *txn.TransactionId
+	}
+	return n.updateStatus(ctx, *txn.OutTradeNo, txnID, status)
+}
+
+func (n *NativePaymentService) updateStatus(ctx context.Context, bizTradeNO string, txnID string,
+	status domain.PaymentStatus) error {
 	// 很显然，就是更新一下我们本地数据库里面 payment 的状态
 	err := n.repo.UpdatePayment(ctx, domain.Payment{
 		// 微信过来的 transaction id
-		TxnID:      *txn.TransactionId,
-		BizTradeNO: *txn.OutTradeNo,
+		TxnID:      txnID,
+		BizTradeNO: bizTradeNO,
 		Status:     status,
 	})
 	if err != nil {
@@ -128,12 +173,12 @@ func (n *NativePaymentService) updateByTxn(ctx context.Context, txn *payments.Tr
 	// 我要是发消息失败了怎么办？
 	// 站在业务的角度，你是不是至少应该发成功一次
 	err1 := n.producer.ProducePaymentEvent(ctx, events.PaymentEvent{
-		BizTradeNO: *txn.OutTradeNo,
+		BizTradeNO: bizTradeNO,
 		Status:     status.AsUint8(),
 	})
 	if err1 != nil {
-		n.l.Error("发送支付事件失败", logger.Error(err),
-			logger.String("biz_trade_no", *txn.OutTradeNo))
+		n.l.Error("发送支付事件失败", zap.Error(err1),
+			zap.String("biz_trade_no", bizTradeNO))
 	}
 	return nil
 }
@@ -0,0 +1,51 @@
+package web
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/wechatpay-apiv3/wechatpay-go/core/notify"
+	"github.com/wechatpay-apiv3/wechatpay-go/services/payments"
+	"go.uber.org/zap"
+	"net/http"
+	"tinybook/tinybook/payment/service/wechat"
+)
+
+// NotifyPath 微信支付结果通知的路由 wechatPay.notifyURL 需要指向这里
+const NotifyPath = "/payment/wechat/notify"
+
+// WechatNotifyHandler 接收微信支付的支付结果通知
+// 验签并解密后更新支付状态，没有配置微信支付时不注册路由
+type WechatNotifyHandler struct {
+	svc     *wechat.NativePaymentService
+	handler *notify.Handler
+	l       *zap.Logger
+}
+
+func NewWechatNotifyHandler(svc *wechat.NativePaymentService, handler *notify.Handler, l *zap.Logger) *WechatNotifyHandler {
+	return &WechatNotifyHandler{svc: svc, handler: handler, l: l}
+}
+
+func (h *WechatNotifyHandler) RegisterRoutes(engine *gin.Engine) {
+	if h.svc == nil || h.handler == nil {
+		return
+	}
+	engine.POST(NotifyPath, h.Notify)
+}
+
+// Notify 按微信支付的约定应答 成功返回 200，失败返回 4xx/5xx 和 FAIL，微信会按策略重新通知
+func (h *WechatNotifyHandler) Notify(ctx *gin.Context) {
+	txn := new(payments.Transaction)
+	// 验证签名 再用 APIv3 密钥解密出交易信息
+	_, err := h.handler.ParseNotifyRequest(ctx, ctx.Request, txn)
+	if err != nil {
+		h.l.Warn("解析微信支付通知失败", zap.Error(err))
+		ctx.JSON(http.StatusBadRequest, gin.H{"code": "FAIL", "message": "通知验签失败"})
+		return
+	}
+	err = h.svc.HandleCallback(ctx, txn)
+	if err != nil {
+		h.l.Error("处理微信支付通知失败", zap.Error(err))
+		ctx.JSON(http.StatusInternalServerError, gin.H{"code": "FAIL", "message": "处理失败"})
+		return
+	}
+	ctx.Status(http.StatusOK)
+}
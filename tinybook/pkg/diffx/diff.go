@@ -0,0 +1,154 @@
+package diffx
+
+import "strings"
+
+type Op uint8
+
+const (
+	OpEqual Op = iota
+	OpInsert
+	OpDelete
+)
+
+func (o Op) String() string {
+	switch o {
+	case OpInsert:
+		return "insert"
+	case OpDelete:
+		return "delete"
+	default:
+		return "equal"
+	}
+}
+
+// Line 表示diff结果中的一行
+type Line struct {
+	Op   Op
+	Text string
+}
+
+// Lines 按行比较两段文本
+func Lines(a, b string) []Line {
+	return Diff(splitLines(a), splitLines(b))
+}
+
+// Diff 使用线性空间的 Myers 算法计算两个字符串序列的最短编辑脚本
+// 每次找出最短编辑路径中间的一段对角线(middle snake)，再分别递归处理两侧，
+// 内存为 O(n+m)，时间为 O((n+m)D)，D 为编辑距离
+func Diff(a, b []string) []Line {
+	if len(a) == 0 && len(b) == 0 {
+		return nil
+	}
+	size := (len(a)+len(b)+1)/2 + 1
+	d := &differ{
+		a:   a,
+		b:   b,
+		vf:  make([]int, 2*size+1),
+		vb:  make([]int, 2*size+1),
+		res: make([]Line, 0, len(a)+len(b)),
+	}
+	d.compare(0, len(a), 0, len(b))
+	return d.res
+}
+
+type differ struct {
+	a, b []string
+	// vf、vb 为正向和反向搜索时每条对角线上到达的最远位置 所有递归共用
+	vf, vb []int
+	res    []Line
+}
+
+// compare 比较 a[aLo:aHi] 与 b[bLo:bHi]，按顺序追加编辑脚本
+func (d *differ) compare(aLo, aHi, bLo, bHi int) {
+	// 先去掉相同的前缀和后缀
+	for aLo < aHi && bLo < bHi && d.a[aLo] == d.b[bLo] {
+		d.res = append(d.res, Line{Op: OpEqual, Text: d.a[aLo]})
+		aLo++
+		bLo++
+	}
+	suffix := aHi
+	for aLo < aHi && bLo < bHi && d.a[aHi-1] == d.b[bHi-1] {
+		aHi--
+		bHi--
+	}
+	switch {
+	case aLo == aHi:
+		for ; bLo < bHi; bLo++ {
+			d.res = append(d.res, Line{Op: OpInsert, Text: d.b[bLo]})
+		}
+	case bLo == bHi:
+		for ; aLo < aHi; aLo++ {
+			d.res = append(d.res, Line{Op: OpDelete, Text: d.a[aLo]})
+		}
+	default:
+		// 去掉前后缀后编辑距离至少为 2，两侧的编辑距离都严格变小
+		x, y, u, v := d.middleSnake(aLo, aHi, bLo, bHi)
+		d.compare(aLo, x, bLo, y)
+		for ; x < u; x++ {
+			d.res = append(d.res, Line{Op: OpEqual, Text: d.a[x]})
+		}
+		d.compare(u, aHi, v, bHi)
+	}
+	for ; aHi < suffix; aHi++ {
+		d.res = append(d.res, Line{Op: OpEqual, Text: d.a[aHi]})
+	}
+}
+
+// middleSnake 同时从两端搜索，返回两条路径重叠处的对角线 (x, y) -> (u, v)
+// 反向搜索的对角线 kr = delta - k，vb 中记录的是距离终点的步数
+func (d *differ) middleSnake(aLo, aHi, bLo, bHi int) (int, int, int, int) {
+	n, m := aHi-aLo, bHi-bLo
+	delta := n - m
+	odd := delta&1 != 0
+	maxD := (n + m + 1) / 2
+	offset := maxD + 1
+	vf, vb := d.vf, d.vb
+	vf[offset+1], vb[offset+1] = 0, 0
+	for step := 0; step <= maxD; step++ {
+		for k := -step; k <= step; k += 2 {
+			var x int
+			if k == -step || (k != step && vf[offset+k-1] < vf[offset+k+1]) {
+				x = vf[offset+k+1] // 向下移动，插入
+			} else {
+				x = vf[offset+k-1] + 1 // 向右移动，删除
+			}
+			y := x - k
+			sx, sy := x, y
+			for x < n && y < m && d.a[aLo+x] == d.b[bLo+y] {
+				x++
+				y++
+			}
+			vf[offset+k] = x
+			if kr := delta - k; odd && kr >= -(step-1) && kr <= step-1 && x+vb[offset+kr] >= n {
+				return aLo + sx, bLo + sy, aLo + x, bLo + y
+			}
+		}
+		for kr := -step; kr <= step; kr += 2 {
+			var x int
+			if kr == -step || (kr != step && vb[offset+kr-1] < vb[offset+kr+1]) {
+				x = vb[offset+kr+1]
+			} else {
+				x = vb[offset+kr-1] + 1
+			}
+			y := x - kr
+			sx, sy := x, y
+			for x < n && y < m && d.a[aHi-1-x] == d.b[bHi-1-y] {
+				x++
+				y++
+			}
+			vb[offset+kr] = x
+			if k := delta - kr; !odd && k >= -step && k <= step && x+vf[offset+k] >= n {
+				return aHi - x, bHi - y, aHi - sx, bHi - sy
+			}
+		}
+	}
+	// 一定会在 maxD 步之内重叠
+	panic("diffx: middle snake not found")
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.ReplaceAll(s, "\r\n", "\n"), "\n")
+}
@@ -0,0 +1,110 @@
+package diffx
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestLines(t *testing.T) {
+	testCases := []struct {
+		name string
+		a    string
+		b    string
+		want []Line
+	}{
+		{
+			name: "完全相同",
+			a:    "a\nb",
+			b:    "a\nb",
+			want: []Line{{OpEqual, "a"}, {OpEqual, "b"}},
+		},
+		{
+			name: "两者都为空",
+		},
+		{
+			name: "新增行",
+			a:    "a\nc",
+			b:    "a\nb\nc",
+			want: []Line{{OpEqual, "a"}, {OpInsert, "b"}, {OpEqual, "c"}},
+		},
+		{
+			name: "删除行",
+			a:    "a\nb\nc",
+			b:    "a\nc",
+			want: []Line{{OpEqual, "a"}, {OpDelete, "b"}, {OpEqual, "c"}},
+		},
+		{
+			name: "修改行",
+			a:    "标题\n旧内容",
+			b:    "标题\n新内容",
+			want: []Line{{OpEqual, "标题"}, {OpDelete, "旧内容"}, {OpInsert, "新内容"}},
+		},
+		{
+			name: "从空文本开始",
+			b:    "a\nb",
+			want: []Line{{OpInsert, "a"}, {OpInsert, "b"}},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, Lines(tc.a, tc.b))
+		})
+	}
+}
+
+func TestDiff_Apply(t *testing.T) {
+	testCases := []struct {
+		name string
+		a    []string
+		b    []string
+		// 最短编辑距离
+		edits int
+	}{
+		{
+			name:  "论文中的例子",
+			a:     []string{"a", "b", "c", "a", "b", "b", "a"},
+			b:     []string{"c", "b", "a", "b", "a", "c"},
+			edits: 5,
+		},
+		{
+			name:  "完全不同",
+			a:     []string{"a", "b", "c"},
+			b:     []string{"x", "y"},
+			edits: 5,
+		},
+		{
+			name:  "交替修改",
+			a:     []string{"a", "1", "b", "2", "c", "3", "d"},
+			b:     []string{"a", "x", "b", "y", "c", "z", "d", "e"},
+			edits: 7,
+		},
+		{
+			name:  "删除全部",
+			a:     []string{"a", "b"},
+			edits: 2,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			lines := Diff(tc.a, tc.b)
+			var gotA, gotB []string
+			edits := 0
+			for _, line := range lines {
+				switch line.Op {
+				case OpEqual:
+					gotA = append(gotA, line.Text)
+					gotB = append(gotB, line.Text)
+				case OpDelete:
+					gotA = append(gotA, line.Text)
+					edits++
+				case OpInsert:
+					gotB = append(gotB, line.Text)
+					edits++
+				}
+			}
+			assert.Equal(t, tc.a, gotA)
+			assert.Equal(t, tc.b, gotB)
+			assert.Equal(t, tc.edits, edits)
+		})
+	}
+}
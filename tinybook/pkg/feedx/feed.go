@@ -0,0 +1,247 @@
+package feedx
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/xml"
+	"time"
+)
+
+// Format 订阅源的格式
+type Format uint8
+
+const (
+	FormatRSS Format = iota
+	FormatAtom
+)
+
+func (f Format) String() string {
+	if f == FormatAtom {
+		return "atom"
+	}
+	return "rss"
+}
+
+// ContentType 响应头中的 Content-Type
+func (f Format) ContentType() string {
+	if f == FormatAtom {
+		return "application/atom+xml; charset=utf-8"
+	}
+	return "application/rss+xml; charset=utf-8"
+}
+
+// Feed 与具体格式无关的订阅源
+type Feed struct {
+	Title       string
+	Link        string // 站点或作者主页
+	SelfLink    string // 订阅源自身的地址
+	Description string
+	Updated     time.Time
+	Items       []Item
+}
+
+// Item 订阅源中的一篇文章 Link 同时作为唯一标识
+type Item struct {
+	Title      string
+	Link       string
+	Author     string
+	Summary    string
+	Content    string // html
+	Categories []string
+	Published  time.Time
+	Updated    time.Time
+}
+
+// Render 按格式输出带 xml 声明的文档
+func Render(feed Feed, format Format) ([]byte, error) {
+	if format == FormatAtom {
+		return Atom(feed)
+	}
+	return RSS(feed)
+}
+
+// ETag 以文档内容的摘要作为强校验的 ETag
+func ETag(data []byte) string {
+	sum := sha1.Sum(data)
+	return `"` + hex.EncodeToString(sum[:]) + `"`
+}
+
+type rss struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	AtomNS  string     `xml:"xmlns:atom,attr"`
+	DCNS    string     `xml:"xmlns:dc,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate,omitempty"`
+	AtomLink      *rssLink  `xml:"atom:link,omitempty"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+	Type string `xml:"type,attr"`
+}
+
+type rssGuid struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+type rssItem struct {
+	Title       string   `xml:"title"`
+	Link        string   `xml:"link"`
+	Guid        rssGuid  `xml:"guid"`
+	Creator     string   `xml:"dc:creator,omitempty"`
+	Description string   `xml:"description"`
+	Categories  []string `xml:"category"`
+	PubDate     string   `xml:"pubDate,omitempty"`
+}
+
+// RSS 输出 RSS 2.0 文档 正文放在 description 中
+func RSS(feed Feed) ([]byte, error) {
+	doc := rss{
+		Version: "2.0",
+		AtomNS:  "http://www.w3.org/2005/Atom",
+		DCNS:    "http://purl.org/dc/elements/1.1/",
+		Channel: rssChannel{
+			Title:         feed.Title,
+			Link:          feed.Link,
+			Description:   feed.Description,
+			LastBuildDate: rssTime(feed.Updated),
+			Items:         make([]rssItem, 0, len(feed.Items)),
+		},
+	}
+	if feed.SelfLink != "" {
+		doc.Channel.AtomLink = &rssLink{Href: feed.SelfLink, Rel: "self", Type: "application/rss+xml"}
+	}
+	for _, item := range feed.Items {
+		description := item.Content
+		if description == "" {
+			description = item.Summary
+		}
+		doc.Channel.Items = append(doc.Channel.Items, rssItem{
+			Title:       item.Title,
+			Link:        item.Link,
+			Guid:        rssGuid{IsPermaLink: true, Value: item.Link},
+			Creator:     item.Author,
+			Description: description,
+			Categories:  item.Categories,
+			PubDate:     rssTime(item.Published),
+		})
+	}
+	return marshal(doc)
+}
+
+type atomFeed struct {
+	XMLName  xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Title    string      `xml:"title"`
+	ID       string      `xml:"id"`
+	Updated  string      `xml:"updated"`
+	Subtitle string      `xml:"subtitle,omitempty"`
+	Links    []atomLink  `xml:"link"`
+	Entries  []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomText struct {
+	Type  string `xml:"type,attr,omitempty"`
+	Value string `xml:",chardata"`
+}
+
+type atomPerson struct {
+	Name string `xml:"name"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type atomEntry struct {
+	Title      string         `xml:"title"`
+	ID         string         `xml:"id"`
+	Link       atomLink       `xml:"link"`
+	Published  string         `xml:"published,omitempty"`
+	Updated    string         `xml:"updated"`
+	Author     atomPerson     `xml:"author"`
+	Categories []atomCategory `xml:"category"`
+	Summary    *atomText      `xml:"summary,omitempty"`
+	Content    *atomText      `xml:"content,omitempty"`
+}
+
+// Atom 输出 Atom 1.0 文档 每篇文章都带作者 因此 feed 级别不再输出 author
+func Atom(feed Feed) ([]byte, error) {
+	doc := atomFeed{
+		Title:    feed.Title,
+		ID:       feed.Link,
+		Updated:  atomTime(feed.Updated),
+		Subtitle: feed.Description,
+		Links:    []atomLink{{Href: feed.Link, Rel: "alternate", Type: "text/html"}},
+		Entries:  make([]atomEntry, 0, len(feed.Items)),
+	}
+	if feed.SelfLink != "" {
+		doc.Links = append(doc.Links, atomLink{Href: feed.SelfLink, Rel: "self", Type: "application/atom+xml"})
+	}
+	for _, item := range feed.Items {
+		updated := item.Updated
+		if updated.IsZero() {
+			updated = item.Published
+		}
+		entry := atomEntry{
+			Title:      item.Title,
+			ID:         item.Link,
+			Link:       atomLink{Href: item.Link, Rel: "alternate", Type: "text/html"},
+			Updated:    atomTime(updated),
+			Author:     atomPerson{Name: item.Author},
+			Categories: make([]atomCategory, 0, len(item.Categories)),
+		}
+		for _, c := range item.Categories {
+			entry.Categories = append(entry.Categories, atomCategory{Term: c})
+		}
+		if !item.Published.IsZero() {
+			entry.Published = atomTime(item.Published)
+		}
+		if item.Summary != "" {
+			entry.Summary = &atomText{Type: "text", Value: item.Summary}
+		}
+		if item.Content != "" {
+			entry.Content = &atomText{Type: "html", Value: item.Content}
+		}
+		doc.Entries = append(doc.Entries, entry)
+	}
+	return marshal(doc)
+}
+
+func marshal(v any) ([]byte, error) {
+	data, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), data...), nil
+}
+
+func rssTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC1123Z)
+}
+
+// atomTime Atom 要求 updated 必填 零值时输出 unix 纪元
+func atomTime(t time.Time) string {
+	if t.IsZero() {
+		t = time.Unix(0, 0)
+	}
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,133 @@
+package feedx
+
+import (
+	"encoding/xml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testFeed() Feed {
+	published := time.Date(2024, 5, 1, 8, 30, 0, 0, time.UTC)
+	return Feed{
+		Title:       "tinybook",
+		Link:        "https://example.com/",
+		SelfLink:    "https://example.com/feeds/site.xml",
+		Description: "最新文章",
+		Updated:     published.Add(time.Hour),
+		Items: []Item{
+			{
+				Title:      "标题 <1> & 2",
+				Link:       "https://example.com/articles/1",
+				Author:     "作者",
+				Summary:    "摘要",
+				Content:    "<p>正文</p>",
+				Categories: []string{"go", "后端"},
+				Published:  published,
+				Updated:    published.Add(time.Hour),
+			},
+			{
+				Title:     "没有正文",
+				Link:      "https://example.com/articles/2",
+				Author:    "作者",
+				Summary:   "只有摘要",
+				Published: published,
+			},
+		},
+	}
+}
+
+func TestRSS(t *testing.T) {
+	data, err := RSS(testFeed())
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(data), xml.Header))
+	assert.Contains(t, string(data), `<atom:link href="https://example.com/feeds/site.xml" rel="self" type="application/rss+xml"></atom:link>`)
+
+	var doc struct {
+		Version string `xml:"version,attr"`
+		Channel struct {
+			Title         string `xml:"title"`
+			LastBuildDate string `xml:"lastBuildDate"`
+			Items         []struct {
+				Title       string   `xml:"title"`
+				Guid        string   `xml:"guid"`
+				Creator     string   `xml:"creator"`
+				Description string   `xml:"description"`
+				Categories  []string `xml:"category"`
+				PubDate     string   `xml:"pubDate"`
+			} `xml:"item"`
+		} `xml:"channel"`
+	}
+	require.NoError(t, xml.Unmarshal(data, &doc))
+	assert.Equal(t, "2.0", doc.Version)
+	assert.Equal(t, "Wed, 01 May 2024 09:30:00 +0000", doc.Channel.LastBuildDate)
+	require.Len(t, doc.Channel.Items, 2)
+	first := doc.Channel.Items[0]
+	assert.Equal(t, "标题 <1> & 2", first.Title)
+	assert.Equal(t, "https://example.com/articles/1", first.Guid)
+	assert.Equal(t, "作者", first.Creator)
+	assert.Equal(t, "<p>正文</p>", first.Description)
+	assert.Equal(t, []string{"go", "后端"}, first.Categories)
+	assert.Equal(t, "Wed, 01 May 2024 08:30:00 +0000", first.PubDate)
+	// 没有正文时使用摘要
+	assert.Equal(t, "只有摘要", doc.Channel.Items[1].Description)
+}
+
+func TestAtom(t *testing.T) {
+	data, err := Atom(testFeed())
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(data), xml.Header))
+
+	type link struct {
+		Href string `xml:"href,attr"`
+		Rel  string `xml:"rel,attr"`
+	}
+	var doc struct {
+		XMLName xml.Name `xml:"http://www.w3.org/2005/Atom feed"`
+		ID      string   `xml:"id"`
+		Updated string   `xml:"updated"`
+		Links   []link   `xml:"link"`
+		Entries []struct {
+			ID        string `xml:"id"`
+			Published string `xml:"published"`
+			Updated   string `xml:"updated"`
+			Author    string `xml:"author>name"`
+			Summary   string `xml:"summary"`
+			Content   struct {
+				Type  string `xml:"type,attr"`
+				Value string `xml:",chardata"`
+			} `xml:"content"`
+			Categories []struct {
+				Term string `xml:"term,attr"`
+			} `xml:"category"`
+		} `xml:"entry"`
+	}
+	require.NoError(t, xml.Unmarshal(data, &doc))
+	assert.Equal(t, "https://example.com/", doc.ID)
+	assert.Equal(t, "2024-05-01T09:30:00Z", doc.Updated)
+	assert.Equal(t, []link{
+		{Href: "https://example.com/", Rel: "alternate"},
+		{Href: "https://example.com/feeds/site.xml", Rel: "self"},
+	}, doc.Links)
+	require.Len(t, doc.Entries, 2)
+	first := doc.Entries[0]
+	assert.Equal(t, "https://example.com/articles/1", first.ID)
+	assert.Equal(t, "2024-05-01T08:30:00Z", first.Published)
+	assert.Equal(t, "2024-05-01T09:30:00Z", first.Updated)
+	assert.Equal(t, "作者", first.Author)
+	assert.Equal(t, "html", first.Content.Type)
+	assert.Equal(t, "<p>正文</p>", first.Content.Value)
+	assert.Len(t, first.Categories, 2)
+	// 没有更新时间时使用发表时间
+	assert.Equal(t, "2024-05-01T08:30:00Z", doc.Entries[1].Updated)
+	assert.Empty(t, doc.Entries[1].Content.Value)
+}
+
+func TestETag(t *testing.T) {
+	a := ETag([]byte("a"))
+	assert.Equal(t, a, ETag([]byte("a")))
+	assert.NotEqual(t, a, ETag([]byte("b")))
+	assert.True(t, strings.HasPrefix(a, `"`) && strings.HasSuffix(a, `"`))
+}
@@ -0,0 +1,144 @@
+package kafkax
+
+import (
+	"context"
+	"github.com/cockroachdb/errors"
+	"github.com/segmentio/kafka-go"
+	"io"
+	"time"
+)
+
+// Brokers kafka 集群的地址
+type Brokers []string
+
+// NewGroupReader 属于消费者组的 reader 位移每秒异步提交一次，重复投递需要由业务自己处理
+// startOffset 为 kafka.FirstOffset 或 kafka.LastOffset，只对新的消费者组生效
+func NewGroupReader(brokers Brokers, groupId string, topic string, startOffset int64) *kafka.Reader {
+	return kafka.NewReader(kafka.ReaderConfig{
+		Brokers:        brokers,
+		GroupID:        groupId,
+		Topic:          topic,
+		MaxWait:        500 * time.Millisecond,
+		CommitInterval: time.Second,
+		StartOffset:    startOffset,
+	})
+}
+
+// NewPartitionReaders 为 topic 的每个分区创建一个不属于任何消费者组的 reader，从最新的位置开始读
+// 适合每个实例都要收到全部消息的广播场景 重启的实例不会在 broker 上留下消费者组和位移
+// 只读取调用时已有的分区，之后新增的分区不会被消费
+func NewPartitionReaders(brokers Brokers, topic string) ([]*kafka.Reader, error) {
+	if len(brokers) == 0 {
+		return nil, errors.New("kafka brokers 未配置")
+	}
+	conn, err := kafka.Dial("tcp", brokers[0])
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	partitions, err := conn.ReadPartitions(topic)
+	if err != nil {
+		return nil, err
+	}
+	readers := make([]*kafka.Reader, 0, len(partitions))
+	for _, partition := range partitions {
+		reader := kafka.NewReader(kafka.ReaderConfig{
+			Brokers:   brokers,
+			Topic:     topic,
+			Partition: partition.ID,
+			MaxWait:   500 * time.Millisecond,
+		})
+		if err = reader.SetOffset(kafka.LastOffset); err != nil {
+			return nil, err
+		}
+		readers = append(readers, reader)
+	}
+	return readers, nil
+}
+
+// Retry 执行 fn 直到成功或者尝试了 attempts 次 第 n 次失败后等待 backoff * 2^(n-1) 再重试
+// 每次失败都会调用 onErr，返回最后一次的错误；ctx 结束时不再等待，返回 ctx 的错误
+func Retry(ctx context.Context, attempts int, backoff time.Duration, fn func() error,
+	onErr func(attempt int, err error)) error {
+	var err error
+	for i := 1; i <= attempts; i++ {
+		if err = fn(); err == nil {
+			return nil
+		}
+		onErr(i, err)
+		if i == attempts {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return errors.CombineErrors(ctx.Err(), err)
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+	return err
+}
+
+// fetchRetryBackoff 读消息失败后重试前的等待时间 broker 不可用时避免空转刷日志
+const fetchRetryBackoff = time.Second
+
+// ShouldRetryFetch 读消息失败后判断是否继续消费 reader 已关闭(io.EOF)或者 ctx 结束时返回 false，
+// 其它错误等待一段时间后返回 true
+func ShouldRetryFetch(ctx context.Context, err error) bool {
+	if errors.Is(err, io.EOF) || ctx.Err() != nil {
+		return false
+	}
+	select {
+	case <-ctx.Done():
+		return false
+	case <-time.After(fetchRetryBackoff):
+		return true
+	}
+}
+
+// maxBatchBackoff 一批消息处理失败后重试的最长等待时间
+const maxBatchBackoff = 10 * time.Second
+
+// BatchConsume 攒够 size 条消息或者等待超过 wait 之后交给 handle 处理，处理成功后提交这批消息的位移
+// handle 失败时调用 onErr，等待一段时间(从 wait 开始翻倍，最长 10 秒)后重新处理同一批消息，直到成功，
+// 因此 handle 需要能够处理重复的消息；ctx 结束或者 reader 关闭时返回
+func BatchConsume(ctx context.Context, reader *kafka.Reader, size int, wait time.Duration,
+	handle func(ctx context.Context, msgs []kafka.Message) error, onErr func(err error)) error {
+	msgs := make([]kafka.Message, 0, size)
+	for {
+		batchCtx, cancel := context.WithTimeout(ctx, wait)
+		for len(msgs) < size {
+			msg, err := reader.FetchMessage(batchCtx)
+			if errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil {
+				break
+			}
+			if err != nil {
+				cancel()
+				return err
+			}
+			msgs = append(msgs, msg)
+		}
+		cancel()
+		if len(msgs) == 0 {
+			continue
+		}
+		backoff := wait
+		for {
+			err := handle(ctx, msgs)
+			if err == nil {
+				break
+			}
+			onErr(err)
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(backoff):
+			}
+			backoff = min(backoff*2, maxBatchBackoff)
+		}
+		if err := reader.CommitMessages(ctx, msgs...); err != nil {
+			onErr(errors.Wrap(err, "提交位移失败"))
+		}
+		msgs = msgs[:0]
+	}
+}
@@ -0,0 +1,103 @@
+package kafkax
+
+import (
+	"context"
+	"github.com/cockroachdb/errors"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestRetry(t *testing.T) {
+	errFail := errors.New("fail")
+	testCases := []struct {
+		name      string
+		failTimes int
+		attempts  int
+		wantCalls int
+		wantErr   error
+	}{
+		{
+			name:      "第一次就成功",
+			attempts:  3,
+			wantCalls: 1,
+		},
+		{
+			name:      "重试后成功",
+			failTimes: 2,
+			attempts:  3,
+			wantCalls: 3,
+		},
+		{
+			name:      "全部失败",
+			failTimes: 5,
+			attempts:  3,
+			wantCalls: 3,
+			wantErr:   errFail,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			calls, failed := 0, 0
+			err := Retry(context.Background(), tc.attempts, time.Millisecond, func() error {
+				calls++
+				if calls <= tc.failTimes {
+					return errFail
+				}
+				return nil
+			}, func(attempt int, err error) {
+				failed++
+				assert.Equal(t, calls, attempt)
+			})
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantCalls, calls)
+			assert.Equal(t, min(tc.failTimes, tc.attempts), failed)
+		})
+	}
+}
+
+func TestRetryCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	calls := 0
+	err := Retry(ctx, 3, time.Hour, func() error {
+		calls++
+		return errors.New("fail")
+	}, func(attempt int, err error) {})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 1, calls)
+}
+
+func TestShouldRetryFetch(t *testing.T) {
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	testCases := []struct {
+		name string
+		ctx  context.Context
+		err  error
+		want bool
+	}{
+		{
+			name: "reader 已关闭",
+			ctx:  context.Background(),
+			err:  io.EOF,
+		},
+		{
+			name: "ctx 已结束",
+			ctx:  canceled,
+			err:  context.Canceled,
+		},
+		{
+			name: "其它错误",
+			ctx:  context.Background(),
+			err:  errors.New("broker 不可用"),
+			want: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, ShouldRetryFetch(tc.ctx, tc.err))
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package mdx
+
+import (
+	"bytes"
+	"github.com/cockroachdb/errors"
+	"gopkg.in/yaml.v3"
+	"strings"
+)
+
+const frontMatterDelimiter = "---"
+
+var ErrInvalidFrontMatter = errors.New("front matter 格式错误")
+
+// MarshalFrontMatter 把 meta 以 YAML front matter 的形式写在 body 前面
+func MarshalFrontMatter(meta any, body string) ([]byte, error) {
+	head, err := yaml.Marshal(meta)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	buf.Grow(len(head) + len(body) + 2*len(frontMatterDelimiter) + 3)
+	buf.WriteString(frontMatterDelimiter + "\n")
+	buf.Write(head)
+	buf.WriteString(frontMatterDelimiter + "\n")
+	buf.WriteString(body)
+	return buf.Bytes(), nil
+}
+
+// UnmarshalFrontMatter 解析开头的 YAML front matter 到 meta，返回去掉 front matter 后的正文
+// 没有 front matter 时 meta 保持不变，整个内容作为正文
+func UnmarshalFrontMatter(src []byte, meta any) (string, error) {
+	text := strings.TrimPrefix(string(src), "\uFEFF")
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	if !strings.HasPrefix(text, frontMatterDelimiter+"\n") {
+		return text, nil
+	}
+	// 保留开头分隔符后的换行 紧跟着的空 front matter 也能用 "\n---\n" 找到结束的分隔符行
+	rest := text[len(frontMatterDelimiter):]
+	end := strings.Index(rest, "\n"+frontMatterDelimiter+"\n")
+	var body string
+	switch {
+	case end >= 0:
+		body = rest[end+len(frontMatterDelimiter)+2:]
+	case strings.HasSuffix(rest, "\n"+frontMatterDelimiter):
+		// 只有 front matter 没有正文
+		end = len(rest) - len(frontMatterDelimiter) - 1
+	default:
+		return "", ErrInvalidFrontMatter
+	}
+	head := rest[:end+1]
+	if err := yaml.Unmarshal([]byte(head), meta); err != nil {
+		return "", errors.Wrap(ErrInvalidFrontMatter, err.Error())
+	}
+	return body, nil
+}
@@ -0,0 +1,88 @@
+package mdx
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+type testMeta struct {
+	Title string    `yaml:"title"`
+	Tags  []string  `yaml:"tags,omitempty"`
+	Ctime time.Time `yaml:"ctime,omitempty"`
+}
+
+func TestFrontMatter_RoundTrip(t *testing.T) {
+	meta := testMeta{
+		Title: "标题: 带冒号",
+		Tags:  []string{"go", "后端"},
+		Ctime: time.Date(2024, 5, 1, 8, 30, 0, 0, time.UTC),
+	}
+	body := "# 正文\n\n---\n\n分割线之后\n"
+	data, err := MarshalFrontMatter(meta, body)
+	require.NoError(t, err)
+
+	var got testMeta
+	gotBody, err := UnmarshalFrontMatter(data, &got)
+	require.NoError(t, err)
+	assert.Equal(t, body, gotBody)
+	assert.Equal(t, meta.Title, got.Title)
+	assert.Equal(t, meta.Tags, got.Tags)
+	assert.True(t, meta.Ctime.Equal(got.Ctime))
+}
+
+func TestUnmarshalFrontMatter(t *testing.T) {
+	testCases := []struct {
+		name     string
+		src      string
+		wantMeta testMeta
+		wantBody string
+		wantErr  error
+	}{
+		{
+			name:     "没有front matter",
+			src:      "# 标题\n正文",
+			wantBody: "# 标题\n正文",
+		},
+		{
+			name:     "windows换行和BOM",
+			src:      "\uFEFF---\r\ntitle: a\r\n---\r\nbody\r\n",
+			wantMeta: testMeta{Title: "a"},
+			wantBody: "body\n",
+		},
+		{
+			name:     "空front matter",
+			src:      "---\n---\nbody",
+			wantBody: "body",
+		},
+		{
+			name:     "只有front matter",
+			src:      "---\ntitle: a\n---",
+			wantMeta: testMeta{Title: "a"},
+		},
+		{
+			name:    "没有结束分隔符",
+			src:     "---\ntitle: a\nbody",
+			wantErr: ErrInvalidFrontMatter,
+		},
+		{
+			name:    "YAML格式错误",
+			src:     "---\ntitle: [a\n---\nbody",
+			wantErr: ErrInvalidFrontMatter,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var meta testMeta
+			body, err := UnmarshalFrontMatter([]byte(tc.src), &meta)
+			if tc.wantErr != nil {
+				assert.ErrorIs(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantMeta, meta)
+			assert.Equal(t, tc.wantBody, body)
+		})
+	}
+}
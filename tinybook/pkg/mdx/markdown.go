@@ -0,0 +1,161 @@
+package mdx
+
+import (
+	"bytes"
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer/html"
+	"github.com/yuin/goldmark/text"
+	"regexp"
+	"strings"
+)
+
+const (
+	AbstractLength = 128 // 摘要的字符数
+
+	cjkPerMinute  = 300 // 中文每分钟阅读字数
+	wordPerMinute = 200 // 英文每分钟阅读单词数
+)
+
+var (
+	md = goldmark.New(
+		goldmark.WithExtensions(extension.GFM),
+		goldmark.WithParserOptions(parser.WithAutoHeadingID()),
+		// 原样输出 Markdown 中的 HTML，统一交给 policy 过滤
+		goldmark.WithRendererOptions(html.WithUnsafe()),
+	)
+	policy = newPolicy()
+)
+
+// Heading 目录中的一项 Anchor 与渲染后标题的 id 一致
+type Heading struct {
+	Level  int
+	Title  string
+	Anchor string
+}
+
+// Result Markdown 渲染结果
+type Result struct {
+	HTML        string // 过滤过 XSS 的 HTML
+	Text        string // 去掉标记、代码块、图片后的纯文本
+	Abstract    string
+	WordCount   int
+	ReadingTime int // 预计阅读分钟数
+	TOC         []Heading
+}
+
+// Render 解析 Markdown，输出安全的 HTML 以及摘要、字数、阅读时间和目录
+func Render(source string) (Result, error) {
+	src := []byte(source)
+	doc := parse(src)
+	var buf bytes.Buffer
+	if err := md.Renderer().Render(&buf, src, doc); err != nil {
+		return Result{}, err
+	}
+	plain, toc := extract(doc, src)
+	cjk, words := countWords(plain)
+	return Result{
+		HTML:        policy.Sanitize(buf.String()),
+		Text:        plain,
+		Abstract:    Abstract(plain, AbstractLength),
+		WordCount:   cjk + words,
+		ReadingTime: readingTime(cjk, words),
+		TOC:         toc,
+	}, nil
+}
+
+// PlainText 只提取 Markdown 的纯文本
+func PlainText(source string) string {
+	src := []byte(source)
+	plain, _ := extract(parse(src), src)
+	return plain
+}
+
+// Abstract 合并空白后截取前 n 个字符，被截断时以 ... 结尾
+func Abstract(plain string, n int) string {
+	runes := []rune(strings.Join(strings.Fields(plain), " "))
+	if len(runes) <= n {
+		return string(runes)
+	}
+	return strings.TrimSpace(string(runes[:n])) + "..."
+}
+
+func parse(src []byte) ast.Node {
+	ctx := parser.NewContext(parser.WithIDs(newIDs()))
+	return md.Parser().Parse(text.NewReader(src), parser.WithContext(ctx))
+}
+
+// extract 遍历语法树，提取纯文本与目录
+func extract(doc ast.Node, src []byte) (string, []Heading) {
+	var (
+		sb  strings.Builder
+		toc []Heading
+	)
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			if n.Type() == ast.TypeBlock {
+				sb.WriteByte('\n')
+			}
+			return ast.WalkContinue, nil
+		}
+		switch node := n.(type) {
+		case *ast.FencedCodeBlock, *ast.CodeBlock, *ast.HTMLBlock, *ast.RawHTML, *ast.Image:
+			return ast.WalkSkipChildren, nil
+		case *ast.Heading:
+			heading := Heading{Level: node.Level, Title: inlineText(node, src)}
+			if id, ok := node.AttributeString("id"); ok {
+				if b, ok := id.([]byte); ok {
+					heading.Anchor = string(b)
+				}
+			}
+			toc = append(toc, heading)
+		case *ast.Text:
+			sb.Write(node.Segment.Value(src))
+			if node.SoftLineBreak() || node.HardLineBreak() {
+				sb.WriteByte(' ')
+			}
+		case *ast.String:
+			sb.Write(node.Value)
+		case *ast.AutoLink:
+			sb.Write(node.Label(src))
+		}
+		return ast.WalkContinue, nil
+	})
+	return strings.TrimSpace(sb.String()), toc
+}
+
+// inlineText 标题等行内节点的纯文本
+func inlineText(n ast.Node, src []byte) string {
+	var sb strings.Builder
+	_ = ast.Walk(n, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch node := n.(type) {
+		case *ast.RawHTML, *ast.Image:
+			return ast.WalkSkipChildren, nil
+		case *ast.Text:
+			sb.Write(node.Segment.Value(src))
+		case *ast.String:
+			sb.Write(node.Value)
+		}
+		return ast.WalkContinue, nil
+	})
+	return strings.TrimSpace(sb.String())
+}
+
+func newPolicy() *bluemonday.Policy {
+	p := bluemonday.UGCPolicy()
+	// 标题的 id 用于目录跳转，默认策略不允许中文 id
+	p.AllowAttrs("id").Matching(regexp.MustCompile(`^[\p{L}\p{N}_-]+$`)).
+		OnElements("h1", "h2", "h3", "h4", "h5", "h6")
+	// 代码块的语言，用于前端语法高亮
+	p.AllowAttrs("class").Matching(regexp.MustCompile(`^language-[\w+#-]+$`)).OnElements("code")
+	// GFM 任务列表
+	p.AllowAttrs("type").Matching(regexp.MustCompile(`^checkbox$`)).OnElements("input")
+	p.AllowAttrs("checked", "disabled").OnElements("input")
+	return p
+}
@@ -0,0 +1,91 @@
+package mdx
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+)
+
+func TestRender_Sanitize(t *testing.T) {
+	testCases := []struct {
+		name    string
+		source  string
+		want    []string // 渲染结果中应包含的片段
+		notWant []string // 渲染结果中不应包含的片段
+	}{
+		{
+			name:    "脚本标签",
+			source:  "hello <script>alert(1)</script>",
+			want:    []string{"hello"},
+			notWant: []string{"<script", "alert(1)"},
+		},
+		{
+			name:    "事件属性",
+			source:  `<img src="x.png" onerror="alert(1)">`,
+			want:    []string{`<img src="x.png"`},
+			notWant: []string{"onerror"},
+		},
+		{
+			name:    "javascript 链接",
+			source:  "[点我](javascript:alert(1))",
+			notWant: []string{"javascript:"},
+		},
+		{
+			name:   "代码块语言",
+			source: "```go\nfmt.Println(1)\n```",
+			want:   []string{`<code class="language-go">`},
+		},
+		{
+			name:   "中文标题id",
+			source: "## 第一章 开始",
+			want:   []string{`<h2 id="第一章-开始">`},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			res, err := Render(tc.source)
+			require.NoError(t, err)
+			for _, s := range tc.want {
+				assert.Contains(t, res.HTML, s)
+			}
+			for _, s := range tc.notWant {
+				assert.NotContains(t, res.HTML, s)
+			}
+		})
+	}
+}
+
+func TestRender_Meta(t *testing.T) {
+	source := `# 标题
+
+正文 **加粗** 和 [链接](https://example.com)。
+
+## 小节
+Hello world
+
+## 小节
+
+` + "```\n代码不计入字数\n```\n![图片](a.png)"
+	res, err := Render(source)
+	require.NoError(t, err)
+	assert.Equal(t, []Heading{
+		{Level: 1, Title: "标题", Anchor: "标题"},
+		{Level: 2, Title: "小节", Anchor: "小节"},
+		{Level: 2, Title: "小节", Anchor: "小节-1"},
+	}, res.TOC)
+	assert.Equal(t, "标题 正文 加粗 和 链接。 小节 Hello world 小节", res.Abstract)
+	// 标题2 + 正文7 + 小节2*2 = 13 个汉字，Hello world 2 个单词
+	assert.Equal(t, 15, res.WordCount)
+	assert.Equal(t, 1, res.ReadingTime)
+}
+
+func TestAbstract(t *testing.T) {
+	assert.Equal(t, "a b", Abstract(" a\n\n b ", 10))
+	assert.Equal(t, "一二三...", Abstract("一二三四五", 3))
+	long := strings.Repeat("字", 3000)
+	res, err := Render(long)
+	require.NoError(t, err)
+	assert.Equal(t, 10, res.ReadingTime)
+	assert.Equal(t, 3000, res.WordCount)
+}
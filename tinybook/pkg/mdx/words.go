@@ -0,0 +1,80 @@
+package mdx
+
+import (
+	"github.com/yuin/goldmark/ast"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// countWords 统计中日韩文字数与其他语言的单词数
+func countWords(plain string) (cjk int, words int) {
+	inWord := false
+	for _, r := range plain {
+		switch {
+		case unicode.Is(unicode.Han, r) || unicode.Is(unicode.Hiragana, r) ||
+			unicode.Is(unicode.Katakana, r) || unicode.Is(unicode.Hangul, r):
+			cjk++
+			inWord = false
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if !inWord {
+				words++
+				inWord = true
+			}
+		default:
+			inWord = false
+		}
+	}
+	return cjk, words
+}
+
+// readingTime 预计阅读分钟数 有内容时至少为1分钟
+func readingTime(cjk int, words int) int {
+	if cjk+words == 0 {
+		return 0
+	}
+	minutes := float64(cjk)/cjkPerMinute + float64(words)/wordPerMinute
+	if minutes <= 1 {
+		return 1
+	}
+	return int(minutes + 0.5)
+}
+
+// ids 生成标题的 id 保留中文等字母与数字，重复时追加序号
+// goldmark 默认的实现会丢掉所有非 ASCII 字符，中文标题都会变成 heading、heading-1 ...
+type ids struct {
+	values map[string]struct{}
+}
+
+func newIDs() *ids {
+	return &ids{values: make(map[string]struct{})}
+}
+
+func (s *ids) Generate(value []byte, kind ast.NodeKind) []byte {
+	var sb strings.Builder
+	for _, r := range strings.TrimSpace(string(value)) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			sb.WriteRune(unicode.ToLower(r))
+		case unicode.IsSpace(r) || r == '-' || r == '_':
+			sb.WriteByte('-')
+		}
+	}
+	id := sb.String()
+	if id == "" {
+		id = "heading"
+	}
+	res := id
+	for i := 1; ; i++ {
+		if _, ok := s.values[res]; !ok {
+			break
+		}
+		res = id + "-" + strconv.Itoa(i)
+	}
+	s.values[res] = struct{}{}
+	return []byte(res)
+}
+
+func (s *ids) Put(value []byte) {
+	s.values[string(value)] = struct{}{}
+}
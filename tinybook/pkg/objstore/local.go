@@ -0,0 +1,120 @@
+package objstore
+
+import (
+	"context"
+	"github.com/cockroachdb/errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// LocalStore 保存在本地目录中 对象按 key 的前两位分到子目录，避免单个目录下文件过多
+// 只适合单实例部署或者挂载了共享存储的场景
+type LocalStore struct {
+	root string
+}
+
+func NewLocalStore(root string) (*LocalStore, error) {
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, err
+	}
+	return &LocalStore{root: root}, nil
+}
+
+// Put 先写临时文件再改名，读取时不会看到写了一半的对象
+func (l *LocalStore) Put(ctx context.Context, key string, data []byte) error {
+	path, err := l.path(key)
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(path)
+	if err = os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // 改名成功后删除会失败，忽略即可
+	if _, err = tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func (l *LocalStore) Get(ctx context.Context, key string) ([]byte, error) {
+	path, err := l.path(key)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return data, err
+}
+
+func (l *LocalStore) Stat(ctx context.Context, key string) (Object, error) {
+	path, err := l.path(key)
+	if err != nil {
+		return Object{}, err
+	}
+	info, err := os.Stat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return Object{}, ErrNotFound
+	}
+	if err != nil {
+		return Object{}, err
+	}
+	return Object{Key: key, ModTime: info.ModTime()}, nil
+}
+
+func (l *LocalStore) Delete(ctx context.Context, key string) error {
+	path, err := l.path(key)
+	if err != nil {
+		return err
+	}
+	err = os.Remove(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// Walk 跳过写了一半的临时文件
+func (l *LocalStore) Walk(ctx context.Context, fn func(obj Object) error) error {
+	return filepath.WalkDir(l.root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err = ctx.Err(); err != nil {
+			return err
+		}
+		if d.IsDir() || !validKey(d.Name()) {
+			return nil
+		}
+		info, err := d.Info()
+		if errors.Is(err, fs.ErrNotExist) {
+			// 遍历期间被删除
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		return fn(Object{Key: d.Name(), ModTime: info.ModTime()})
+	})
+}
+
+func (l *LocalStore) path(key string) (string, error) {
+	if !validKey(key) {
+		return "", ErrInvalidKey
+	}
+	if len(key) < 2 {
+		return filepath.Join(l.root, key), nil
+	}
+	return filepath.Join(l.root, key[:2], key), nil
+}
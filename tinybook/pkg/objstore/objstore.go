@@ -0,0 +1,52 @@
+// Package objstore 按 key 存取不可变对象的对象存储
+package objstore
+
+import (
+	"context"
+	"github.com/cockroachdb/errors"
+	"time"
+)
+
+var (
+	ErrNotFound   = errors.New("对象不存在")
+	ErrInvalidKey = errors.New("对象的 key 不合法")
+)
+
+// Store 对象存储 同一个 key 重复写入相同的内容是幂等的
+type Store interface {
+	Put(ctx context.Context, key string, data []byte) error
+	// Get 对象不存在时返回 ErrNotFound
+	Get(ctx context.Context, key string) ([]byte, error)
+	// Stat 获取对象最后写入的时间 对象不存在时返回 ErrNotFound
+	Stat(ctx context.Context, key string) (Object, error)
+	// Delete 对象不存在时不返回错误
+	Delete(ctx context.Context, key string) error
+	// Walk 遍历所有对象 fn 返回错误时停止遍历并返回该错误，遍历期间写入或删除的对象可能遍历不到
+	Walk(ctx context.Context, fn func(obj Object) error) error
+}
+
+// Object 对象的 key 和最后写入的时间 重复写入相同的内容也会更新 ModTime
+type Object struct {
+	Key     string
+	ModTime time.Time
+}
+
+// ContentKey 以内容的 sha256 作为 key，相同的内容只保存一份
+func ContentKey(data []byte) string {
+	return sha256Hex(data)
+}
+
+// validKey key 只能由字母、数字和 -_. 组成且不能以 . 开头，避免拼接路径时越过根目录
+func validKey(key string) bool {
+	if key == "" || key[0] == '.' || len(key) > 255 {
+		return false
+	}
+	for _, c := range key {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '-', c == '_', c == '.':
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,282 @@
+package objstore
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"github.com/cockroachdb/errors"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// S3Config 兼容 S3 协议的对象存储 使用路径风格的地址 {Endpoint}/{Bucket}/{Prefix}{key}
+// 除了 AWS S3 之外，MinIO、Ceph RGW 以及各家云厂商的兼容接口都可以使用
+type S3Config struct {
+	Endpoint  string `yaml:"endpoint"` // 例如 http://127.0.0.1:9000
+	Region    string `yaml:"region"`
+	Bucket    string `yaml:"bucket"`
+	AccessKey string `yaml:"accessKey"`
+	SecretKey string `yaml:"secretKey"`
+	Prefix    string `yaml:"prefix"`
+}
+
+// S3Store 直接用 AWS 签名 V4 调用 PutObject/GetObject/HeadObject/DeleteObject/ListObjectsV2，不依赖 SDK
+type S3Store struct {
+	cfg    S3Config
+	client *http.Client
+	now    func() time.Time
+}
+
+func NewS3Store(cfg S3Config, client *http.Client) *S3Store {
+	if cfg.Region == "" {
+		cfg.Region = "us-east-1"
+	}
+	cfg.Endpoint = strings.TrimSuffix(cfg.Endpoint, "/")
+	return &S3Store{cfg: cfg, client: client, now: time.Now}
+}
+
+func (s *S3Store) Put(ctx context.Context, key string, data []byte) error {
+	resp, err := s.do(ctx, http.MethodPut, key, data)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return s.statusErr(resp)
+	}
+	return nil
+}
+
+func (s *S3Store) Get(ctx context.Context, key string) ([]byte, error) {
+	resp, err := s.do(ctx, http.MethodGet, key, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return io.ReadAll(resp.Body)
+	case http.StatusNotFound:
+		return nil, ErrNotFound
+	default:
+		return nil, s.statusErr(resp)
+	}
+}
+
+// Stat HeadObject 的 Last-Modified 只精确到秒
+func (s *S3Store) Stat(ctx context.Context, key string) (Object, error) {
+	resp, err := s.do(ctx, http.MethodHead, key, nil)
+	if err != nil {
+		return Object{}, err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return Object{}, ErrNotFound
+	default:
+		return Object{}, s.statusErr(resp)
+	}
+	modTime, err := http.ParseTime(resp.Header.Get("Last-Modified"))
+	if err != nil {
+		return Object{}, errors.Wrap(err, "解析 Last-Modified 失败")
+	}
+	return Object{Key: key, ModTime: modTime}, nil
+}
+
+func (s *S3Store) Delete(ctx context.Context, key string) error {
+	resp, err := s.do(ctx, http.MethodDelete, key, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusNoContent, http.StatusNotFound:
+		return nil
+	default:
+		return s.statusErr(resp)
+	}
+}
+
+// listBucketResult ListObjectsV2 的响应 只解析用到的字段
+type listBucketResult struct {
+	Contents []struct {
+		Key          string    `xml:"Key"`
+		LastModified time.Time `xml:"LastModified"`
+	} `xml:"Contents"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+// Walk 按 Prefix 分页列出 bucket 中的对象 每页最多 1000 个
+func (s *S3Store) Walk(ctx context.Context, fn func(obj Object) error) error {
+	var token string
+	for {
+		query := url.Values{"list-type": {"2"}, "prefix": {s.cfg.Prefix}}
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+		res, err := s.list(ctx, query)
+		if err != nil {
+			return err
+		}
+		for _, item := range res.Contents {
+			key := strings.TrimPrefix(item.Key, s.cfg.Prefix)
+			if !validKey(key) {
+				// 前缀下不是由 Put 写入的对象
+				continue
+			}
+			if err = fn(Object{Key: key, ModTime: item.LastModified}); err != nil {
+				return err
+			}
+		}
+		if !res.IsTruncated || res.NextContinuationToken == "" {
+			return nil
+		}
+		token = res.NextContinuationToken
+	}
+}
+
+func (s *S3Store) list(ctx context.Context, query url.Values) (listBucketResult, error) {
+	resp, err := s.send(ctx, http.MethodGet, s.cfg.Endpoint+"/"+s.cfg.Bucket+"?"+canonicalQuery(query), nil)
+	if err != nil {
+		return listBucketResult{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return listBucketResult{}, s.statusErr(resp)
+	}
+	var res listBucketResult
+	if err = xml.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return listBucketResult{}, errors.Wrap(err, "解析对象列表失败")
+	}
+	return res, nil
+}
+
+func (s *S3Store) do(ctx context.Context, method string, key string, body []byte) (*http.Response, error) {
+	if !validKey(key) {
+		return nil, ErrInvalidKey
+	}
+	return s.send(ctx, method, s.cfg.Endpoint+"/"+s.cfg.Bucket+"/"+s.cfg.Prefix+key, body)
+}
+
+func (s *S3Store) send(ctx context.Context, method string, rawURL string, body []byte) (*http.Response, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if method == http.MethodPut {
+		req.Header.Set("Content-Type", "application/octet-stream")
+	}
+	signV4(req, sha256Hex(body), s.cfg, s.now())
+	return s.client.Do(req)
+}
+
+// statusErr 错误响应的内容是 XML 格式的错误码和说明，只截取一部分放到错误中
+func (s *S3Store) statusErr(resp *http.Response) error {
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	return errors.Newf("对象存储返回 %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+}
+
+// signV4 按 AWS 签名 V4 给请求加上 Authorization 请求头 Host 以及已经设置的请求头都参与签名
+func signV4(req *http.Request, payloadHash string, cfg S3Config, now time.Time) {
+	now = now.UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		uriEncode(req.URL.Path, false),
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	scope := date + "/" + cfg.Region + "/s3/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+
+	key := hmacSHA256([]byte("AWS4"+cfg.SecretKey), date)
+	key = hmacSHA256(key, cfg.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential="+cfg.AccessKey+"/"+scope+
+		", SignedHeaders="+signedHeaders+", Signature="+signature)
+}
+
+func canonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var parts []string
+	for _, k := range keys {
+		values := query[k]
+		sort.Strings(values)
+		for _, v := range values {
+			parts = append(parts, uriEncode(k, true)+"="+uriEncode(v, true))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// uriEncode 除了 A-Za-z0-9-_.~ 之外都要编码，路径中的 / 保留
+func uriEncode(s string, encodeSlash bool) string {
+	const hexChars = "0123456789ABCDEF"
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '-', c == '_', c == '.', c == '~':
+			sb.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			sb.WriteByte(c)
+		default:
+			sb.WriteByte('%')
+			sb.WriteByte(hexChars[c>>4])
+			sb.WriteByte(hexChars[c&15])
+		}
+	}
+	return sb.String()
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,90 @@
+package searchx
+
+import (
+	"html"
+	"strings"
+)
+
+const (
+	HighlightPre  = "<em>"
+	HighlightPost = "</em>"
+)
+
+// Highlight 将文本中命中查询词的部分用 <em></em> 包裹，其余部分做 HTML 转义
+// maxLen 大于0时截取以第一个命中位置为中心、不超过 maxLen 个字符的片段，截断处用 ... 表示
+func Highlight(text string, query string, maxLen int) string {
+	terms := make(map[string]struct{})
+	for _, term := range Terms(query) {
+		terms[term] = struct{}{}
+	}
+	// 命中区间 中文二元组会互相重叠，需要合并
+	var spans [][2]int
+	for _, token := range Tokenize(text) {
+		if _, ok := terms[token.Term]; !ok {
+			continue
+		}
+		if n := len(spans); n > 0 && token.Start <= spans[n-1][1] {
+			if token.End > spans[n-1][1] {
+				spans[n-1][1] = token.End
+			}
+			continue
+		}
+		spans = append(spans, [2]int{token.Start, token.End})
+	}
+
+	start, end := 0, len(text)
+	if maxLen > 0 && runeLen(text) > maxLen {
+		start, end = window(text, spans, maxLen)
+	}
+
+	var sb strings.Builder
+	if start > 0 {
+		sb.WriteString("...")
+	}
+	cur := start
+	for _, span := range spans {
+		if span[1] <= cur || span[0] >= end {
+			continue
+		}
+		s, e := max(span[0], cur), min(span[1], end)
+		sb.WriteString(html.EscapeString(text[cur:s]))
+		sb.WriteString(HighlightPre)
+		sb.WriteString(html.EscapeString(text[s:e]))
+		sb.WriteString(HighlightPost)
+		cur = e
+	}
+	sb.WriteString(html.EscapeString(text[cur:end]))
+	if end < len(text) {
+		sb.WriteString("...")
+	}
+	return sb.String()
+}
+
+// window 计算截取片段的字节区间 第一个命中位置前保留约四分之一的上下文
+func window(text string, spans [][2]int, maxLen int) (int, int) {
+	// 每个字符的起始字节偏移
+	offsets := make([]int, 0, len(text))
+	for i := range text {
+		offsets = append(offsets, i)
+	}
+	first := 0
+	if len(spans) > 0 {
+		for idx, off := range offsets {
+			if off >= spans[0][0] {
+				first = idx
+				break
+			}
+		}
+	}
+	from := max(first-maxLen/4, 0)
+	to := from + maxLen
+	if to > len(offsets) {
+		to = len(offsets)
+		from = max(to-maxLen, 0)
+	}
+	end := len(text)
+	if to < len(offsets) {
+		end = offsets[to]
+	}
+	return offsets[from], end
+}
@@ -0,0 +1,177 @@
+package searchx
+
+import (
+	"math"
+	"sort"
+	"sync"
+)
+
+// BM25 参数
+const (
+	k1 = 1.2
+	b  = 0.75
+)
+
+// Field 文档中参与检索的字段 Boost 为字段权重，例如标题命中比正文命中更重要
+type Field struct {
+	Name  string
+	Text  string
+	Boost float64
+}
+
+type Document struct {
+	ID     int64
+	Fields []Field
+}
+
+// Hit 检索命中的文档
+type Hit struct {
+	ID    int64
+	Score float64
+}
+
+type docEntry struct {
+	terms  []string // 文档包含的词，删除时用于清理倒排表
+	length float64  // 文档的加权长度
+}
+
+// Index 内存倒排索引 并发安全
+// 词 -> 文档ID -> 加权词频，打分使用 BM25，多个查询词之间是 AND 关系
+type Index struct {
+	mu       sync.RWMutex
+	postings map[string]map[int64]float64
+	docs     map[int64]docEntry
+	totalLen float64
+}
+
+func NewIndex() *Index {
+	return &Index{
+		postings: make(map[string]map[int64]float64),
+		docs:     make(map[int64]docEntry),
+	}
+}
+
+// Put 新增或覆盖一篇文档
+func (i *Index) Put(doc Document) {
+	tf := make(map[string]float64)
+	var length float64
+	for _, field := range doc.Fields {
+		boost := field.Boost
+		if boost <= 0 {
+			boost = 1
+		}
+		for _, token := range Tokenize(field.Text) {
+			tf[token.Term] += boost
+			length += boost
+		}
+	}
+	terms := make([]string, 0, len(tf))
+	for term := range tf {
+		terms = append(terms, term)
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.remove(doc.ID)
+	for term, freq := range tf {
+		posting, ok := i.postings[term]
+		if !ok {
+			posting = make(map[int64]float64)
+			i.postings[term] = posting
+		}
+		posting[doc.ID] = freq
+	}
+	i.docs[doc.ID] = docEntry{terms: terms, length: length}
+	i.totalLen += length
+}
+
+// Delete 删除文档 文档不存在时什么也不做
+func (i *Index) Delete(id int64) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.remove(id)
+}
+
+func (i *Index) remove(id int64) {
+	entry, ok := i.docs[id]
+	if !ok {
+		return
+	}
+	for _, term := range entry.terms {
+		posting := i.postings[term]
+		delete(posting, id)
+		if len(posting) == 0 {
+			delete(i.postings, term)
+		}
+	}
+	delete(i.docs, id)
+	i.totalLen -= entry.length
+}
+
+// Len 索引中的文档数
+func (i *Index) Len() int {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	return len(i.docs)
+}
+
+// Search 检索同时包含所有查询词的文档，按得分倒序分页返回，同时返回命中总数
+func (i *Index) Search(query string, limit int, offset int) ([]Hit, int) {
+	terms := Terms(query)
+	if len(terms) == 0 {
+		return nil, 0
+	}
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	// 从文档数最少的词开始求交集，减少遍历
+	postings := make([]map[int64]float64, 0, len(terms))
+	for _, term := range terms {
+		posting, ok := i.postings[term]
+		if !ok {
+			return nil, 0
+		}
+		postings = append(postings, posting)
+	}
+	sort.Slice(postings, func(x, y int) bool {
+		return len(postings[x]) < len(postings[y])
+	})
+
+	n := float64(len(i.docs))
+	avgLen := i.totalLen / n
+	hits := make([]Hit, 0, len(postings[0]))
+	for id := range postings[0] {
+		var score float64
+		matched := true
+		for _, posting := range postings {
+			freq, ok := posting[id]
+			if !ok {
+				matched = false
+				break
+			}
+			df := float64(len(posting))
+			idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+			norm := k1 * (1 - b + b*i.docs[id].length/avgLen)
+			score += idf * freq * (k1 + 1) / (freq + norm)
+		}
+		if matched {
+			hits = append(hits, Hit{ID: id, Score: score})
+		}
+	}
+	sort.Slice(hits, func(x, y int) bool {
+		if hits[x].Score != hits[y].Score {
+			return hits[x].Score > hits[y].Score
+		}
+		return hits[x].ID > hits[y].ID // 得分相同时新文章(ID大)在前，保证分页稳定
+	})
+
+	total := len(hits)
+	if offset >= total {
+		return nil, total
+	}
+	end := offset + limit
+	if end > total {
+		end = total
+	}
+	return hits[offset:end], total
+}
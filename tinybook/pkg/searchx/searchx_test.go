@@ -0,0 +1,162 @@
+package searchx
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestTerms(t *testing.T) {
+	testCases := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{
+			name: "英文转小写",
+			text: "Hello, Go World! go",
+			want: []string{"hello", "go", "world"},
+		},
+		{
+			name: "中文二元组",
+			text: "数据库",
+			want: []string{"数据", "据库"},
+		},
+		{
+			name: "单个汉字",
+			text: "a 文 b",
+			want: []string{"a", "文", "b"},
+		},
+		{
+			name: "中英混合",
+			text: "使用Redis缓存",
+			want: []string{"使用", "redis", "缓存"},
+		},
+		{
+			name: "空文本",
+			text: " ,.!",
+			want: []string{},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, Terms(tc.text))
+		})
+	}
+}
+
+func TestIndex_Search(t *testing.T) {
+	idx := NewIndex()
+	idx.Put(Document{ID: 1, Fields: []Field{
+		{Name: "title", Text: "Go 语言并发编程", Boost: 2},
+		{Name: "content", Text: "goroutine 和 channel"},
+	}})
+	idx.Put(Document{ID: 2, Fields: []Field{
+		{Name: "title", Text: "MySQL 数据库索引", Boost: 2},
+		{Name: "content", Text: "B+树与 Go 语言无关"},
+	}})
+	idx.Put(Document{ID: 3, Fields: []Field{
+		{Name: "title", Text: "Redis 缓存", Boost: 2},
+		{Name: "content", Text: "缓存穿透与数据库"},
+	}})
+
+	testCases := []struct {
+		name      string
+		query     string
+		limit     int
+		offset    int
+		wantIds   []int64
+		wantTotal int
+	}{
+		{
+			name:      "标题命中排在正文命中之前",
+			query:     "go语言",
+			limit:     10,
+			wantIds:   []int64{1, 2},
+			wantTotal: 2,
+		},
+		{
+			name:      "多个词需要同时命中",
+			query:     "数据库 缓存",
+			limit:     10,
+			wantIds:   []int64{3},
+			wantTotal: 1,
+		},
+		{
+			name:      "分页",
+			query:     "数据库",
+			limit:     1,
+			offset:    1,
+			wantIds:   []int64{3},
+			wantTotal: 2,
+		},
+		{
+			name:      "没有命中",
+			query:     "kafka",
+			limit:     10,
+			wantTotal: 0,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			hits, total := idx.Search(tc.query, tc.limit, tc.offset)
+			var ids []int64
+			for _, hit := range hits {
+				ids = append(ids, hit.ID)
+			}
+			assert.Equal(t, tc.wantIds, ids)
+			assert.Equal(t, tc.wantTotal, total)
+		})
+	}
+}
+
+func TestIndex_PutDelete(t *testing.T) {
+	idx := NewIndex()
+	idx.Put(Document{ID: 1, Fields: []Field{{Text: "旧标题"}}})
+	// 覆盖后旧内容不能再被检索到
+	idx.Put(Document{ID: 1, Fields: []Field{{Text: "新标题"}}})
+	hits, _ := idx.Search("旧标", 10, 0)
+	assert.Empty(t, hits)
+	hits, _ = idx.Search("新标题", 10, 0)
+	assert.Len(t, hits, 1)
+
+	idx.Delete(1)
+	hits, _ = idx.Search("新标题", 10, 0)
+	assert.Empty(t, hits)
+	assert.Equal(t, 0, idx.Len())
+	assert.Empty(t, idx.postings)
+}
+
+func TestHighlight(t *testing.T) {
+	testCases := []struct {
+		name   string
+		text   string
+		query  string
+		maxLen int
+		want   string
+	}{
+		{
+			name:  "重叠的中文命中合并",
+			text:  "关系型数据库设计",
+			query: "数据库",
+			want:  "关系型<em>数据库</em>设计",
+		},
+		{
+			name:  "英文不区分大小写",
+			text:  "Learn Go <fast>",
+			query: "go",
+			want:  "Learn <em>Go</em> &lt;fast&gt;",
+		},
+		{
+			name:   "截取命中附近的片段",
+			text:   "一二三四五六七八九十数据库十一十二十三",
+			query:  "数据库",
+			maxLen: 8,
+			want:   "...九十<em>数据库</em>十一十...",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, Highlight(tc.text, tc.query, tc.maxLen))
+		})
+	}
+}
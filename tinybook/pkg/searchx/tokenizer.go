@@ -0,0 +1,94 @@
+package searchx
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Token 分词结果 Start/End 为词在原文中的字节偏移，用于高亮
+type Token struct {
+	Term  string
+	Start int
+	End   int
+}
+
+// Tokenize 对文本分词
+// 英文与数字按连续的字母数字切分并转小写；中日韩文字没有天然分隔符，按二元组(bigram)切分，
+// 例如 "数据库" 切成 "数据" "据库"，单独的一个汉字保留为一个词。
+// 查询词与文档使用相同的分词方式，因此连续的中文查询可以命中包含该片段的文档。
+func Tokenize(text string) []Token {
+	var (
+		res   []Token
+		start = -1  // 当前字母数字词的起始位置
+		cjk   []int // 当前连续中文片段中每个字的起始位置
+	)
+	flushWord := func(end int) {
+		if start >= 0 {
+			res = append(res, Token{Term: strings.ToLower(text[start:end]), Start: start, End: end})
+			start = -1
+		}
+	}
+	flushCJK := func(end int) {
+		switch len(cjk) {
+		case 0:
+			return
+		case 1:
+			res = append(res, Token{Term: text[cjk[0]:end], Start: cjk[0], End: end})
+		default:
+			for i := 0; i+1 < len(cjk); i++ {
+				e := end
+				if i+2 < len(cjk) {
+					e = cjk[i+2]
+				}
+				res = append(res, Token{Term: text[cjk[i]:e], Start: cjk[i], End: e})
+			}
+		}
+		cjk = cjk[:0]
+	}
+	for i, r := range text {
+		switch {
+		case isCJK(r):
+			flushWord(i)
+			cjk = append(cjk, i)
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			flushCJK(i)
+			if start < 0 {
+				start = i
+			}
+		default:
+			flushWord(i)
+			flushCJK(i)
+		}
+	}
+	flushWord(len(text))
+	flushCJK(len(text))
+	return res
+}
+
+// Terms 返回去重后的词，保持首次出现的顺序
+func Terms(text string) []string {
+	tokens := Tokenize(text)
+	seen := make(map[string]struct{}, len(tokens))
+	res := make([]string, 0, len(tokens))
+	for _, token := range tokens {
+		if _, ok := seen[token.Term]; ok {
+			continue
+		}
+		seen[token.Term] = struct{}{}
+		res = append(res, token.Term)
+	}
+	return res
+}
+
+func isCJK(r rune) bool {
+	return unicode.Is(unicode.Han, r) ||
+		unicode.Is(unicode.Hiragana, r) ||
+		unicode.Is(unicode.Katakana, r) ||
+		unicode.Is(unicode.Hangul, r)
+}
+
+// runeLen 字符数
+func runeLen(s string) int {
+	return utf8.RuneCountInString(s)
+}
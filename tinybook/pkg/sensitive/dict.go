@@ -0,0 +1,145 @@
+package sensitive
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Result 一段文本的检查结果 Block 为禁止出现的词，Review 为需要人工审核的词
+type Result struct {
+	Block  []string
+	Review []string
+}
+
+func (r Result) Blocked() bool {
+	return len(r.Block) > 0
+}
+
+func (r Result) NeedReview() bool {
+	return len(r.Review) > 0
+}
+
+// Dict 分级的敏感词词典 可以在运行时整体替换(热加载)，替换过程中的检查使用旧的词典
+type Dict struct {
+	v atomic.Pointer[levels]
+}
+
+type levels struct {
+	block  *Matcher
+	review *Matcher
+}
+
+// NewDict 空词典 任何文本都能通过检查
+func NewDict() *Dict {
+	d := &Dict{}
+	d.Load(nil, nil)
+	return d
+}
+
+// Load 用新的词替换整个词典
+func (d *Dict) Load(block []string, review []string) {
+	d.v.Store(&levels{block: NewMatcher(block), review: NewMatcher(review)})
+}
+
+// Size 两个级别各自的词数
+func (d *Dict) Size() (block int, review int) {
+	l := d.v.Load()
+	return l.block.Len(), l.review.Len()
+}
+
+// Check 检查多段文本 同一个词只出现一次，已经是 Block 的词不会再出现在 Review 中
+func (d *Dict) Check(texts ...string) Result {
+	l := d.v.Load()
+	var res Result
+	seen := map[string]struct{}{}
+	collect := func(words []string, dst *[]string) {
+		for _, w := range words {
+			if _, ok := seen[w]; !ok {
+				seen[w] = struct{}{}
+				*dst = append(*dst, w)
+			}
+		}
+	}
+	for _, text := range texts {
+		collect(l.block.FindAll(text), &res.Block)
+	}
+	for _, text := range texts {
+		collect(l.review.FindAll(text), &res.Review)
+	}
+	return res
+}
+
+// Parse 解析词典文件 每行一个词，[block] 与 [review] 分节，
+// 没有分节的词按 review 处理，# 开头的行为注释
+func Parse(r io.Reader) (block []string, review []string, err error) {
+	dst := &review
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		switch {
+		case text == "" || strings.HasPrefix(text, "#"):
+		case text == "[block]":
+			dst = &block
+		case text == "[review]":
+			dst = &review
+		case strings.HasPrefix(text, "[") && strings.HasSuffix(text, "]"):
+			return nil, nil, fmt.Errorf("第 %d 行: 未知的分节 %s", line, text)
+		default:
+			*dst = append(*dst, text)
+		}
+	}
+	return block, review, scanner.Err()
+}
+
+// LoadFile 从文件加载词典 失败时保留原来的词典
+func (d *Dict) LoadFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	block, review, err := Parse(f)
+	if err != nil {
+		return err
+	}
+	d.Load(block, review)
+	return nil
+}
+
+// WatchFile 每隔 interval 检查一次文件的修改时间和大小，变化后重新加载 直到 ctx 结束
+// 第一次检查总会重新加载，避免错过启动监听之前的修改
+// 加载失败时调用 onErr 并继续使用原来的词典
+func (d *Dict) WatchFile(ctx context.Context, path string, interval time.Duration, onErr func(error)) {
+	var modTime time.Time
+	var size int64 = -1
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			onErr(err)
+			continue
+		}
+		if info.ModTime().Equal(modTime) && info.Size() == size {
+			continue
+		}
+		// 同一个版本的文件只加载一次 加载失败也要等文件再次修改
+		modTime, size = info.ModTime(), info.Size()
+		if err = d.LoadFile(path); err != nil {
+			onErr(err)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package sensitive
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Matcher 基于 Aho-Corasick 自动机的多模式匹配 一次扫描找出文本中出现的所有敏感词
+// 匹配时忽略大小写 构建完成后只读，可以并发使用
+type Matcher struct {
+	nodes []node
+	words []string
+}
+
+type node struct {
+	next map[rune]int
+	fail int
+	// 以该节点结尾的词在 words 中的下标 -1 表示不是词尾
+	word int
+	// 沿 fail 链能到达的最近的词尾节点 0 表示没有
+	out int
+}
+
+func newNode() node {
+	return node{next: map[rune]int{}, word: -1}
+}
+
+// NewMatcher 空白和重复的词会被忽略
+func NewMatcher(words []string) *Matcher {
+	m := &Matcher{nodes: []node{newNode()}}
+	for _, w := range words {
+		m.insert(strings.TrimSpace(w))
+	}
+	m.build()
+	return m
+}
+
+func (m *Matcher) insert(word string) {
+	if word == "" {
+		return
+	}
+	cur := 0
+	for _, r := range word {
+		r = unicode.ToLower(r)
+		nxt, ok := m.nodes[cur].next[r]
+		if !ok {
+			nxt = len(m.nodes)
+			m.nodes = append(m.nodes, newNode())
+			m.nodes[cur].next[r] = nxt
+		}
+		cur = nxt
+	}
+	if m.nodes[cur].word < 0 {
+		m.nodes[cur].word = len(m.words)
+		m.words = append(m.words, word)
+	}
+}
+
+// build 按层序计算 fail 指针和输出链接
+func (m *Matcher) build() {
+	queue := make([]int, 0, len(m.nodes))
+	for _, child := range m.nodes[0].next {
+		queue = append(queue, child)
+	}
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		for r, child := range m.nodes[cur].next {
+			f := m.nodes[cur].fail
+			for f != 0 {
+				if _, ok := m.nodes[f].next[r]; ok {
+					break
+				}
+				f = m.nodes[f].fail
+			}
+			if nxt, ok := m.nodes[f].next[r]; ok && nxt != child {
+				m.nodes[child].fail = nxt
+			}
+			fail := m.nodes[child].fail
+			if m.nodes[fail].word >= 0 {
+				m.nodes[child].out = fail
+			} else {
+				m.nodes[child].out = m.nodes[fail].out
+			}
+			queue = append(queue, child)
+		}
+	}
+}
+
+// Len 词的数量
+func (m *Matcher) Len() int {
+	return len(m.words)
+}
+
+// FindAll 返回文本中出现的敏感词 按第一次出现的位置排序且不重复
+func (m *Matcher) FindAll(text string) []string {
+	var res []string
+	seen := map[int]struct{}{}
+	m.scan(text, func(word int) bool {
+		if _, ok := seen[word]; !ok {
+			seen[word] = struct{}{}
+			res = append(res, m.words[word])
+		}
+		return true
+	})
+	return res
+}
+
+// Contains 文本中是否出现了任意一个敏感词 找到第一个就返回
+func (m *Matcher) Contains(text string) bool {
+	found := false
+	m.scan(text, func(word int) bool {
+		found = true
+		return false
+	})
+	return found
+}
+
+// scan 每匹配到一个词调用一次 hit 返回 false 时停止扫描
+func (m *Matcher) scan(text string, hit func(word int) bool) {
+	if len(m.words) == 0 {
+		return
+	}
+	cur := 0
+	for _, r := range text {
+		r = unicode.ToLower(r)
+		for cur != 0 {
+			if _, ok := m.nodes[cur].next[r]; ok {
+				break
+			}
+			cur = m.nodes[cur].fail
+		}
+		cur = m.nodes[cur].next[r] // 不存在时回到根节点
+		for n := cur; n != 0; n = m.nodes[n].out {
+			if w := m.nodes[n].word; w >= 0 && !hit(w) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package sensitive
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMatcher_FindAll(t *testing.T) {
+	testCases := []struct {
+		name  string
+		words []string
+		text  string
+		want  []string
+	}{
+		{
+			name:  "重叠的词",
+			words: []string{"he", "she", "his", "hers"},
+			text:  "ushers",
+			want:  []string{"she", "he", "hers"},
+		},
+		{
+			name:  "中文",
+			words: []string{"赌博", "博彩", "彩票"},
+			text:  "这里有赌博彩票",
+			want:  []string{"赌博", "博彩", "彩票"},
+		},
+		{
+			name:  "忽略大小写",
+			words: []string{"VPN"},
+			text:  "免费 vpn 下载",
+			want:  []string{"VPN"},
+		},
+		{
+			name:  "重复出现只返回一次",
+			words: []string{"代购"},
+			text:  "代购代购代购",
+			want:  []string{"代购"},
+		},
+		{
+			name:  "失配后跳转",
+			words: []string{"abcd", "bce"},
+			text:  "abce",
+			want:  []string{"bce"},
+		},
+		{
+			name:  "空白和重复的词",
+			words: []string{"", "  ", "a", "a"},
+			text:  "aaa",
+			want:  []string{"a"},
+		},
+		{
+			name: "空词典",
+			text: "anything",
+		},
+		{
+			name:  "没有命中",
+			words: []string{"赌博"},
+			text:  "今天天气不错",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			m := NewMatcher(tc.words)
+			assert.Equal(t, tc.want, m.FindAll(tc.text))
+			assert.Equal(t, len(tc.want) > 0, m.Contains(tc.text))
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	src := `# 默认按 review 处理
+代购
+[block]
+赌博
+
+ 毒品 
+[review]
+VPN
+`
+	block, review, err := Parse(strings.NewReader(src))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"赌博", "毒品"}, block)
+	assert.Equal(t, []string{"代购", "VPN"}, review)
+
+	_, _, err = Parse(strings.NewReader("[unknown]\nword"))
+	assert.Error(t, err)
+}
+
+func TestDict_Check(t *testing.T) {
+	d := NewDict()
+	assert.Equal(t, Result{}, d.Check("赌博"))
+
+	d.Load([]string{"赌博"}, []string{"代购", "赌博"})
+	res := d.Check("标题里有代购", "正文里有赌博和代购")
+	assert.True(t, res.Blocked())
+	assert.Equal(t, []string{"赌博"}, res.Block)
+	assert.Equal(t, []string{"代购"}, res.Review)
+
+	res = d.Check("正常的内容")
+	assert.False(t, res.Blocked())
+	assert.False(t, res.NeedReview())
+}
+
+func TestDict_WatchFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "words.txt")
+	require.NoError(t, os.WriteFile(path, []byte("[block]\n赌博\n"), 0644))
+	d := NewDict()
+	require.NoError(t, d.LoadFile(path))
+	assert.True(t, d.Check("赌博").Blocked())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	errs := make(chan error, 10)
+	go d.WatchFile(ctx, path, 10*time.Millisecond, func(err error) { errs <- err })
+
+	// 格式错误时保留原来的词典
+	require.NoError(t, os.WriteFile(path, []byte("[bad]\n"), 0644))
+	select {
+	case err := <-errs:
+		assert.Error(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("没有报告加载错误")
+	}
+	assert.True(t, d.Check("赌博").Blocked())
+
+	require.NoError(t, os.WriteFile(path, []byte("[review]\n代购\n"), 0644))
+	later := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(path, later, later))
+	assert.Eventually(t, func() bool {
+		res := d.Check("赌博代购")
+		return !res.Blocked() && res.NeedReview()
+	}, time.Second, 10*time.Millisecond)
+}
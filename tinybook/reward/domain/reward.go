@@ -0,0 +1,75 @@
+package domain
+
+const (
+	MinRewardAmount int64 = 1     // 单次打赏最少 1 分
+	MaxRewardAmount int64 = 50000 // 单次打赏最多 500 元
+)
+
+type RewardStatus uint8
+
+func (s RewardStatus) AsUint8() uint8 {
+	return uint8(s)
+}
+
+const (
+	RewardStatusUnknown RewardStatus = iota
+	RewardStatusInit                 // 已下单 等待支付
+	RewardStatusPayed                // 支付成功
+	RewardStatusFailed               // 支付失败或已关闭
+)
+
+// Target 被打赏的资源 例如文章 Uid 为资源作者 即收款人
+type Target struct {
+	Biz     string
+	BizId   int64
+	BizName string
+	Uid     int64
+}
+
+// Reward 打赏记录 Uid 为打赏人 金额单位为分
+type Reward struct {
+	ID     int64
+	Uid    int64
+	Target Target
+	Amt    int64
+	Status RewardStatus
+	Ctime  int64
+	Utime  int64
+}
+
+// CodeURL 支付二维码 Rid 用于查询打赏结果
+type CodeURL struct {
+	Rid int64  `json:"rid"`
+	URL string `json:"url"`
+}
+
+type RewardVo struct {
+	Id       int64  `json:"id"`
+	Biz      string `json:"biz"`
+	BizId    int64  `json:"bizId"`
+	Title    string `json:"title"`
+	AuthorId int64  `json:"authorId"`
+	Amt      int64  `json:"amt"`
+	Status   uint8  `json:"status"`
+	Ctime    string `json:"ctime"`
+}
+
+type RewardListVo struct {
+	Rewards    []RewardVo `json:"rewards"`
+	NextCursor int64      `json:"nextCursor"` // 为 0 表示没有下一页
+}
+
+// Earning 某个资源累计收到的打赏 只统计支付成功的
+type Earning struct {
+	Biz   string `json:"biz"`
+	BizId int64  `json:"bizId"`
+	Title string `json:"title"`
+	Total int64  `json:"total"`
+	Count int64  `json:"count"`
+}
+
+type EarningsVo struct {
+	Total    int64     `json:"total"`
+	Count    int64     `json:"count"`
+	Articles []Earning `json:"articles"`
+}
@@ -0,0 +1,136 @@
+package events
+
+import (
+	"context"
+	"github.com/bytedance/sonic"
+	"github.com/cockroachdb/errors"
+	"github.com/segmentio/kafka-go"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+	"strings"
+	"time"
+	paydomain "tinybook/tinybook/payment/domain"
+	payevents "tinybook/tinybook/payment/events"
+	"tinybook/tinybook/pkg/kafkax"
+	"tinybook/tinybook/reward/domain"
+	"tinybook/tinybook/reward/service"
+)
+
+const (
+	GroupReward = "group-reward"
+
+	// 更新打赏失败后等待 retryBackoff * 2^(n-1) 再重试 最多等待 maxRetryBackoff
+	retryBackoff    = 200 * time.Millisecond
+	maxRetryBackoff = 10 * time.Second
+)
+
+// PaymentEventConsumer 监听支付结果 更新打赏状态
+type PaymentEventConsumer struct {
+	reader *kafka.Reader
+	svc    service.RewardService
+	log    *zap.Logger
+}
+
+func NewPaymentEventConsumer(svc service.RewardService, log *zap.Logger) *PaymentEventConsumer {
+	return &PaymentEventConsumer{
+		reader: initReader(GroupReward, payevents.TopicPaymentEvents),
+		svc:    svc,
+		log:    log,
+	}
+}
+
+func (p *PaymentEventConsumer) Start() {
+	go func() {
+		p.Consume(context.Background())
+	}()
+}
+
+func (p *PaymentEventConsumer) Consume(ctx context.Context) {
+	defer func(reader *kafka.Reader) {
+		err := reader.Close()
+		if err != nil {
+			p.log.Error("close kafka consumer failed", zap.Error(err))
+		}
+	}(p.reader)
+	for {
+		message, err := p.reader.FetchMessage(ctx)
+		if err != nil {
+			p.log.Error("fetch message failed", zap.Error(err))
+			if !kafkax.ShouldRetryFetch(ctx, err) {
+				return
+			}
+			continue
+		}
+		// 成功之前不能提交 提交后面的消息会把这条一起提交掉，支付结果就丢了
+		if !p.handleWithRetry(ctx, message) {
+			return
+		}
+		err = p.reader.CommitMessages(ctx, message)
+		if err != nil {
+			p.log.Error("commit message failed", zap.Error(err))
+		}
+	}
+}
+
+// handleWithRetry 更新打赏状态是幂等的 失败后一直重试直到成功，ctx 结束时返回 false
+func (p *PaymentEventConsumer) handleWithRetry(ctx context.Context, message kafka.Message) bool {
+	backoff := retryBackoff
+	for i := 1; ; i++ {
+		err := p.handle(ctx, message)
+		if err == nil {
+			return true
+		}
+		p.log.Error("update reward failed", zap.Error(err), zap.Int("attempt", i),
+			zap.Int64("offset", message.Offset))
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, maxRetryBackoff)
+	}
+}
+
+func (p *PaymentEventConsumer) handle(ctx context.Context, message kafka.Message) error {
+	var evt payevents.PaymentEvent
+	err := sonic.Unmarshal(message.Value, &evt)
+	if err != nil {
+		// 格式错误的消息重试也没用
+		p.log.Error("consumer unmarshal message failed", zap.Error(err))
+		return nil
+	}
+	var status domain.RewardStatus
+	switch evt.Status {
+	case paydomain.PaymentStatusSuccess:
+		status = domain.RewardStatusPayed
+	case paydomain.PaymentStatusFailed:
+		status = domain.RewardStatusFailed
+	default:
+		// 其它状态打赏不关心
+		return nil
+	}
+	err = p.svc.UpdateReward(ctx, evt.BizTradeNO, status)
+	if errors.Is(err, service.ErrUnknownBizTradeNO) {
+		return nil
+	}
+	return errors.Wrapf(err, "biz_trade_no: %s", evt.BizTradeNO)
+}
+
+func initReader(groupId string, topic string) *kafka.Reader {
+	type config struct {
+		Brokers string `yaml:"brokers"`
+	}
+	var cfg config
+	err := viper.UnmarshalKey("kafka", &cfg)
+	if err != nil {
+		panic(err)
+	}
+	return kafka.NewReader(kafka.ReaderConfig{
+		Brokers:        strings.Split(cfg.Brokers, ","),
+		GroupID:        groupId,
+		Topic:          topic,
+		MaxWait:        500 * time.Millisecond,
+		CommitInterval: 0,                 // 同步提交
+		StartOffset:    kafka.FirstOffset, // 新的消费者组从头消费 不能漏掉支付结果
+	})
+}
@@ -0,0 +1,10 @@
+package dao
+
+import "gorm.io/gorm"
+
+func CreateTableForReward(db *gorm.DB) {
+	err := db.AutoMigrate(&Reward{})
+	if err != nil {
+		panic(err)
+	}
+}
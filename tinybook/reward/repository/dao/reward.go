@@ -0,0 +1,102 @@
+package dao
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"time"
+)
+
+const (
+	RewardStatusInit   uint8 = 1
+	RewardStatusPayed  uint8 = 2
+	RewardStatusFailed uint8 = 3
+)
+
+var ErrRewardNotFound = gorm.ErrRecordNotFound
+
+type Reward struct {
+	ID      int64  `gorm:"column:id;primaryKey;autoIncrement;not null"`
+	Biz     string `gorm:"column:biz;type:varchar(64);not null;index:idx_target,priority:3"`
+	BizId   int64  `gorm:"column:biz_id;not null;index:idx_target,priority:4"`
+	BizName string `gorm:"column:biz_name;type:varchar(256)"`
+	// 收款人 统计作者收入用
+	TargetUid int64 `gorm:"column:target_uid;not null;index:idx_target,priority:1"`
+	// 打赏人
+	Uid    int64 `gorm:"column:uid;not null;index"`
+	Amount int64 `gorm:"column:amount;not null"`
+	Status uint8 `gorm:"column:status;type:tinyint(1);not null;index:idx_target,priority:2"`
+	Ctime  int64 `gorm:"column:ctime;not null"`
+	Utime  int64 `gorm:"column:utime;not null"`
+}
+
+// Earning 按资源聚合的打赏收入
+type Earning struct {
+	Biz     string
+	BizId   int64
+	BizName string
+	Total   int64
+	Cnt     int64
+}
+
+type RewardDAO interface {
+	Insert(ctx context.Context, r Reward) (int64, error)
+	GetById(ctx context.Context, id int64) (Reward, error)
+	// UpdateStatus 只更新待支付的打赏 重复的支付通知不会生效，返回是否更新了
+	UpdateStatus(ctx context.Context, id int64, status uint8) (bool, error)
+	// GetByUid 按ID倒序获取用户的打赏记录 maxId 为 0 表示第一页
+	GetByUid(ctx context.Context, uid int64, maxId int64, limit int) ([]Reward, error)
+	// GetEarnings 作者每个资源收到的打赏 按收入倒序
+	GetEarnings(ctx context.Context, targetUid int64) ([]Earning, error)
+}
+
+type GormRewardDAO struct {
+	db *gorm.DB
+}
+
+func NewGormRewardDAO(db *gorm.DB) RewardDAO {
+	return &GormRewardDAO{db: db}
+}
+
+func (g *GormRewardDAO) Insert(ctx context.Context, r Reward) (int64, error) {
+	now := time.Now().Unix()
+	r.Ctime, r.Utime = now, now
+	err := g.db.WithContext(ctx).Create(&r).Error
+	return r.ID, err
+}
+
+func (g *GormRewardDAO) GetById(ctx context.Context, id int64) (Reward, error) {
+	var r Reward
+	err := g.db.WithContext(ctx).Where("id = ?", id).First(&r).Error
+	return r, err
+}
+
+func (g *GormRewardDAO) UpdateStatus(ctx context.Context, id int64, status uint8) (bool, error) {
+	res := g.db.WithContext(ctx).Model(&Reward{}).
+		Where("id = ? AND status = ?", id, RewardStatusInit).
+		Updates(map[string]any{
+			"status": status,
+			"utime":  time.Now().Unix(),
+		})
+	return res.RowsAffected > 0, res.Error
+}
+
+func (g *GormRewardDAO) GetByUid(ctx context.Context, uid int64, maxId int64, limit int) ([]Reward, error) {
+	var rewards []Reward
+	query := g.db.WithContext(ctx).Where("uid = ?", uid)
+	if maxId > 0 {
+		query = query.Where("id < ?", maxId)
+	}
+	err := query.Order("id DESC").Limit(limit).Find(&rewards).Error
+	return rewards, err
+}
+
+func (g *GormRewardDAO) GetEarnings(ctx context.Context, targetUid int64) ([]Earning, error) {
+	var earnings []Earning
+	err := g.db.WithContext(ctx).Model(&Reward{}).
+		Select("biz, biz_id, MAX(biz_name) AS biz_name, SUM(amount) AS total, COUNT(*) AS cnt").
+		Where("target_uid = ? AND status = ?", targetUid, RewardStatusPayed).
+		Group("biz, biz_id").
+		Order("total DESC").
+		Scan(&earnings).Error
+	return earnings, err
+}
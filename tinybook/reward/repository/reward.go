@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+	"github.com/samber/lo"
+	"tinybook/tinybook/reward/domain"
+	"tinybook/tinybook/reward/repository/dao"
+)
+
+var ErrRewardNotFound = dao.ErrRewardNotFound
+
+type RewardRepository interface {
+	CreateReward(ctx context.Context, r domain.Reward) (int64, error)
+	GetReward(ctx context.Context, id int64) (domain.Reward, error)
+	// UpdateStatus 返回是否更新了 已经处理过的打赏不会重复更新
+	UpdateStatus(ctx context.Context, id int64, status domain.RewardStatus) (bool, error)
+	GetByUid(ctx context.Context, uid int64, maxId int64, limit int) ([]domain.Reward, error)
+	GetEarnings(ctx context.Context, targetUid int64) ([]domain.Earning, error)
+}
+
+type rewardRepository struct {
+	dao dao.RewardDAO
+}
+
+func NewRewardRepository(dao dao.RewardDAO) RewardRepository {
+	return &rewardRepository{dao: dao}
+}
+
+func (r *rewardRepository) CreateReward(ctx context.Context, reward domain.Reward) (int64, error) {
+	return r.dao.Insert(ctx, r.toEntity(reward))
+}
+
+func (r *rewardRepository) GetReward(ctx context.Context, id int64) (domain.Reward, error) {
+	reward, err := r.dao.GetById(ctx, id)
+	if err != nil {
+		return domain.Reward{}, err
+	}
+	return r.toDomain(reward), nil
+}
+
+func (r *rewardRepository) UpdateStatus(ctx context.Context, id int64, status domain.RewardStatus) (bool, error) {
+	return r.dao.UpdateStatus(ctx, id, status.AsUint8())
+}
+
+func (r *rewardRepository) GetByUid(ctx context.Context, uid int64, maxId int64, limit int) ([]domain.Reward, error) {
+	rewards, err := r.dao.GetByUid(ctx, uid, maxId, limit)
+	if err != nil {
+		return nil, err
+	}
+	return lo.Map(rewards, func(reward dao.Reward, index int) domain.Reward {
+		return r.toDomain(reward)
+	}), nil
+}
+
+func (r *rewardRepository) GetEarnings(ctx context.Context, targetUid int64) ([]domain.Earning, error) {
+	earnings, err := r.dao.GetEarnings(ctx, targetUid)
+	if err != nil {
+		return nil, err
+	}
+	return lo.Map(earnings, func(e dao.Earning, index int) domain.Earning {
+		return domain.Earning{
+			Biz:   e.Biz,
+			BizId: e.BizId,
+			Title: e.BizName,
+			Total: e.Total,
+			Count: e.Cnt,
+		}
+	}), nil
+}
+
+func (r *rewardRepository) toEntity(reward domain.Reward) dao.Reward {
+	return dao.Reward{
+		ID:        reward.ID,
+		Biz:       reward.Target.Biz,
+		BizId:     reward.Target.BizId,
+		BizName:   reward.Target.BizName,
+		TargetUid: reward.Target.Uid,
+		Uid:       reward.Uid,
+		Amount:    reward.Amt,
+		Status:    reward.Status.AsUint8(),
+	}
+}
+
+func (r *rewardRepository) toDomain(reward dao.Reward) domain.Reward {
+	return domain.Reward{
+		ID:  reward.ID,
+		Uid: reward.Uid,
+		Target: domain.Target{
+			Biz:     reward.Biz,
+			BizId:   reward.BizId,
+			BizName: reward.BizName,
+			Uid:     reward.TargetUid,
+		},
+		Amt:    reward.Amount,
+		Status: domain.RewardStatus(reward.Status),
+		Ctime:  reward.Ctime,
+		Utime:  reward.Utime,
+	}
+}
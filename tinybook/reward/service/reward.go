@@ -0,0 +1,196 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"github.com/cockroachdb/errors"
+	"github.com/samber/lo"
+	"strconv"
+	"strings"
+	"time"
+	paydomain "tinybook/tinybook/payment/domain"
+	"tinybook/tinybook/reward/domain"
+	"tinybook/tinybook/reward/repository"
+)
+
+// 打赏在支付模块里的业务单号前缀 支付事件是所有业务共用的，靠前缀区分
+const bizTradeNOPrefix = "reward-"
+
+// 微信支付的商品描述最多 127 个字符
+const maxDescriptionLength = 60
+
+var (
+	ErrInvalidAmount     = errors.New("打赏金额不合法")
+	ErrRewardSelf        = errors.New("不能打赏自己")
+	ErrRewardNotFound    = errors.New("打赏记录不存在")
+	ErrBizNotFound       = errors.New("打赏对象不存在")
+	ErrUnknownBizTradeNO = errors.New("不是打赏业务的支付单号")
+
+	ErrPaymentUnavailable = errors.New("暂未开通打赏支付")
+)
+
+// PaymentService 支付模块 由 wechat.NativePaymentService 实现
+type PaymentService interface {
+	// Prepay 下单 返回支付二维码链接
+	Prepay(ctx context.Context, pmt paydomain.Payment) (string, error)
+}
+
+// TargetResolver 查询被打赏的资源 资源不存在时返回 ErrBizNotFound
+type TargetResolver interface {
+	Resolve(ctx context.Context, biz string, bizId int64) (domain.Target, error)
+}
+
+// TargetResolverFunc 函数形式的 TargetResolver
+type TargetResolverFunc func(ctx context.Context, biz string, bizId int64) (domain.Target, error)
+
+func (f TargetResolverFunc) Resolve(ctx context.Context, biz string, bizId int64) (domain.Target, error) {
+	return f(ctx, biz, bizId)
+}
+
+type RewardService interface {
+	// PreReward 创建打赏并下单 返回支付二维码
+	PreReward(ctx context.Context, r domain.Reward) (domain.CodeURL, error)
+	// UpdateReward 根据支付结果更新打赏状态 不是打赏的单号返回 ErrUnknownBizTradeNO
+	UpdateReward(ctx context.Context, bizTradeNO string, status domain.RewardStatus) error
+	// GetReward 打赏人查询打赏结果
+	GetReward(ctx context.Context, rid int64, uid int64) (domain.RewardVo, error)
+	// ListByUid 打赏人的打赏记录 cursor 为上一页返回的 NextCursor
+	ListByUid(ctx context.Context, uid int64, cursor int64, limit int) (domain.RewardListVo, error)
+	// Earnings 作者每篇文章收到的打赏
+	Earnings(ctx context.Context, uid int64) (domain.EarningsVo, error)
+}
+
+type rewardService struct {
+	repo     repository.RewardRepository
+	payment  PaymentService
+	resolver TargetResolver
+}
+
+func NewRewardService(repo repository.RewardRepository, payment PaymentService, resolver TargetResolver) RewardService {
+	return &rewardService{repo: repo, payment: payment, resolver: resolver}
+}
+
+func (s *rewardService) PreReward(ctx context.Context, r domain.Reward) (domain.CodeURL, error) {
+	if r.Amt < domain.MinRewardAmount || r.Amt > domain.MaxRewardAmount {
+		return domain.CodeURL{}, ErrInvalidAmount
+	}
+	target, err := s.resolver.Resolve(ctx, r.Target.Biz, r.Target.BizId)
+	if err != nil {
+		return domain.CodeURL{}, err
+	}
+	if target.Uid == r.Uid {
+		return domain.CodeURL{}, ErrRewardSelf
+	}
+	r.Target = target
+	r.Status = domain.RewardStatusInit
+	rid, err := s.repo.CreateReward(ctx, r)
+	if err != nil {
+		return domain.CodeURL{}, err
+	}
+	url, err := s.payment.Prepay(ctx, paydomain.Payment{
+		Amt: paydomain.Amount{
+			Currency: "CNY",
+			Total:    r.Amt,
+		},
+		BizTradeNO:  s.bizTradeNO(rid),
+		Description: s.description(target),
+	})
+	if errors.Is(err, ErrPaymentUnavailable) {
+		// 没有开通支付 这条打赏不会再被支付 尽力关掉即可
+		_, _ = s.repo.UpdateStatus(ctx, rid, domain.RewardStatusFailed)
+		return domain.CodeURL{}, err
+	}
+	if err != nil {
+		// 超时等错误时微信那边可能已经下单成功，保持初始状态，等支付结果或者对账来更新
+		return domain.CodeURL{}, err
+	}
+	return domain.CodeURL{Rid: rid, URL: url}, nil
+}
+
+func (s *rewardService) UpdateReward(ctx context.Context, bizTradeNO string, status domain.RewardStatus) error {
+	rid, ok := s.parseBizTradeNO(bizTradeNO)
+	if !ok {
+		return ErrUnknownBizTradeNO
+	}
+	_, err := s.repo.UpdateStatus(ctx, rid, status)
+	return err
+}
+
+func (s *rewardService) GetReward(ctx context.Context, rid int64, uid int64) (domain.RewardVo, error) {
+	r, err := s.repo.GetReward(ctx, rid)
+	if errors.Is(err, repository.ErrRewardNotFound) {
+		return domain.RewardVo{}, ErrRewardNotFound
+	}
+	if err != nil {
+		return domain.RewardVo{}, err
+	}
+	// 只能查自己的打赏
+	if r.Uid != uid {
+		return domain.RewardVo{}, ErrRewardNotFound
+	}
+	return s.toVo(r), nil
+}
+
+func (s *rewardService) ListByUid(ctx context.Context, uid int64, cursor int64, limit int) (domain.RewardListVo, error) {
+	// 多查一条判断是否还有下一页
+	rewards, err := s.repo.GetByUid(ctx, uid, cursor, limit+1)
+	if err != nil {
+		return domain.RewardListVo{}, err
+	}
+	var next int64
+	if len(rewards) > limit {
+		rewards = rewards[:limit]
+		next = rewards[limit-1].ID
+	}
+	return domain.RewardListVo{
+		Rewards:    lo.Map(rewards, func(r domain.Reward, index int) domain.RewardVo { return s.toVo(r) }),
+		NextCursor: next,
+	}, nil
+}
+
+func (s *rewardService) Earnings(ctx context.Context, uid int64) (domain.EarningsVo, error) {
+	earnings, err := s.repo.GetEarnings(ctx, uid)
+	if err != nil {
+		return domain.EarningsVo{}, err
+	}
+	res := domain.EarningsVo{Articles: earnings}
+	for _, e := range earnings {
+		res.Total += e.Total
+		res.Count += e.Count
+	}
+	return res, nil
+}
+
+func (s *rewardService) bizTradeNO(rid int64) string {
+	return bizTradeNOPrefix + strconv.FormatInt(rid, 10)
+}
+
+func (s *rewardService) parseBizTradeNO(bizTradeNO string) (int64, bool) {
+	raw, ok := strings.CutPrefix(bizTradeNO, bizTradeNOPrefix)
+	if !ok {
+		return 0, false
+	}
+	rid, err := strconv.ParseInt(raw, 10, 64)
+	return rid, err == nil && rid > 0
+}
+
+func (s *rewardService) description(target domain.Target) string {
+	title := []rune(target.BizName)
+	if len(title) > maxDescriptionLength {
+		title = append(title[:maxDescriptionLength], []rune("...")...)
+	}
+	return fmt.Sprintf("打赏: %s", string(title))
+}
+
+func (s *rewardService) toVo(r domain.Reward) domain.RewardVo {
+	return domain.RewardVo{
+		Id:       r.ID,
+		Biz:      r.Target.Biz,
+		BizId:    r.Target.BizId,
+		Title:    r.Target.BizName,
+		AuthorId: r.Target.Uid,
+		Amt:      r.Amt,
+		Status:   r.Status.AsUint8(),
+		Ctime:    time.Unix(r.Ctime, 0).Format("2006-01-02 15:04:05"),
+	}
+}
@@ -0,0 +1,121 @@
+package web
+
+import (
+	"github.com/cockroachdb/errors"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"net/http"
+	"strconv"
+	"tinybook/tinybook/internal/web/jwt"
+	"tinybook/tinybook/reward/service"
+)
+
+type RewardHandler struct {
+	rewardService service.RewardService
+	l             *zap.Logger
+}
+
+func NewRewardHandler(rewardService service.RewardService, l *zap.Logger) *RewardHandler {
+	return &RewardHandler{rewardService: rewardService, l: l}
+}
+
+// Detail 查询打赏结果 扫码后前端轮询
+func (h *RewardHandler) Detail(ctx *gin.Context) {
+	type Req struct {
+		Rid int64 `json:"rid" binding:"required"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 400,
+			Msg:  "参数错误",
+		})
+		return
+	}
+	claims := (ctx.MustGet("userClaims")).(jwt.UserClaims)
+	reward, err := h.rewardService.GetReward(ctx, req.Rid, claims.Uid)
+	if err != nil {
+		h.handleServiceErr(ctx, err, "查询打赏失败, 打赏ID: "+strconv.FormatInt(req.Rid, 10))
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Code: 200,
+		Msg:  "获取成功",
+		Data: reward,
+	})
+}
+
+// History 当前用户的打赏记录
+func (h *RewardHandler) History(ctx *gin.Context) {
+	type Req struct {
+		Cursor int64 `json:"cursor"`
+		Limit  int   `json:"limit"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil || !h.checkPage(req.Cursor, &req.Limit) {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 400,
+			Msg:  "参数错误",
+		})
+		return
+	}
+	claims := (ctx.MustGet("userClaims")).(jwt.UserClaims)
+	rewards, err := h.rewardService.ListByUid(ctx, claims.Uid, req.Cursor, req.Limit)
+	if err != nil {
+		h.handleServiceErr(ctx, err, "获取打赏记录失败, 用户ID: "+strconv.FormatInt(claims.Uid, 10))
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Code: 200,
+		Msg:  "获取成功",
+		Data: rewards,
+	})
+}
+
+// Earnings 当前作者每篇文章收到的打赏
+func (h *RewardHandler) Earnings(ctx *gin.Context) {
+	claims := (ctx.MustGet("userClaims")).(jwt.UserClaims)
+	earnings, err := h.rewardService.Earnings(ctx, claims.Uid)
+	if err != nil {
+		h.handleServiceErr(ctx, err, "获取打赏收入失败, 用户ID: "+strconv.FormatInt(claims.Uid, 10))
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Code: 200,
+		Msg:  "获取成功",
+		Data: earnings,
+	})
+}
+
+// checkPage 游标不能为负数 每页默认10条 最多50条
+func (h *RewardHandler) checkPage(cursor int64, limit *int) bool {
+	if cursor < 0 || *limit < 0 || *limit > 50 {
+		return false
+	}
+	if *limit == 0 {
+		*limit = 10
+	}
+	return true
+}
+
+func (h *RewardHandler) handleServiceErr(ctx *gin.Context, err error, msg string) {
+	if errors.Is(err, service.ErrRewardNotFound) {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 400,
+			Msg:  err.Error(),
+		})
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Code: 500,
+		Msg:  "服务器错误",
+	})
+	h.l.Error(msg, zap.Error(err))
+}
+
+func (h *RewardHandler) RegisterRoutes(engine *gin.Engine) {
+	group := engine.Group("/rewards")
+	group.POST("/detail", h.Detail)    // 查询打赏结果
+	group.POST("/history", h.History)  // 我的打赏记录
+	group.GET("/earnings", h.Earnings) // 作者打赏收入
+}
@@ -4,12 +4,33 @@ package main
 
 import (
 	"github.com/google/wire"
+	events4 "tinybook/tinybook/analytics/events"
+	repository10 "tinybook/tinybook/analytics/repository"
+	dao10 "tinybook/tinybook/analytics/repository/dao"
+	service9 "tinybook/tinybook/analytics/service"
+	web8 "tinybook/tinybook/analytics/web"
+	"tinybook/tinybook/article/events/invalidation"
 	readcount2 "tinybook/tinybook/article/events/readcount"
 	repository3 "tinybook/tinybook/article/repository"
 	cache3 "tinybook/tinybook/article/repository/cache"
 	dao3 "tinybook/tinybook/article/repository/dao"
+	"tinybook/tinybook/article/repository/search"
 	service3 "tinybook/tinybook/article/service"
 	web2 "tinybook/tinybook/article/web"
+	repository4 "tinybook/tinybook/comment/repository"
+	cache4 "tinybook/tinybook/comment/repository/cache"
+	dao4 "tinybook/tinybook/comment/repository/dao"
+	service4 "tinybook/tinybook/comment/service"
+	web3 "tinybook/tinybook/comment/web"
+	repository6 "tinybook/tinybook/feed/repository"
+	dao6 "tinybook/tinybook/feed/repository/dao"
+	service6 "tinybook/tinybook/feed/service"
+	web5 "tinybook/tinybook/feed/web"
+	repository5 "tinybook/tinybook/follow/repository"
+	cache5 "tinybook/tinybook/follow/repository/cache"
+	dao5 "tinybook/tinybook/follow/repository/dao"
+	service5 "tinybook/tinybook/follow/service"
+	web4 "tinybook/tinybook/follow/web"
 	"tinybook/tinybook/internal/events/consumer"
 	"tinybook/tinybook/internal/job"
 	"tinybook/tinybook/internal/repository"
@@ -19,6 +40,18 @@ import (
 	"tinybook/tinybook/internal/web"
 	"tinybook/tinybook/internal/web/jwt"
 	"tinybook/tinybook/ioc"
+	repository9 "tinybook/tinybook/notification/repository"
+	dao9 "tinybook/tinybook/notification/repository/dao"
+	service8 "tinybook/tinybook/notification/service"
+	web7 "tinybook/tinybook/notification/web"
+	events2 "tinybook/tinybook/payment/events"
+	repository7 "tinybook/tinybook/payment/repository"
+	dao7 "tinybook/tinybook/payment/repository/dao"
+	events3 "tinybook/tinybook/reward/events"
+	repository8 "tinybook/tinybook/reward/repository"
+	dao8 "tinybook/tinybook/reward/repository/dao"
+	service7 "tinybook/tinybook/reward/service"
+	web6 "tinybook/tinybook/reward/web"
 )
 
 // 热榜服务
@@ -47,15 +80,16 @@ var jobServiceProvider = wire.NewSet(
 	repository.NewCronJobRepository,
 	dao.NewGormCronJobDao,
 
-	job.NewScheduler,
+	ioc.InitScheduler,
 	job.NewLocalFuncExecutor,
+	job.NewArticlePublishExecutor,
 )
 
 func InitWebServer() *App {
 
 	wire.Build(
 		// 初始化redis, db, localCache, mongoDB
-		ioc.InitRedis, ioc.InitDB, ioc.InitLocalCache, ioc.InitMongoDB, ioc.InitMongoDBV2,
+		ioc.InitRedis, ioc.InitDB, ioc.InitLocalCache, ioc.InitMongoClient, ioc.InitMongoDB,
 		// 初始化redisLock
 		ioc.InitRedisLock,
 		// 初始化etcd client
@@ -67,20 +101,60 @@ func InitWebServer() *App {
 		// 初始化sms模块
 		ioc.InitSMSService, repository.NewGormSMSRepository, dao.NewGormSMSDAO,
 		// 初始化article模块
-		repository3.NewCachedArticleRepository, dao3.NewMongoDBArticleDAO, service3.NewArticleService, cache3.NewRedisArticleCache,
+		// 配置了 articleSharding.dsns 时文章按作者分库存放在 MySQL 中，否则存放在 MongoDB 中
+		repository3.NewCachedArticleRepository, ioc.InitArticleDAO, service3.NewArticleService, cache3.NewRedisArticleCache,
+		ioc.InitPubArticleCache,
+		// 文章正文保存在对象存储中，按内容的哈希寻址
+		ioc.InitArticleContentStore, ioc.InitArticleContentCache,
+		// 文章写入后通过 kafka 通知所有实例删除缓存
+		invalidation.NewKafkaProducer, invalidation.NewCacheInvalidationConsumer,
+		wire.Bind(new(invalidation.Evictor), new(repository3.ArticleRepository)),
+		search.NewLocalArticleSearcher,
+		// 作者与全站的 RSS/Atom 订阅源
+		ioc.InitSyndicationService,
+		// 未公开文章的分享链接
+		dao3.NewGormShareLinkDAO, repository3.NewShareLinkRepository, ioc.InitShareService,
+		// 文章系列(专栏)
+		dao3.NewGormSeriesDAO, cache3.NewRedisSeriesCache, repository3.NewCachedSeriesRepository, service3.NewSeriesService,
+		// 初始化文章审核 发表前检查敏感词，审核结果通过站内通知告诉作者
+		ioc.InitSensitiveDict, dao3.NewGormReviewDAO, repository3.NewReviewRepository, service3.NewReviewService,
+		// 文章状态变化的审计日志
+		dao3.NewGormStatusLogDAO, repository3.NewStatusLogRepository,
+		ioc.InitReviewNotifier, ioc.InitAdminMiddlewareBuilder,
+		dao9.NewGormNotificationDAO, repository9.NewNotificationRepository, service8.NewNotificationService,
+		// 初始化comment模块
+		dao4.NewGormCommentDAO, cache4.NewRedisCommentCache, repository4.NewCachedCommentRepository,
+		service4.NewCommentService, ioc.InitCommentBizResolver,
+		// 初始化follow、feed模块 文章发表后推送到粉丝的feed
+		dao5.NewGormFollowDAO, cache5.NewRedisFollowCache, repository5.NewCachedFollowRepository, service5.NewFollowService,
+		dao6.NewGormFeedDAO, repository6.NewFeedRepository, ioc.InitFeedService,
+		wire.Bind(new(repository3.PublishListener), new(service6.FeedService)),
+		// 初始化payment、reward模块 打赏通过微信native支付下单，监听支付结果
+		dao7.NewPaymentGORMDAO, repository7.NewPaymentRepository, events2.NewKafkaProducer, ioc.InitNativePaymentService,
+		ioc.InitRewardPaymentService, ioc.InitWechatNotifyHandler, job.NewPaymentSyncJob,
+		dao8.NewGormRewardDAO, repository8.NewRewardRepository, service7.NewRewardService, ioc.InitRewardTargetResolver,
+		events3.NewPaymentEventConsumer,
+		// 初始化作者数据看板 消费阅读、点赞、收藏事件按天汇总
+		dao10.NewGormAnalyticsDAO, repository10.NewAnalyticsRepository, service9.NewAnalyticsService,
+		ioc.InitAnalyticsAuthorResolver, events4.NewAnalyticsConsumer,
 		// 初始化interactive模块
 		interactiveServiceProvider,
 		// 初始化oauth2模块
 		ioc.InitWechatService,
 		// 初始化ranking模块
 		rankingServiceProvider, ioc.InitJobs, ioc.InitRankingJob,
+		// 初始化搜索索引重建、回收站清理任务
+		job.NewSearchIndexJob,
+		job.NewTrashPurgeJob,
 		// 初始化handler
 		web.NewUserHandler, web.NewOAuth2WechatHandler, jwt.NewRedisJWTHandler,
-		web2.NewArticleHandler,
+		web2.NewArticleHandler, web3.NewCommentHandler, web4.NewFollowHandler, web5.NewFeedHandler,
+		web6.NewRewardHandler, web2.NewReviewHandler, web7.NewNotificationHandler, web2.NewSyndicationHandler,
+		web2.NewShareHandler, web2.NewSeriesHandler, web8.NewAnalyticsHandler,
 		// 初始化web 和 中间件
 		ioc.InitWebServer, ioc.InitHandlerFunc, ioc.InitLogger,
 		// 初始化kafka writer
-		ioc.InitWriter,
+		ioc.InitWriter, ioc.InitKafkaBrokers,
 		// 初始化阅读数 read num kafka 生产者
 		readcount2.NewKafkaReadCountProducer,
 		//readcount.NewKafkaReadCountConsumer,
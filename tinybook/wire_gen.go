@@ -8,12 +8,32 @@ package main
 
 import (
 	"github.com/google/wire"
+	events3 "tinybook/tinybook/analytics/events"
+	repository9 "tinybook/tinybook/analytics/repository"
+	dao9 "tinybook/tinybook/analytics/repository/dao"
+	service7 "tinybook/tinybook/analytics/service"
+	web8 "tinybook/tinybook/analytics/web"
+	"tinybook/tinybook/article/events/invalidation"
 	"tinybook/tinybook/article/events/readcount"
-	repository2 "tinybook/tinybook/article/repository"
+	repository4 "tinybook/tinybook/article/repository"
 	cache2 "tinybook/tinybook/article/repository/cache"
-	dao2 "tinybook/tinybook/article/repository/dao"
-	service2 "tinybook/tinybook/article/service"
+	dao4 "tinybook/tinybook/article/repository/dao"
+	"tinybook/tinybook/article/repository/search"
+	service3 "tinybook/tinybook/article/service"
 	web2 "tinybook/tinybook/article/web"
+	repository5 "tinybook/tinybook/comment/repository"
+	cache4 "tinybook/tinybook/comment/repository/cache"
+	dao5 "tinybook/tinybook/comment/repository/dao"
+	service4 "tinybook/tinybook/comment/service"
+	web3 "tinybook/tinybook/comment/web"
+	repository2 "tinybook/tinybook/feed/repository"
+	dao2 "tinybook/tinybook/feed/repository/dao"
+	web5 "tinybook/tinybook/feed/web"
+	repository3 "tinybook/tinybook/follow/repository"
+	cache3 "tinybook/tinybook/follow/repository/cache"
+	dao3 "tinybook/tinybook/follow/repository/dao"
+	service2 "tinybook/tinybook/follow/service"
+	web4 "tinybook/tinybook/follow/web"
 	"tinybook/tinybook/internal/events/consumer"
 	"tinybook/tinybook/internal/job"
 	"tinybook/tinybook/internal/repository"
@@ -23,6 +43,18 @@ import (
 	"tinybook/tinybook/internal/web"
 	"tinybook/tinybook/internal/web/jwt"
 	"tinybook/tinybook/ioc"
+	repository8 "tinybook/tinybook/notification/repository"
+	dao8 "tinybook/tinybook/notification/repository/dao"
+	service6 "tinybook/tinybook/notification/service"
+	web7 "tinybook/tinybook/notification/web"
+	"tinybook/tinybook/payment/events"
+	repository7 "tinybook/tinybook/payment/repository"
+	dao7 "tinybook/tinybook/payment/repository/dao"
+	events2 "tinybook/tinybook/reward/events"
+	repository6 "tinybook/tinybook/reward/repository"
+	dao6 "tinybook/tinybook/reward/repository/dao"
+	service5 "tinybook/tinybook/reward/service"
+	web6 "tinybook/tinybook/reward/web"
 )
 
 import (
@@ -51,34 +83,103 @@ func InitWebServer() *App {
 	userHandler := web.NewUserHandler(userService, codeService, handler)
 	wechatService := ioc.InitWechatService()
 	oAuth2WechatHandler := web.NewOAuth2WechatHandler(wechatService, userService, handler)
-	database := ioc.InitMongoDB(logger)
-	conn := ioc.InitMongoDBV2()
-	articleDAO := dao2.NewMongoDBArticleDAO(database, conn)
+	client := ioc.InitMongoClient(logger)
+	database := ioc.InitMongoDB(client)
+	articleDAO := ioc.InitArticleDAO(client, database, logger)
 	articleCache := cache2.NewRedisArticleCache(cmdable)
-	client := ioc.InitEtcd()
-	interactiveServiceClient := ioc.InitIntrClientV1(client)
-	articleRepository := repository2.NewCachedArticleRepository(articleDAO, articleCache, userRepository, logger, interactiveServiceClient)
+	pubArticleCache := ioc.InitPubArticleCache(theineCache, cmdable)
+	articleSearcher := search.NewLocalArticleSearcher()
+	clientv3Client := ioc.InitEtcd()
+	interactiveServiceClient := ioc.InitIntrClientV1(clientv3Client)
+	feedDAO := dao2.NewGormFeedDAO(db)
+	feedRepository := repository2.NewFeedRepository(feedDAO)
+	followDAO := dao3.NewGormFollowDAO(db)
+	followCache := cache3.NewRedisFollowCache(cmdable)
+	followRepository := repository3.NewCachedFollowRepository(followDAO, followCache, logger)
+	followService := service2.NewFollowService(followRepository, userRepository, logger)
+	feedService := ioc.InitFeedService(feedRepository, followService, logger)
 	writer := ioc.InitWriter()
+	producer := invalidation.NewKafkaProducer(writer)
+	store := ioc.InitArticleContentStore()
+	articleContentCache := ioc.InitArticleContentCache()
+	articleRepository := repository4.NewCachedArticleRepository(articleDAO, articleCache, pubArticleCache, articleSearcher, userRepository, logger, interactiveServiceClient, feedService, producer, store, articleContentCache)
+	reviewDAO := dao4.NewGormReviewDAO(db)
+	reviewRepository := repository4.NewReviewRepository(reviewDAO)
+	statusLogDAO := dao4.NewGormStatusLogDAO(db)
+	statusLogRepository := repository4.NewStatusLogRepository(statusLogDAO)
+	cronJobDao := dao.NewGormCronJobDao(db)
+	cronJobRepository := repository.NewCronJobRepository(cronJobDao)
 	readEventProducer := readcount.NewKafkaReadCountProducer(writer)
-	articleService := service2.NewArticleService(articleRepository, readEventProducer, logger)
-	articleHandler := web2.NewArticleHandler(articleService, logger)
-	engine := ioc.InitWebServer(v, userHandler, oAuth2WechatHandler, articleHandler)
-	v2 := consumer.CollectConsumer()
+	dict := ioc.InitSensitiveDict(logger)
+	articleService := service3.NewArticleService(articleRepository, reviewRepository, statusLogRepository, cronJobRepository, readEventProducer, dict, logger)
+	commentDAO := dao5.NewGormCommentDAO(db)
+	commentCache := cache4.NewRedisCommentCache(cmdable)
+	commentRepository := repository5.NewCachedCommentRepository(commentDAO, commentCache, userRepository, logger)
+	bizResolver := ioc.InitCommentBizResolver(articleRepository)
+	commentService := service4.NewCommentService(commentRepository, bizResolver)
+	rewardDAO := dao6.NewGormRewardDAO(db)
+	rewardRepository := repository6.NewRewardRepository(rewardDAO)
+	paymentDAO := dao7.NewPaymentGORMDAO(db)
+	paymentRepository := repository7.NewPaymentRepository(paymentDAO)
+	eventsProducer := events.NewKafkaProducer(writer)
+	nativePaymentService := ioc.InitNativePaymentService(paymentRepository, eventsProducer, logger)
+	paymentService := ioc.InitRewardPaymentService(nativePaymentService)
+	targetResolver := ioc.InitRewardTargetResolver(articleRepository)
+	rewardService := service5.NewRewardService(rewardRepository, paymentService, targetResolver)
+	seriesDAO := dao4.NewGormSeriesDAO(db)
+	seriesCache := cache2.NewRedisSeriesCache(cmdable)
+	seriesRepository := repository4.NewCachedSeriesRepository(seriesDAO, seriesCache, logger)
+	seriesService := service3.NewSeriesService(seriesRepository, articleRepository)
+	articleHandler := web2.NewArticleHandler(articleService, commentService, rewardService, seriesService, logger)
+	commentHandler := web3.NewCommentHandler(commentService, logger)
+	followHandler := web4.NewFollowHandler(followService, logger)
+	feedHandler := web5.NewFeedHandler(feedService, articleService, logger)
+	rewardHandler := web6.NewRewardHandler(rewardService, logger)
+	notificationDAO := dao8.NewGormNotificationDAO(db)
+	notificationRepository := repository8.NewNotificationRepository(notificationDAO)
+	notificationService := service6.NewNotificationService(notificationRepository)
+	reviewNotifier := ioc.InitReviewNotifier(notificationService)
+	reviewService := service3.NewReviewService(articleRepository, reviewRepository, statusLogRepository, cronJobRepository, reviewNotifier, logger)
+	adminMiddlewareBuilder := ioc.InitAdminMiddlewareBuilder()
+	reviewHandler := web2.NewReviewHandler(reviewService, adminMiddlewareBuilder, logger)
+	notificationHandler := web7.NewNotificationHandler(notificationService, logger)
+	syndicationService := ioc.InitSyndicationService(articleRepository, logger)
+	syndicationHandler := web2.NewSyndicationHandler(syndicationService, logger)
+	shareLinkDAO := dao4.NewGormShareLinkDAO(db)
+	shareLinkRepository := repository4.NewShareLinkRepository(shareLinkDAO)
+	shareService := ioc.InitShareService(articleRepository, shareLinkRepository, userRepository, dict, logger)
+	shareHandler := web2.NewShareHandler(shareService, logger)
+	seriesHandler := web2.NewSeriesHandler(seriesService, logger)
+	analyticsDAO := dao9.NewGormAnalyticsDAO(db)
+	analyticsRepository := repository9.NewAnalyticsRepository(analyticsDAO)
+	authorResolver := ioc.InitAnalyticsAuthorResolver(articleRepository)
+	analyticsService := service7.NewAnalyticsService(analyticsRepository, authorResolver)
+	analyticsHandler := web8.NewAnalyticsHandler(analyticsService, logger)
+	wechatNotifyHandler := ioc.InitWechatNotifyHandler(nativePaymentService, logger)
+	engine := ioc.InitWebServer(v, userHandler, oAuth2WechatHandler, articleHandler, commentHandler, followHandler, feedHandler, rewardHandler, reviewHandler, notificationHandler, syndicationHandler, shareHandler, seriesHandler, analyticsHandler, wechatNotifyHandler)
+	paymentEventConsumer := events2.NewPaymentEventConsumer(rewardService, logger)
+	brokers := ioc.InitKafkaBrokers()
+	cacheInvalidationConsumer := invalidation.NewCacheInvalidationConsumer(articleRepository, brokers, logger)
+	analyticsConsumer := events3.NewAnalyticsConsumer(analyticsService, brokers, logger)
+	v2 := consumer.CollectConsumer(paymentEventConsumer, cacheInvalidationConsumer, analyticsConsumer)
 	rankingCache := cache.NewRedisRankingCache(cmdable)
 	rankingRepository := repository.NewCachedRankingRepository(rankingCache)
 	rankingService := service.NewBatchRankingService(articleService, rankingRepository)
 	redislockClient := ioc.InitRedisLock(cmdable)
 	rankingJob := ioc.InitRankingJob(rankingService, redislockClient, logger)
-	cron := ioc.InitJobs(logger, rankingJob)
-	cronJobDao := dao.NewGormCronJobDao(db)
-	cronJobRepository := repository.NewCronJobRepository(cronJobDao)
+	searchIndexJob := job.NewSearchIndexJob(articleService, logger)
+	trashPurgeJob := job.NewTrashPurgeJob(articleService, redislockClient, logger)
+	paymentSyncJob := job.NewPaymentSyncJob(nativePaymentService, redislockClient, logger)
+	cron := ioc.InitJobs(logger, rankingJob, searchIndexJob, trashPurgeJob, paymentSyncJob)
 	cronJobService := service.NewCronJobService(logger, cronJobRepository)
-	scheduler := job.NewScheduler(cronJobService, logger)
+	articlePublishExecutor := job.NewArticlePublishExecutor(articleService, logger)
+	scheduler := ioc.InitScheduler(cronJobService, logger, articlePublishExecutor)
 	app := &App{
 		server:    engine,
 		consumers: v2,
 		cron:      cron,
 		scheduler: scheduler,
+		searchJob: searchIndexJob,
 	}
 	return app
 }
@@ -92,4 +193,4 @@ var rankingServiceProvider = wire.NewSet(cache.NewRedisRankingCache, repository.
 var interactiveServiceProvider = wire.NewSet(ioc.InitIntrClientV1)
 
 // job 服务
-var jobServiceProvider = wire.NewSet(service.NewCronJobService, repository.NewCronJobRepository, dao.NewGormCronJobDao, job.NewScheduler, job.NewLocalFuncExecutor)
+var jobServiceProvider = wire.NewSet(service.NewCronJobService, repository.NewCronJobRepository, dao.NewGormCronJobDao, ioc.InitScheduler, job.NewLocalFuncExecutor, job.NewArticlePublishExecutor)